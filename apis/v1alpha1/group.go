@@ -32,7 +32,8 @@ import (
 //
 //   - ListGroups
 type GroupSpec struct {
-	InlinePolicies map[string]*string `json:"inlinePolicies,omitempty"`
+	AWSManagedPolicies []*string          `json:"awsManagedPolicies,omitempty"`
+	InlinePolicies     map[string]*string `json:"inlinePolicies,omitempty"`
 	// The name of the group to create. Do not include the path in this value.
 	//
 	// IAM user, group, role, and policy names must be unique within the account.
@@ -57,9 +58,11 @@ type GroupSpec struct {
 	// letters.
 	//
 	// Regex Pattern: `^(\u002F)|(\u002F[\u0021-\u007E]+\u002F)$`
-	Path       *string                                    `json:"path,omitempty"`
-	Policies   []*string                                  `json:"policies,omitempty"`
-	PolicyRefs []*ackv1alpha1.AWSResourceReferenceWrapper `json:"policyRefs,omitempty"`
+	Path                           *string                                    `json:"path,omitempty"`
+	Policies                       []*string                                  `json:"policies,omitempty"`
+	PolicyRefs                     []*ackv1alpha1.AWSResourceReferenceWrapper `json:"policyRefs,omitempty"`
+	RemoveMembersOnDelete          *bool                                      `json:"removeMembersOnDelete,omitempty"`
+	ServiceLastAccessedGranularity *string                                    `json:"serviceLastAccessedGranularity,omitempty"`
 }
 
 // GroupStatus defines the observed state of Group
@@ -86,6 +89,10 @@ type GroupStatus struct {
 	// Regex Pattern: `^[\w]+$`
 	// +kubebuilder:validation:Optional
 	GroupID *string `json:"groupID,omitempty"`
+	// +kubebuilder:validation:Optional
+	RenderedInlinePolicies map[string]*string `json:"renderedInlinePolicies,omitempty"`
+	// +kubebuilder:validation:Optional
+	ServiceLastAccessed *ServiceLastAccessedReport `json:"serviceLastAccessed,omitempty"`
 }
 
 // Group is the Schema for the Groups API
@@ -0,0 +1,91 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1alpha1
+
+import (
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IAMImportSpec defines the existing IAM resources to import as custom
+// resources.
+type IAMImportSpec struct {
+	// The kinds of custom resources to generate, among Role, User, Group,
+	// Policy, InstanceProfile and OpenIDConnectProvider. All of them when
+	// empty.
+	Kinds []*string `json:"kinds,omitempty"`
+	// Only the IAM resources whose path starts with this prefix are imported.
+	// Defaults to /, which imports every path.
+	//
+	// OpenID Connect providers, which have no path, are not imported when a
+	// prefix other than / is set. Service-linked roles and AWS managed
+	// policies are never imported.
+	PathPrefix *string `json:"pathPrefix,omitempty"`
+	// Only the IAM resources carrying all of these tags are imported. A tag
+	// without a value matches any value. Groups, which can not be tagged, are
+	// not imported when tags are set.
+	Tags []*Tag `json:"tags,omitempty"`
+	// The namespace the custom resources are generated in. Defaults to the
+	// namespace of the IAMImport. Another namespace requires the controller to
+	// run with --enable-cross-namespace.
+	TargetNamespace *string `json:"targetNamespace,omitempty"`
+}
+
+// IAMImportStatus defines the observed state of IAMImport
+type IAMImportStatus struct {
+	// Conditions report whether the import completed, and why not.
+	// +kubebuilder:validation:Optional
+	Conditions []*ackv1alpha1.Condition `json:"conditions"`
+	// The custom resources generated for the imported IAM resources. Custom
+	// resources that already existed for the same IAM resource are left
+	// untouched but still listed.
+	// +kubebuilder:validation:Optional
+	ImportedResources []*ImportedResource `json:"importedResources,omitempty"`
+	// The generation of the IAMImport last imported. The import runs again
+	// when the spec changes.
+	// +kubebuilder:validation:Optional
+	ObservedGeneration *int64 `json:"observedGeneration,omitempty"`
+}
+
+// ImportedResource identifies a custom resource generated by an IAMImport
+// and the IAM resource it adopts.
+type ImportedResource struct {
+	ARN  *string `json:"arn,omitempty"`
+	Kind *string `json:"kind,omitempty"`
+	Name *string `json:"name,omitempty"`
+}
+
+// IAMImport generates adopted Role, User, Group, Policy, InstanceProfile and
+// OpenIDConnectProvider custom resources for the existing IAM resources of the account that match
+// its path prefix and tags.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+type IAMImport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              IAMImportSpec   `json:"spec,omitempty"`
+	Status            IAMImportStatus `json:"status,omitempty"`
+}
+
+// IAMImportList contains a list of IAMImport
+// +kubebuilder:object:root=true
+type IAMImportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IAMImport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IAMImport{}, &IAMImportList{})
+}
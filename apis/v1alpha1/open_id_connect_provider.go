@@ -34,7 +34,8 @@ type OpenIDConnectProviderSpec struct {
 	//
 	// There is no defined format for a client ID. The CreateOpenIDConnectProviderRequest
 	// operation accepts client IDs up to 255 characters long.
-	ClientIDs []*string `json:"clientIDs,omitempty"`
+	ClientIDs         []*string `json:"clientIDs,omitempty"`
+	FromClusterIssuer *bool     `json:"fromClusterIssuer,omitempty"`
 	// A list of tags that you want to attach to the new IAM OpenID Connect (OIDC)
 	// provider. Each tag consists of a key name and an associated value. For more
 	// information about tagging, see Tagging IAM resources (https://docs.aws.amazon.com/IAM/latest/UserGuide/id_tags.html)
@@ -42,7 +43,8 @@ type OpenIDConnectProviderSpec struct {
 	//
 	// If any one of the tags is invalid or if you exceed the allowed maximum number
 	// of tags, then the entire request fails and the resource is not created.
-	Tags []*Tag `json:"tags,omitempty"`
+	Tags             []*Tag  `json:"tags,omitempty"`
+	ThumbprintPolicy *string `json:"thumbprintPolicy,omitempty"`
 	// A list of server certificate thumbprints for the OpenID Connect (OIDC) identity
 	// provider's server certificates. Typically this list includes only one entry.
 	// However, IAM lets you have up to five thumbprints for an OIDC provider. This
@@ -77,8 +79,7 @@ type OpenIDConnectProviderSpec struct {
 	// an OpenID Connect provider in the Amazon Web Services account, you will get
 	// an error.
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable once set"
-	// +kubebuilder:validation:Required
-	URL *string `json:"url"`
+	URL *string `json:"url,omitempty"`
 }
 
 // OpenIDConnectProviderStatus defines the observed state of OpenIDConnectProvider
@@ -94,6 +95,8 @@ type OpenIDConnectProviderStatus struct {
 	// resource
 	// +kubebuilder:validation:Optional
 	Conditions []*ackv1alpha1.Condition `json:"conditions"`
+	// +kubebuilder:validation:Optional
+	DiscoveredThumbprint *string `json:"discoveredThumbprint,omitempty"`
 }
 
 // OpenIDConnectProvider is the Schema for the OpenIDConnectProviders API
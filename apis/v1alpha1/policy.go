@@ -31,7 +31,8 @@ import (
 // inline policies (https://docs.aws.amazon.com/IAM/latest/UserGuide/policies-managed-vs-inline.html)
 // in the IAM User Guide.
 type PolicySpec struct {
-
+	AttachedEntitiesLimit *int64  `json:"attachedEntitiesLimit,omitempty"`
+	DeletionMode          *string `json:"deletionMode,omitempty"`
 	// A friendly description of the policy.
 	//
 	// Typically used to store information about the permissions defined in the
@@ -100,6 +101,14 @@ type PolicySpec struct {
 	// Regex Pattern: `^[\u0009\u000A\u000D\u0020-\u00FF]+$`
 	// +kubebuilder:validation:Required
 	PolicyDocument *string `json:"policyDocument"`
+	// Other Kubernetes resources, such as ACK S3 Buckets or SQS Queues, whose
+	// ARN (or the field at fieldPath) replaces the ${ref:<alias>} placeholders
+	// of PolicyDocument. A reference that cannot be resolved blocks creating
+	// and updating the Policy but not deleting it. The controller must be
+	// allowed to get the referenced kinds. It does not watch them: a changed
+	// value is picked up on the next resync of the Policy.
+	ResourceRefs           map[string]*PolicyResourceRef `json:"resourceRefs,omitempty"`
+	SplitOversizedDocument *bool                         `json:"splitOversizedDocument,omitempty"`
 	// A list of tags that you want to attach to the new IAM customer managed policy.
 	// Each tag consists of a key name and an associated value. For more information
 	// about tagging, see Tagging IAM resources (https://docs.aws.amazon.com/IAM/latest/UserGuide/id_tags.html)
@@ -123,6 +132,8 @@ type PolicyStatus struct {
 	// resource
 	// +kubebuilder:validation:Optional
 	Conditions []*ackv1alpha1.Condition `json:"conditions"`
+	// +kubebuilder:validation:Optional
+	AttachedEntities *PolicyEntities `json:"attachedEntities,omitempty"`
 	// The number of entities (users, groups, and roles) that the policy is attached
 	// to.
 	// +kubebuilder:validation:Optional
@@ -139,6 +150,8 @@ type PolicyStatus struct {
 	// Specifies whether the policy can be attached to an IAM user, group, or role.
 	// +kubebuilder:validation:Optional
 	IsAttachable *bool `json:"isAttachable,omitempty"`
+	// +kubebuilder:validation:Optional
+	PermissionsBoundaryEntities *PolicyEntities `json:"permissionsBoundaryEntities,omitempty"`
 	// The number of entities (users and roles) for which the policy is used to
 	// set the permissions boundary.
 	//
@@ -155,6 +168,14 @@ type PolicyStatus struct {
 	// Regex Pattern: `^[\w]+$`
 	// +kubebuilder:validation:Optional
 	PolicyID *string `json:"policyID,omitempty"`
+	// The policy document sent to IAM, with the ${ack:...} variables of
+	// PolicyDocument substituted.
+	// +kubebuilder:validation:Optional
+	RenderedPolicyDocument *string `json:"renderedPolicyDocument,omitempty"`
+	// +kubebuilder:validation:Optional
+	ResolvedResourceRefs map[string]*string `json:"resolvedResourceRefs,omitempty"`
+	// +kubebuilder:validation:Optional
+	ShardARNs []*string `json:"shardARNs,omitempty"`
 	// The date and time, in ISO 8601 date-time format (http://www.iso.org/iso/iso8601),
 	// when the policy was last updated.
 	//
@@ -25,7 +25,7 @@ import (
 // Contains information about an IAM role. This structure is returned as a response
 // element in several API operations that interact with roles.
 type RoleSpec struct {
-
+	AWSManagedPolicies []*string `json:"awsManagedPolicies,omitempty"`
 	// The trust relationship policy document that grants an entity permission to
 	// assume the role.
 	//
@@ -49,13 +49,23 @@ type RoleSpec struct {
 	// Upon success, the response includes the same trust policy in JSON format.
 	//
 	// Regex Pattern: `^[\u0009\u000A\u000D\u0020-\u00FF]+$`
-	// +kubebuilder:validation:Required
-	AssumeRolePolicyDocument *string `json:"assumeRolePolicyDocument"`
+	AssumeRolePolicyDocument *string `json:"assumeRolePolicyDocument,omitempty"`
 	// A description of the role.
 	//
 	// Regex Pattern: `^[\u0009\u000A\u000D\u0020-\u007E\u00A1-\u00FF]*$`
-	Description    *string            `json:"description,omitempty"`
-	InlinePolicies map[string]*string `json:"inlinePolicies,omitempty"`
+	CompactInlinePolicies *bool   `json:"compactInlinePolicies,omitempty"`
+	Description           *string `json:"description,omitempty"`
+	// When true, managed and inline policies attached to the Role but not
+	// declared in its spec are detached before the Role is deleted.
+	// Otherwise their presence blocks the deletion: the Role's ACK.Advisory
+	// condition with reason UndeclaredPoliciesAttached lists them, and the
+	// deletion is retried until they are detached.
+	//
+	// Earlier releases detached every policy unconditionally. Set this field
+	// to true to keep that behavior.
+	ForceDetachPolicies *bool                `json:"forceDetachPolicies,omitempty"`
+	InlinePolicies      map[string]*string   `json:"inlinePolicies,omitempty"`
+	InstanceProfile     *RoleInstanceProfile `json:"instanceProfile,omitempty"`
 	// The maximum session duration (in seconds) that you want to set for the specified
 	// role. If you do not specify a value for this setting, the default value of
 	// one hour is applied. This setting can have a value from 1 hour to 12 hours.
@@ -112,10 +122,14 @@ type RoleSpec struct {
 	//
 	// For more information about policy types, see Policy types (https://docs.aws.amazon.com/IAM/latest/UserGuide/access_policies.html#access_policy-types)
 	// in the IAM User Guide.
-	PermissionsBoundary    *string                                    `json:"permissionsBoundary,omitempty"`
-	PermissionsBoundaryRef *ackv1alpha1.AWSResourceReferenceWrapper   `json:"permissionsBoundaryRef,omitempty"`
-	Policies               []*string                                  `json:"policies,omitempty"`
-	PolicyRefs             []*ackv1alpha1.AWSResourceReferenceWrapper `json:"policyRefs,omitempty"`
+	PermissionsBoundary            *string                                    `json:"permissionsBoundary,omitempty"`
+	PermissionsBoundaryRef         *ackv1alpha1.AWSResourceReferenceWrapper   `json:"permissionsBoundaryRef,omitempty"`
+	Policies                       []*string                                  `json:"policies,omitempty"`
+	PolicyRefs                     []*ackv1alpha1.AWSResourceReferenceWrapper `json:"policyRefs,omitempty"`
+	PolicyRecommendationUnusedDays *int64                                     `json:"policyRecommendationUnusedDays,omitempty"`
+	ServiceAccountBindings         []*ServiceAccountBinding                   `json:"serviceAccountBindings,omitempty"`
+	ServiceAccountTrust            []*ServiceAccountTrust                     `json:"serviceAccountTrust,omitempty"`
+	ServiceLastAccessedGranularity *string                                    `json:"serviceLastAccessedGranularity,omitempty"`
 	// A list of tags that you want to attach to the new role. Each tag consists
 	// of a key name and an associated value. For more information about tagging,
 	// see Tagging IAM resources (https://docs.aws.amazon.com/IAM/latest/UserGuide/id_tags.html)
@@ -123,7 +137,8 @@ type RoleSpec struct {
 	//
 	// If any one of the tags is invalid or if you exceed the allowed maximum number
 	// of tags, then the entire request fails and the resource is not created.
-	Tags []*Tag `json:"tags,omitempty"`
+	Tags         []*Tag         `json:"tags,omitempty"`
+	TrustPresets []*TrustPreset `json:"trustPresets,omitempty"`
 }
 
 // RoleStatus defines the observed state of Role
@@ -139,10 +154,18 @@ type RoleStatus struct {
 	// resource
 	// +kubebuilder:validation:Optional
 	Conditions []*ackv1alpha1.Condition `json:"conditions"`
+	// +kubebuilder:validation:Optional
+	BoundServiceAccounts []*ServiceAccountBinding `json:"boundServiceAccounts,omitempty"`
 	// The date and time, in ISO 8601 date-time format (http://www.iso.org/iso/iso8601),
 	// when the role was created.
 	// +kubebuilder:validation:Optional
 	CreateDate *metav1.Time `json:"createDate,omitempty"`
+	// +kubebuilder:validation:Optional
+	InstanceProfileARN *string `json:"instanceProfileARN,omitempty"`
+	// +kubebuilder:validation:Optional
+	PolicyRecommendation *PolicyRecommendation `json:"policyRecommendation,omitempty"`
+	// +kubebuilder:validation:Optional
+	RenderedInlinePolicies map[string]*string `json:"renderedInlinePolicies,omitempty"`
 	// The stable and unique string identifying the role. For more information about
 	// IDs, see IAM identifiers (https://docs.aws.amazon.com/IAM/latest/UserGuide/Using_Identifiers.html)
 	// in the IAM User Guide.
@@ -159,6 +182,8 @@ type RoleStatus struct {
 	// in the IAM user Guide.
 	// +kubebuilder:validation:Optional
 	RoleLastUsed *RoleLastUsed `json:"roleLastUsed,omitempty"`
+	// +kubebuilder:validation:Optional
+	ServiceLastAccessed *ServiceLastAccessedReport `json:"serviceLastAccessed,omitempty"`
 }
 
 // Role is the Schema for the Roles API
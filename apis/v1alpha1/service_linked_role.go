@@ -55,6 +55,7 @@ type ServiceLinkedRoleSpec struct {
 	//
 	// Regex Pattern: `^[\u0009\u000A\u000D\u0020-\u007E\u00A1-\u00FF]*$`
 	Description *string `json:"description,omitempty"`
+	Tags        []*Tag  `json:"tags,omitempty"`
 }
 
 // ServiceLinkedRoleStatus defines the observed state of ServiceLinkedRole
@@ -79,6 +80,8 @@ type ServiceLinkedRoleStatus struct {
 	// when the role was created.
 	// +kubebuilder:validation:Optional
 	CreateDate *metav1.Time `json:"createDate,omitempty"`
+	// +kubebuilder:validation:Optional
+	DeletionTaskID *string `json:"deletionTaskID,omitempty"`
 	// The maximum session duration (in seconds) for the specified role. Anyone
 	// who uses the CLI, or API to assume the role can specify the duration using
 	// the optional DurationSeconds API parameter or duration-seconds CLI parameter.
@@ -119,11 +122,6 @@ type ServiceLinkedRoleStatus struct {
 	// Regex Pattern: `^[\w+=,.@-]+$`
 	// +kubebuilder:validation:Optional
 	RoleName *string `json:"roleName,omitempty"`
-	// A list of tags that are attached to the role. For more information about
-	// tagging, see Tagging IAM resources (https://docs.aws.amazon.com/IAM/latest/UserGuide/id_tags.html)
-	// in the IAM User Guide.
-	// +kubebuilder:validation:Optional
-	Tags []*Tag `json:"tags,omitempty"`
 }
 
 // ServiceLinkedRole is the Schema for the ServiceLinkedRoles API
@@ -300,6 +300,15 @@ type PolicyDetail struct {
 	PolicyName     *string `json:"policyName,omitempty"`
 }
 
+// PolicyEntities lists the IAM entities using a managed policy, as returned by
+// ListEntitiesForPolicy. Truncated is set when the list was capped.
+type PolicyEntities struct {
+	PolicyGroups []*PolicyGroup `json:"policyGroups,omitempty"`
+	PolicyRoles  []*PolicyRole  `json:"policyRoles,omitempty"`
+	PolicyUsers  []*PolicyUser  `json:"policyUsers,omitempty"`
+	Truncated    *bool          `json:"truncated,omitempty"`
+}
+
 // Contains details about the permissions policies that are attached to the
 // specified identity (user, group, or role).
 //
@@ -328,6 +337,28 @@ type PolicyGroup struct {
 	GroupName *string `json:"groupName,omitempty"`
 }
 
+// PolicyRecommendation is a least-privilege policy document proposed for a
+// Role from its service last accessed report. It is never applied.
+type PolicyRecommendation struct {
+	GeneratedAt          *metav1.Time `json:"generatedAt,omitempty"`
+	PolicyDocument       *string      `json:"policyDocument,omitempty"`
+	ReportCompletionDate *metav1.Time `json:"reportCompletionDate,omitempty"`
+	UnusedDays           *int64       `json:"unusedDays,omitempty"`
+	UnusedServices       []*string    `json:"unusedServices,omitempty"`
+}
+
+// PolicyResourceRef points at a field of another Kubernetes resource, by
+// default its ACK ARN, whose value replaces the ${ref:<alias>} placeholders of
+// a Policy's document. Group may carry the API version, as in
+// s3.services.k8s.aws/v1alpha1, and defaults to version v1alpha1.
+type PolicyResourceRef struct {
+	FieldPath *string `json:"fieldPath,omitempty"`
+	Group     *string `json:"group,omitempty"`
+	Kind      *string `json:"kind"`
+	Name      *string `json:"name"`
+	Namespace *string `json:"namespace,omitempty"`
+}
+
 // Contains information about a role that a managed policy is attached to.
 //
 // This data type is used as a response element in the ListEntitiesForPolicy
@@ -439,6 +470,19 @@ type RoleDetail struct {
 	Tags         []*Tag        `json:"tags,omitempty"`
 }
 
+// RoleInstanceProfile describes the instance profile a Role manages for
+// itself, so that EC2 instances and node provisioners can use the Role
+// without a separate InstanceProfile resource.
+type RoleInstanceProfile struct {
+	// Creates the instance profile and adds the Role to it.
+	Create *bool `json:"create,omitempty"`
+	// Defaults to the name of the Role.
+	Name *string `json:"name,omitempty"`
+	// Defaults to "/".
+	Path *string `json:"path,omitempty"`
+	Tags []*Tag  `json:"tags,omitempty"`
+}
+
 // Contains information about the last time that an IAM role was used. This
 // includes the date and time and the Region in which the role was last used.
 // Activity is only reported for the trailing 400 days. This period can be shorter
@@ -548,6 +592,29 @@ type ServerCertificateMetadata struct {
 	UploadDate          *metav1.Time `json:"uploadDate,omitempty"`
 }
 
+// ServiceAccountBinding names a Kubernetes ServiceAccount the controller
+// annotates with the ARN of a Role once it is known.
+type ServiceAccountBinding struct {
+	// +kubebuilder:validation:Required
+	Name *string `json:"name"`
+	// Defaults to the namespace of the Role.
+	Namespace *string `json:"namespace,omitempty"`
+	// Also sets the eks.amazonaws.com/sts-regional-endpoints annotation.
+	STSRegionalEndpoints *bool `json:"stsRegionalEndpoints,omitempty"`
+}
+
+// ServiceAccountTrust identifies a Kubernetes ServiceAccount allowed to assume
+// a Role through IAM roles for service accounts (IRSA). The controller renders
+// an sts:AssumeRoleWithWebIdentity statement for it in the Role's trust policy.
+type ServiceAccountTrust struct {
+	// +kubebuilder:validation:Required
+	Name *string `json:"name"`
+	// Defaults to the namespace of the Role.
+	Namespace       *string                                  `json:"namespace,omitempty"`
+	OIDCProviderARN *string                                  `json:"oidcProviderARN,omitempty"`
+	OIDCProviderRef *ackv1alpha1.AWSResourceReferenceWrapper `json:"oidcProviderRef,omitempty"`
+}
+
 // Contains details about the most recent attempt to access the service.
 //
 // This data type is used as a response element in the GetServiceLastAccessedDetails
@@ -559,8 +626,21 @@ type ServiceLastAccessed struct {
 	//
 	// For more information about ARNs, go to Amazon Resource Names (ARNs) (https://docs.aws.amazon.com/general/latest/gr/aws-arns-and-namespaces.html)
 	// in the Amazon Web Services General Reference.
-	LastAuthenticatedEntity *string `json:"lastAuthenticatedEntity,omitempty"`
-	LastAuthenticatedRegion *string `json:"lastAuthenticatedRegion,omitempty"`
+	LastAuthenticatedEntity    *string                      `json:"lastAuthenticatedEntity,omitempty"`
+	LastAuthenticatedRegion    *string                      `json:"lastAuthenticatedRegion,omitempty"`
+	ServiceName                *string                      `json:"serviceName,omitempty"`
+	ServiceNamespace           *string                      `json:"serviceNamespace,omitempty"`
+	TotalAuthenticatedEntities *int64                       `json:"totalAuthenticatedEntities,omitempty"`
+	TrackedActionsLastAccessed []*TrackedActionLastAccessed `json:"trackedActionsLastAccessed,omitempty"`
+}
+
+// ServiceLastAccessedReport is the latest service last accessed (Access
+// Advisor) report generated for an IAM entity.
+type ServiceLastAccessedReport struct {
+	JobCompletionDate    *metav1.Time           `json:"jobCompletionDate,omitempty"`
+	JobID                *string                `json:"jobID,omitempty"`
+	JobType              *string                `json:"jobType,omitempty"`
+	ServicesLastAccessed []*ServiceLastAccessed `json:"servicesLastAccessed,omitempty"`
 }
 
 // Contains the details of a service-specific credential.
@@ -610,6 +690,16 @@ type TrackedActionLastAccessed struct {
 	LastAccessedTime   *metav1.Time `json:"lastAccessedTime,omitempty"`
 }
 
+// TrustPreset renders a well-known trust policy statement into a Role's
+// trust policy. Preset is one of eksPodIdentity, ec2, lambda or service. The
+// service preset trusts the service principal named in Service.
+type TrustPreset struct {
+	// +kubebuilder:validation:Required
+	Preset        *string `json:"preset"`
+	Service       *string `json:"service,omitempty"`
+	SourceAccount *string `json:"sourceAccount,omitempty"`
+}
+
 // Contains information about an IAM user, including all the user's policies
 // and all the IAM groups the user is in.
 //
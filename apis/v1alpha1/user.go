@@ -32,7 +32,9 @@ import (
 //
 //   - ListUsers
 type UserSpec struct {
-	InlinePolicies map[string]*string `json:"inlinePolicies,omitempty"`
+	AWSManagedPolicies []*string          `json:"awsManagedPolicies,omitempty"`
+	DeletionCleanup    *string            `json:"deletionCleanup,omitempty"`
+	InlinePolicies     map[string]*string `json:"inlinePolicies,omitempty"`
 	// The name of the user to create.
 	//
 	// IAM user, group, role, and policy names must be unique within the account.
@@ -70,10 +72,11 @@ type UserSpec struct {
 	//
 	// For more information about policy types, see Policy types (https://docs.aws.amazon.com/IAM/latest/UserGuide/access_policies.html#access_policy-types)
 	// in the IAM User Guide.
-	PermissionsBoundary    *string                                    `json:"permissionsBoundary,omitempty"`
-	PermissionsBoundaryRef *ackv1alpha1.AWSResourceReferenceWrapper   `json:"permissionsBoundaryRef,omitempty"`
-	Policies               []*string                                  `json:"policies,omitempty"`
-	PolicyRefs             []*ackv1alpha1.AWSResourceReferenceWrapper `json:"policyRefs,omitempty"`
+	PermissionsBoundary            *string                                    `json:"permissionsBoundary,omitempty"`
+	PermissionsBoundaryRef         *ackv1alpha1.AWSResourceReferenceWrapper   `json:"permissionsBoundaryRef,omitempty"`
+	Policies                       []*string                                  `json:"policies,omitempty"`
+	PolicyRefs                     []*ackv1alpha1.AWSResourceReferenceWrapper `json:"policyRefs,omitempty"`
+	ServiceLastAccessedGranularity *string                                    `json:"serviceLastAccessedGranularity,omitempty"`
 	// A list of tags that you want to attach to the new user. Each tag consists
 	// of a key name and an associated value. For more information about tagging,
 	// see Tagging IAM resources (https://docs.aws.amazon.com/IAM/latest/UserGuide/id_tags.html)
@@ -122,6 +125,10 @@ type UserStatus struct {
 	// This value is returned only in the GetUser and ListUsers operations.
 	// +kubebuilder:validation:Optional
 	PasswordLastUsed *metav1.Time `json:"passwordLastUsed,omitempty"`
+	// +kubebuilder:validation:Optional
+	RenderedInlinePolicies map[string]*string `json:"renderedInlinePolicies,omitempty"`
+	// +kubebuilder:validation:Optional
+	ServiceLastAccessed *ServiceLastAccessedReport `json:"serviceLastAccessed,omitempty"`
 	// The stable and unique string identifying the user. For more information about
 	// IDs, see IAM identifiers (https://docs.aws.amazon.com/IAM/latest/UserGuide/Using_Identifiers.html)
 	// in the IAM User Guide.
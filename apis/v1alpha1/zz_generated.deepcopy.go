@@ -355,6 +355,17 @@ func (in *GroupList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GroupSpec) DeepCopyInto(out *GroupSpec) {
 	*out = *in
+	if in.AWSManagedPolicies != nil {
+		in, out := &in.AWSManagedPolicies, &out.AWSManagedPolicies
+		*out = make([]*string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(string)
+				**out = **in
+			}
+		}
+	}
 	if in.InlinePolicies != nil {
 		in, out := &in.InlinePolicies, &out.InlinePolicies
 		*out = make(map[string]*string, len(*in))
@@ -403,6 +414,16 @@ func (in *GroupSpec) DeepCopyInto(out *GroupSpec) {
 			}
 		}
 	}
+	if in.RemoveMembersOnDelete != nil {
+		in, out := &in.RemoveMembersOnDelete, &out.RemoveMembersOnDelete
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ServiceLastAccessedGranularity != nil {
+		in, out := &in.ServiceLastAccessedGranularity, &out.ServiceLastAccessedGranularity
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupSpec.
@@ -443,6 +464,27 @@ func (in *GroupStatus) DeepCopyInto(out *GroupStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.RenderedInlinePolicies != nil {
+		in, out := &in.RenderedInlinePolicies, &out.RenderedInlinePolicies
+		*out = make(map[string]*string, len(*in))
+		for key, val := range *in {
+			var outVal *string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(string)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ServiceLastAccessed != nil {
+		in, out := &in.ServiceLastAccessed, &out.ServiceLastAccessed
+		*out = new(ServiceLastAccessedReport)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupStatus.
@@ -494,6 +536,184 @@ func (in *Group_SDK) DeepCopy() *Group_SDK {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IAMImport) DeepCopyInto(out *IAMImport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IAMImport.
+func (in *IAMImport) DeepCopy() *IAMImport {
+	if in == nil {
+		return nil
+	}
+	out := new(IAMImport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IAMImport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IAMImportList) DeepCopyInto(out *IAMImportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IAMImport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IAMImportList.
+func (in *IAMImportList) DeepCopy() *IAMImportList {
+	if in == nil {
+		return nil
+	}
+	out := new(IAMImportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IAMImportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IAMImportSpec) DeepCopyInto(out *IAMImportSpec) {
+	*out = *in
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]*string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(string)
+				**out = **in
+			}
+		}
+	}
+	if in.PathPrefix != nil {
+		in, out := &in.PathPrefix, &out.PathPrefix
+		*out = new(string)
+		**out = **in
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]*Tag, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Tag)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.TargetNamespace != nil {
+		in, out := &in.TargetNamespace, &out.TargetNamespace
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IAMImportSpec.
+func (in *IAMImportSpec) DeepCopy() *IAMImportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IAMImportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IAMImportStatus) DeepCopyInto(out *IAMImportStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]*corev1alpha1.Condition, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(corev1alpha1.Condition)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.ImportedResources != nil {
+		in, out := &in.ImportedResources, &out.ImportedResources
+		*out = make([]*ImportedResource, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ImportedResource)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.ObservedGeneration != nil {
+		in, out := &in.ObservedGeneration, &out.ObservedGeneration
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IAMImportStatus.
+func (in *IAMImportStatus) DeepCopy() *IAMImportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IAMImportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportedResource) DeepCopyInto(out *ImportedResource) {
+	*out = *in
+	if in.ARN != nil {
+		in, out := &in.ARN, &out.ARN
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(string)
+		**out = **in
+	}
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportedResource.
+func (in *ImportedResource) DeepCopy() *ImportedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceProfile) DeepCopyInto(out *InstanceProfile) {
 	*out = *in
@@ -903,6 +1123,11 @@ func (in *OpenIDConnectProviderSpec) DeepCopyInto(out *OpenIDConnectProviderSpec
 			}
 		}
 	}
+	if in.FromClusterIssuer != nil {
+		in, out := &in.FromClusterIssuer, &out.FromClusterIssuer
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Tags != nil {
 		in, out := &in.Tags, &out.Tags
 		*out = make([]*Tag, len(*in))
@@ -914,6 +1139,11 @@ func (in *OpenIDConnectProviderSpec) DeepCopyInto(out *OpenIDConnectProviderSpec
 			}
 		}
 	}
+	if in.ThumbprintPolicy != nil {
+		in, out := &in.ThumbprintPolicy, &out.ThumbprintPolicy
+		*out = new(string)
+		**out = **in
+	}
 	if in.Thumbprints != nil {
 		in, out := &in.Thumbprints, &out.Thumbprints
 		*out = make([]*string, len(*in))
@@ -961,6 +1191,11 @@ func (in *OpenIDConnectProviderStatus) DeepCopyInto(out *OpenIDConnectProviderSt
 			}
 		}
 	}
+	if in.DiscoveredThumbprint != nil {
+		in, out := &in.DiscoveredThumbprint, &out.DiscoveredThumbprint
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenIDConnectProviderStatus.
@@ -1110,6 +1345,59 @@ func (in *PolicyDetail) DeepCopy() *PolicyDetail {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyEntities) DeepCopyInto(out *PolicyEntities) {
+	*out = *in
+	if in.PolicyGroups != nil {
+		in, out := &in.PolicyGroups, &out.PolicyGroups
+		*out = make([]*PolicyGroup, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(PolicyGroup)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.PolicyRoles != nil {
+		in, out := &in.PolicyRoles, &out.PolicyRoles
+		*out = make([]*PolicyRole, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(PolicyRole)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.PolicyUsers != nil {
+		in, out := &in.PolicyUsers, &out.PolicyUsers
+		*out = make([]*PolicyUser, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(PolicyUser)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Truncated != nil {
+		in, out := &in.Truncated, &out.Truncated
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyEntities.
+func (in *PolicyEntities) DeepCopy() *PolicyEntities {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyEntities)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PolicyGrantingServiceAccess) DeepCopyInto(out *PolicyGrantingServiceAccess) {
 	*out = *in
@@ -1192,6 +1480,90 @@ func (in *PolicyList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyRecommendation) DeepCopyInto(out *PolicyRecommendation) {
+	*out = *in
+	if in.GeneratedAt != nil {
+		in, out := &in.GeneratedAt, &out.GeneratedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.PolicyDocument != nil {
+		in, out := &in.PolicyDocument, &out.PolicyDocument
+		*out = new(string)
+		**out = **in
+	}
+	if in.ReportCompletionDate != nil {
+		in, out := &in.ReportCompletionDate, &out.ReportCompletionDate
+		*out = (*in).DeepCopy()
+	}
+	if in.UnusedDays != nil {
+		in, out := &in.UnusedDays, &out.UnusedDays
+		*out = new(int64)
+		**out = **in
+	}
+	if in.UnusedServices != nil {
+		in, out := &in.UnusedServices, &out.UnusedServices
+		*out = make([]*string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(string)
+				**out = **in
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyRecommendation.
+func (in *PolicyRecommendation) DeepCopy() *PolicyRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyResourceRef) DeepCopyInto(out *PolicyResourceRef) {
+	*out = *in
+	if in.FieldPath != nil {
+		in, out := &in.FieldPath, &out.FieldPath
+		*out = new(string)
+		**out = **in
+	}
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(string)
+		**out = **in
+	}
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyResourceRef.
+func (in *PolicyResourceRef) DeepCopy() *PolicyResourceRef {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyResourceRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PolicyRole) DeepCopyInto(out *PolicyRole) {
 	*out = *in
@@ -1220,6 +1592,16 @@ func (in *PolicyRole) DeepCopy() *PolicyRole {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PolicySpec) DeepCopyInto(out *PolicySpec) {
 	*out = *in
+	if in.AttachedEntitiesLimit != nil {
+		in, out := &in.AttachedEntitiesLimit, &out.AttachedEntitiesLimit
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DeletionMode != nil {
+		in, out := &in.DeletionMode, &out.DeletionMode
+		*out = new(string)
+		**out = **in
+	}
 	if in.Description != nil {
 		in, out := &in.Description, &out.Description
 		*out = new(string)
@@ -1240,6 +1622,27 @@ func (in *PolicySpec) DeepCopyInto(out *PolicySpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ResourceRefs != nil {
+		in, out := &in.ResourceRefs, &out.ResourceRefs
+		*out = make(map[string]*PolicyResourceRef, len(*in))
+		for key, val := range *in {
+			var outVal *PolicyResourceRef
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(PolicyResourceRef)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.SplitOversizedDocument != nil {
+		in, out := &in.SplitOversizedDocument, &out.SplitOversizedDocument
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Tags != nil {
 		in, out := &in.Tags, &out.Tags
 		*out = make([]*Tag, len(*in))
@@ -1282,6 +1685,11 @@ func (in *PolicyStatus) DeepCopyInto(out *PolicyStatus) {
 			}
 		}
 	}
+	if in.AttachedEntities != nil {
+		in, out := &in.AttachedEntities, &out.AttachedEntities
+		*out = new(PolicyEntities)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AttachmentCount != nil {
 		in, out := &in.AttachmentCount, &out.AttachmentCount
 		*out = new(int64)
@@ -1301,6 +1709,11 @@ func (in *PolicyStatus) DeepCopyInto(out *PolicyStatus) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PermissionsBoundaryEntities != nil {
+		in, out := &in.PermissionsBoundaryEntities, &out.PermissionsBoundaryEntities
+		*out = new(PolicyEntities)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PermissionsBoundaryUsageCount != nil {
 		in, out := &in.PermissionsBoundaryUsageCount, &out.PermissionsBoundaryUsageCount
 		*out = new(int64)
@@ -1311,6 +1724,38 @@ func (in *PolicyStatus) DeepCopyInto(out *PolicyStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.RenderedPolicyDocument != nil {
+		in, out := &in.RenderedPolicyDocument, &out.RenderedPolicyDocument
+		*out = new(string)
+		**out = **in
+	}
+	if in.ResolvedResourceRefs != nil {
+		in, out := &in.ResolvedResourceRefs, &out.ResolvedResourceRefs
+		*out = make(map[string]*string, len(*in))
+		for key, val := range *in {
+			var outVal *string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(string)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ShardARNs != nil {
+		in, out := &in.ShardARNs, &out.ShardARNs
+		*out = make([]*string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(string)
+				**out = **in
+			}
+		}
+	}
 	if in.UpdateDate != nil {
 		in, out := &in.UpdateDate, &out.UpdateDate
 		*out = (*in).DeepCopy()
@@ -1530,18 +1975,59 @@ func (in *RoleDetail) DeepCopyInto(out *RoleDetail) {
 		*out = new(AttachedPermissionsBoundary)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.RoleID != nil {
-		in, out := &in.RoleID, &out.RoleID
+	if in.RoleID != nil {
+		in, out := &in.RoleID, &out.RoleID
+		*out = new(string)
+		**out = **in
+	}
+	if in.RoleLastUsed != nil {
+		in, out := &in.RoleLastUsed, &out.RoleLastUsed
+		*out = new(RoleLastUsed)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RoleName != nil {
+		in, out := &in.RoleName, &out.RoleName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]*Tag, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Tag)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleDetail.
+func (in *RoleDetail) DeepCopy() *RoleDetail {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleDetail)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleInstanceProfile) DeepCopyInto(out *RoleInstanceProfile) {
+	*out = *in
+	if in.Create != nil {
+		in, out := &in.Create, &out.Create
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
 		*out = new(string)
 		**out = **in
 	}
-	if in.RoleLastUsed != nil {
-		in, out := &in.RoleLastUsed, &out.RoleLastUsed
-		*out = new(RoleLastUsed)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.RoleName != nil {
-		in, out := &in.RoleName, &out.RoleName
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
 		*out = new(string)
 		**out = **in
 	}
@@ -1558,12 +2044,12 @@ func (in *RoleDetail) DeepCopyInto(out *RoleDetail) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleDetail.
-func (in *RoleDetail) DeepCopy() *RoleDetail {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleInstanceProfile.
+func (in *RoleInstanceProfile) DeepCopy() *RoleInstanceProfile {
 	if in == nil {
 		return nil
 	}
-	out := new(RoleDetail)
+	out := new(RoleInstanceProfile)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1627,16 +2113,37 @@ func (in *RoleList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RoleSpec) DeepCopyInto(out *RoleSpec) {
 	*out = *in
+	if in.AWSManagedPolicies != nil {
+		in, out := &in.AWSManagedPolicies, &out.AWSManagedPolicies
+		*out = make([]*string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(string)
+				**out = **in
+			}
+		}
+	}
 	if in.AssumeRolePolicyDocument != nil {
 		in, out := &in.AssumeRolePolicyDocument, &out.AssumeRolePolicyDocument
 		*out = new(string)
 		**out = **in
 	}
+	if in.CompactInlinePolicies != nil {
+		in, out := &in.CompactInlinePolicies, &out.CompactInlinePolicies
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Description != nil {
 		in, out := &in.Description, &out.Description
 		*out = new(string)
 		**out = **in
 	}
+	if in.ForceDetachPolicies != nil {
+		in, out := &in.ForceDetachPolicies, &out.ForceDetachPolicies
+		*out = new(bool)
+		**out = **in
+	}
 	if in.InlinePolicies != nil {
 		in, out := &in.InlinePolicies, &out.InlinePolicies
 		*out = make(map[string]*string, len(*in))
@@ -1653,6 +2160,11 @@ func (in *RoleSpec) DeepCopyInto(out *RoleSpec) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.InstanceProfile != nil {
+		in, out := &in.InstanceProfile, &out.InstanceProfile
+		*out = new(RoleInstanceProfile)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.MaxSessionDuration != nil {
 		in, out := &in.MaxSessionDuration, &out.MaxSessionDuration
 		*out = new(int64)
@@ -1700,6 +2212,38 @@ func (in *RoleSpec) DeepCopyInto(out *RoleSpec) {
 			}
 		}
 	}
+	if in.PolicyRecommendationUnusedDays != nil {
+		in, out := &in.PolicyRecommendationUnusedDays, &out.PolicyRecommendationUnusedDays
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ServiceAccountBindings != nil {
+		in, out := &in.ServiceAccountBindings, &out.ServiceAccountBindings
+		*out = make([]*ServiceAccountBinding, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ServiceAccountBinding)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.ServiceAccountTrust != nil {
+		in, out := &in.ServiceAccountTrust, &out.ServiceAccountTrust
+		*out = make([]*ServiceAccountTrust, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ServiceAccountTrust)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.ServiceLastAccessedGranularity != nil {
+		in, out := &in.ServiceLastAccessedGranularity, &out.ServiceLastAccessedGranularity
+		*out = new(string)
+		**out = **in
+	}
 	if in.Tags != nil {
 		in, out := &in.Tags, &out.Tags
 		*out = make([]*Tag, len(*in))
@@ -1711,6 +2255,17 @@ func (in *RoleSpec) DeepCopyInto(out *RoleSpec) {
 			}
 		}
 	}
+	if in.TrustPresets != nil {
+		in, out := &in.TrustPresets, &out.TrustPresets
+		*out = make([]*TrustPreset, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(TrustPreset)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleSpec.
@@ -1742,10 +2297,47 @@ func (in *RoleStatus) DeepCopyInto(out *RoleStatus) {
 			}
 		}
 	}
+	if in.BoundServiceAccounts != nil {
+		in, out := &in.BoundServiceAccounts, &out.BoundServiceAccounts
+		*out = make([]*ServiceAccountBinding, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ServiceAccountBinding)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 	if in.CreateDate != nil {
 		in, out := &in.CreateDate, &out.CreateDate
 		*out = (*in).DeepCopy()
 	}
+	if in.InstanceProfileARN != nil {
+		in, out := &in.InstanceProfileARN, &out.InstanceProfileARN
+		*out = new(string)
+		**out = **in
+	}
+	if in.PolicyRecommendation != nil {
+		in, out := &in.PolicyRecommendation, &out.PolicyRecommendation
+		*out = new(PolicyRecommendation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RenderedInlinePolicies != nil {
+		in, out := &in.RenderedInlinePolicies, &out.RenderedInlinePolicies
+		*out = make(map[string]*string, len(*in))
+		for key, val := range *in {
+			var outVal *string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(string)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
 	if in.RoleID != nil {
 		in, out := &in.RoleID, &out.RoleID
 		*out = new(string)
@@ -1756,6 +2348,11 @@ func (in *RoleStatus) DeepCopyInto(out *RoleStatus) {
 		*out = new(RoleLastUsed)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ServiceLastAccessed != nil {
+		in, out := &in.ServiceLastAccessed, &out.ServiceLastAccessed
+		*out = new(ServiceLastAccessedReport)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleStatus.
@@ -1983,6 +2580,71 @@ func (in *ServerCertificateMetadata) DeepCopy() *ServerCertificateMetadata {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceAccountBinding) DeepCopyInto(out *ServiceAccountBinding) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+	if in.STSRegionalEndpoints != nil {
+		in, out := &in.STSRegionalEndpoints, &out.STSRegionalEndpoints
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAccountBinding.
+func (in *ServiceAccountBinding) DeepCopy() *ServiceAccountBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceAccountBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceAccountTrust) DeepCopyInto(out *ServiceAccountTrust) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+	if in.OIDCProviderARN != nil {
+		in, out := &in.OIDCProviderARN, &out.OIDCProviderARN
+		*out = new(string)
+		**out = **in
+	}
+	if in.OIDCProviderRef != nil {
+		in, out := &in.OIDCProviderRef, &out.OIDCProviderRef
+		*out = new(corev1alpha1.AWSResourceReferenceWrapper)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAccountTrust.
+func (in *ServiceAccountTrust) DeepCopy() *ServiceAccountTrust {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceAccountTrust)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceLastAccessed) DeepCopyInto(out *ServiceLastAccessed) {
 	*out = *in
@@ -2000,6 +2662,32 @@ func (in *ServiceLastAccessed) DeepCopyInto(out *ServiceLastAccessed) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ServiceName != nil {
+		in, out := &in.ServiceName, &out.ServiceName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ServiceNamespace != nil {
+		in, out := &in.ServiceNamespace, &out.ServiceNamespace
+		*out = new(string)
+		**out = **in
+	}
+	if in.TotalAuthenticatedEntities != nil {
+		in, out := &in.TotalAuthenticatedEntities, &out.TotalAuthenticatedEntities
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TrackedActionsLastAccessed != nil {
+		in, out := &in.TrackedActionsLastAccessed, &out.TrackedActionsLastAccessed
+		*out = make([]*TrackedActionLastAccessed, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(TrackedActionLastAccessed)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceLastAccessed.
@@ -2012,6 +2700,46 @@ func (in *ServiceLastAccessed) DeepCopy() *ServiceLastAccessed {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceLastAccessedReport) DeepCopyInto(out *ServiceLastAccessedReport) {
+	*out = *in
+	if in.JobCompletionDate != nil {
+		in, out := &in.JobCompletionDate, &out.JobCompletionDate
+		*out = (*in).DeepCopy()
+	}
+	if in.JobID != nil {
+		in, out := &in.JobID, &out.JobID
+		*out = new(string)
+		**out = **in
+	}
+	if in.JobType != nil {
+		in, out := &in.JobType, &out.JobType
+		*out = new(string)
+		**out = **in
+	}
+	if in.ServicesLastAccessed != nil {
+		in, out := &in.ServicesLastAccessed, &out.ServicesLastAccessed
+		*out = make([]*ServiceLastAccessed, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ServiceLastAccessed)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceLastAccessedReport.
+func (in *ServiceLastAccessedReport) DeepCopy() *ServiceLastAccessedReport {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceLastAccessedReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceLinkedRole) DeepCopyInto(out *ServiceLinkedRole) {
 	*out = *in
@@ -2089,6 +2817,17 @@ func (in *ServiceLinkedRoleSpec) DeepCopyInto(out *ServiceLinkedRoleSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]*Tag, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Tag)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceLinkedRoleSpec.
@@ -2129,6 +2868,11 @@ func (in *ServiceLinkedRoleStatus) DeepCopyInto(out *ServiceLinkedRoleStatus) {
 		in, out := &in.CreateDate, &out.CreateDate
 		*out = (*in).DeepCopy()
 	}
+	if in.DeletionTaskID != nil {
+		in, out := &in.DeletionTaskID, &out.DeletionTaskID
+		*out = new(string)
+		**out = **in
+	}
 	if in.MaxSessionDuration != nil {
 		in, out := &in.MaxSessionDuration, &out.MaxSessionDuration
 		*out = new(int64)
@@ -2159,17 +2903,6 @@ func (in *ServiceLinkedRoleStatus) DeepCopyInto(out *ServiceLinkedRoleStatus) {
 		*out = new(string)
 		**out = **in
 	}
-	if in.Tags != nil {
-		in, out := &in.Tags, &out.Tags
-		*out = make([]*Tag, len(*in))
-		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(Tag)
-				(*in).DeepCopyInto(*out)
-			}
-		}
-	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceLinkedRoleStatus.
@@ -2313,6 +3046,36 @@ func (in *TrackedActionLastAccessed) DeepCopy() *TrackedActionLastAccessed {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustPreset) DeepCopyInto(out *TrustPreset) {
+	*out = *in
+	if in.Preset != nil {
+		in, out := &in.Preset, &out.Preset
+		*out = new(string)
+		**out = **in
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(string)
+		**out = **in
+	}
+	if in.SourceAccount != nil {
+		in, out := &in.SourceAccount, &out.SourceAccount
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustPreset.
+func (in *TrustPreset) DeepCopy() *TrustPreset {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustPreset)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *User) DeepCopyInto(out *User) {
 	*out = *in
@@ -2430,6 +3193,22 @@ func (in *UserList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserSpec) DeepCopyInto(out *UserSpec) {
 	*out = *in
+	if in.AWSManagedPolicies != nil {
+		in, out := &in.AWSManagedPolicies, &out.AWSManagedPolicies
+		*out = make([]*string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(string)
+				**out = **in
+			}
+		}
+	}
+	if in.DeletionCleanup != nil {
+		in, out := &in.DeletionCleanup, &out.DeletionCleanup
+		*out = new(string)
+		**out = **in
+	}
 	if in.InlinePolicies != nil {
 		in, out := &in.InlinePolicies, &out.InlinePolicies
 		*out = make(map[string]*string, len(*in))
@@ -2488,6 +3267,11 @@ func (in *UserSpec) DeepCopyInto(out *UserSpec) {
 			}
 		}
 	}
+	if in.ServiceLastAccessedGranularity != nil {
+		in, out := &in.ServiceLastAccessedGranularity, &out.ServiceLastAccessedGranularity
+		*out = new(string)
+		**out = **in
+	}
 	if in.Tags != nil {
 		in, out := &in.Tags, &out.Tags
 		*out = make([]*Tag, len(*in))
@@ -2538,6 +3322,27 @@ func (in *UserStatus) DeepCopyInto(out *UserStatus) {
 		in, out := &in.PasswordLastUsed, &out.PasswordLastUsed
 		*out = (*in).DeepCopy()
 	}
+	if in.RenderedInlinePolicies != nil {
+		in, out := &in.RenderedInlinePolicies, &out.RenderedInlinePolicies
+		*out = make(map[string]*string, len(*in))
+		for key, val := range *in {
+			var outVal *string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(string)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ServiceLastAccessed != nil {
+		in, out := &in.ServiceLastAccessed, &out.ServiceLastAccessed
+		*out = new(ServiceLastAccessedReport)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.UserID != nil {
 		in, out := &in.UserID, &out.UserID
 		*out = new(string)
@@ -0,0 +1,160 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Command export writes the existing IAM resources of an account as custom
+// resource manifests, annotated for the controller to adopt them. It reads
+// IAM with the read path of the controller's resource managers and needs no
+// Kubernetes cluster.
+//
+//	export --aws-region us-west-2 --path-prefix /platform/ --output iam.yaml
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackmetrics "github.com/aws-controllers-k8s/runtime/pkg/metrics"
+	acktypes "github.com/aws-controllers-k8s/runtime/pkg/types"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	flag "github.com/spf13/pflag"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	"github.com/aws-controllers-k8s/iam-controller/pkg/importer"
+	svcresource "github.com/aws-controllers-k8s/iam-controller/pkg/resource"
+
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/group"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/instance_profile"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/open_id_connect_provider"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/policy"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/role"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/user"
+)
+
+func main() {
+	var (
+		region      string
+		endpointURL string
+		accountID   string
+		kinds       []string
+		pathPrefix  string
+		tags        map[string]string
+		namespace   string
+		output      string
+	)
+	flag.StringVar(&region, "aws-region", "", "The AWS region of the IAM endpoint. Defaults to the region of the AWS configuration.")
+	flag.StringVar(&endpointURL, "aws-endpoint-url", "", "The URL of the IAM endpoint.")
+	flag.StringVar(&accountID, "aws-account-id", "", "The AWS account ID. Looked up with STS when empty.")
+	flag.StringSliceVar(&kinds, "kinds", nil, "The kinds of custom resources to export. All of them when empty.")
+	flag.StringVar(&pathPrefix, "path-prefix", "", "Only export the IAM resources whose path starts with this prefix.")
+	flag.StringToStringVar(&tags, "tags", nil, "Only export the IAM resources carrying all of these tags. An empty value matches any value.")
+	flag.StringVar(&namespace, "namespace", "default", "The namespace of the exported custom resources.")
+	flag.StringVarP(&output, "output", "o", "-", "The file the manifests are written to, - for the standard output.")
+	flag.Parse()
+
+	if err := run(context.Background(), region, endpointURL, accountID, importer.Options{
+		Kinds:      kinds,
+		PathPrefix: pathPrefix,
+		Tags:       toTags(tags),
+		Namespace:  namespace,
+	}, output); err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run exports the IAM resources selected by the supplied options to the
+// supplied output file.
+func run(
+	ctx context.Context,
+	region string,
+	endpointURL string,
+	accountID string,
+	opts importer.Options,
+	output string,
+) error {
+	optFns := []func(*config.LoadOptions) error{}
+	if region != "" {
+		optFns = append(optFns, config.WithRegion(region))
+	}
+	clientcfg, err := config.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return err
+	}
+	if endpointURL != "" {
+		clientcfg.BaseEndpoint = aws.String(endpointURL)
+	}
+	if accountID == "" {
+		resp, err := sts.NewFromConfig(clientcfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+		if err != nil {
+			return fmt.Errorf("looking up the AWS account ID: %w", err)
+		}
+		accountID = aws.ToString(resp.Account)
+	}
+
+	factories := []acktypes.AWSResourceManagerFactory{}
+	for _, f := range svcresource.GetManagerFactories() {
+		factories = append(factories, f)
+	}
+	imp, err := importer.New(
+		svcsdk.NewFromConfig(clientcfg),
+		factories,
+		ackcfg.Config{Region: clientcfg.Region, EndpointURL: endpointURL},
+		clientcfg,
+		zap.New(zap.WriteTo(os.Stderr)),
+		ackmetrics.NewMetrics("iam"),
+		ackv1alpha1.AWSAccountID(accountID),
+		ackv1alpha1.AWSRegion(clientcfg.Region),
+	)
+	if err != nil {
+		return err
+	}
+	resources, err := imp.Import(ctx, opts)
+	if err != nil {
+		return err
+	}
+	importer.RewritePolicyRefs(resources)
+
+	w := os.Stdout
+	if output != "-" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return importer.WriteYAML(w, resources)
+}
+
+// toTags returns the supplied tags, sorted by key.
+func toTags(tags map[string]string) []*svcapitypes.Tag {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	res := []*svcapitypes.Tag{}
+	for _, k := range keys {
+		key, value := k, tags[k]
+		res = append(res, &svcapitypes.Tag{Key: &key, Value: &value})
+	}
+	return res
+}
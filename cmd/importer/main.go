@@ -0,0 +1,148 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Command importer runs the IAMImport reconciler next to the controller. It
+// takes the controller's flags, runs under the controller's service account
+// and is deployed with config/importer.
+package main
+
+import (
+	"context"
+	"os"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackrt "github.com/aws-controllers-k8s/runtime/pkg/runtime"
+	acktypes "github.com/aws-controllers-k8s/runtime/pkg/types"
+	flag "github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrlrt "sigs.k8s.io/controller-runtime"
+	ctrlrtcache "sigs.k8s.io/controller-runtime/pkg/cache"
+	ctrlrthealthz "sigs.k8s.io/controller-runtime/pkg/healthz"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	svctypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	"github.com/aws-controllers-k8s/iam-controller/pkg/importer"
+	svcresource "github.com/aws-controllers-k8s/iam-controller/pkg/resource"
+
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/group"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/instance_profile"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/open_id_connect_provider"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/policy"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/role"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/user"
+
+	"github.com/aws-controllers-k8s/iam-controller/pkg/version"
+)
+
+var (
+	awsServiceAPIGroup = "iam.services.k8s.aws"
+	awsServiceAlias    = "iam"
+	scheme             = runtime.NewScheme()
+	setupLog           = ctrlrt.Log.WithName("setup")
+)
+
+func init() {
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	_ = svctypes.AddToScheme(scheme)
+	_ = ackv1alpha1.AddToScheme(scheme)
+}
+
+func main() {
+	var ackCfg ackcfg.Config
+	ackCfg.BindFlags()
+	flag.Parse()
+	ackCfg.SetupLogger()
+
+	if err := run(context.Background(), ackCfg); err != nil {
+		setupLog.Error(err, "unable to run the IAMImport reconciler", "aws.service", awsServiceAlias)
+		os.Exit(1)
+	}
+}
+
+// run validates the supplied configuration and runs the IAMImport reconciler
+// until the process is signaled to stop.
+func run(ctx context.Context, ackCfg ackcfg.Config) error {
+	managerFactories := svcresource.GetManagerFactories()
+	resourceGVKs := []schema.GroupVersionKind{svctypes.GroupVersion.WithKind("IAMImport")}
+	for _, mf := range managerFactories {
+		resourceGVKs = append(resourceGVKs, mf.ResourceDescriptor().GroupVersionKind())
+	}
+	if err := ackCfg.Validate(ctx, ackcfg.WithGVKs(resourceGVKs)); err != nil {
+		return err
+	}
+
+	watchNamespaces := map[string]ctrlrtcache.Config{}
+	namespaces, err := ackCfg.GetWatchNamespaces()
+	if err != nil {
+		return err
+	}
+	for _, namespace := range namespaces {
+		watchNamespaces[namespace] = ctrlrtcache.Config{}
+	}
+	watchSelectors, err := ackCfg.ParseWatchSelectors()
+	if err != nil {
+		return err
+	}
+	mgr, err := ctrlrt.NewManager(ctrlrt.GetConfigOrDie(), ctrlrt.Options{
+		Scheme: scheme,
+		Cache: ctrlrtcache.Options{
+			Scheme:               scheme,
+			DefaultNamespaces:    watchNamespaces,
+			DefaultLabelSelector: watchSelectors,
+		},
+		Metrics:                 metricsserver.Options{BindAddress: ackCfg.MetricsAddr},
+		LeaderElection:          ackCfg.EnableLeaderElection,
+		LeaderElectionID:        "ack-importer-" + awsServiceAPIGroup,
+		LeaderElectionNamespace: ackCfg.LeaderElectionNamespace,
+		HealthProbeBindAddress:  ackCfg.HealthzAddr,
+		LivenessEndpointName:    "/healthz",
+		ReadinessEndpointName:   "/readyz",
+	})
+	if err != nil {
+		return err
+	}
+
+	setupLog.Info(
+		"initializing IAMImport reconciler",
+		"aws.service", awsServiceAlias,
+		"version", version.GitVersion,
+	)
+	sc := ackrt.NewServiceController(
+		awsServiceAlias, awsServiceAPIGroup,
+		acktypes.VersionInfo{
+			GitCommit:  version.GitCommit,
+			GitVersion: version.GitVersion,
+			BuildDate:  version.BuildDate,
+		},
+	).WithLogger(
+		ctrlrt.Log,
+	).WithResourceManagerFactories(
+		managerFactories,
+	)
+	if err := importer.NewReconciler(sc, ctrlrt.Log, ackCfg).BindControllerManager(mgr); err != nil {
+		return err
+	}
+	if err := mgr.AddHealthzCheck("health", ctrlrthealthz.Ping); err != nil {
+		return err
+	}
+	if err := mgr.AddReadyzCheck("check", ctrlrthealthz.Ping); err != nil {
+		return err
+	}
+
+	setupLog.Info("starting manager", "aws.service", awsServiceAlias)
+	return mgr.Start(ctrlrt.SetupSignalHandler())
+}
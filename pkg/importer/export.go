@@ -0,0 +1,82 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package importer
+
+import (
+	"fmt"
+	"io"
+
+	"sigs.k8s.io/yaml"
+)
+
+// RewritePolicyRefs replaces the ARNs of the managed policies attached to the
+// Roles, Users and Groups of the supplied resources with references to the
+// Policy custom resources generated for them. A Role, User or Group cannot
+// set both policies and policyRefs, so its policies are only rewritten when
+// every one of them has a Policy among the supplied resources. Those with an
+// AWS managed policy, which is never exported, keep their ARNs.
+func RewritePolicyRefs(resources []*Resource) {
+	policies := map[string]string{}
+	for _, r := range resources {
+		if r.Kind == KindPolicy && r.ARN != "" {
+			policies[r.ARN] = r.Object.GetName()
+		}
+	}
+
+	for _, r := range resources {
+		if r.Kind != KindRole && r.Kind != KindUser && r.Kind != KindGroup {
+			continue
+		}
+		spec, ok := r.Object.Object["spec"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		arns, _ := spec["policies"].([]interface{})
+		if len(arns) == 0 {
+			continue
+		}
+		refs := make([]interface{}, 0, len(arns))
+		for _, arn := range arns {
+			s, _ := arn.(string)
+			name, ok := policies[s]
+			if !ok {
+				refs = nil
+				break
+			}
+			refs = append(refs, map[string]interface{}{
+				"from": map[string]interface{}{"name": name},
+			})
+		}
+		if refs == nil {
+			continue
+		}
+		delete(spec, "policies")
+		spec["policyRefs"] = refs
+	}
+}
+
+// WriteYAML writes the supplied resources to the supplied writer as a stream
+// of YAML documents.
+func WriteYAML(w io.Writer, resources []*Resource) error {
+	for _, r := range resources {
+		b, err := yaml.Marshal(r.Object.Object)
+		if err != nil {
+			return fmt.Errorf("marshaling %s %s: %w", r.Kind, r.Object.GetName(), err)
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
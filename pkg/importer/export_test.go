@@ -0,0 +1,104 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package importer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestResource(kind string, name string, arn string, spec map[string]interface{}) *Resource {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "iam.services.k8s.aws/v1alpha1",
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name, "namespace": "iam"},
+		"spec":       spec,
+	}}
+	return &Resource{Kind: kind, ARN: arn, Object: u}
+}
+
+func TestRewritePolicyRefs(t *testing.T) {
+	const (
+		readerARN  = "arn:aws:iam::123456789012:policy/reader"
+		writerARN  = "arn:aws:iam::123456789012:policy/writer"
+		managedARN = "arn:aws:iam::aws:policy/ReadOnlyAccess"
+	)
+	reader := newTestResource(KindPolicy, "reader", readerARN, map[string]interface{}{"name": "reader"})
+	writer := newTestResource(KindPolicy, "writer", writerARN, map[string]interface{}{"name": "writer"})
+	role := newTestResource(KindRole, "app", "", map[string]interface{}{
+		"policies": []interface{}{readerARN, managedARN},
+	})
+	user := newTestResource(KindUser, "ci", "", map[string]interface{}{
+		"policies": []interface{}{readerARN, writerARN},
+	})
+	group := newTestResource(KindGroup, "ops", "", map[string]interface{}{
+		"policies": []interface{}{managedARN},
+	})
+
+	RewritePolicyRefs([]*Resource{reader, writer, role, user, group})
+
+	// A policy without a Policy custom resource keeps every ARN, since
+	// policies and policyRefs cannot be set together.
+	assert.Equal(t, map[string]interface{}{
+		"policies": []interface{}{readerARN, managedARN},
+	}, role.Object.Object["spec"])
+	assert.Equal(t, map[string]interface{}{
+		"policyRefs": []interface{}{
+			map[string]interface{}{"from": map[string]interface{}{"name": "reader"}},
+			map[string]interface{}{"from": map[string]interface{}{"name": "writer"}},
+		},
+	}, user.Object.Object["spec"])
+	assert.Equal(t, map[string]interface{}{
+		"policies": []interface{}{managedARN},
+	}, group.Object.Object["spec"])
+
+	for _, r := range []*Resource{role, user, group} {
+		spec := r.Object.Object["spec"].(map[string]interface{})
+		assert.False(t, spec["policies"] != nil && spec["policyRefs"] != nil,
+			"%s %s sets both policies and policyRefs", r.Kind, r.Object.GetName())
+	}
+}
+
+func TestWriteYAML(t *testing.T) {
+	var b bytes.Buffer
+	err := WriteYAML(&b, []*Resource{
+		newTestResource(KindPolicy, "reader", "", map[string]interface{}{"name": "reader"}),
+		newTestResource(KindRole, "app", "", map[string]interface{}{
+			"inlinePolicies": map[string]interface{}{"s3": `{"Version":"2012-10-17"}`},
+		}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `---
+apiVersion: iam.services.k8s.aws/v1alpha1
+kind: Policy
+metadata:
+  name: reader
+  namespace: iam
+spec:
+  name: reader
+---
+apiVersion: iam.services.k8s.aws/v1alpha1
+kind: Role
+metadata:
+  name: app
+  namespace: iam
+spec:
+  inlinePolicies:
+    s3: '{"Version":"2012-10-17"}'
+`, b.String())
+}
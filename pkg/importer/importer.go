@@ -0,0 +1,443 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package importer turns the existing IAM resources of an account into
+// custom resources, reading each one with the read path of its resource
+// manager.
+package importer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackmetrics "github.com/aws-controllers-k8s/runtime/pkg/metrics"
+	acktypes "github.com/aws-controllers-k8s/runtime/pkg/types"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+const (
+	// KindRole is the kind of the custom resources generated for roles.
+	KindRole = "Role"
+	// KindUser is the kind of the custom resources generated for users.
+	KindUser = "User"
+	// KindGroup is the kind of the custom resources generated for groups.
+	KindGroup = "Group"
+	// KindPolicy is the kind of the custom resources generated for
+	// customer managed policies.
+	KindPolicy = "Policy"
+	// KindInstanceProfile is the kind of the custom resources generated for
+	// instance profiles.
+	KindInstanceProfile = "InstanceProfile"
+	// KindOpenIDConnectProvider is the kind of the custom resources generated
+	// for OpenID Connect providers.
+	KindOpenIDConnectProvider = "OpenIDConnectProvider"
+
+	// serviceLinkedRolePathPrefix is the path prefix of service-linked
+	// roles, which are not imported as Roles.
+	serviceLinkedRolePathPrefix = "/aws-service-role/"
+	// maxNameLength is the maximum length of a Kubernetes object name.
+	maxNameLength = 253
+	// nameHashLength is the length of the hash of the ARN suffixing the names
+	// KubernetesName has to change.
+	nameHashLength = 8
+)
+
+// Kinds are the kinds of custom resources an import generates, in the order
+// they are imported.
+var Kinds = []string{
+	KindPolicy, KindRole, KindUser, KindGroup, KindInstanceProfile, KindOpenIDConnectProvider,
+}
+
+// ListAPI is the subset of the IAM API used to list the resources to import.
+// It is satisfied by *svcsdk.Client.
+type ListAPI interface {
+	svcsdk.ListRolesAPIClient
+	svcsdk.ListUsersAPIClient
+	svcsdk.ListGroupsAPIClient
+	svcsdk.ListPoliciesAPIClient
+	svcsdk.ListInstanceProfilesAPIClient
+	ListOpenIDConnectProviders(context.Context, *svcsdk.ListOpenIDConnectProvidersInput, ...func(*svcsdk.Options)) (*svcsdk.ListOpenIDConnectProvidersOutput, error)
+}
+
+// Options selects the IAM resources to import.
+type Options struct {
+	// Kinds are the kinds of custom resources to generate. All of Kinds when
+	// empty.
+	Kinds []string
+	// PathPrefix only selects the IAM resources whose path starts with it.
+	PathPrefix string
+	// Tags only selects the IAM resources carrying all of these tags. A tag
+	// without a value matches any value.
+	Tags []*svcapitypes.Tag
+	// Namespace is the namespace of the generated custom resources.
+	Namespace string
+}
+
+// Resource is a custom resource generated for an IAM resource.
+type Resource struct {
+	// Kind is the kind of the custom resource.
+	Kind string
+	// ARN is the ARN of the IAM resource.
+	ARN string
+	// Object is the custom resource, annotated for ACK to adopt the IAM
+	// resource.
+	Object *unstructured.Unstructured
+}
+
+// entity is an IAM resource listed for import.
+type entity struct {
+	name string
+	arn  string
+}
+
+// reader reads the IAM resources of a kind with its resource manager.
+type reader struct {
+	rd acktypes.AWSResourceDescriptor
+	rm acktypes.AWSResourceManager
+}
+
+// Importer reads the IAM resources of an account into custom resources.
+type Importer struct {
+	sdkapi  ListAPI
+	readers map[string]reader
+}
+
+// New returns an Importer listing IAM resources with the supplied client and
+// reading each one with a resource manager built by the factory of its kind.
+func New(
+	sdkapi ListAPI,
+	factories []acktypes.AWSResourceManagerFactory,
+	cfg ackcfg.Config,
+	clientcfg aws.Config,
+	log logr.Logger,
+	metrics *ackmetrics.Metrics,
+	accountID ackv1alpha1.AWSAccountID,
+	region ackv1alpha1.AWSRegion,
+) (*Importer, error) {
+	readers := map[string]reader{}
+	for _, f := range factories {
+		rd := f.ResourceDescriptor()
+		kind := rd.GroupVersionKind().Kind
+		if !isKind(kind) {
+			continue
+		}
+		rm, err := f.ManagerFor(cfg, clientcfg, log, metrics, nil, accountID, region, "")
+		if err != nil {
+			return nil, fmt.Errorf("creating %s resource manager: %w", kind, err)
+		}
+		readers[kind] = reader{rd: rd, rm: rm}
+	}
+	return &Importer{sdkapi: sdkapi, readers: readers}, nil
+}
+
+// Import lists the IAM resources selected by the supplied options and returns
+// a custom resource, with its spec read from AWS, for each one of them.
+func (i *Importer) Import(ctx context.Context, opts Options) ([]*Resource, error) {
+	kinds := opts.Kinds
+	if len(kinds) == 0 {
+		kinds = Kinds
+	}
+	for _, kind := range kinds {
+		if _, ok := i.readers[kind]; !ok {
+			return nil, fmt.Errorf("unsupported kind %q, must be one of %s", kind, strings.Join(Kinds, ", "))
+		}
+	}
+
+	res := []*Resource{}
+	// Follow the order of Kinds whatever the order of the options.
+	for _, kind := range Kinds {
+		if !containsString(kinds, kind) {
+			continue
+		}
+		// Groups can not be tagged.
+		if kind == KindGroup && len(opts.Tags) > 0 {
+			continue
+		}
+		// OpenID Connect providers have no path.
+		if kind == KindOpenIDConnectProvider && opts.PathPrefix != "" && opts.PathPrefix != "/" {
+			continue
+		}
+		entities, err := i.list(ctx, kind, opts.PathPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("listing %s resources: %w", kind, err)
+		}
+		for _, e := range entities {
+			r, err := i.read(ctx, kind, e, opts)
+			if err != nil {
+				return nil, fmt.Errorf("reading %s %s: %w", kind, e.name, err)
+			}
+			if r != nil {
+				res = append(res, r)
+			}
+		}
+	}
+	return res, nil
+}
+
+// read returns the custom resource of the supplied kind for the supplied IAM
+// resource, or nil if it no longer exists or does not carry the selected
+// tags.
+func (i *Importer) read(
+	ctx context.Context,
+	kind string,
+	e entity,
+	opts Options,
+) (*Resource, error) {
+	rd := i.readers[kind].rd
+	rm := i.readers[kind].rm
+
+	obj, fields := identify(kind, e)
+	latest, err := rm.ReadOne(ctx, rd.ResourceFromRuntimeObject(obj))
+	if err == ackerr.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	observed, err := runtime.DefaultUnstructuredConverter.ToUnstructured(latest.RuntimeObject())
+	if err != nil {
+		return nil, err
+	}
+	spec, _, _ := unstructured.NestedMap(observed, "spec")
+	if !tagsMatch(spec, opts.Tags) {
+		return nil, nil
+	}
+
+	adoptionFields, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	u.SetGroupVersionKind(rd.GroupVersionKind())
+	u.SetName(KubernetesName(e.name, e.arn))
+	u.SetNamespace(opts.Namespace)
+	u.SetAnnotations(map[string]string{
+		ackv1alpha1.AnnotationAdoptionPolicy: "adopt",
+		ackv1alpha1.AnnotationAdoptionFields: string(adoptionFields),
+	})
+	return &Resource{Kind: kind, ARN: e.arn, Object: u}, nil
+}
+
+// identify returns the custom resource of the supplied kind holding only the
+// identifiers ReadOne needs to read the supplied IAM resource, and the
+// adoption fields holding those identifiers.
+func identify(kind string, e entity) (client.Object, map[string]string) {
+	name := e.name
+	switch kind {
+	case KindRole:
+		return &svcapitypes.Role{Spec: svcapitypes.RoleSpec{Name: &name}}, map[string]string{"name": name}
+	case KindUser:
+		return &svcapitypes.User{Spec: svcapitypes.UserSpec{Name: &name}}, map[string]string{"name": name}
+	case KindGroup:
+		return &svcapitypes.Group{Spec: svcapitypes.GroupSpec{Name: &name}}, map[string]string{"name": name}
+	case KindInstanceProfile:
+		return &svcapitypes.InstanceProfile{Spec: svcapitypes.InstanceProfileSpec{Name: &name}}, map[string]string{"name": name}
+	case KindOpenIDConnectProvider:
+		arn := ackv1alpha1.AWSResourceName(e.arn)
+		return &svcapitypes.OpenIDConnectProvider{
+			Status: svcapitypes.OpenIDConnectProviderStatus{
+				ACKResourceMetadata: &ackv1alpha1.ResourceMetadata{ARN: &arn},
+			},
+		}, map[string]string{"arn": e.arn}
+	default:
+		arn := ackv1alpha1.AWSResourceName(e.arn)
+		return &svcapitypes.Policy{
+			Spec: svcapitypes.PolicySpec{Name: &name},
+			Status: svcapitypes.PolicyStatus{
+				ACKResourceMetadata: &ackv1alpha1.ResourceMetadata{ARN: &arn},
+			},
+		}, map[string]string{"arn": e.arn}
+	}
+}
+
+// list returns the IAM resources of the supplied kind whose path starts with
+// the supplied prefix.
+func (i *Importer) list(ctx context.Context, kind string, pathPrefix string) ([]entity, error) {
+	var prefix *string
+	if pathPrefix != "" {
+		prefix = &pathPrefix
+	}
+	res := []entity{}
+	switch kind {
+	case KindRole:
+		p := svcsdk.NewListRolesPaginator(i.sdkapi, &svcsdk.ListRolesInput{PathPrefix: prefix})
+		for p.HasMorePages() {
+			page, err := p.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range page.Roles {
+				if strings.HasPrefix(aws.ToString(r.Path), serviceLinkedRolePathPrefix) {
+					continue
+				}
+				res = append(res, entity{name: aws.ToString(r.RoleName), arn: aws.ToString(r.Arn)})
+			}
+		}
+	case KindUser:
+		p := svcsdk.NewListUsersPaginator(i.sdkapi, &svcsdk.ListUsersInput{PathPrefix: prefix})
+		for p.HasMorePages() {
+			page, err := p.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, u := range page.Users {
+				res = append(res, entity{name: aws.ToString(u.UserName), arn: aws.ToString(u.Arn)})
+			}
+		}
+	case KindGroup:
+		p := svcsdk.NewListGroupsPaginator(i.sdkapi, &svcsdk.ListGroupsInput{PathPrefix: prefix})
+		for p.HasMorePages() {
+			page, err := p.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, g := range page.Groups {
+				res = append(res, entity{name: aws.ToString(g.GroupName), arn: aws.ToString(g.Arn)})
+			}
+		}
+	case KindPolicy:
+		p := svcsdk.NewListPoliciesPaginator(i.sdkapi, &svcsdk.ListPoliciesInput{
+			PathPrefix: prefix,
+			// AWS managed policies are attached by ARN, not managed.
+			Scope: svcsdktypes.PolicyScopeTypeLocal,
+		})
+		for p.HasMorePages() {
+			page, err := p.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, pol := range page.Policies {
+				res = append(res, entity{name: aws.ToString(pol.PolicyName), arn: aws.ToString(pol.Arn)})
+			}
+		}
+	case KindInstanceProfile:
+		p := svcsdk.NewListInstanceProfilesPaginator(i.sdkapi, &svcsdk.ListInstanceProfilesInput{PathPrefix: prefix})
+		for p.HasMorePages() {
+			page, err := p.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, ip := range page.InstanceProfiles {
+				res = append(res, entity{name: aws.ToString(ip.InstanceProfileName), arn: aws.ToString(ip.Arn)})
+			}
+		}
+	case KindOpenIDConnectProvider:
+		// OpenID Connect providers have no path, and are not paginated.
+		resp, err := i.sdkapi.ListOpenIDConnectProviders(ctx, &svcsdk.ListOpenIDConnectProvidersInput{})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range resp.OpenIDConnectProviderList {
+			arn := aws.ToString(p.Arn)
+			// The name of a provider is its URL, which follows the
+			// oidc-provider/ resource type in its ARN.
+			_, name, _ := strings.Cut(arn, ":oidc-provider/")
+			res = append(res, entity{name: name, arn: arn})
+		}
+	}
+	return res, nil
+}
+
+// tagsMatch returns true if the tags of the supplied spec include all of the
+// supplied tags. A tag without a value matches any value.
+func tagsMatch(spec map[string]interface{}, tags []*svcapitypes.Tag) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	observed := map[string]string{}
+	list, _, _ := unstructured.NestedSlice(spec, "tags")
+	for _, t := range list {
+		m, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key, _ := m["key"].(string)
+		value, _ := m["value"].(string)
+		observed[key] = value
+	}
+	for _, t := range tags {
+		if t == nil || t.Key == nil {
+			continue
+		}
+		value, ok := observed[*t.Key]
+		if !ok {
+			return false
+		}
+		if t.Value != nil && *t.Value != "" && *t.Value != value {
+			return false
+		}
+	}
+	return true
+}
+
+// invalidNameChars matches the characters of IAM names that Kubernetes
+// object names can not hold.
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// KubernetesName returns a valid Kubernetes object name for the IAM resource
+// of the supplied name and ARN: lowercased, with the characters Kubernetes
+// rejects replaced by dashes.
+//
+// IAM names are unique per kind regardless of case, so the names that are
+// valid as they are cannot collide. The others, such as App_Reader which
+// would collide with app-reader, are suffixed with a short hash of the ARN.
+func KubernetesName(name string, arn string) string {
+	res := invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	res = strings.Trim(res, "-.")
+	if res == name && len(res) <= maxNameLength {
+		return res
+	}
+	sum := sha256.Sum256([]byte(arn))
+	suffix := hex.EncodeToString(sum[:])[:nameHashLength]
+	if len(res) > maxNameLength-len(suffix)-1 {
+		res = strings.TrimRight(res[:maxNameLength-len(suffix)-1], "-.")
+	}
+	if res == "" {
+		return suffix
+	}
+	return res + "-" + suffix
+}
+
+// isKind returns true if custom resources of the supplied kind can be
+// generated by an import.
+func isKind(kind string) bool {
+	return containsString(Kinds, kind)
+}
+
+// containsString returns true if the supplied slice contains the supplied
+// string.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,291 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package importer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackmetrics "github.com/aws-controllers-k8s/runtime/pkg/metrics"
+	acktypes "github.com/aws-controllers-k8s/runtime/pkg/types"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	svcresource "github.com/aws-controllers-k8s/iam-controller/pkg/resource"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/open_id_connect_provider"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/policy"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/role"
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+)
+
+const testTrustPolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole"}]}`
+
+// testBackend is the IAM backend of the importer tests. The resource manager
+// factories cache their managers by account and region, so that the tests
+// share a single backend and must not reuse the names of each other's IAM
+// resources.
+var testBackend = fakeiam.New()
+
+// newTestImporter returns an Importer reading testBackend with the
+// controller's resource managers, and an IAM client of testBackend.
+func newTestImporter(t *testing.T) (*Importer, *svcsdk.Client) {
+	factories := []acktypes.AWSResourceManagerFactory{}
+	for _, f := range svcresource.GetManagerFactories() {
+		factories = append(factories, f)
+	}
+	api := svcsdk.NewFromConfig(testBackend.Config())
+	i, err := New(
+		api, factories, ackcfg.Config{}, testBackend.Config(), logr.Discard(),
+		ackmetrics.NewMetrics("iam"), fakeiam.AccountID, fakeiam.Region,
+	)
+	require.NoError(t, err)
+	return i, api
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.TODO()
+	i, api := newTestImporter(t)
+	_, err := api.CreateRole(ctx, &svcsdk.CreateRoleInput{
+		RoleName:                 aws.String("App_Reader"),
+		Path:                     aws.String("/apps/"),
+		AssumeRolePolicyDocument: aws.String(testTrustPolicy),
+		Tags:                     []svcsdktypes.Tag{{Key: aws.String("team"), Value: aws.String("a")}},
+	})
+	require.NoError(t, err)
+	_, err = api.CreateRole(ctx, &svcsdk.CreateRoleInput{
+		RoleName:                 aws.String("untagged"),
+		Path:                     aws.String("/apps/"),
+		AssumeRolePolicyDocument: aws.String(testTrustPolicy),
+	})
+	require.NoError(t, err)
+	_, err = api.CreateServiceLinkedRole(ctx, &svcsdk.CreateServiceLinkedRoleInput{
+		AWSServiceName: aws.String("support.amazonaws.com"),
+	})
+	require.NoError(t, err)
+	_, err = api.CreatePolicy(ctx, &svcsdk.CreatePolicyInput{
+		PolicyName:     aws.String("reader"),
+		PolicyDocument: aws.String(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`),
+	})
+	require.NoError(t, err)
+
+	// Service-linked roles and AWS managed policies are not imported.
+	resources, err := i.Import(ctx, Options{
+		Kinds:     []string{KindRole, KindPolicy},
+		Namespace: "imported",
+	})
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	// Policies are imported first, and adopted by ARN.
+	assert.Equal(t, KindPolicy, resources[0].Kind)
+	assert.Equal(t, map[string]string{
+		ackv1alpha1.AnnotationAdoptionPolicy: "adopt",
+		ackv1alpha1.AnnotationAdoptionFields: `{"arn":"arn:aws:iam::123456789012:policy/reader"}`,
+	}, resources[0].Object.GetAnnotations())
+
+	role := resources[1].Object
+	assert.Equal(t, "Role", role.GetKind())
+	assert.Equal(t, "iam.services.k8s.aws/v1alpha1", role.GetAPIVersion())
+	assert.Equal(t, KubernetesName("App_Reader", "arn:aws:iam::123456789012:role/apps/App_Reader"), role.GetName())
+	assert.Equal(t, "imported", role.GetNamespace())
+	assert.Equal(t, `{"name":"App_Reader"}`, role.GetAnnotations()[ackv1alpha1.AnnotationAdoptionFields])
+	assert.Equal(t, "arn:aws:iam::123456789012:role/apps/App_Reader", resources[1].ARN)
+	assert.JSONEq(t, testTrustPolicy, role.Object["spec"].(map[string]interface{})["assumeRolePolicyDocument"].(string))
+	assert.NotContains(t, role.Object, "status")
+	assert.Equal(t, "untagged", resources[2].Object.GetName())
+
+	resources, err = i.Import(ctx, Options{
+		Kinds: []string{KindRole},
+		Tags:  []*svcapitypes.Tag{{Key: aws.String("team")}},
+	})
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/apps/App_Reader", resources[0].ARN)
+
+	resources, err = i.Import(ctx, Options{Kinds: []string{KindRole}, PathPrefix: "/platform/"})
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+
+	_, err = i.Import(ctx, Options{Kinds: []string{"ServiceLinkedRole"}})
+	assert.ErrorContains(t, err, "unsupported kind")
+}
+
+func TestImport_OpenIDConnectProviders(t *testing.T) {
+	ctx := context.TODO()
+	i, api := newTestImporter(t)
+	_, err := api.CreateOpenIDConnectProvider(ctx, &svcsdk.CreateOpenIDConnectProviderInput{
+		Url:          aws.String("https://token.actions.githubusercontent.com"),
+		ClientIDList: []string{"sts.amazonaws.com"},
+	})
+	require.NoError(t, err)
+
+	resources, err := i.Import(ctx, Options{Kinds: []string{KindOpenIDConnectProvider}})
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "token.actions.githubusercontent.com", resources[0].Object.GetName())
+	assert.Equal(t,
+		`{"arn":"arn:aws:iam::123456789012:oidc-provider/token.actions.githubusercontent.com"}`,
+		resources[0].Object.GetAnnotations()[ackv1alpha1.AnnotationAdoptionFields],
+	)
+
+	// Providers have no path.
+	resources, err = i.Import(ctx, Options{
+		Kinds:      []string{KindOpenIDConnectProvider},
+		PathPrefix: "/platform/",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+}
+
+func TestTagsMatch(t *testing.T) {
+	spec := map[string]interface{}{"tags": []interface{}{
+		map[string]interface{}{"key": "team", "value": "a"},
+		map[string]interface{}{"key": "env", "value": "prod"},
+	}}
+	assert.True(t, tagsMatch(spec, nil))
+	assert.True(t, tagsMatch(spec, []*svcapitypes.Tag{{Key: aws.String("team")}}))
+	assert.True(t, tagsMatch(spec, []*svcapitypes.Tag{
+		{Key: aws.String("team"), Value: aws.String("a")},
+		{Key: aws.String("env"), Value: aws.String("prod")},
+	}))
+	assert.False(t, tagsMatch(spec, []*svcapitypes.Tag{{Key: aws.String("team"), Value: aws.String("b")}}))
+	assert.False(t, tagsMatch(spec, []*svcapitypes.Tag{{Key: aws.String("owner")}}))
+	assert.False(t, tagsMatch(map[string]interface{}{}, []*svcapitypes.Tag{{Key: aws.String("team")}}))
+}
+
+func TestKubernetesName(t *testing.T) {
+	assert.Equal(t, "reader", KubernetesName("reader", "arn:aws:iam::123456789012:role/reader"))
+	assert.Equal(t, "token.actions.githubusercontent.com", KubernetesName(
+		"token.actions.githubusercontent.com",
+		"arn:aws:iam::123456789012:oidc-provider/token.actions.githubusercontent.com",
+	))
+
+	// Names Kubernetes rejects are changed and suffixed with a hash of the
+	// ARN, so that they do not collide with the names kept as they are.
+	name := KubernetesName("App_Reader", "arn:aws:iam::123456789012:role/App_Reader")
+	assert.Regexp(t, `^app-reader-[0-9a-f]{8}$`, name)
+	assert.NotEqual(t, name, KubernetesName("APP_READER", "arn:aws:iam::210987654321:role/APP_READER"))
+	assert.Regexp(t, `^ci-deploy-example.com-[0-9a-f]{8}$`, KubernetesName("ci+deploy@example.com", "arn:aws:iam::123456789012:user/ci+deploy@example.com"))
+	assert.Regexp(t, `^x-[0-9a-f]{8}$`, KubernetesName("_x_", "arn:aws:iam::123456789012:role/_x_"))
+	assert.Regexp(t, `^[0-9a-f]{8}$`, KubernetesName("__", "arn:aws:iam::123456789012:role/__"))
+
+	long := KubernetesName(strings.Repeat("a", 300), "arn:aws:iam::123456789012:role/"+strings.Repeat("a", 300))
+	assert.Len(t, long, maxNameLength)
+	assert.Regexp(t, `-[0-9a-f]{8}$`, long)
+}
+
+func TestImport_NameCollisions(t *testing.T) {
+	ctx := context.TODO()
+	i, api := newTestImporter(t)
+	for _, name := range []string{"Ops_Admin", "ops-admin"} {
+		_, err := api.CreatePolicy(ctx, &svcsdk.CreatePolicyInput{
+			PolicyName:     aws.String(name),
+			Path:           aws.String("/collisions/"),
+			PolicyDocument: aws.String(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`),
+		})
+		require.NoError(t, err)
+	}
+
+	resources, err := i.Import(ctx, Options{Kinds: []string{KindPolicy}, PathPrefix: "/collisions/"})
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	names := map[string]string{}
+	for _, res := range resources {
+		names[res.ARN] = res.Object.GetName()
+	}
+	assert.Equal(t, "ops-admin", names["arn:aws:iam::123456789012:policy/collisions/ops-admin"])
+	assert.Regexp(t, `^ops-admin-[0-9a-f]{8}$`, names["arn:aws:iam::123456789012:policy/collisions/Ops_Admin"])
+}
+
+func TestOptionsFor(t *testing.T) {
+	imp := &svcapitypes.IAMImport{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "imports"},
+		Spec: svcapitypes.IAMImportSpec{
+			Kinds:      []*string{aws.String(KindRole)},
+			PathPrefix: aws.String("/legacy/"),
+		},
+	}
+	opts, err := OptionsFor(imp, false)
+	require.NoError(t, err)
+	assert.Equal(t, Options{Kinds: []string{KindRole}, PathPrefix: "/legacy/", Namespace: "imports"}, opts)
+
+	imp.Spec.TargetNamespace = aws.String("imports")
+	_, err = OptionsFor(imp, false)
+	assert.NoError(t, err)
+
+	imp.Spec.TargetNamespace = aws.String("iam")
+	_, err = OptionsFor(imp, false)
+	assert.ErrorIs(t, err, ackerr.ResourceReferenceCrossNamespaceNotAllowed)
+
+	opts, err = OptionsFor(imp, true)
+	require.NoError(t, err)
+	assert.Equal(t, "iam", opts.Namespace)
+}
+
+func TestReconciler_Create(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, svcapitypes.AddToScheme(s))
+	arn := "arn:aws:iam::123456789012:role/App_Reader"
+	imported := func(name string) *Resource {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{}}}
+		u.SetGroupVersionKind(svcapitypes.GroupVersion.WithKind(KindRole))
+		u.SetName(name)
+		u.SetNamespace("imports")
+		u.SetAnnotations(map[string]string{
+			ackv1alpha1.AnnotationAdoptionPolicy: "adopt",
+			ackv1alpha1.AnnotationAdoptionFields: `{"name":"App_Reader"}`,
+		})
+		return &Resource{Kind: KindRole, ARN: arn, Object: u}
+	}
+	syncedARN := ackv1alpha1.AWSResourceName(arn)
+	r := &Reconciler{kc: fake.NewClientBuilder().WithScheme(s).WithObjects(
+		// Adopting the same role.
+		imported("adopting").Object,
+		// Managing the same role.
+		&svcapitypes.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "synced", Namespace: "imports"},
+			Spec:       svcapitypes.RoleSpec{Name: aws.String("App_Reader")},
+			Status: svcapitypes.RoleStatus{
+				ACKResourceMetadata: &ackv1alpha1.ResourceMetadata{ARN: &syncedARN},
+			},
+		},
+		// Managing another role.
+		&svcapitypes.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-reader", Namespace: "imports"},
+			Spec:       svcapitypes.RoleSpec{Name: aws.String("app-reader")},
+		},
+	).Build()}
+
+	require.NoError(t, r.create(context.TODO(), imported("new")))
+	created := &svcapitypes.Role{}
+	require.NoError(t, r.kc.Get(context.TODO(), client.ObjectKey{Namespace: "imports", Name: "new"}, created))
+
+	assert.NoError(t, r.create(context.TODO(), imported("adopting")))
+	assert.NoError(t, r.create(context.TODO(), imported("synced")))
+	assert.ErrorContains(t, r.create(context.TODO(), imported("app-reader")), "already exists for another IAM resource")
+}
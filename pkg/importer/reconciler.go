@@ -0,0 +1,275 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package importer
+
+import (
+	"context"
+	"fmt"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackmetrics "github.com/aws-controllers-k8s/runtime/pkg/metrics"
+	ackrt "github.com/aws-controllers-k8s/runtime/pkg/runtime"
+	acktypes "github.com/aws-controllers-k8s/runtime/pkg/types"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrlrt "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=iam.services.k8s.aws,resources=iamimports,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=iam.services.k8s.aws,resources=iamimports/status,verbs=get;update;patch
+
+const (
+	// kindIAMImport is the kind of the IAMImport custom resource.
+	kindIAMImport = "IAMImport"
+	// LabelImport is the label carrying the name of the IAMImport that
+	// generated a custom resource.
+	LabelImport = "iam.services.k8s.aws/import"
+)
+
+// Reconciler generates the custom resources requested by IAMImports. An
+// IAMImport is imported once per generation: the generated custom resources
+// are then managed like any other, and are left in place when the IAMImport
+// is deleted.
+type Reconciler struct {
+	sc      acktypes.ServiceController
+	log     logr.Logger
+	cfg     ackcfg.Config
+	metrics *ackmetrics.Metrics
+	kc      client.Client
+}
+
+// NewReconciler returns a Reconciler importing IAM resources with the
+// resource managers of the supplied service controller.
+func NewReconciler(
+	sc acktypes.ServiceController,
+	log logr.Logger,
+	cfg ackcfg.Config,
+) *Reconciler {
+	return &Reconciler{
+		sc:      sc,
+		log:     log.WithName("iamimport"),
+		cfg:     cfg,
+		metrics: ackmetrics.NewMetrics("iam"),
+	}
+}
+
+// BindControllerManager sets up the Reconciler with the supplied manager. It
+// does nothing when the IAMImport CRD is not installed, or when the
+// --reconcile-resources flag leaves IAMImport out.
+func (r *Reconciler) BindControllerManager(mgr ctrlrt.Manager) error {
+	reconcileResources, err := r.cfg.GetReconcileResources()
+	if err != nil {
+		return err
+	}
+	if len(reconcileResources) > 0 && !containsString(reconcileResources, kindIAMImport) {
+		r.log.Info("excluding reconciler for resource kind", "kind", kindIAMImport, "reason", "not in reconcile-resources flag")
+		return nil
+	}
+	gvk := svcapitypes.GroupVersion.WithKind(kindIAMImport)
+	if _, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		r.log.Info("IAMImport CRD not installed. The IAMImport reconciler will not be started")
+		return nil
+	}
+	r.kc = mgr.GetClient()
+	return ctrlrt.NewControllerManagedBy(mgr).
+		For(&svcapitypes.IAMImport{}).
+		Complete(r)
+}
+
+// Reconcile imports the IAM resources selected by an IAMImport whose current
+// generation has not been imported yet.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrlrt.Request) (ctrlrt.Result, error) {
+	imp := &svcapitypes.IAMImport{}
+	if err := r.kc.Get(ctx, req.NamespacedName, imp); err != nil {
+		return ctrlrt.Result{}, client.IgnoreNotFound(err)
+	}
+	if imp.DeletionTimestamp != nil {
+		return ctrlrt.Result{}, nil
+	}
+	if imp.Status.ObservedGeneration != nil && *imp.Status.ObservedGeneration == imp.Generation {
+		return ctrlrt.Result{}, nil
+	}
+	log := r.log.WithValues("namespace", imp.Namespace, "name", imp.Name)
+
+	orig := imp.DeepCopy()
+	imported, err := r.importResources(ctx, imp)
+	if err != nil {
+		log.Error(err, "unable to import IAM resources")
+		message := err.Error()
+		setCondition(imp, corev1.ConditionFalse, &message)
+	} else {
+		message := fmt.Sprintf("imported %d IAM resources", len(imported))
+		setCondition(imp, corev1.ConditionTrue, &message)
+		generation := imp.Generation
+		imp.Status.ObservedGeneration = &generation
+		imp.Status.ImportedResources = imported
+	}
+	if perr := r.kc.Status().Patch(ctx, imp, client.MergeFrom(orig)); perr != nil && err == nil {
+		err = perr
+	}
+	return ctrlrt.Result{}, err
+}
+
+// importResources creates the custom resources for the IAM resources selected
+// by the supplied IAMImport, skipping the ones that already exist for the same
+// IAM resource, and returns all of them.
+func (r *Reconciler) importResources(
+	ctx context.Context,
+	imp *svcapitypes.IAMImport,
+) ([]*svcapitypes.ImportedResource, error) {
+	importer, err := r.newImporter(ctx, imp)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := OptionsFor(imp, r.cfg.EnableCrossNamespace)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := importer.Import(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	imported := []*svcapitypes.ImportedResource{}
+	for _, res := range resources {
+		labels := res.Object.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[LabelImport] = imp.Name
+		res.Object.SetLabels(labels)
+		if err := r.create(ctx, res); err != nil {
+			return nil, fmt.Errorf("creating %s %s: %w", res.Kind, res.Object.GetName(), err)
+		}
+		kind, name, arn := res.Kind, res.Object.GetName(), res.ARN
+		imported = append(imported, &svcapitypes.ImportedResource{Kind: &kind, Name: &name, ARN: &arn})
+	}
+	return imported, nil
+}
+
+// create creates the custom resource of the supplied imported resource. A
+// custom resource of the same name that already refers to the same IAM
+// resource is left as is.
+func (r *Reconciler) create(ctx context.Context, res *Resource) error {
+	err := r.kc.Create(ctx, res.Object)
+	if !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(res.Object.GroupVersionKind())
+	if err := r.kc.Get(ctx, client.ObjectKeyFromObject(res.Object), existing); err != nil {
+		return err
+	}
+	if !refersTo(existing, res) {
+		return fmt.Errorf("a custom resource of the same name already exists for another IAM resource than %s", res.ARN)
+	}
+	return nil
+}
+
+// refersTo returns whether the supplied custom resource refers to the IAM
+// resource of the supplied imported resource, either because it is adopting
+// it with the same adoption fields or because it has already synced it.
+func refersTo(existing *unstructured.Unstructured, res *Resource) bool {
+	field := ackv1alpha1.AnnotationAdoptionFields
+	if fields, ok := existing.GetAnnotations()[field]; ok && fields == res.Object.GetAnnotations()[field] {
+		return true
+	}
+	arn, _, _ := unstructured.NestedString(existing.Object, "status", "ackResourceMetadata", "arn")
+	return arn != "" && arn == res.ARN
+}
+
+// newImporter returns an Importer for the account and region the controller
+// manages.
+func (r *Reconciler) newImporter(ctx context.Context, imp *svcapitypes.IAMImport) (*Importer, error) {
+	region := ackv1alpha1.AWSRegion(r.cfg.Region)
+	var endpointURL *string
+	if r.cfg.EndpointURL != "" {
+		endpointURL = &r.cfg.EndpointURL
+	}
+	clientcfg, err := r.sc.NewAWSConfig(
+		ctx, region, endpointURL, "",
+		svcapitypes.GroupVersion.WithKind(kindIAMImport), imp.Labels,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return New(
+		svcsdk.NewFromConfig(clientcfg),
+		managerFactories(r.sc),
+		r.cfg, clientcfg, r.log, r.metrics,
+		ackv1alpha1.AWSAccountID(r.cfg.AccountID), region,
+	)
+}
+
+// OptionsFor returns the import Options of the supplied IAMImport. A target
+// namespace other than the namespace of the IAMImport requires
+// enableCrossNamespace.
+func OptionsFor(imp *svcapitypes.IAMImport, enableCrossNamespace bool) (Options, error) {
+	namespace, _, err := ackrt.ValidateCrossNamespaceReference(
+		enableCrossNamespace, imp.Namespace, imp.Spec.TargetNamespace, imp.Name,
+	)
+	if err != nil {
+		return Options{}, err
+	}
+	opts := Options{Tags: imp.Spec.Tags, Namespace: namespace}
+	for _, k := range imp.Spec.Kinds {
+		if k != nil {
+			opts.Kinds = append(opts.Kinds, *k)
+		}
+	}
+	if imp.Spec.PathPrefix != nil {
+		opts.PathPrefix = *imp.Spec.PathPrefix
+	}
+	return opts, nil
+}
+
+// managerFactories returns the resource manager factories of the supplied
+// service controller.
+func managerFactories(sc acktypes.ServiceController) []acktypes.AWSResourceManagerFactory {
+	res := []acktypes.AWSResourceManagerFactory{}
+	for _, f := range sc.GetResourceManagerFactories() {
+		res = append(res, f)
+	}
+	return res
+}
+
+// setCondition sets the ResourceSynced condition of the supplied IAMImport,
+// which reports whether its current generation was imported.
+func setCondition(imp *svcapitypes.IAMImport, status corev1.ConditionStatus, message *string) {
+	now := metav1.Now()
+	for _, c := range imp.Status.Conditions {
+		if c.Type == ackv1alpha1.ConditionTypeResourceSynced {
+			if c.Status != status {
+				c.LastTransitionTime = &now
+			}
+			c.Status = status
+			c.Message = message
+			return
+		}
+	}
+	imp.Status.Conditions = append(imp.Status.Conditions, &ackv1alpha1.Condition{
+		Type:               ackv1alpha1.ConditionTypeResourceSynced,
+		Status:             status,
+		LastTransitionTime: &now,
+		Message:            message,
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package group
+
+import (
+	"context"
+	"fmt"
+
+	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	corev1 "k8s.io/api/core/v1"
+
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+// removeMembers removes every user from the supplied Group when
+// Spec.RemoveMembersOnDelete is set, so that DeleteGroup does not fail with
+// DeleteConflict. An Event naming each removed member is recorded.
+func (rm *resourceManager) removeMembers(
+	ctx context.Context,
+	r *resource,
+) (err error) {
+	if r.ko.Spec.RemoveMembersOnDelete == nil || !*r.ko.Spec.RemoveMembersOnDelete {
+		return nil
+	}
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.removeMembers")
+	defer func() { exit(err) }()
+
+	members := []string{}
+	paginator := svcsdk.NewGetGroupPaginator(rm.sdkapi, &svcsdk.GetGroupInput{
+		GroupName: r.ko.Spec.Name,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		rm.metrics.RecordAPICall("READ_ONE", "GetGroup", err)
+		if err != nil {
+			return fmt.Errorf("listing group members: %w", err)
+		}
+		for _, u := range page.Users {
+			members = append(members, *u.UserName)
+		}
+	}
+	for _, name := range members {
+		_, err = rm.sdkapi.RemoveUserFromGroup(ctx, &svcsdk.RemoveUserFromGroupInput{
+			GroupName: r.ko.Spec.Name,
+			UserName:  &name,
+		})
+		rm.metrics.RecordAPICall("DELETE", "RemoveUserFromGroup", err)
+		if err != nil {
+			return fmt.Errorf("removing user %s from group: %w", name, err)
+		}
+		recordGroupEvent(ctx, r, "MemberRemoved", "Removed user %s from group", name)
+	}
+	return nil
+}
+
+// recordGroupEvent records a Normal Event about the supplied Group. Failing
+// to get an event recorder is logged and otherwise ignored.
+func recordGroupEvent(
+	ctx context.Context,
+	r *resource,
+	reason string,
+	format string,
+	args ...interface{},
+) {
+	recorder, err := commonutil.EventRecorder()
+	if err != nil {
+		ackrtlog.FromContext(ctx).Info("unable to record event", "reason", reason, "error", err.Error())
+		return
+	}
+	recorder.Eventf(r.ko, corev1.EventTypeNormal, reason, format, args...)
+}
@@ -0,0 +1,127 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package group
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackmetrics "github.com/aws-controllers-k8s/runtime/pkg/metrics"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+func newTestResourceManager(t *testing.T) *resourceManager {
+	rm, err := newResourceManager(
+		ackcfg.Config{}, fakeiam.New().Config(), logr.Discard(), ackmetrics.NewMetrics("iam"),
+		nil, fakeiam.AccountID, fakeiam.Region,
+	)
+	require.NoError(t, err)
+	return rm
+}
+
+// recordCalls makes the IAM client of the supplied resource manager record
+// the operations it calls, with the user they are about if any.
+func recordCalls(rm *resourceManager) *[]string {
+	calls := &[]string{}
+	rm.sdkapi = svcsdk.New(rm.sdkapi.Options(), func(o *svcsdk.Options) {
+		o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+			return stack.Initialize.Add(middleware.InitializeMiddlewareFunc(
+				"RecordCalls",
+				func(
+					ctx context.Context,
+					in middleware.InitializeInput,
+					next middleware.InitializeHandler,
+				) (middleware.InitializeOutput, middleware.Metadata, error) {
+					call := strings.TrimSuffix(strings.TrimPrefix(fmt.Sprintf("%T", in.Parameters), "*iam."), "Input")
+					if input, ok := in.Parameters.(*svcsdk.RemoveUserFromGroupInput); ok {
+						call += " " + aws.ToString(input.UserName)
+					}
+					*calls = append(*calls, call)
+					return next.HandleInitialize(ctx, in)
+				},
+			), middleware.Before)
+		})
+	})
+	return calls
+}
+
+func TestResourceManager_DeleteWithMembers(t *testing.T) {
+	ctx := context.TODO()
+	rm := newTestResourceManager(t)
+	recorder := record.NewFakeRecorder(10)
+	commonutil.SetEventRecorder(recorder)
+
+	name := aws.String("test-group")
+	_, err := rm.sdkapi.CreateGroup(ctx, &svcsdk.CreateGroupInput{GroupName: name})
+	require.NoError(t, err)
+	for _, user := range []string{"alice", "bob"} {
+		_, err = rm.sdkapi.CreateUser(ctx, &svcsdk.CreateUserInput{UserName: aws.String(user)})
+		require.NoError(t, err)
+		_, err = rm.sdkapi.AddUserToGroup(ctx, &svcsdk.AddUserToGroupInput{
+			GroupName: name,
+			UserName:  aws.String(user),
+		})
+		require.NoError(t, err)
+	}
+	calls := recordCalls(rm)
+
+	// Without removeMembersOnDelete, IAM refuses to delete the group.
+	desired := &resource{ko: &svcapitypes.Group{Spec: svcapitypes.GroupSpec{Name: name}}}
+	latest, err := rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	_, err = rm.Delete(ctx, latest)
+	assert.ErrorContains(t, err, "DeleteConflict")
+	assert.NotContains(t, *calls, "RemoveUserFromGroup alice")
+
+	desired.ko.Spec.RemoveMembersOnDelete = aws.Bool(true)
+	latest, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	*calls = nil
+	_, err = rm.Delete(ctx, latest)
+	require.NoError(t, err)
+
+	// Every member is removed before the group is deleted.
+	require.Greater(t, len(*calls), 3)
+	assert.Equal(t, []string{
+		"GetGroup",
+		"RemoveUserFromGroup alice",
+		"RemoveUserFromGroup bob",
+	}, (*calls)[:3])
+	assert.Equal(t, "DeleteGroup", (*calls)[len(*calls)-1])
+	_, err = rm.ReadOne(ctx, desired)
+	assert.Equal(t, ackerr.NotFound, err)
+
+	events := []string{}
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	assert.Equal(t, []string{
+		"Normal MemberRemoved Removed user alice from group",
+		"Normal MemberRemoved Removed user bob from group",
+	}, events)
+}
@@ -15,13 +15,16 @@ package group
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
 	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
 	ackutil "github.com/aws-controllers-k8s/runtime/pkg/util"
 	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/samber/lo"
 
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
 	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
 )
 
@@ -41,16 +44,23 @@ func (rm *resourceManager) syncManagedPolicies(
 	toAdd := []*string{}
 	toDelete := []*string{}
 
-	existingPolicies := latest.ko.Spec.Policies
+	desiredPolicies, err := rm.managedPolicyARNs(ctx, desired.ko)
+	if err != nil {
+		return err
+	}
+	existingPolicies, err := rm.managedPolicyARNs(ctx, latest.ko)
+	if err != nil {
+		return err
+	}
 
-	for _, p := range desired.ko.Spec.Policies {
+	for _, p := range desiredPolicies {
 		if !ackutil.InStringPs(*p, existingPolicies) {
 			toAdd = append(toAdd, p)
 		}
 	}
 
 	for _, p := range existingPolicies {
-		if !ackutil.InStringPs(*p, desired.ko.Spec.Policies) {
+		if !ackutil.InStringPs(*p, desiredPolicies) {
 			toDelete = append(toDelete, p)
 		}
 	}
@@ -251,6 +261,37 @@ func (rm *resourceManager) getInlinePolicies(
 	return res, nil
 }
 
+// policyVariables returns the values of the ${ack:...} variables of the
+// inline policy documents of the supplied Group.
+func (rm *resourceManager) policyVariables(r *resource) commonutil.PolicyVariables {
+	return commonutil.NewPolicyVariables(
+		string(rm.awsAccountID), string(rm.awsPartition), string(rm.awsRegion), r.ko,
+	)
+}
+
+// setRenderedInlinePolicies records the inline policy documents rendered from
+// the declared Group in Status.RenderedInlinePolicies and, for every inline
+// policy read from IAM that matches its rendering, keeps the declared
+// document in the Spec so that its variables are not reported as a
+// difference.
+func (rm *resourceManager) setRenderedInlinePolicies(
+	declared *resource,
+	ko *svcapitypes.Group,
+) {
+	rendered, err := commonutil.RenderPolicyDocuments(
+		declared.ko.Spec.InlinePolicies, rm.policyVariables(declared),
+	)
+	if err != nil {
+		// The error is reported when the inline policies are synced.
+		ko.Status.RenderedInlinePolicies = nil
+		return
+	}
+	ko.Status.RenderedInlinePolicies = rendered
+	ko.Spec.InlinePolicies = commonutil.DeclaredPolicyDocuments(
+		declared.ko.Spec.InlinePolicies, rendered, ko.Spec.InlinePolicies,
+	)
+}
+
 // addInlinePolicy adds the supplied inline Policy to the supplied Group
 // resource
 func (rm *resourceManager) addInlinePolicy(
@@ -270,6 +311,9 @@ func (rm *resourceManager) addInlinePolicy(
 	if err != nil {
 		return err
 	}
+	if cleanedDoc, err = commonutil.RenderPolicyDocument(cleanedDoc, rm.policyVariables(r)); err != nil {
+		return ackerr.NewTerminalError(fmt.Errorf("inline policy %q: %w", policyName, err))
+	}
 	input.PolicyDocument = &cleanedDoc
 	_, err = rm.sdkapi.PutGroupPolicy(ctx, input)
 	rm.metrics.RecordAPICall("UPDATE", "PutGroupPolicy", err)
@@ -298,3 +342,93 @@ func (rm *resourceManager) removeInlinePolicy(
 func decodeDocument(encoded string) (string, error) {
 	return url.QueryUnescape(encoded)
 }
+
+// syncServiceLastAccessed refreshes the service last accessed (Access
+// Advisor) report in the status of the supplied Group. It is best-effort:
+// failures are reported as a condition, and the report is left alone while
+// the Group is being deleted.
+func (rm *resourceManager) syncServiceLastAccessed(
+	ctx context.Context,
+	ko *svcapitypes.Group,
+) {
+	if ko.DeletionTimestamp != nil ||
+		ko.Status.ACKResourceMetadata == nil || ko.Status.ACKResourceMetadata.ARN == nil {
+		return
+	}
+	report, err := commonutil.SyncServiceLastAccessed(
+		ctx, rm.sdkapi, rm.metrics, string(*ko.Status.ACKResourceMetadata.ARN),
+		ko.Spec.ServiceLastAccessedGranularity, ko.Status.ServiceLastAccessed,
+	)
+	if err != nil {
+		ackrtlog.FromContext(ctx).Debug("failed to refresh service last accessed report", "error", err)
+	}
+	ko.Status.ServiceLastAccessed = report
+	commonutil.SetServiceLastAccessedConditions(&resource{ko}, report, err)
+}
+
+// declarePolicyShards adds to Spec.Policies of the supplied declared Group the
+// shards of the Policies it references in Spec.PolicyRefs, so that they are
+// attached and detached along with them.
+func (rm *resourceManager) declarePolicyShards(
+	ctx context.Context,
+	ko *svcapitypes.Group,
+) error {
+	policies, err := commonutil.AppendPolicyShardARNs(
+		ctx, ko.Namespace, ko.Spec.PolicyRefs, ko.Spec.Policies,
+	)
+	if err != nil {
+		return err
+	}
+	ko.Spec.Policies = policies
+	return nil
+}
+
+// managedPolicyARNs returns the ARNs of the managed policies the supplied
+// Group declares, both in Spec.Policies and, by name, in
+// Spec.AWSManagedPolicies.
+func (rm *resourceManager) managedPolicyARNs(
+	ctx context.Context,
+	ko *svcapitypes.Group,
+) ([]*string, error) {
+	if len(ko.Spec.AWSManagedPolicies) == 0 {
+		return ko.Spec.Policies, nil
+	}
+	arns, err := commonutil.AWSManagedPolicyARNs(
+		ctx, rm.sdkapi, rm.metrics, string(rm.awsPartition), ko.Spec.AWSManagedPolicies,
+	)
+	if err != nil {
+		return nil, err
+	}
+	res := append([]*string{}, ko.Spec.Policies...)
+	for _, arn := range arns {
+		if !ackutil.InStringPs(*arn, res) {
+			res = append(res, arn)
+		}
+	}
+	return res, nil
+}
+
+// splitAWSManagedPolicies moves the policy ARNs read into Spec.Policies that
+// the Group declares by name into Spec.AWSManagedPolicies, so that both fields
+// of the latest state compare against the desired ones. When the names
+// cannot be resolved, none are moved and the error surfaces on update.
+func (rm *resourceManager) splitAWSManagedPolicies(
+	ctx context.Context,
+	ko *svcapitypes.Group,
+) {
+	names := ko.Spec.AWSManagedPolicies
+	ko.Spec.AWSManagedPolicies = nil
+	if len(names) == 0 {
+		return
+	}
+	arns, err := commonutil.AWSManagedPolicyARNs(
+		ctx, rm.sdkapi, rm.metrics, string(rm.awsPartition), names,
+	)
+	if err != nil {
+		ackrtlog.FromContext(ctx).Info("unable to resolve AWS managed policies", "error", err.Error())
+		return
+	}
+	ko.Spec.Policies, ko.Spec.AWSManagedPolicies = commonutil.SplitAWSManagedPolicies(
+		ko.Spec.Policies, names, arns,
+	)
+}
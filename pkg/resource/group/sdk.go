@@ -62,6 +62,11 @@ func (rm *resourceManager) sdkFind(
 	defer func() {
 		exit(err)
 	}()
+	// The shards of the Policies referenced in PolicyRefs are declared, and
+	// attached, along with them.
+	if err = rm.declarePolicyShards(ctx, r.ko); err != nil {
+		return nil, err
+	}
 	// If any required fields in the input shape are missing, AWS resource is
 	// not created yet. Return NotFound here to indicate to callers that the
 	// resource isn't yet created.
@@ -122,10 +127,13 @@ func (rm *resourceManager) sdkFind(
 	if err != nil {
 		return nil, err
 	}
+	rm.splitAWSManagedPolicies(ctx, ko)
 	ko.Spec.InlinePolicies, err = rm.getInlinePolicies(ctx, &resource{ko})
 	if err != nil {
 		return nil, err
 	}
+	rm.setRenderedInlinePolicies(r, ko)
+	rm.syncServiceLastAccessed(ctx, ko)
 
 	return &resource{ko}, nil
 }
@@ -249,7 +257,7 @@ func (rm *resourceManager) sdkUpdate(
 	defer func() {
 		exit(err)
 	}()
-	if delta.DifferentAt("Spec.Policies") {
+	if delta.DifferentAt("Spec.Policies") || delta.DifferentAt("Spec.AWSManagedPolicies") {
 		err = rm.syncManagedPolicies(ctx, desired, latest)
 		if err != nil {
 			return nil, err
@@ -261,7 +269,7 @@ func (rm *resourceManager) sdkUpdate(
 			return nil, err
 		}
 	}
-	if !delta.DifferentExcept("Spec.Tags", "Spec.Policies", "Spec.InlinePolicies", "Spec.PermissionsBoundary") {
+	if !delta.DifferentExcept("Spec.Tags", "Spec.Policies", "Spec.AWSManagedPolicies", "Spec.InlinePolicies", "Spec.PermissionsBoundary") {
 		return desired, nil
 	}
 
@@ -322,9 +330,13 @@ func (rm *resourceManager) sdkDelete(
 	defer func() {
 		exit(err)
 	}()
+	if err := rm.removeMembers(ctx, r); err != nil {
+		return nil, err
+	}
 	// This deletes all associated managed and inline policies from the user
 	groupCpy := r.ko.DeepCopy()
 	groupCpy.Spec.Policies = nil
+	groupCpy.Spec.AWSManagedPolicies = nil
 	if err := rm.syncManagedPolicies(ctx, &resource{ko: groupCpy}, r); err != nil {
 		return nil, err
 	}
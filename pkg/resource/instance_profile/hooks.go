@@ -3,12 +3,10 @@ package instance_profile
 import (
 	"context"
 
-	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
 	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
 	ackcompare "github.com/aws-controllers-k8s/runtime/pkg/compare"
 	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
 	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
-	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 )
 
 // customUpdateInstanceProfile is the custom implementation for
@@ -113,9 +111,8 @@ func (rm *resourceManager) detachRole(
 	return err
 }
 
-// syncTags examines the Tags in the supplied InstanceProfile and calls TagInstanceProfile
-// and UntagInstanceProfile APIs to ensure that the set of associated Tags stays in sync
-// with InstanceProfile.Spec.Tags
+// syncTags calls the TagInstanceProfile and UntagInstanceProfile APIs to ensure that the set of
+// Tags associated with the InstanceProfile stays in sync with the InstanceProfile.Spec.Tags
 func (rm *resourceManager) syncTags(
 	ctx context.Context,
 	desired *resource,
@@ -125,102 +122,10 @@ func (rm *resourceManager) syncTags(
 	exit := rlog.Trace("rm.syncTags")
 	defer func() { exit(err) }()
 
-	toAdd := []*svcapitypes.Tag{}
-	toDelete := []*svcapitypes.Tag{}
-	existingTags := latest.ko.Spec.Tags
-
-	for _, t := range existingTags {
-		if !inTags(*t.Key, *t.Value, desired.ko.Spec.Tags) {
-			toDelete = append(toDelete, t)
-		}
-	}
-	for _, t := range desired.ko.Spec.Tags {
-		if !inTags(*t.Key, *t.Value, existingTags) {
-			toAdd = append(toAdd, t)
-		}
-	}
-
-	if len(toDelete) > 0 {
-		for _, t := range toDelete {
-			rlog.Debug("removing tag from instance profile", "key", *t.Key, "value", *t.Value)
-		}
-		if err = rm.removeTags(ctx, desired, toDelete); err != nil {
-			return err
-		}
-	}
-	if len(toAdd) > 0 {
-		for _, t := range toAdd {
-			rlog.Debug("adding tag to instance profile", "key", *t.Key, "value", *t.Value)
-		}
-		if err = rm.addTags(ctx, desired, toAdd); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-// inTags returns true if the supplied key and value can be found in the
-// supplied list of Tag structs.
-func inTags(
-	key string,
-	value string,
-	tags []*svcapitypes.Tag,
-) bool {
-	for _, t := range tags {
-		if *t.Key == key && *t.Value == value {
-			return true
-		}
-	}
-	return false
-}
-
-// addTags adds the supplied Tags to the supplied InstanceProfile resource
-func (rm *resourceManager) addTags(
-	ctx context.Context,
-	r *resource,
-	tags []*svcapitypes.Tag,
-) (err error) {
-	rlog := ackrtlog.FromContext(ctx)
-	exit := rlog.Trace("rm.addTag")
-	defer func() { exit(err) }()
-
-	input := &svcsdk.TagInstanceProfileInput{}
-	input.InstanceProfileName = r.ko.Spec.Name
-	inTags := []svcsdktypes.Tag{}
-
-	for _, t := range tags {
-		inTags = append(inTags, svcsdktypes.Tag{Key: t.Key, Value: t.Value})
-	}
-	input.Tags = inTags
-
-	_, err = rm.sdkapi.TagInstanceProfile(ctx, input)
-	rm.metrics.RecordAPICall("UPDATE", "TagInstanceProfile", err)
-	return err
-}
-
-// removeTags removes the supplied Tags from the supplied InstanceProfile resource
-func (rm *resourceManager) removeTags(
-	ctx context.Context,
-	r *resource,
-	tags []*svcapitypes.Tag,
-) (err error) {
-	rlog := ackrtlog.FromContext(ctx)
-	exit := rlog.Trace("rm.removeTag")
-	defer func() { exit(err) }()
-
-	input := &svcsdk.UntagInstanceProfileInput{}
-	input.InstanceProfileName = r.ko.Spec.Name
-	inTagKeys := []string{}
-
-	for _, t := range tags {
-		inTagKeys = append(inTagKeys, *t.Key)
-	}
-	input.TagKeys = inTagKeys
-
-	_, err = rm.sdkapi.UntagInstanceProfile(ctx, input)
-	rm.metrics.RecordAPICall("UPDATE", "UntagInstanceProfile", err)
-	return err
+	return commonutil.SyncTags(
+		ctx, rm.sdkapi, rm.metrics, commonutil.ARNResourceTypeInstanceProfile,
+		desired.ko.Spec.Name, desired.ko.Spec.Tags, latest.ko.Spec.Tags,
+	)
 }
 
 // compareTags is a custom comparison function for comparing lists of Tag
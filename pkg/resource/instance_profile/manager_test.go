@@ -0,0 +1,114 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance_profile
+
+import (
+	"context"
+	"testing"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackmetrics "github.com/aws-controllers-k8s/runtime/pkg/metrics"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+)
+
+const testTrustPolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole"}]}`
+
+func newTestResourceManager(t *testing.T) *resourceManager {
+	rm, err := newResourceManager(
+		ackcfg.Config{}, fakeiam.New().Config(), logr.Discard(), ackmetrics.NewMetrics("iam"),
+		nil, fakeiam.AccountID, fakeiam.Region,
+	)
+	require.NoError(t, err)
+	return rm
+}
+
+func TestResourceManager_Lifecycle(t *testing.T) {
+	ctx := context.TODO()
+	rm := newTestResourceManager(t)
+	for _, name := range []string{"nodes", "workers"} {
+		_, err := rm.sdkapi.CreateRole(ctx, &svcsdk.CreateRoleInput{
+			RoleName:                 aws.String(name),
+			AssumeRolePolicyDocument: aws.String(testTrustPolicy),
+		})
+		require.NoError(t, err)
+	}
+
+	desired := &resource{ko: &svcapitypes.InstanceProfile{
+		Spec: svcapitypes.InstanceProfileSpec{
+			Name: aws.String("test-profile"),
+			Role: aws.String("nodes"),
+			Tags: []*svcapitypes.Tag{{Key: aws.String("team"), Value: aws.String("a")}},
+		},
+	}}
+	res, err := rm.Create(ctx, desired)
+	require.NoError(t, err)
+	created := rm.concreteResource(res)
+	assert.Equal(
+		t,
+		ackv1alpha1.AWSResourceName("arn:aws:iam::"+fakeiam.AccountID+":instance-profile/test-profile"),
+		*created.ko.Status.ACKResourceMetadata.ARN,
+	)
+
+	// The role is added by the Update following the creation, as the
+	// reconciler would do.
+	desired.SetStatus(created)
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest := rm.concreteResource(res)
+	assert.Nil(t, latest.ko.Spec.Role)
+	_, err = rm.Update(ctx, desired, latest, newResourceDelta(desired, latest))
+	require.NoError(t, err)
+
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest = rm.concreteResource(res)
+	assert.Equal(t, "nodes", *latest.ko.Spec.Role)
+	require.Len(t, latest.ko.Spec.Tags, 1)
+	assert.Equal(t, "a", *latest.ko.Spec.Tags[0].Value)
+
+	// The role is replaced and the tag updated in place.
+	desired = rm.concreteResource(latest.DeepCopy())
+	desired.ko.Spec.Role = aws.String("workers")
+	desired.ko.Spec.Tags = []*svcapitypes.Tag{{Key: aws.String("team"), Value: aws.String("b")}}
+	_, err = rm.Update(ctx, desired, latest, newResourceDelta(desired, latest))
+	require.NoError(t, err)
+
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest = rm.concreteResource(res)
+	assert.Equal(t, "workers", *latest.ko.Spec.Role)
+	require.Len(t, latest.ko.Spec.Tags, 1)
+	assert.Equal(t, "b", *latest.ko.Spec.Tags[0].Value)
+	assert.Empty(t, newResourceDelta(desired, latest).Differences)
+
+	// IAM refuses to delete a role still in an instance profile, until the
+	// instance profile is deleted.
+	_, err = rm.sdkapi.DeleteRole(ctx, &svcsdk.DeleteRoleInput{RoleName: aws.String("workers")})
+	assert.ErrorContains(t, err, "DeleteConflict")
+	_, err = rm.Delete(ctx, latest)
+	require.NoError(t, err)
+	_, err = rm.ReadOne(ctx, latest)
+	assert.Equal(t, ackerr.NotFound, err)
+	_, err = rm.sdkapi.DeleteRole(ctx, &svcsdk.DeleteRoleInput{RoleName: aws.String("workers")})
+	assert.NoError(t, err)
+}
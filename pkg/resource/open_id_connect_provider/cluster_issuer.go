@@ -0,0 +1,205 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package open_id_connect_provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
+	"k8s.io/client-go/rest"
+
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+const (
+	// defaultClusterIssuerClientID is the audience used by IRSA-style
+	// federation when the user does not supply any client IDs.
+	defaultClusterIssuerClientID = "sts.amazonaws.com"
+
+	// clusterIssuerMismatchReason is the reason of the ACK.Advisory condition
+	// reporting that the cluster's service account issuer no longer matches
+	// the URL of the provider. customPreCompare turns it into a difference,
+	// so that the update returns the terminal error of verifyClusterIssuer.
+	clusterIssuerMismatchReason = "ClusterIssuerMismatch"
+	// clusterIssuerUnavailableReason is the reason of the ACK.Advisory
+	// condition reporting that the cluster's service account issuer could not
+	// be read.
+	clusterIssuerUnavailableReason = "ClusterIssuerUnavailable"
+)
+
+// errMissingURL is returned when a provider has neither a URL nor
+// fromClusterIssuer set.
+var errMissingURL = ackerr.NewTerminalError(errors.New(
+	"one of url or fromClusterIssuer must be set",
+))
+
+// getClusterRESTClient returns a REST client talking to the API server the
+// controller runs against.
+//
+// NOTE: The service account issuer discovery endpoint is readable by all
+// service accounts through the default system:service-account-issuer-discovery
+// ClusterRoleBinding, so no extra RBAC is needed for the controller.
+func getClusterRESTClient() (rest.Interface, error) {
+	cs, err := commonutil.KubeClient()
+	if err != nil {
+		return nil, err
+	}
+	return cs.Discovery().RESTClient(), nil
+}
+
+// fetchClusterIssuer reads the API server's OpenID configuration through the
+// supplied REST client and returns the service account issuer URL.
+func fetchClusterIssuer(
+	ctx context.Context,
+	rc rest.Interface,
+) (issuer string, err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("fetchClusterIssuer")
+	defer func() { exit(err) }()
+
+	raw, err := rc.Get().AbsPath(openIDConfigurationPath).DoRaw(ctx)
+	if err != nil {
+		return "", fmt.Errorf("reading cluster OpenID configuration: %w", err)
+	}
+	config := &openIDConfiguration{}
+	if err = json.Unmarshal(raw, config); err != nil {
+		return "", fmt.Errorf("decoding cluster OpenID configuration: %w", err)
+	}
+	if config.Issuer == "" {
+		return "", fmt.Errorf("cluster OpenID configuration has no issuer")
+	}
+	return config.Issuer, nil
+}
+
+// fromClusterIssuer returns true if the supplied resource federates the
+// cluster's own service account issuer.
+func fromClusterIssuer(r *resource) bool {
+	return r.ko.Spec.FromClusterIssuer != nil && *r.ko.Spec.FromClusterIssuer
+}
+
+// applyClusterIssuer fills in the URL and default ClientIDs of the supplied
+// desired resource from the cluster's service account issuer when
+// Spec.FromClusterIssuer is true. Otherwise it returns a terminal error if the
+// resource has no URL.
+func applyClusterIssuer(
+	ctx context.Context,
+	desired *resource,
+) error {
+	if !fromClusterIssuer(desired) {
+		if desired.ko.Spec.URL == nil || *desired.ko.Spec.URL == "" {
+			return errMissingURL
+		}
+		return nil
+	}
+	rc, err := getClusterRESTClient()
+	if err != nil {
+		return err
+	}
+	issuer, err := fetchClusterIssuer(ctx, rc)
+	if err != nil {
+		return err
+	}
+	if err := checkIssuerUnchanged(desired, issuer); err != nil {
+		return err
+	}
+	desired.ko.Spec.URL = &issuer
+	if len(desired.ko.Spec.ClientIDs) == 0 {
+		clientID := defaultClusterIssuerClientID
+		desired.ko.Spec.ClientIDs = []*string{&clientID}
+	}
+	return nil
+}
+
+// verifyClusterIssuer returns a terminal error if the cluster's service
+// account issuer no longer matches the URL of the supplied resource. URL is
+// immutable, so the only way out is to recreate the OpenIDConnectProvider.
+// It is a no-op unless Spec.FromClusterIssuer is true.
+//
+// It is only called when updating the resource: returning a terminal error
+// when reading it would also block its deletion.
+func verifyClusterIssuer(
+	ctx context.Context,
+	r *resource,
+) error {
+	if !fromClusterIssuer(r) {
+		return nil
+	}
+	rc, err := getClusterRESTClient()
+	if err != nil {
+		return err
+	}
+	issuer, err := fetchClusterIssuer(ctx, rc)
+	if err != nil {
+		return err
+	}
+	return checkIssuerUnchanged(r, issuer)
+}
+
+// refreshClusterIssuer is the verifyClusterIssuer run when reading the
+// resource. Failures are reported as an ACK.Advisory condition rather than
+// returned, so that the resource can still be deleted.
+func refreshClusterIssuer(
+	ctx context.Context,
+	r *resource,
+) {
+	err := verifyClusterIssuer(ctx, r)
+	if err != nil {
+		ackrtlog.FromContext(ctx).Debug("failed to verify cluster issuer", "error", err)
+	}
+	setClusterIssuerConditions(r, err)
+}
+
+// setClusterIssuerConditions sets the ACK.Advisory conditions of the supplied
+// resource from the error of verifyClusterIssuer: a terminal error means the
+// issuer changed, any other one that it could not be read.
+func setClusterIssuerConditions(r *resource, err error) {
+	var termErr *ackerr.TerminalError
+	if errors.As(err, &termErr) {
+		commonutil.SetAdvisoryFromError(r, clusterIssuerMismatchReason, err)
+		commonutil.SetAdvisoryFromError(r, clusterIssuerUnavailableReason, nil)
+		return
+	}
+	commonutil.SetAdvisoryFromError(r, clusterIssuerMismatchReason, nil)
+	commonutil.SetAdvisoryFromError(r, clusterIssuerUnavailableReason, err)
+}
+
+// clusterIssuerChanged returns true if reading the supplied resource found
+// that the cluster's service account issuer no longer matches its URL.
+func clusterIssuerChanged(r *resource) bool {
+	return ackcondition.AdvisoryWithReason(r, clusterIssuerMismatchReason) != nil
+}
+
+// checkIssuerUnchanged returns a terminal error if the supplied resource
+// already has a URL that differs from the supplied issuer. IAM strips the
+// https:// prefix from provider URLs, so it is ignored in the comparison.
+func checkIssuerUnchanged(r *resource, issuer string) error {
+	if r.ko.Spec.URL == nil || *r.ko.Spec.URL == "" {
+		return nil
+	}
+	current := strings.TrimPrefix(*r.ko.Spec.URL, "https://")
+	if current == strings.TrimPrefix(issuer, "https://") {
+		return nil
+	}
+	return ackerr.NewTerminalError(fmt.Errorf(
+		"cluster service account issuer changed from %q to %q; URL is "+
+			"immutable, recreate the OpenIDConnectProvider to federate the new issuer",
+		*r.ko.Spec.URL, issuer,
+	))
+}
@@ -0,0 +1,120 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package open_id_connect_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ackcompare "github.com/aws-controllers-k8s/runtime/pkg/compare"
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+func TestFetchClusterIssuer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != openIDConfigurationPath {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"issuer": "https://oidc.example.com/cluster", "jwks_uri": "https://oidc.example.com/cluster/keys"}`)
+	}))
+	defer srv.Close()
+
+	cs, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	require.NoError(t, err)
+
+	issuer, err := fetchClusterIssuer(context.TODO(), cs.Discovery().RESTClient())
+	require.NoError(t, err)
+	assert.Equal(t, "https://oidc.example.com/cluster", issuer)
+}
+
+func TestCheckIssuerUnchanged(t *testing.T) {
+	newProvider := func(url *string) *resource {
+		return &resource{
+			ko: &svcapitypes.OpenIDConnectProvider{
+				Spec: svcapitypes.OpenIDConnectProviderSpec{
+					FromClusterIssuer: aws.Bool(true),
+					URL:               url,
+				},
+			},
+		}
+	}
+	issuer := "https://oidc.example.com/cluster"
+
+	assert.NoError(t, checkIssuerUnchanged(newProvider(nil), issuer))
+	assert.NoError(t, checkIssuerUnchanged(newProvider(aws.String(issuer)), issuer))
+	// IAM returns the URL without its scheme
+	assert.NoError(t, checkIssuerUnchanged(newProvider(aws.String("oidc.example.com/cluster")), issuer))
+
+	err := checkIssuerUnchanged(newProvider(aws.String("oidc.example.com/old")), issuer)
+	var termErr *ackerr.TerminalError
+	assert.ErrorAs(t, err, &termErr)
+}
+
+func TestApplyClusterIssuer_MissingURL(t *testing.T) {
+	r := &resource{ko: &svcapitypes.OpenIDConnectProvider{}}
+
+	err := applyClusterIssuer(context.TODO(), r)
+	var termErr *ackerr.TerminalError
+	assert.ErrorAs(t, err, &termErr)
+
+	r.ko.Spec.URL = aws.String("https://oidc.example.com")
+	assert.NoError(t, applyClusterIssuer(context.TODO(), r))
+}
+
+func TestSetClusterIssuerConditions(t *testing.T) {
+	newProvider := func(url string) *resource {
+		return &resource{
+			ko: &svcapitypes.OpenIDConnectProvider{
+				Spec: svcapitypes.OpenIDConnectProviderSpec{
+					FromClusterIssuer: aws.Bool(true),
+					URL:               aws.String(url),
+				},
+			},
+		}
+	}
+	desired := newProvider("https://oidc.example.com/cluster")
+	latest := newProvider("oidc.example.com/cluster")
+
+	setClusterIssuerConditions(latest, errors.New("forbidden"))
+	assert.NotNil(t, ackcondition.AdvisoryWithReason(latest, clusterIssuerUnavailableReason))
+	assert.False(t, clusterIssuerChanged(latest))
+	delta := ackcompare.NewDelta()
+	customPreCompare(delta, desired, latest)
+	assert.False(t, delta.DifferentAt("Spec.URL"))
+
+	// a changed issuer must reach the update, which fails terminally
+	setClusterIssuerConditions(latest, checkIssuerUnchanged(latest, "https://oidc.example.com/new"))
+	assert.Nil(t, ackcondition.AdvisoryWithReason(latest, clusterIssuerUnavailableReason))
+	assert.True(t, clusterIssuerChanged(latest))
+	delta = ackcompare.NewDelta()
+	customPreCompare(delta, desired, latest)
+	assert.True(t, delta.DifferentAt("Spec.URL"))
+
+	setClusterIssuerConditions(latest, nil)
+	assert.False(t, clusterIssuerChanged(latest))
+	assert.Nil(t, ackcondition.AdvisoryWithReason(latest, clusterIssuerUnavailableReason))
+}
@@ -49,6 +49,20 @@ func newResourceDelta(
 			delta.Add("Spec.ClientIDs", a.ko.Spec.ClientIDs, b.ko.Spec.ClientIDs)
 		}
 	}
+	if ackcompare.HasNilDifference(a.ko.Spec.FromClusterIssuer, b.ko.Spec.FromClusterIssuer) {
+		delta.Add("Spec.FromClusterIssuer", a.ko.Spec.FromClusterIssuer, b.ko.Spec.FromClusterIssuer)
+	} else if a.ko.Spec.FromClusterIssuer != nil && b.ko.Spec.FromClusterIssuer != nil {
+		if *a.ko.Spec.FromClusterIssuer != *b.ko.Spec.FromClusterIssuer {
+			delta.Add("Spec.FromClusterIssuer", a.ko.Spec.FromClusterIssuer, b.ko.Spec.FromClusterIssuer)
+		}
+	}
+	if ackcompare.HasNilDifference(a.ko.Spec.ThumbprintPolicy, b.ko.Spec.ThumbprintPolicy) {
+		delta.Add("Spec.ThumbprintPolicy", a.ko.Spec.ThumbprintPolicy, b.ko.Spec.ThumbprintPolicy)
+	} else if a.ko.Spec.ThumbprintPolicy != nil && b.ko.Spec.ThumbprintPolicy != nil {
+		if *a.ko.Spec.ThumbprintPolicy != *b.ko.Spec.ThumbprintPolicy {
+			delta.Add("Spec.ThumbprintPolicy", a.ko.Spec.ThumbprintPolicy, b.ko.Spec.ThumbprintPolicy)
+		}
+	}
 	if len(a.ko.Spec.Thumbprints) != len(b.ko.Spec.Thumbprints) {
 		delta.Add("Spec.Thumbprints", a.ko.Spec.Thumbprints, b.ko.Spec.Thumbprints)
 	} else if len(a.ko.Spec.Thumbprints) > 0 {
@@ -21,7 +21,6 @@ import (
 	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
 	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
 	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
-	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 	corev1 "k8s.io/api/core/v1"
 
 	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
@@ -38,6 +37,22 @@ func (rm *resourceManager) customUpdateOpenIDConnectProvider(
 	exit := rlog.Trace("rm.customUpdateOpenIDConnectProvider")
 	defer func() { exit(err) }()
 
+	// An invalid thumbprint policy or a changed cluster issuer are only
+	// reported as advisories while reading the resource, so that they do
+	// not block its deletion.
+	if _, err := thumbprintPolicyIsAuto(desired); err != nil {
+		return nil, err
+	}
+	if err := verifyClusterIssuer(ctx, desired); err != nil {
+		return nil, err
+	}
+	if thumbprintRotated(latest) {
+		// The issuer rotated its CA. Replace whatever thumbprints were
+		// registered with the one we just discovered.
+		desired.ko.Spec.Thumbprints = []*string{latest.ko.Status.DiscoveredThumbprint}
+		desired.ko.Status.DiscoveredThumbprint = latest.ko.Status.DiscoveredThumbprint
+	}
+
 	if delta.DifferentAt("Spec.Thumbprints") {
 		// Update the thumbprint list
 		thumbprintInput, err := rm.newUpdateThumbprintRequestPayload(ctx, desired)
@@ -124,11 +139,19 @@ func (rm *resourceManager) customUpdateOpenIDConnectProvider(
 // custom comparison function for comparing
 //   - lists of Tag structs where the order of the structs in the list is not important.
 //   - URLs where a prefix of https:// should be disregarded
+//   - thumbprints that no longer match the issuer's CA when the thumbprint
+//     policy is auto
+//   - URLs that no longer match the cluster's service account issuer when
+//     FromClusterIssuer is true
 func customPreCompare(
 	delta *ackcompare.Delta,
 	a *resource,
 	b *resource,
 ) {
+	if thumbprintRotated(b) {
+		delta.Add("Spec.Thumbprints", a.ko.Spec.Thumbprints, b.ko.Spec.Thumbprints)
+	}
+
 	if len(a.ko.Spec.Tags) != len(b.ko.Spec.Tags) {
 		delta.Add("Spec.Tags", a.ko.Spec.Tags, b.ko.Spec.Tags)
 	} else if len(a.ko.Spec.Tags) > 0 {
@@ -137,7 +160,7 @@ func customPreCompare(
 		}
 	}
 
-	if ackcompare.HasNilDifference(a.ko.Spec.URL, b.ko.Spec.URL) {
+	if ackcompare.HasNilDifference(a.ko.Spec.URL, b.ko.Spec.URL) || clusterIssuerChanged(b) {
 		delta.Add("Spec.URL", a.ko.Spec.URL, b.ko.Spec.URL)
 	} else if a.ko.Spec.URL != nil && b.ko.Spec.URL != nil {
 		// the URL field must begin with "https://"
@@ -163,63 +186,16 @@ func (rm *resourceManager) syncTags(
 ) (err error) {
 	rlog := ackrtlog.FromContext(ctx)
 	exit := rlog.Trace("rm.syncTags")
-	defer func(err error) { exit(err) }(err)
-	toAdd := []*svcapitypes.Tag{}
-	toDelete := []*svcapitypes.Tag{}
+	defer func() { exit(err) }()
 
 	existingTags, err := rm.getTags(ctx, r)
 	if err != nil {
 		return err
 	}
-
-	for _, t := range r.ko.Spec.Tags {
-		if !inTags(*t.Key, *t.Value, existingTags) {
-			toAdd = append(toAdd, t)
-		}
-	}
-
-	for _, t := range existingTags {
-		if !inTags(*t.Key, *t.Value, r.ko.Spec.Tags) {
-			toDelete = append(toDelete, t)
-		}
-	}
-
-	if len(toAdd) > 0 {
-		for _, t := range toAdd {
-			rlog.Debug("adding tag to OpenIDConnectProvider", "key", *t.Key, "value", *t.Value)
-		}
-		if err = rm.addTags(ctx, r, toAdd); err != nil {
-			return err
-		}
-	}
-	if len(toDelete) > 0 {
-		for _, t := range toDelete {
-			rlog.Debug("removing tag from OpenIDConnectProvider", "key", *t.Key, "value", *t.Value)
-		}
-		if err = rm.removeTags(ctx, r, toDelete); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-// inTags returns true if the supplied key and value can be found in the
-// supplied list of Tag structs.
-//
-// TODO(jaypipes): When we finally standardize Tag handling in ACK, move this
-// to the ACK common runtime/ or pkg/ repos
-func inTags(
-	key string,
-	value string,
-	tags []*svcapitypes.Tag,
-) bool {
-	for _, t := range tags {
-		if *t.Key == key && t.Value != nil && *t.Value == value {
-			return true
-		}
-	}
-	return false
+	return commonutil.SyncTags(
+		ctx, rm.sdkapi, rm.metrics, commonutil.ARNResourceTypeOIDCProvider,
+		(*string)(r.ko.Status.ACKResourceMetadata.ARN), r.ko.Spec.Tags, existingTags,
+	)
 }
 
 // getTags returns the list of tags attached to the OpenIDConnectProvider
@@ -254,52 +230,6 @@ func (rm *resourceManager) getTags(
 	return res, err
 }
 
-// addTags adds the supplied Tags to the supplied OpenIDConnectProvider resource
-func (rm *resourceManager) addTags(
-	ctx context.Context,
-	r *resource,
-	tags []*svcapitypes.Tag,
-) (err error) {
-	rlog := ackrtlog.FromContext(ctx)
-	exit := rlog.Trace("rm.addTags")
-	defer func() { exit(err) }()
-
-	input := &svcsdk.TagOpenIDConnectProviderInput{}
-	input.OpenIDConnectProviderArn = (*string)(r.ko.Status.ACKResourceMetadata.ARN)
-	inTags := []svcsdktypes.Tag{}
-	for _, t := range tags {
-		inTags = append(inTags, svcsdktypes.Tag{Key: t.Key, Value: t.Value})
-	}
-	input.Tags = inTags
-
-	_, err = rm.sdkapi.TagOpenIDConnectProvider(ctx, input)
-	rm.metrics.RecordAPICall("UPDATE", "TagOpenIDConnectProvider", err)
-	return err
-}
-
-// removeTags removes the supplied Tags from the supplied OpenIDConnectProvider resource
-func (rm *resourceManager) removeTags(
-	ctx context.Context,
-	r *resource,
-	tags []*svcapitypes.Tag,
-) (err error) {
-	rlog := ackrtlog.FromContext(ctx)
-	exit := rlog.Trace("rm.removeTags")
-	defer func() { exit(err) }()
-
-	input := &svcsdk.UntagOpenIDConnectProviderInput{}
-	input.OpenIDConnectProviderArn = (*string)(r.ko.Status.ACKResourceMetadata.ARN)
-	inTagKeys := []string{}
-	for _, t := range tags {
-		inTagKeys = append(inTagKeys, *t.Key)
-	}
-	input.TagKeys = inTagKeys
-
-	_, err = rm.sdkapi.UntagOpenIDConnectProvider(ctx, input)
-	rm.metrics.RecordAPICall("UPDATE", "UntagOpenIDConnectProvider", err)
-	return err
-}
-
 // returns an SDK-specific struct for the HTTP request
 // payload of the UpdateThumbprint API call for the resource
 func (rm *resourceManager) newUpdateThumbprintRequestPayload(
@@ -0,0 +1,97 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package open_id_connect_provider
+
+import (
+	"context"
+	"testing"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackmetrics "github.com/aws-controllers-k8s/runtime/pkg/metrics"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+)
+
+func newTestResourceManager(t *testing.T) *resourceManager {
+	rm, err := newResourceManager(
+		ackcfg.Config{}, fakeiam.New().Config(), logr.Discard(), ackmetrics.NewMetrics("iam"),
+		nil, fakeiam.AccountID, fakeiam.Region,
+	)
+	require.NoError(t, err)
+	return rm
+}
+
+func TestResourceManager_Lifecycle(t *testing.T) {
+	ctx := context.TODO()
+	rm := newTestResourceManager(t)
+
+	const (
+		thumbprint        = "6938fd4d98bab03faadb97b34396831e3780aea1"
+		rotatedThumbprint = "1c58a3a8518e8759bf075b76b750d4f2df264fcd"
+	)
+	desired := &resource{ko: &svcapitypes.OpenIDConnectProvider{
+		Spec: svcapitypes.OpenIDConnectProviderSpec{
+			URL:         aws.String("https://token.actions.githubusercontent.com"),
+			ClientIDs:   []*string{aws.String("sts.amazonaws.com")},
+			Thumbprints: []*string{aws.String(thumbprint)},
+			Tags:        []*svcapitypes.Tag{{Key: aws.String("team"), Value: aws.String("a")}},
+		},
+	}}
+	res, err := rm.Create(ctx, desired)
+	require.NoError(t, err)
+	created := rm.concreteResource(res)
+	assert.Equal(
+		t,
+		ackv1alpha1.AWSResourceName("arn:aws:iam::"+fakeiam.AccountID+":oidc-provider/token.actions.githubusercontent.com"),
+		*created.ko.Status.ACKResourceMetadata.ARN,
+	)
+
+	desired.SetStatus(created)
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest := rm.concreteResource(res)
+	assert.Equal(t, []*string{aws.String("sts.amazonaws.com")}, latest.ko.Spec.ClientIDs)
+	assert.Equal(t, []*string{aws.String(thumbprint)}, latest.ko.Spec.Thumbprints)
+	assert.Empty(t, newResourceDelta(desired, latest).Differences)
+
+	// The client IDs are replaced, the thumbprint rotated and the tag
+	// updated in place.
+	desired = rm.concreteResource(latest.DeepCopy())
+	desired.ko.Spec.ClientIDs = []*string{aws.String("sigstore")}
+	desired.ko.Spec.Thumbprints = []*string{aws.String(rotatedThumbprint)}
+	desired.ko.Spec.Tags = []*svcapitypes.Tag{{Key: aws.String("team"), Value: aws.String("b")}}
+	_, err = rm.Update(ctx, desired, latest, newResourceDelta(desired, latest))
+	require.NoError(t, err)
+
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest = rm.concreteResource(res)
+	assert.Equal(t, []*string{aws.String("sigstore")}, latest.ko.Spec.ClientIDs)
+	assert.Equal(t, []*string{aws.String(rotatedThumbprint)}, latest.ko.Spec.Thumbprints)
+	require.Len(t, latest.ko.Spec.Tags, 1)
+	assert.Equal(t, "b", *latest.ko.Spec.Tags[0].Value)
+	assert.Empty(t, newResourceDelta(desired, latest).Differences)
+
+	_, err = rm.Delete(ctx, latest)
+	require.NoError(t, err)
+	_, err = rm.ReadOne(ctx, latest)
+	assert.Equal(t, ackerr.NotFound, err)
+}
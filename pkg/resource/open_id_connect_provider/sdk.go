@@ -128,6 +128,8 @@ func (rm *resourceManager) sdkFind(
 	} else {
 		ko.Spec.Tags = tags
 	}
+	refreshClusterIssuer(ctx, &resource{ko})
+	refreshThumbprint(ctx, &resource{ko})
 	return &resource{ko}, nil
 }
 
@@ -167,6 +169,15 @@ func (rm *resourceManager) sdkCreate(
 	defer func() {
 		exit(err)
 	}()
+	if err = applyClusterIssuer(ctx, desired); err != nil {
+		return nil, err
+	}
+	if err = observeThumbprint(ctx, desired); err != nil {
+		return nil, err
+	}
+	if desired.ko.Status.DiscoveredThumbprint != nil {
+		desired.ko.Spec.Thumbprints = []*string{desired.ko.Status.DiscoveredThumbprint}
+	}
 	input, err := rm.newCreateRequestPayload(ctx, desired)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,220 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package open_id_connect_provider
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
+
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+const (
+	// ThumbprintPolicyManual is the default thumbprint policy. The
+	// controller sends whatever is in Spec.Thumbprints to IAM.
+	ThumbprintPolicyManual = "manual"
+	// ThumbprintPolicyAuto makes the controller discover the thumbprint of
+	// the issuer's top intermediate CA and keep Spec.Thumbprints in sync with
+	// it.
+	ThumbprintPolicyAuto = "auto"
+
+	// openIDConfigurationPath is the well-known path of the OpenID Connect
+	// discovery document, relative to the issuer URL.
+	//
+	// https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderConfig
+	openIDConfigurationPath = "/.well-known/openid-configuration"
+
+	// thumbprintDiscoveryFailedReason is the reason of the ACK.Advisory
+	// condition reporting that the issuer's thumbprint could not be
+	// discovered while reading the resource.
+	thumbprintDiscoveryFailedReason = "ThumbprintDiscoveryFailed"
+)
+
+// thumbprintHTTPClient is the HTTP client used to fetch OpenID Connect
+// discovery documents and JWKS endpoints.
+var thumbprintHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// openIDConfiguration contains the subset of the OpenID Connect discovery
+// document we care about.
+type openIDConfiguration struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// thumbprintPolicyIsAuto returns true if the supplied resource asks for its
+// thumbprints to be discovered automatically. Resources federating the
+// cluster's own issuer default to the auto policy. An unknown policy is
+// reported as a terminal error.
+func thumbprintPolicyIsAuto(r *resource) (bool, error) {
+	if r.ko.Spec.ThumbprintPolicy == nil {
+		return fromClusterIssuer(r), nil
+	}
+	switch *r.ko.Spec.ThumbprintPolicy {
+	case "", ThumbprintPolicyManual:
+		return false, nil
+	case ThumbprintPolicyAuto:
+		return true, nil
+	default:
+		return false, ackerr.NewTerminalError(fmt.Errorf(
+			"invalid thumbprintPolicy %q, must be one of %q or %q",
+			*r.ko.Spec.ThumbprintPolicy, ThumbprintPolicyManual, ThumbprintPolicyAuto,
+		))
+	}
+}
+
+// discoverThumbprint fetches the OpenID Connect discovery document of the
+// supplied issuer, follows its jwks_uri and returns the hex-encoded SHA-1
+// thumbprint of the top intermediate CA certificate presented by the JWKS
+// endpoint.
+//
+// https://docs.aws.amazon.com/IAM/latest/UserGuide/id_roles_providers_create_oidc_verify-thumbprint.html
+func discoverThumbprint(
+	ctx context.Context,
+	client *http.Client,
+	issuerURL string,
+) (thumbprint string, err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("discoverThumbprint")
+	defer func() { exit(err) }()
+
+	config, err := fetchOpenIDConfiguration(ctx, client, issuerURL)
+	if err != nil {
+		return "", err
+	}
+	if config.JWKSURI == "" {
+		return "", fmt.Errorf(
+			"OpenID configuration for issuer %q has no jwks_uri", issuerURL,
+		)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, config.JWKSURI, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetching jwks_uri %q: %w", config.JWKSURI, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return "", fmt.Errorf(
+			"jwks_uri %q did not present a TLS certificate chain", config.JWKSURI,
+		)
+	}
+	// The top intermediate CA is the last certificate in the chain the
+	// server presents.
+	chain := resp.TLS.PeerCertificates
+	sum := sha1.Sum(chain[len(chain)-1].Raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// fetchOpenIDConfiguration returns the OpenID Connect discovery document for
+// the supplied issuer URL. The https:// scheme is assumed when the URL does
+// not carry one, mirroring what IAM does with the provider URL.
+func fetchOpenIDConfiguration(
+	ctx context.Context,
+	client *http.Client,
+	issuerURL string,
+) (*openIDConfiguration, error) {
+	if !strings.Contains(issuerURL, "://") {
+		issuerURL = "https://" + issuerURL
+	}
+	configURL := strings.TrimSuffix(issuerURL, "/") + openIDConfigurationPath
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, configURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching OpenID configuration %q: %w", configURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"fetching OpenID configuration %q: unexpected status %s",
+			configURL, resp.Status,
+		)
+	}
+	config := &openIDConfiguration{}
+	if err := json.NewDecoder(resp.Body).Decode(config); err != nil {
+		return nil, fmt.Errorf("decoding OpenID configuration %q: %w", configURL, err)
+	}
+	return config, nil
+}
+
+// observeThumbprint discovers the current thumbprint of the issuer of the
+// supplied resource and records it in Status.DiscoveredThumbprint when the
+// resource uses the auto thumbprint policy. It is a no-op otherwise.
+func observeThumbprint(
+	ctx context.Context,
+	r *resource,
+) error {
+	auto, err := thumbprintPolicyIsAuto(r)
+	if err != nil || !auto {
+		return err
+	}
+	if r.ko.Spec.URL == nil {
+		return nil
+	}
+	thumbprint, err := discoverThumbprint(ctx, thumbprintHTTPClient, *r.ko.Spec.URL)
+	if err != nil {
+		return err
+	}
+	r.ko.Status.DiscoveredThumbprint = &thumbprint
+	return nil
+}
+
+// refreshThumbprint is the best-effort observeThumbprint run when reading the
+// resource. The issuer is an arbitrary HTTPS endpoint outside of AWS, and an
+// unreachable issuer must not block the reconciliation or the deletion of the
+// resource, so failures are reported as an ACK.Advisory condition and the
+// previously discovered thumbprint is kept.
+func refreshThumbprint(
+	ctx context.Context,
+	r *resource,
+) {
+	err := observeThumbprint(ctx, r)
+	if err != nil {
+		ackrtlog.FromContext(ctx).Debug("failed to discover issuer thumbprint", "error", err)
+	}
+	commonutil.SetAdvisoryFromError(r, thumbprintDiscoveryFailedReason, err)
+}
+
+// thumbprintRotated returns true if the resource uses the auto thumbprint
+// policy and the thumbprint discovered from the issuer is not the one
+// registered with IAM.
+func thumbprintRotated(r *resource) bool {
+	if auto, _ := thumbprintPolicyIsAuto(r); !auto {
+		return false
+	}
+	discovered := r.ko.Status.DiscoveredThumbprint
+	if discovered == nil {
+		return false
+	}
+	registered := r.ko.Spec.Thumbprints
+	return len(registered) != 1 || registered[0] == nil || !strings.EqualFold(*registered[0], *discovered)
+}
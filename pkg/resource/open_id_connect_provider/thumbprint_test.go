@@ -0,0 +1,152 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package open_id_connect_provider
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+// newIssuerServer returns a TLS server serving an OpenID configuration
+// document whose jwks_uri points back at the same server.
+func newIssuerServer(t *testing.T, withJWKS bool) *httptest.Server {
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc(openIDConfigurationPath, func(w http.ResponseWriter, _ *http.Request) {
+		jwksURI := ""
+		if withJWKS {
+			jwksURI = srv.URL + "/keys"
+		}
+		fmt.Fprintf(w, `{"issuer": %q, "jwks_uri": %q}`, srv.URL, jwksURI)
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"keys": []}`)
+	})
+	srv = httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDiscoverThumbprint(t *testing.T) {
+	srv := newIssuerServer(t, true)
+
+	got, err := discoverThumbprint(context.TODO(), srv.Client(), srv.URL)
+	require.NoError(t, err)
+
+	sum := sha1.Sum(srv.Certificate().Raw)
+	assert.Equal(t, hex.EncodeToString(sum[:]), got)
+}
+
+func TestDiscoverThumbprint_MissingJWKSURI(t *testing.T) {
+	srv := newIssuerServer(t, false)
+
+	_, err := discoverThumbprint(context.TODO(), srv.Client(), srv.URL)
+	assert.ErrorContains(t, err, "has no jwks_uri")
+}
+
+func TestDiscoverThumbprint_NotFound(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := discoverThumbprint(context.TODO(), srv.Client(), srv.URL)
+	assert.ErrorContains(t, err, "unexpected status")
+}
+
+func TestRefreshThumbprint_UnreachableIssuer(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	srv.Close()
+	r := &resource{
+		ko: &svcapitypes.OpenIDConnectProvider{
+			Spec: svcapitypes.OpenIDConnectProviderSpec{
+				URL:              aws.String(srv.URL),
+				ThumbprintPolicy: aws.String(ThumbprintPolicyAuto),
+			},
+			Status: svcapitypes.OpenIDConnectProviderStatus{
+				DiscoveredThumbprint: aws.String("aaaa"),
+			},
+		},
+	}
+
+	refreshThumbprint(context.TODO(), r)
+	assert.Equal(t, "aaaa", *r.ko.Status.DiscoveredThumbprint)
+	require.NotNil(t, ackcondition.AdvisoryWithReason(r, thumbprintDiscoveryFailedReason))
+
+	r.ko.Spec.ThumbprintPolicy = aws.String(ThumbprintPolicyManual)
+	refreshThumbprint(context.TODO(), r)
+	assert.Nil(t, ackcondition.AdvisoryWithReason(r, thumbprintDiscoveryFailedReason))
+}
+
+func TestThumbprintRotated(t *testing.T) {
+	newProvider := func(policy string, registered []*string, discovered *string) *resource {
+		return &resource{
+			ko: &svcapitypes.OpenIDConnectProvider{
+				Spec: svcapitypes.OpenIDConnectProviderSpec{
+					ThumbprintPolicy: aws.String(policy),
+					Thumbprints:      registered,
+				},
+				Status: svcapitypes.OpenIDConnectProviderStatus{
+					DiscoveredThumbprint: discovered,
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name     string
+		r        *resource
+		expected bool
+	}{
+		{
+			name:     "manual policy never rotates",
+			r:        newProvider(ThumbprintPolicyManual, aws.StringSlice([]string{"aaaa"}), aws.String("bbbb")),
+			expected: false,
+		},
+		{
+			name:     "auto policy with matching thumbprint",
+			r:        newProvider(ThumbprintPolicyAuto, aws.StringSlice([]string{"AAAA"}), aws.String("aaaa")),
+			expected: false,
+		},
+		{
+			name:     "auto policy with stale thumbprint",
+			r:        newProvider(ThumbprintPolicyAuto, aws.StringSlice([]string{"aaaa"}), aws.String("bbbb")),
+			expected: true,
+		},
+		{
+			name:     "auto policy with extra thumbprints",
+			r:        newProvider(ThumbprintPolicyAuto, aws.StringSlice([]string{"aaaa", "bbbb"}), aws.String("bbbb")),
+			expected: true,
+		},
+		{
+			name:     "auto policy before discovery",
+			r:        newProvider(ThumbprintPolicyAuto, aws.StringSlice([]string{"aaaa"}), nil),
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, thumbprintRotated(tt.r))
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	corev1 "k8s.io/api/core/v1"
+
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+const (
+	// DeletionModeBlock is the default deletion mode. Deleting a Policy that
+	// is still attached to a role, user or group, or used as a permissions
+	// boundary, fails until it is detached.
+	DeletionModeBlock = "block"
+	// DeletionModeForceDetach makes the controller detach the Policy from
+	// every role, user and group, and remove it as a permissions boundary,
+	// before deleting it.
+	DeletionModeForceDetach = "forceDetach"
+)
+
+// deletionModeIsForceDetach returns true if the supplied Policy is to be
+// detached from every entity before it is deleted. An unknown deletion mode
+// is reported as a terminal error.
+func deletionModeIsForceDetach(r *resource) (bool, error) {
+	if r.ko.Spec.DeletionMode == nil {
+		return false, nil
+	}
+	switch *r.ko.Spec.DeletionMode {
+	case "", DeletionModeBlock:
+		return false, nil
+	case DeletionModeForceDetach:
+		return true, nil
+	default:
+		return false, ackerr.NewTerminalError(fmt.Errorf(
+			"invalid deletionMode %q, must be one of %q or %q",
+			*r.ko.Spec.DeletionMode, DeletionModeBlock, DeletionModeForceDetach,
+		))
+	}
+}
+
+// prepareForDeletion makes sure the supplied Policy can be deleted. With the
+// forceDetach deletion mode, the Policy is detached from every entity using
+// it. Otherwise an error describing the entities still using the Policy is
+// returned instead of letting DeletePolicy fail with DeleteConflict.
+func (rm *resourceManager) prepareForDeletion(
+	ctx context.Context,
+	r *resource,
+) error {
+	force, err := deletionModeIsForceDetach(r)
+	if err != nil {
+		return err
+	}
+	if force {
+		if r.ko.Status.ACKResourceMetadata == nil || r.ko.Status.ACKResourceMetadata.ARN == nil {
+			return nil
+		}
+		return rm.detachFromAllEntities(ctx, r, string(*r.ko.Status.ACKResourceMetadata.ARN))
+	}
+	attachments := int64(0)
+	if r.ko.Status.AttachmentCount != nil {
+		attachments = *r.ko.Status.AttachmentCount
+	}
+	boundaries := int64(0)
+	if r.ko.Status.PermissionsBoundaryUsageCount != nil {
+		boundaries = *r.ko.Status.PermissionsBoundaryUsageCount
+	}
+	if attachments == 0 && boundaries == 0 {
+		return nil
+	}
+	return fmt.Errorf(
+		"policy is attached to %d entities and used as the permissions boundary "+
+			"of %d entities; detach it or set deletionMode to %q",
+		attachments, boundaries, DeletionModeForceDetach,
+	)
+}
+
+// detachFromAllEntities detaches the managed policy with the supplied ARN,
+// the Policy's own or one of its shards, from every role, user and group it
+// is attached to, and removes it from the roles and users using it as their
+// permissions boundary. An Event is recorded on the Policy for each detach.
+func (rm *resourceManager) detachFromAllEntities(
+	ctx context.Context,
+	r *resource,
+	policyARN string,
+) (err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.detachFromAllEntities")
+	defer func() { exit(err) }()
+
+	recordEvent := func(reason, format string, args ...interface{}) {
+		recorder, err := commonutil.EventRecorder()
+		if err != nil {
+			rlog.Info("unable to record event", "reason", reason, "error", err.Error())
+			return
+		}
+		recorder.Eventf(r.ko, corev1.EventTypeNormal, reason, format, args...)
+	}
+
+	attached, err := rm.listEntitiesForPolicy(ctx, policyARN, svcsdktypes.PolicyUsageTypePermissionsPolicy, 0)
+	if err != nil {
+		return err
+	}
+	for _, g := range attached.PolicyGroups {
+		_, err = rm.sdkapi.DetachGroupPolicy(ctx, &svcsdk.DetachGroupPolicyInput{
+			GroupName: g.GroupName,
+			PolicyArn: &policyARN,
+		})
+		rm.metrics.RecordAPICall("DELETE", "DetachGroupPolicy", err)
+		if err != nil {
+			return err
+		}
+		recordEvent("PolicyDetached", "Detached policy from group %s", *g.GroupName)
+	}
+	for _, role := range attached.PolicyRoles {
+		_, err = rm.sdkapi.DetachRolePolicy(ctx, &svcsdk.DetachRolePolicyInput{
+			RoleName:  role.RoleName,
+			PolicyArn: &policyARN,
+		})
+		rm.metrics.RecordAPICall("DELETE", "DetachRolePolicy", err)
+		if err != nil {
+			return err
+		}
+		recordEvent("PolicyDetached", "Detached policy from role %s", *role.RoleName)
+	}
+	for _, u := range attached.PolicyUsers {
+		_, err = rm.sdkapi.DetachUserPolicy(ctx, &svcsdk.DetachUserPolicyInput{
+			UserName:  u.UserName,
+			PolicyArn: &policyARN,
+		})
+		rm.metrics.RecordAPICall("DELETE", "DetachUserPolicy", err)
+		if err != nil {
+			return err
+		}
+		recordEvent("PolicyDetached", "Detached policy from user %s", *u.UserName)
+	}
+
+	boundary, err := rm.listEntitiesForPolicy(ctx, policyARN, svcsdktypes.PolicyUsageTypePermissionsBoundary, 0)
+	if err != nil {
+		return err
+	}
+	for _, role := range boundary.PolicyRoles {
+		_, err = rm.sdkapi.DeleteRolePermissionsBoundary(ctx, &svcsdk.DeleteRolePermissionsBoundaryInput{
+			RoleName: role.RoleName,
+		})
+		rm.metrics.RecordAPICall("DELETE", "DeleteRolePermissionsBoundary", err)
+		if err != nil {
+			return err
+		}
+		recordEvent("PermissionsBoundaryRemoved", "Removed policy as the permissions boundary of role %s", *role.RoleName)
+	}
+	for _, u := range boundary.PolicyUsers {
+		_, err = rm.sdkapi.DeleteUserPermissionsBoundary(ctx, &svcsdk.DeleteUserPermissionsBoundaryInput{
+			UserName: u.UserName,
+		})
+		rm.metrics.RecordAPICall("DELETE", "DeleteUserPermissionsBoundary", err)
+		if err != nil {
+			return err
+		}
+		recordEvent("PermissionsBoundaryRemoved", "Removed policy as the permissions boundary of user %s", *u.UserName)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+func policyWithUsage(mode *string, attachments, boundaries int64) *resource {
+	return &resource{
+		ko: &svcapitypes.Policy{
+			Spec: svcapitypes.PolicySpec{
+				Name:         aws.String("test-policy"),
+				DeletionMode: mode,
+			},
+			Status: svcapitypes.PolicyStatus{
+				AttachmentCount:               aws.Int64(attachments),
+				PermissionsBoundaryUsageCount: aws.Int64(boundaries),
+			},
+		},
+	}
+}
+
+func TestPrepareForDeletion_Block(t *testing.T) {
+	rm := &resourceManager{}
+	ctx := context.TODO()
+
+	assert.NoError(t, rm.prepareForDeletion(ctx, policyWithUsage(nil, 0, 0)))
+	assert.ErrorContains(t, rm.prepareForDeletion(ctx, policyWithUsage(nil, 2, 0)), "attached to 2 entities")
+	assert.ErrorContains(
+		t,
+		rm.prepareForDeletion(ctx, policyWithUsage(aws.String(DeletionModeBlock), 0, 1)),
+		"permissions boundary of 1 entities",
+	)
+}
+
+func TestPrepareForDeletion_InvalidMode(t *testing.T) {
+	rm := &resourceManager{}
+
+	err := rm.prepareForDeletion(context.TODO(), policyWithUsage(aws.String("detach"), 0, 0))
+	var termErr *ackerr.TerminalError
+	assert.ErrorAs(t, err, &termErr)
+}
+
+func TestPrepareForDeletion_ForceDetach(t *testing.T) {
+	ctx := context.TODO()
+	rm := newTestResourceManager(t)
+	recorder := record.NewFakeRecorder(10)
+	commonutil.SetEventRecorder(recorder)
+
+	policy, err := rm.sdkapi.CreatePolicy(ctx, &svcsdk.CreatePolicyInput{
+		PolicyName:     aws.String("test-policy"),
+		PolicyDocument: policyDocument("s3:GetObject"),
+	})
+	require.NoError(t, err)
+	policyARN := policy.Policy.Arn
+	trust := aws.String(`{"Version":"2012-10-17","Statement":[]}`)
+	_, err = rm.sdkapi.CreateRole(ctx, &svcsdk.CreateRoleInput{RoleName: aws.String("attached"), AssumeRolePolicyDocument: trust})
+	require.NoError(t, err)
+	_, err = rm.sdkapi.AttachRolePolicy(ctx, &svcsdk.AttachRolePolicyInput{RoleName: aws.String("attached"), PolicyArn: policyARN})
+	require.NoError(t, err)
+	_, err = rm.sdkapi.CreateRole(ctx, &svcsdk.CreateRoleInput{
+		RoleName: aws.String("bounded"), AssumeRolePolicyDocument: trust, PermissionsBoundary: policyARN,
+	})
+	require.NoError(t, err)
+	_, err = rm.sdkapi.CreateUser(ctx, &svcsdk.CreateUserInput{UserName: aws.String("user"), PermissionsBoundary: policyARN})
+	require.NoError(t, err)
+	_, err = rm.sdkapi.AttachUserPolicy(ctx, &svcsdk.AttachUserPolicyInput{UserName: aws.String("user"), PolicyArn: policyARN})
+	require.NoError(t, err)
+	_, err = rm.sdkapi.CreateGroup(ctx, &svcsdk.CreateGroupInput{GroupName: aws.String("group")})
+	require.NoError(t, err)
+	_, err = rm.sdkapi.AttachGroupPolicy(ctx, &svcsdk.AttachGroupPolicyInput{GroupName: aws.String("group"), PolicyArn: policyARN})
+	require.NoError(t, err)
+
+	_, err = rm.sdkapi.DeletePolicy(ctx, &svcsdk.DeletePolicyInput{PolicyArn: policyARN})
+	require.ErrorContains(t, err, "DeleteConflict")
+
+	// The Policy is detached from every entity, whatever its usage counts in
+	// status, so that it can be deleted.
+	r := policyWithUsage(aws.String(DeletionModeForceDetach), 0, 0)
+	arn := ackv1alpha1.AWSResourceName(*policyARN)
+	r.ko.Status.ACKResourceMetadata = &ackv1alpha1.ResourceMetadata{ARN: &arn}
+	require.NoError(t, rm.prepareForDeletion(ctx, r))
+	_, err = rm.sdkapi.DeletePolicy(ctx, &svcsdk.DeletePolicyInput{PolicyArn: policyARN})
+	require.NoError(t, err)
+
+	role, err := rm.sdkapi.GetRole(ctx, &svcsdk.GetRoleInput{RoleName: aws.String("bounded")})
+	require.NoError(t, err)
+	assert.Nil(t, role.Role.PermissionsBoundary)
+	user, err := rm.sdkapi.GetUser(ctx, &svcsdk.GetUserInput{UserName: aws.String("user")})
+	require.NoError(t, err)
+	assert.Nil(t, user.User.PermissionsBoundary)
+
+	events := []string{}
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	assert.Equal(t, []string{
+		"Normal PolicyDetached Detached policy from group group",
+		"Normal PolicyDetached Detached policy from role attached",
+		"Normal PolicyDetached Detached policy from user user",
+		"Normal PermissionsBoundaryRemoved Removed policy as the permissions boundary of role bounded",
+		"Normal PermissionsBoundaryRemoved Removed policy as the permissions boundary of user user",
+	}, events)
+}
+
+func TestPrepareForDeletion_ForceDetachNotCreated(t *testing.T) {
+	rm := &resourceManager{}
+
+	// A Policy that was never created has nothing to detach.
+	err := rm.prepareForDeletion(context.TODO(), policyWithUsage(aws.String(DeletionModeForceDetach), 1, 0))
+	assert.NoError(t, err)
+}
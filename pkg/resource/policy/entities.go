@@ -0,0 +1,164 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package policy
+
+import (
+	"context"
+
+	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+const (
+	// defaultAttachedEntitiesLimit is the maximum number of entities of each
+	// usage listed in status when Spec.AttachedEntitiesLimit is not set.
+	defaultAttachedEntitiesLimit = 100
+	// maxEntitiesPageSize is the largest page ListEntitiesForPolicy returns.
+	maxEntitiesPageSize = 1000
+	// attachedEntitiesFailedReason is the reason of the ACK.Advisory
+	// condition reporting that the entities of a Policy could not be listed.
+	attachedEntitiesFailedReason = "AttachedEntitiesFailed"
+)
+
+// listEntitiesForPolicy calls the ListEntitiesForPolicy API for the supplied
+// policy ARN and usage filter, following pagination until at most limit
+// entities are collected. Pages are requested no larger than the entities
+// still missing. A limit of zero or less lists every entity.
+func (rm *resourceManager) listEntitiesForPolicy(
+	ctx context.Context,
+	policyARN string,
+	usage svcsdktypes.PolicyUsageType,
+	limit int,
+) (entities *svcapitypes.PolicyEntities, err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.listEntitiesForPolicy")
+	defer func() { exit(err) }()
+
+	input := &svcsdk.ListEntitiesForPolicyInput{
+		PolicyArn:         &policyARN,
+		PolicyUsageFilter: usage,
+	}
+	entities = &svcapitypes.PolicyEntities{}
+	count := 0
+	truncated := false
+	for {
+		if limit > 0 {
+			input.MaxItems = aws.Int32(int32(min(limit-count, maxEntitiesPageSize)))
+		}
+		page, err := rm.sdkapi.ListEntitiesForPolicy(ctx, input)
+		rm.metrics.RecordAPICall("READ_MANY", "ListEntitiesForPolicy", err)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range page.PolicyGroups {
+			entities.PolicyGroups = append(entities.PolicyGroups, &svcapitypes.PolicyGroup{
+				GroupID:   g.GroupId,
+				GroupName: g.GroupName,
+			})
+		}
+		for _, r := range page.PolicyRoles {
+			entities.PolicyRoles = append(entities.PolicyRoles, &svcapitypes.PolicyRole{
+				RoleID:   r.RoleId,
+				RoleName: r.RoleName,
+			})
+		}
+		for _, u := range page.PolicyUsers {
+			entities.PolicyUsers = append(entities.PolicyUsers, &svcapitypes.PolicyUser{
+				UserID:   u.UserId,
+				UserName: u.UserName,
+			})
+		}
+		count += len(page.PolicyGroups) + len(page.PolicyRoles) + len(page.PolicyUsers)
+		if !page.IsTruncated || page.Marker == nil {
+			break
+		}
+		if limit > 0 && count >= limit {
+			truncated = true
+			break
+		}
+		input.Marker = page.Marker
+	}
+	if limit > 0 && count > limit {
+		truncated = true
+		entities.PolicyGroups, entities.PolicyRoles, entities.PolicyUsers = truncateEntities(
+			entities.PolicyGroups, entities.PolicyRoles, entities.PolicyUsers, limit,
+		)
+	}
+	entities.Truncated = &truncated
+	return entities, nil
+}
+
+// truncateEntities keeps the first limit entities, in group, role, user
+// order.
+func truncateEntities(
+	groups []*svcapitypes.PolicyGroup,
+	roles []*svcapitypes.PolicyRole,
+	users []*svcapitypes.PolicyUser,
+	limit int,
+) ([]*svcapitypes.PolicyGroup, []*svcapitypes.PolicyRole, []*svcapitypes.PolicyUser) {
+	if len(groups) >= limit {
+		return groups[:limit], nil, nil
+	}
+	limit -= len(groups)
+	if len(roles) >= limit {
+		return groups, roles[:limit], nil
+	}
+	limit -= len(roles)
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return groups, roles, users
+}
+
+// setAttachedEntities publishes the roles, users and groups the supplied
+// Policy is attached to, and those using it as a permissions boundary, in its
+// status. Listing is disabled by setting Spec.AttachedEntitiesLimit to zero.
+// It is best-effort: failures are reported as a condition and the previously
+// listed entities are kept.
+func (rm *resourceManager) setAttachedEntities(
+	ctx context.Context,
+	ko *svcapitypes.Policy,
+) {
+	limit := defaultAttachedEntitiesLimit
+	if ko.Spec.AttachedEntitiesLimit != nil {
+		limit = int(*ko.Spec.AttachedEntitiesLimit)
+	}
+	if limit <= 0 || ko.Status.ACKResourceMetadata == nil || ko.Status.ACKResourceMetadata.ARN == nil {
+		ko.Status.AttachedEntities = nil
+		ko.Status.PermissionsBoundaryEntities = nil
+		return
+	}
+	policyARN := string(*ko.Status.ACKResourceMetadata.ARN)
+	attached, err := rm.listEntitiesForPolicy(
+		ctx, policyARN, svcsdktypes.PolicyUsageTypePermissionsPolicy, limit,
+	)
+	var boundaries *svcapitypes.PolicyEntities
+	if err == nil {
+		boundaries, err = rm.listEntitiesForPolicy(
+			ctx, policyARN, svcsdktypes.PolicyUsageTypePermissionsBoundary, limit,
+		)
+	}
+	if err != nil {
+		ackrtlog.FromContext(ctx).Debug("failed to list policy entities", "error", err)
+	} else {
+		ko.Status.AttachedEntities = attached
+		ko.Status.PermissionsBoundaryEntities = boundaries
+	}
+	commonutil.SetAdvisoryFromError(&resource{ko}, attachedEntitiesFailedReason, err)
+}
@@ -0,0 +1,122 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+// recordListEntitiesForPolicy makes the IAM client of the supplied resource
+// manager record the MaxItems of its ListEntitiesForPolicy calls.
+func recordListEntitiesForPolicy(rm *resourceManager) *[]int32 {
+	pageSizes := &[]int32{}
+	rm.sdkapi = svcsdk.New(rm.sdkapi.Options(), func(o *svcsdk.Options) {
+		o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+			return stack.Initialize.Add(middleware.InitializeMiddlewareFunc(
+				"RecordListEntitiesForPolicy",
+				func(
+					ctx context.Context,
+					in middleware.InitializeInput,
+					next middleware.InitializeHandler,
+				) (middleware.InitializeOutput, middleware.Metadata, error) {
+					if input, ok := in.Parameters.(*svcsdk.ListEntitiesForPolicyInput); ok {
+						*pageSizes = append(*pageSizes, aws.ToInt32(input.MaxItems))
+					}
+					return next.HandleInitialize(ctx, in)
+				},
+			), middleware.Before)
+		})
+	})
+	return pageSizes
+}
+
+func TestSetAttachedEntities(t *testing.T) {
+	ctx := context.TODO()
+	rm := newTestResourceManager(t)
+
+	policy, err := rm.sdkapi.CreatePolicy(ctx, &svcsdk.CreatePolicyInput{
+		PolicyName:     aws.String("test-policy"),
+		PolicyDocument: policyDocument("s3:GetObject"),
+	})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		name := aws.String(fmt.Sprintf("role-%d", i))
+		_, err = rm.sdkapi.CreateRole(ctx, &svcsdk.CreateRoleInput{
+			RoleName:                 name,
+			AssumeRolePolicyDocument: aws.String(`{"Version":"2012-10-17","Statement":[]}`),
+		})
+		require.NoError(t, err)
+		_, err = rm.sdkapi.AttachRolePolicy(ctx, &svcsdk.AttachRolePolicyInput{
+			RoleName:  name,
+			PolicyArn: policy.Policy.Arn,
+		})
+		require.NoError(t, err)
+	}
+	pageSizes := recordListEntitiesForPolicy(rm)
+
+	arn := ackv1alpha1.AWSResourceName(*policy.Policy.Arn)
+	ko := &svcapitypes.Policy{
+		Spec: svcapitypes.PolicySpec{AttachedEntitiesLimit: aws.Int64(2)},
+		Status: svcapitypes.PolicyStatus{
+			ACKResourceMetadata: &ackv1alpha1.ResourceMetadata{ARN: &arn},
+		},
+	}
+	rm.setAttachedEntities(ctx, ko)
+	require.NotNil(t, ko.Status.AttachedEntities)
+	assert.Len(t, ko.Status.AttachedEntities.PolicyRoles, 2)
+	assert.True(t, *ko.Status.AttachedEntities.Truncated)
+	assert.Empty(t, ko.Status.PermissionsBoundaryEntities.PolicyRoles)
+	assert.False(t, *ko.Status.PermissionsBoundaryEntities.Truncated)
+	// Only the entities up to the limit are requested.
+	assert.Equal(t, []int32{2, 2}, *pageSizes)
+
+	// Failures are reported, and the entities listed before are kept.
+	missing := ackv1alpha1.AWSResourceName("arn:aws:iam::123456789012:policy/missing")
+	ko.Status.ACKResourceMetadata.ARN = &missing
+	rm.setAttachedEntities(ctx, ko)
+	assert.Len(t, ko.Status.AttachedEntities.PolicyRoles, 2)
+	assert.NotNil(t, ackcondition.AdvisoryWithReason(&resource{ko}, attachedEntitiesFailedReason))
+
+	ko.Status.ACKResourceMetadata.ARN = &arn
+	rm.setAttachedEntities(ctx, ko)
+	assert.Nil(t, ackcondition.AdvisoryWithReason(&resource{ko}, attachedEntitiesFailedReason))
+}
+
+func TestTruncateEntities(t *testing.T) {
+	groups := []*svcapitypes.PolicyGroup{{GroupName: aws.String("g")}}
+	roles := []*svcapitypes.PolicyRole{{RoleName: aws.String("r1")}, {RoleName: aws.String("r2")}}
+	users := []*svcapitypes.PolicyUser{{UserName: aws.String("u")}}
+
+	g, r, u := truncateEntities(groups, roles, users, 2)
+	assert.Len(t, g, 1)
+	assert.Len(t, r, 1)
+	assert.Empty(t, u)
+
+	g, r, u = truncateEntities(groups, roles, users, 4)
+	assert.Len(t, g, 1)
+	assert.Len(t, r, 2)
+	assert.Len(t, u, 1)
+}
@@ -21,9 +21,9 @@ import (
 
 	ackcompare "github.com/aws-controllers-k8s/runtime/pkg/compare"
 	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
 	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
 	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
-	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 	corev1 "k8s.io/api/core/v1"
 
 	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
@@ -55,11 +55,16 @@ func (rm *resourceManager) customUpdatePolicy(
 	}
 
 	if delta.DifferentAt("Spec.PolicyDocument") {
-		newVersionID, err := rm.updatePolicyDocument(ctx, desired)
-		if err != nil {
+		if !rm.primaryPolicyDocumentInSync(desired, latest) {
+			newVersionID, err := rm.updatePolicyDocument(ctx, desired)
+			if err != nil {
+				return nil, err
+			}
+			ko.Status.DefaultVersionID = &newVersionID
+		}
+		if err := rm.syncPolicyShards(ctx, desired, latest, ko); err != nil {
 			return nil, err
 		}
-		ko.Status.DefaultVersionID = &newVersionID
 	}
 
 	// There really isn't a status of a policy... it either exists or doesn't.
@@ -70,9 +75,8 @@ func (rm *resourceManager) customUpdatePolicy(
 	return &resource{ko}, nil
 }
 
-// syncTags examines the Tags in the supplied Policy and calls the
-// ListPolicyTags, TagPolicy and UntagPolicy APIs to ensure that the set of
-// associated Tags  stays in sync with the Policy.Spec.Tags
+// syncTags calls the TagPolicy and UntagPolicy APIs to ensure that the set of
+// Tags associated with the Policy stays in sync with the Policy.Spec.Tags
 func (rm *resourceManager) syncTags(
 	ctx context.Context,
 	desired *resource,
@@ -81,41 +85,11 @@ func (rm *resourceManager) syncTags(
 	rlog := ackrtlog.FromContext(ctx)
 	exit := rlog.Trace("rm.syncTags")
 	defer func() { exit(err) }()
-	toAdd := []*svcapitypes.Tag{}
-	toDelete := []*svcapitypes.Tag{}
-
-	existingTags := latest.ko.Spec.Tags
-
-	for _, t := range desired.ko.Spec.Tags {
-		if !inTags(*t.Key, *t.Value, existingTags) {
-			toAdd = append(toAdd, t)
-		}
-	}
-
-	for _, t := range existingTags {
-		if !inTags(*t.Key, *t.Value, desired.ko.Spec.Tags) {
-			toDelete = append(toDelete, t)
-		}
-	}
-
-	if len(toDelete) > 0 {
-		for _, t := range toDelete {
-			rlog.Debug("removing tag from policy", "key", *t.Key, "value", *t.Value)
-		}
-		if err = rm.removeTags(ctx, desired, toDelete); err != nil {
-			return err
-		}
-	}
-	if len(toAdd) > 0 {
-		for _, t := range toAdd {
-			rlog.Debug("adding tag to policy", "key", *t.Key, "value", *t.Value)
-		}
-		if err = rm.addTags(ctx, desired, toAdd); err != nil {
-			return err
-		}
-	}
 
-	return nil
+	return commonutil.SyncTags(
+		ctx, rm.sdkapi, rm.metrics, commonutil.ARNResourceTypePolicy,
+		(*string)(desired.ko.Status.ACKResourceMetadata.ARN), desired.ko.Spec.Tags, latest.ko.Spec.Tags,
+	)
 }
 
 // compareTags is a custom comparison function for comparing lists of Tag
@@ -134,24 +108,6 @@ func compareTags(
 	}
 }
 
-// inTags returns true if the supplied key and value can be found in the
-// supplied list of Tag structs.
-//
-// TODO(jaypipes): When we finally standardize Tag handling in ACK, move this
-// to the ACK common runtime/ or pkg/ repos
-func inTags(
-	key string,
-	value string,
-	tags []*svcapitypes.Tag,
-) bool {
-	for _, t := range tags {
-		if *t.Key == key && *t.Value == value {
-			return true
-		}
-	}
-	return false
-}
-
 // getTags returns the list of tags attached to the Policy
 func (rm *resourceManager) getTags(
 	ctx context.Context,
@@ -184,50 +140,67 @@ func (rm *resourceManager) getTags(
 	return res, err
 }
 
-// addTags adds the supplied Tags to the supplied Policy resource
-func (rm *resourceManager) addTags(
-	ctx context.Context,
-	r *resource,
-	tags []*svcapitypes.Tag,
-) (err error) {
-	rlog := ackrtlog.FromContext(ctx)
-	exit := rlog.Trace("rm.addTag")
-	defer func() { exit(err) }()
+// policyVariables returns the values of the ${ack:...} variables of the
+// policy document of the supplied Policy.
+func (rm *resourceManager) policyVariables(r *resource) commonutil.PolicyVariables {
+	return commonutil.NewPolicyVariables(
+		string(rm.awsAccountID), string(rm.awsPartition), string(rm.awsRegion), r.ko,
+	)
+}
 
-	input := &svcsdk.TagPolicyInput{}
-	input.PolicyArn = (*string)(r.ko.Status.ACKResourceMetadata.ARN)
-	inTags := []svcsdktypes.Tag{}
-	for _, t := range tags {
-		inTags = append(inTags, svcsdktypes.Tag{Key: t.Key, Value: t.Value})
+// renderedPolicyDocument returns the policy document of the supplied Policy
+// with its ${ack:...} variables and ${ref:<alias>} placeholders substituted,
+// reporting unknown variables and aliases as terminal errors since they can
+// only be fixed by changing the spec.
+func (rm *resourceManager) renderedPolicyDocument(r *resource) (*string, error) {
+	if r.ko.Spec.PolicyDocument == nil {
+		return nil, nil
 	}
-	input.Tags = inTags
-
-	_, err = rm.sdkapi.TagPolicy(ctx, input)
-	rm.metrics.RecordAPICall("UPDATE", "TagPolicy", err)
-	return err
+	doc, err := commonutil.RenderPolicyDocument(*r.ko.Spec.PolicyDocument, rm.policyVariables(r))
+	if err != nil {
+		return nil, ackerr.NewTerminalError(err)
+	}
+	doc, err = commonutil.RenderPolicyReferences(doc, r.ko.Status.ResolvedResourceRefs)
+	if err != nil {
+		return nil, ackerr.NewTerminalError(err)
+	}
+	return &doc, nil
 }
 
-// removeTags removes the supplied Tags from the supplied Policy resource
-func (rm *resourceManager) removeTags(
-	ctx context.Context,
-	r *resource,
-	tags []*svcapitypes.Tag,
-) (err error) {
-	rlog := ackrtlog.FromContext(ctx)
-	exit := rlog.Trace("rm.removeTag")
-	defer func() { exit(err) }()
-
-	input := &svcsdk.UntagPolicyInput{}
-	input.PolicyArn = (*string)(r.ko.Status.ACKResourceMetadata.ARN)
-	inTagKeys := []string{}
-	for _, t := range tags {
-		inTagKeys = append(inTagKeys, *t.Key)
+// setRenderedPolicyDocument records the policy document rendered from the
+// declared Policy, or with Spec.SplitOversizedDocument the part of it held by
+// the Policy itself, in Status.RenderedPolicyDocument and, when the document
+// read from IAM matches it, keeps the declared document in the Spec so that
+// its variables are not reported as a difference.
+func (rm *resourceManager) setRenderedPolicyDocument(
+	declared *resource,
+	ko *svcapitypes.Policy,
+) {
+	rendered, err := rm.primaryPolicyDocument(declared)
+	if err != nil {
+		// The error is reported when the document is updated.
+		ko.Status.RenderedPolicyDocument = nil
+		return
 	}
-	input.TagKeys = inTagKeys
+	ko.Status.RenderedPolicyDocument = rendered
+	ko.Spec.PolicyDocument = commonutil.DeclaredPolicyDocument(
+		declared.ko.Spec.PolicyDocument, rendered, ko.Spec.PolicyDocument,
+	)
+}
 
-	_, err = rm.sdkapi.UntagPolicy(ctx, input)
-	rm.metrics.RecordAPICall("UPDATE", "UntagPolicy", err)
-	return err
+// primaryPolicyDocumentInSync returns true if the document read from IAM
+// into the latest Policy matches the one of the desired Policy itself, that
+// is when only its shards differ.
+func (rm *resourceManager) primaryPolicyDocumentInSync(
+	desired *resource,
+	latest *resource,
+) bool {
+	doc, err := rm.primaryPolicyDocument(desired)
+	if err != nil || doc == nil || latest.ko.Spec.PolicyDocument == nil {
+		return false
+	}
+	equal, err := ackcompare.IAMPolicyDocumentEqual(*doc, *latest.ko.Spec.PolicyDocument)
+	return err == nil && equal
 }
 
 // updatePolicyDocument creates a new Policy version with the new
@@ -253,7 +226,9 @@ func (rm *resourceManager) updatePolicyDocument(
 
 	input := &svcsdk.CreatePolicyVersionInput{}
 	input.PolicyArn = policyARN
-	input.PolicyDocument = r.ko.Spec.PolicyDocument
+	if input.PolicyDocument, err = rm.primaryPolicyDocument(r); err != nil {
+		return "", err
+	}
 
 	input.SetAsDefault = true
 
@@ -428,17 +403,16 @@ func (rm *resourceManager) deletePolicyVersion(
 }
 
 // deleteNonDefaultPolicyVersions removes all policy versions other than the
-// default version (which is deleted when the policy itself is deleted).
+// default version (which is deleted when the policy itself is deleted) from
+// the managed policy with the supplied ARN.
 func (rm *resourceManager) deleteNonDefaultPolicyVersions(
 	ctx context.Context,
-	r *resource,
+	policyARN string,
 ) (err error) {
 	rlog := ackrtlog.FromContext(ctx)
 	exit := rlog.Trace("rm.deleteNonDefaultPolicyVersions")
 	defer func() { exit(err) }()
 
-	policyARN := string(*r.ko.Status.ACKResourceMetadata.ARN)
-
 	versions, err := rm.getPolicyVersions(ctx, policyARN)
 	if err != nil {
 		return err
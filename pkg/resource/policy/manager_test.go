@@ -0,0 +1,90 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackmetrics "github.com/aws-controllers-k8s/runtime/pkg/metrics"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+)
+
+func newTestResourceManager(t *testing.T) *resourceManager {
+	rm, err := newResourceManager(
+		ackcfg.Config{Partition: "aws"}, fakeiam.New().Config(), logr.Discard(), ackmetrics.NewMetrics("iam"),
+		nil, fakeiam.AccountID, fakeiam.Region,
+	)
+	require.NoError(t, err)
+	return rm
+}
+
+func policyDocument(action string) *string {
+	return aws.String(fmt.Sprintf(
+		`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"%s","Resource":"*"}]}`,
+		action,
+	))
+}
+
+func TestResourceManager_Lifecycle(t *testing.T) {
+	ctx := context.TODO()
+	rm := newTestResourceManager(t)
+
+	desired := &resource{ko: &svcapitypes.Policy{
+		Spec: svcapitypes.PolicySpec{
+			Name:           aws.String("test-policy"),
+			PolicyDocument: policyDocument("s3:GetObject"),
+		},
+	}}
+	res, err := rm.Create(ctx, desired)
+	require.NoError(t, err)
+	latest := rm.concreteResource(res)
+	require.NotNil(t, latest.ko.Status.ACKResourceMetadata.ARN)
+	assert.Equal(t, "v1", *latest.ko.Status.DefaultVersionID)
+
+	// Updating the document more often than the version limit allows
+	// deletes the oldest non-default versions.
+	for i := 0; i < limitPolicyVersions+1; i++ {
+		desired = rm.concreteResource(latest.DeepCopy())
+		desired.ko.Spec.PolicyDocument = policyDocument(fmt.Sprintf("s3:Action%d", i))
+		delta := newResourceDelta(desired, latest)
+		require.True(t, delta.DifferentAt("Spec.PolicyDocument"))
+		_, err = rm.Update(ctx, desired, latest, delta)
+		require.NoError(t, err)
+
+		res, err = rm.ReadOne(ctx, desired)
+		require.NoError(t, err)
+		latest = rm.concreteResource(res)
+	}
+	assert.Equal(t, fmt.Sprintf("v%d", limitPolicyVersions+2), *latest.ko.Status.DefaultVersionID)
+	assert.JSONEq(t, *policyDocument(fmt.Sprintf("s3:Action%d", limitPolicyVersions)), *latest.ko.Spec.PolicyDocument)
+	versions, err := rm.getPolicyVersions(ctx, string(*latest.ko.Status.ACKResourceMetadata.ARN))
+	require.NoError(t, err)
+	assert.Len(t, versions, limitPolicyVersions)
+
+	// Deleting the policy deletes its non-default versions first.
+	_, err = rm.Delete(ctx, latest)
+	require.NoError(t, err)
+	_, err = rm.ReadOne(ctx, latest)
+	assert.Equal(t, ackerr.NotFound, err)
+}
@@ -0,0 +1,160 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackrt "github.com/aws-controllers-k8s/runtime/pkg/runtime"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+const (
+	// defaultResourceRefFieldPath is the field read from a resource
+	// referenced in Spec.ResourceRefs when none is specified: the ARN every
+	// ACK resource reports.
+	defaultResourceRefFieldPath = ".status.ackResourceMetadata.arn"
+	// defaultResourceRefVersion is the API version of a resource referenced
+	// in Spec.ResourceRefs whose group does not carry one.
+	defaultResourceRefVersion = "v1alpha1"
+)
+
+// resolveResourceRefs reads the resources referenced from the ResourceRefs
+// field and records the value of each one's field in
+// Status.ResolvedResourceRefs, keyed by alias, for the ${ref:<alias>}
+// placeholders of the PolicyDocument. Returns an error until every
+// referenced resource reports its value. It is called by ResolveReferences,
+// with the runtime's uncached API reader, before the Policy is read, created,
+// updated or deleted; the runtime only ignores the error when deleting.
+func (rm *resourceManager) resolveResourceRefs(
+	ctx context.Context,
+	apiReader client.Reader,
+	ko *svcapitypes.Policy,
+) error {
+	if len(ko.Spec.ResourceRefs) == 0 {
+		ko.Status.ResolvedResourceRefs = nil
+		return nil
+	}
+	aliases := make([]string, 0, len(ko.Spec.ResourceRefs))
+	for alias := range ko.Spec.ResourceRefs {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	resolved := make(map[string]*string, len(aliases))
+	for _, alias := range aliases {
+		value, err := rm.resolveResourceRef(ctx, apiReader, ko, alias, ko.Spec.ResourceRefs[alias])
+		if err != nil {
+			return err
+		}
+		resolved[alias] = &value
+	}
+	ko.Status.ResolvedResourceRefs = resolved
+	return nil
+}
+
+// resolveResourceRef returns the value of the field of the resource
+// referenced under the supplied alias.
+func (rm *resourceManager) resolveResourceRef(
+	ctx context.Context,
+	apiReader client.Reader,
+	ko *svcapitypes.Policy,
+	alias string,
+	ref *svcapitypes.PolicyResourceRef,
+) (string, error) {
+	if ref == nil || ref.Kind == nil || *ref.Kind == "" || ref.Name == nil || *ref.Name == "" {
+		return "", fmt.Errorf("provided resource reference is nil or empty: ResourceRefs[%s]", alias)
+	}
+	namespace, err := ackrt.ResolveCrossNamespaceReference(
+		ctx,
+		rm.cfg.EnableCrossNamespace,
+		&ko.Status.Conditions,
+		ackrt.CrossNamespaceRefKindResource,
+		ko.ObjectMeta.GetNamespace(),
+		ref.Namespace,
+		*ref.Name,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(resourceRefGroupVersionKind(ref))
+	namespacedName := types.NamespacedName{Namespace: namespace, Name: *ref.Name}
+	if err := apiReader.Get(ctx, namespacedName, obj); err != nil {
+		return "", err
+	}
+	if hasTerminalCondition(obj) {
+		return "", ackerr.ResourceReferenceTerminalFor(*ref.Kind, namespace, *ref.Name)
+	}
+	fieldPath := resourceRefFieldPath(ref)
+	value, found, err := unstructured.NestedString(obj.Object, strings.Split(strings.TrimPrefix(fieldPath, "."), ".")...)
+	if err != nil || !found || value == "" {
+		return "", ackerr.ResourceReferenceMissingTargetFieldFor(*ref.Kind, namespace, *ref.Name, fieldPath)
+	}
+	return value, nil
+}
+
+// resourceRefGroupVersionKind returns the GroupVersionKind of the resource
+// referenced by the supplied PolicyResourceRef. The group may carry the API
+// version, as in s3.services.k8s.aws/v1alpha1.
+func resourceRefGroupVersionKind(ref *svcapitypes.PolicyResourceRef) schema.GroupVersionKind {
+	gvk := schema.GroupVersionKind{Kind: *ref.Kind, Version: defaultResourceRefVersion}
+	if ref.Group == nil || *ref.Group == "" {
+		// The core API group only has the v1 version.
+		gvk.Version = "v1"
+		return gvk
+	}
+	group, version, found := strings.Cut(*ref.Group, "/")
+	gvk.Group = group
+	if found && version != "" {
+		gvk.Version = version
+	}
+	return gvk
+}
+
+// resourceRefFieldPath returns the path of the field read from the resource
+// referenced by the supplied PolicyResourceRef.
+func resourceRefFieldPath(ref *svcapitypes.PolicyResourceRef) string {
+	if ref.FieldPath == nil || *ref.FieldPath == "" {
+		return defaultResourceRefFieldPath
+	}
+	return *ref.FieldPath
+}
+
+// hasTerminalCondition returns true if the supplied resource has an ACK
+// Terminal condition set to True.
+func hasTerminalCondition(obj *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if cond["type"] == string(ackv1alpha1.ConditionTypeTerminal) && cond["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
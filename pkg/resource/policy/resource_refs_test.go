@@ -0,0 +1,170 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+func bucket(name string, status map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "s3.services.k8s.aws/v1alpha1",
+		"kind":       "Bucket",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "team-a"},
+		"spec":       map[string]interface{}{"name": name + "-bucket"},
+	}}
+	if status != nil {
+		obj.Object["status"] = status
+	}
+	return obj
+}
+
+func policyWithRefs(refs map[string]*svcapitypes.PolicyResourceRef) *svcapitypes.Policy {
+	return &svcapitypes.Policy{
+		ObjectMeta: metav1.ObjectMeta{Name: "reader", Namespace: "team-a"},
+		Spec: svcapitypes.PolicySpec{
+			Name:         aws.String("reader"),
+			ResourceRefs: refs,
+		},
+	}
+}
+
+func TestResolveResourceRefs(t *testing.T) {
+	reader := fake.NewClientBuilder().WithObjects(
+		bucket("data", map[string]interface{}{
+			"ackResourceMetadata": map[string]interface{}{"arn": "arn:aws:s3:::data-bucket"},
+		}),
+		bucket("pending", nil),
+	).Build()
+	rm := &resourceManager{}
+
+	ko := policyWithRefs(map[string]*svcapitypes.PolicyResourceRef{
+		"data": {Group: aws.String("s3.services.k8s.aws"), Kind: aws.String("Bucket"), Name: aws.String("data")},
+		"name": {
+			Group:     aws.String("s3.services.k8s.aws/v1alpha1"),
+			Kind:      aws.String("Bucket"),
+			Name:      aws.String("data"),
+			FieldPath: aws.String(".spec.name"),
+		},
+	})
+	err := rm.resolveResourceRefs(context.TODO(), reader, ko)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*string{
+		"data": aws.String("arn:aws:s3:::data-bucket"),
+		"name": aws.String("data-bucket"),
+	}, ko.Status.ResolvedResourceRefs)
+
+	// A referenced resource that has no ARN yet requeues the Policy.
+	ko = policyWithRefs(map[string]*svcapitypes.PolicyResourceRef{
+		"pending": {Group: aws.String("s3.services.k8s.aws"), Kind: aws.String("Bucket"), Name: aws.String("pending")},
+	})
+	err = rm.resolveResourceRefs(context.TODO(), reader, ko)
+	assert.ErrorIs(t, err, ackerr.ResourceReferenceMissingTargetField)
+
+	ko = policyWithRefs(nil)
+	ko.Status.ResolvedResourceRefs = map[string]*string{"stale": aws.String("x")}
+	err = rm.resolveResourceRefs(context.TODO(), reader, ko)
+	require.NoError(t, err)
+	assert.Nil(t, ko.Status.ResolvedResourceRefs)
+}
+
+func TestResolveResourceRefs_Terminal(t *testing.T) {
+	reader := fake.NewClientBuilder().WithObjects(
+		bucket("broken", map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "ACK.Terminal", "status": "True"},
+			},
+		}),
+	).Build()
+	rm := &resourceManager{}
+
+	ko := policyWithRefs(map[string]*svcapitypes.PolicyResourceRef{
+		"broken": {Group: aws.String("s3.services.k8s.aws"), Kind: aws.String("Bucket"), Name: aws.String("broken")},
+	})
+	err := rm.resolveResourceRefs(context.TODO(), reader, ko)
+	assert.ErrorIs(t, err, ackerr.ResourceReferenceTerminal)
+}
+
+func TestResourceManager_ResourceRefs(t *testing.T) {
+	ctx := context.TODO()
+	reader := fake.NewClientBuilder().WithObjects(
+		bucket("data", map[string]interface{}{
+			"ackResourceMetadata": map[string]interface{}{"arn": "arn:aws:s3:::data-bucket"},
+		}),
+	).Build()
+	rm := newTestResourceManager(t)
+
+	ko := policyWithRefs(map[string]*svcapitypes.PolicyResourceRef{
+		"data": {Group: aws.String("s3.services.k8s.aws"), Kind: aws.String("Bucket"), Name: aws.String("data")},
+	})
+	ko.Spec.PolicyDocument = aws.String(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"${ref:data}"}]}`)
+
+	// As in a reconciliation, the references of the Policy are resolved
+	// before it is read and created.
+	resolved, hasReferences, err := rm.ResolveReferences(ctx, reader, &resource{ko})
+	require.NoError(t, err)
+	assert.True(t, hasReferences)
+	desired := rm.concreteResource(resolved)
+	_, err = rm.ReadOne(ctx, desired)
+	require.Equal(t, ackerr.NotFound, err)
+	res, err := rm.Create(ctx, desired)
+	require.NoError(t, err)
+	latest := rm.concreteResource(res)
+	assert.Equal(t, map[string]*string{"data": aws.String("arn:aws:s3:::data-bucket")}, latest.ko.Status.ResolvedResourceRefs)
+
+	pv, err := rm.getPolicyVersion(ctx, string(*latest.ko.Status.ACKResourceMetadata.ARN), *latest.ko.Status.DefaultVersionID)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"arn:aws:s3:::data-bucket"}]}`, pv.document)
+
+	// The declared document is kept while the rendered one is in sync.
+	res, err = rm.ReadOne(ctx, rm.concreteResource(latest.DeepCopy()))
+	require.NoError(t, err)
+	assert.Equal(t, *ko.Spec.PolicyDocument, *rm.concreteResource(res).ko.Spec.PolicyDocument)
+
+	// A reference that can no longer be resolved fails ResolveReferences,
+	// which blocks updates, but neither reading nor deleting the Policy.
+	_, _, err = rm.ResolveReferences(ctx, fake.NewClientBuilder().Build(), latest.DeepCopy())
+	require.Error(t, err)
+	res, err = rm.ReadOne(ctx, latest)
+	require.NoError(t, err)
+	_, err = rm.Delete(ctx, res)
+	require.NoError(t, err)
+}
+
+func TestRenderedPolicyDocument_ResourceRefs(t *testing.T) {
+	rm := &resourceManager{awsAccountID: "123456789012", awsPartition: "aws"}
+	ko := policyWithRefs(nil)
+	ko.Spec.PolicyDocument = aws.String(`{"Resource":["${ref:data}","arn:${ack:partition}:s3:::x"]}`)
+	ko.Status.ResolvedResourceRefs = map[string]*string{"data": aws.String("arn:aws:s3:::data-bucket")}
+
+	doc, err := rm.renderedPolicyDocument(&resource{ko})
+	require.NoError(t, err)
+	assert.Equal(t, `{"Resource":["arn:aws:s3:::data-bucket","arn:aws:s3:::x"]}`, *doc)
+
+	ko.Spec.PolicyDocument = aws.String(`{"Resource":"${ref:missing}"}`)
+	_, err = rm.renderedPolicyDocument(&resource{ko})
+	var termErr *ackerr.TerminalError
+	assert.ErrorAs(t, err, &termErr)
+}
@@ -172,8 +172,17 @@ func (rm *resourceManager) sdkFind(
 			return nil, err
 		} else {
 			ko.Spec.PolicyDocument = &pv.document
+			rm.setRenderedPolicyDocument(r, ko)
+			if inSync, err := rm.observePolicyShards(ctx, r, ko); err != nil {
+				return nil, err
+			} else if !inSync {
+				// Report the document read from IAM so that the shards are
+				// synced on update.
+				ko.Spec.PolicyDocument = &pv.document
+			}
 		}
 	}
+	rm.setAttachedEntities(ctx, ko)
 
 	return &resource{ko}, nil
 }
@@ -218,6 +227,9 @@ func (rm *resourceManager) sdkCreate(
 	if err != nil {
 		return nil, err
 	}
+	if input.PolicyDocument, err = rm.primaryPolicyDocument(desired); err != nil {
+		return nil, err
+	}
 
 	var resp *svcsdk.CreatePolicyOutput
 	_ = resp
@@ -298,6 +310,11 @@ func (rm *resourceManager) sdkCreate(
 	}
 
 	rm.setStatusDefaults(ko)
+	if splitOversizedDocument(desired) {
+		// The shards are tagged with the ARN of the Policy, so they are
+		// created on update.
+		return &resource{ko}, ackrequeue.Needed(fmt.Errorf("policy created, requeuing to create its shards"))
+	}
 	return &resource{ko}, nil
 }
 
@@ -360,12 +377,20 @@ func (rm *resourceManager) sdkDelete(
 	defer func() {
 		exit(err)
 	}()
+	// DeletePolicy also fails with DeleteConflict while the policy is attached
+	// to any entity or used as a permissions boundary.
+	if err = rm.prepareForDeletion(ctx, r); err != nil {
+		return r, err
+	}
+	if err = rm.deletePolicyShards(ctx, r); err != nil {
+		return r, err
+	}
 	// This is to avoid the following error:
 	//
 	// DeleteConflict: This policy has more than one version. Before you delete a
 	// policy, you must delete the policy's versions. The default version is
 	// deleted with the policy.
-	if err = rm.deleteNonDefaultPolicyVersions(ctx, r); err != nil {
+	if err = rm.deleteNonDefaultPolicyVersions(ctx, string(*r.ko.Status.ACKResourceMetadata.ARN)); err != nil {
 		return r, err
 	}
 
@@ -0,0 +1,411 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	ackcompare "github.com/aws-controllers-k8s/runtime/pkg/compare"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
+	ackutil "github.com/aws-controllers-k8s/runtime/pkg/util"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	smithy "github.com/aws/smithy-go"
+	corev1 "k8s.io/api/core/v1"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+// policyShardOwnerTagKey is the key of the tag naming the Policy, by ARN,
+// that a shard was created for. Only shards carrying it are updated and
+// deleted along with the Policy.
+const policyShardOwnerTagKey = "iam.services.k8s.aws/owner-policy"
+
+// splitOversizedDocument returns true if the document of the supplied Policy
+// is to be compacted and, when still too large, split across shards.
+func splitOversizedDocument(r *resource) bool {
+	return r.ko.Spec.SplitOversizedDocument != nil && *r.ko.Spec.SplitOversizedDocument
+}
+
+// policyDocuments returns the documents of the managed policies making up
+// the supplied Policy. Without Spec.SplitOversizedDocument, that is the
+// rendered policy document alone. Otherwise the rendered document is
+// compacted and split across documents within IAM's size limit, the first of
+// which is the Policy's own and the others those of its shards.
+func (rm *resourceManager) policyDocuments(r *resource) ([]string, error) {
+	doc, err := rm.renderedPolicyDocument(r)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	if !splitOversizedDocument(r) {
+		return []string{*doc}, nil
+	}
+	docs, err := commonutil.SplitPolicyDocument(*doc, commonutil.ManagedPolicyMaxSize)
+	if err != nil {
+		return nil, ackerr.NewTerminalError(fmt.Errorf("splitting policy document: %w", err))
+	}
+	return docs, nil
+}
+
+// primaryPolicyDocument returns the document of the managed policy of the
+// supplied Policy itself.
+func (rm *resourceManager) primaryPolicyDocument(r *resource) (*string, error) {
+	docs, err := rm.policyDocuments(r)
+	if err != nil || len(docs) == 0 {
+		return nil, err
+	}
+	return &docs[0], nil
+}
+
+// shardARN returns the ARN of the n-th managed policy, counting the Policy's
+// own as the first, holding the statements of the supplied Policy. Shards are
+// named <name>-part<n> and share the path of the Policy.
+func (rm *resourceManager) shardARN(ko *svcapitypes.Policy, n int) string {
+	path := ""
+	if ko.Spec.Path != nil {
+		path = *ko.Spec.Path
+	}
+	return commonutil.IAMARN(
+		string(rm.awsPartition), string(rm.awsAccountID), commonutil.ARNResourceTypePolicy,
+		path, shardName(ko, n),
+	)
+}
+
+// shardName returns the name of the n-th managed policy of the supplied
+// Policy.
+func shardName(ko *svcapitypes.Policy, n int) string {
+	return fmt.Sprintf("%s-part%d", *ko.Spec.Name, n)
+}
+
+// policyShard is a managed policy holding part of the statements of a
+// Policy, as read from IAM.
+type policyShard struct {
+	// owned is true if the shard carries the owner tag of the Policy.
+	owned bool
+	// document is the document of the shard's default version.
+	document string
+}
+
+// getPolicyShard returns the managed policy with the supplied ARN, or nil if
+// it does not exist, telling whether it is owned by the supplied Policy.
+func (rm *resourceManager) getPolicyShard(
+	ctx context.Context,
+	ko *svcapitypes.Policy,
+	arn string,
+) (shard *policyShard, err error) {
+	resp, err := rm.sdkapi.GetPolicy(ctx, &svcsdk.GetPolicyInput{PolicyArn: &arn})
+	rm.metrics.RecordAPICall("READ_ONE", "GetPolicy", err)
+	if isNoSuchEntity(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	shard = &policyShard{}
+	for _, t := range resp.Policy.Tags {
+		if aws.ToString(t.Key) == policyShardOwnerTagKey &&
+			aws.ToString(t.Value) == string(*ko.Status.ACKResourceMetadata.ARN) {
+			shard.owned = true
+		}
+	}
+	if resp.Policy.DefaultVersionId != nil {
+		pv, err := rm.getPolicyVersion(ctx, arn, *resp.Policy.DefaultVersionId)
+		if err != nil {
+			return nil, err
+		}
+		shard.document = pv.document
+	}
+	return shard, nil
+}
+
+// shardDocuments returns the documents of the shards of the supplied
+// declared Policy, keyed by shard ARN. It returns an error if the document
+// cannot be rendered.
+func (rm *resourceManager) shardDocuments(
+	declared *resource,
+	ko *svcapitypes.Policy,
+) (map[string]string, error) {
+	if !splitOversizedDocument(declared) {
+		return map[string]string{}, nil
+	}
+	docs, err := rm.policyDocuments(declared)
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]string{}
+	for i := 1; i < len(docs); i++ {
+		res[rm.shardARN(ko, i+1)] = docs[i]
+	}
+	return res, nil
+}
+
+// observePolicyShards records in Status.ShardARNs the ARNs of the shards
+// owned by the supplied latest Policy, both those the declared Policy needs
+// and those recorded before that it no longer needs. It returns false if the
+// shards do not hold the documents split from the declared Policy, in which
+// case they are synced by syncPolicyShards on update.
+func (rm *resourceManager) observePolicyShards(
+	ctx context.Context,
+	declared *resource,
+	ko *svcapitypes.Policy,
+) (inSync bool, err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.observePolicyShards")
+	defer func() { exit(err) }()
+
+	docs, err := rm.shardDocuments(declared, ko)
+	if err != nil {
+		// The error is reported when the document is updated.
+		return true, nil
+	}
+	inSync = true
+	shardARNs := []*string{}
+	for _, arn := range rm.policyShardARNs(ko, docs, ko.Status.ShardARNs) {
+		shard, err := rm.getPolicyShard(ctx, ko, arn)
+		if err != nil {
+			return false, err
+		}
+		doc, needed := docs[arn]
+		switch {
+		case shard == nil:
+			inSync = inSync && !needed
+			continue
+		case !shard.owned:
+			inSync = inSync && !needed
+			continue
+		case !needed:
+			inSync = false
+		default:
+			if equal, err := ackcompare.IAMPolicyDocumentEqual(shard.document, doc); err != nil || !equal {
+				inSync = false
+			}
+		}
+		shardARNs = append(shardARNs, aws.String(arn))
+	}
+	if len(shardARNs) == 0 {
+		shardARNs = nil
+	}
+	ko.Status.ShardARNs = shardARNs
+	return inSync, nil
+}
+
+// policyShardARNs returns the ARNs of the shards of the supplied Policy
+// holding the supplied documents, in order, followed by those of the
+// supplied recorded shards that are not among them.
+func (rm *resourceManager) policyShardARNs(
+	ko *svcapitypes.Policy,
+	docs map[string]string,
+	recorded []*string,
+) []string {
+	res := make([]string, 0, len(docs)+len(recorded))
+	for n := 2; n < len(docs)+2; n++ {
+		res = append(res, rm.shardARN(ko, n))
+	}
+	for _, arn := range recorded {
+		if arn != nil && !ackutil.InStrings(*arn, res) {
+			res = append(res, *arn)
+		}
+	}
+	return res
+}
+
+// syncPolicyShards makes the shards of the supplied Policy hold the
+// documents split from the declared Policy beyond the first one, deletes the
+// shards recorded in the latest Policy that are no longer needed, and records
+// the ARNs of the others in Status.ShardARNs of the supplied Policy.
+//
+// Shards are created with a tag naming the Policy as their owner. A managed
+// policy of the same name lacking that tag is never modified or deleted.
+func (rm *resourceManager) syncPolicyShards(
+	ctx context.Context,
+	desired *resource,
+	latest *resource,
+	ko *svcapitypes.Policy,
+) (err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.syncPolicyShards")
+	defer func() { exit(err) }()
+
+	docs, err := rm.shardDocuments(desired, latest.ko)
+	if err != nil {
+		return err
+	}
+	shardARNs := []*string{}
+	for _, arn := range rm.policyShardARNs(latest.ko, docs, latest.ko.Status.ShardARNs) {
+		doc, needed := docs[arn]
+		if !needed {
+			if err = rm.deletePolicyShard(ctx, latest, arn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = rm.syncPolicyShard(ctx, latest.ko, arn, doc); err != nil {
+			return err
+		}
+		shardARNs = append(shardARNs, aws.String(arn))
+	}
+	if len(shardARNs) == 0 {
+		shardARNs = nil
+	}
+	ko.Status.ShardARNs = shardARNs
+	return nil
+}
+
+// syncPolicyShard creates the shard of the supplied Policy with the supplied
+// ARN and document, or sets its document if it exists and differs.
+func (rm *resourceManager) syncPolicyShard(
+	ctx context.Context,
+	ko *svcapitypes.Policy,
+	arn string,
+	doc string,
+) (err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.syncPolicyShard")
+	defer func() { exit(err) }()
+
+	shard, err := rm.getPolicyShard(ctx, ko, arn)
+	if err != nil {
+		return err
+	}
+	if shard == nil {
+		name := arn[strings.LastIndex(arn, "/")+1:]
+		input := &svcsdk.CreatePolicyInput{
+			PolicyName:     aws.String(name),
+			Path:           ko.Spec.Path,
+			PolicyDocument: &doc,
+			Description:    aws.String(fmt.Sprintf("Part of policy %s", *ko.Spec.Name)),
+			Tags: []svcsdktypes.Tag{{
+				Key:   aws.String(policyShardOwnerTagKey),
+				Value: aws.String(string(*ko.Status.ACKResourceMetadata.ARN)),
+			}},
+		}
+		for _, t := range ko.Spec.Tags {
+			if aws.ToString(t.Key) != policyShardOwnerTagKey {
+				input.Tags = append(input.Tags, svcsdktypes.Tag{Key: t.Key, Value: t.Value})
+			}
+		}
+		_, err = rm.sdkapi.CreatePolicy(ctx, input)
+		rm.metrics.RecordAPICall("CREATE", "CreatePolicy", err)
+		if err != nil {
+			return err
+		}
+		recordPolicyEvent(ctx, ko, "ShardCreated", "Created policy shard %s", arn)
+		return nil
+	}
+	if !shard.owned {
+		return ackerr.NewTerminalError(fmt.Errorf(
+			"policy %s already exists and is not a shard of this policy; "+
+				"rename the policy or delete the existing one", arn,
+		))
+	}
+	if equal, err := ackcompare.IAMPolicyDocumentEqual(shard.document, doc); err == nil && equal {
+		return nil
+	}
+	if err = rm.ensureVersionsLimitNotExceeded(ctx, arn); err != nil {
+		return err
+	}
+	_, err = rm.sdkapi.CreatePolicyVersion(ctx, &svcsdk.CreatePolicyVersionInput{
+		PolicyArn:      &arn,
+		PolicyDocument: &doc,
+		SetAsDefault:   true,
+	})
+	rm.metrics.RecordAPICall("UPDATE", "CreatePolicyVersion", err)
+	return err
+}
+
+// deletePolicyShards deletes the shards recorded in Status.ShardARNs of the
+// supplied Policy.
+func (rm *resourceManager) deletePolicyShards(
+	ctx context.Context,
+	r *resource,
+) (err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.deletePolicyShards")
+	defer func() { exit(err) }()
+
+	for _, arn := range r.ko.Status.ShardARNs {
+		if arn == nil {
+			continue
+		}
+		if err = rm.deletePolicyShard(ctx, r, *arn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deletePolicyShard detaches the shard with the supplied ARN from every
+// entity and deletes it, unless it does not exist or is not owned by the
+// supplied Policy.
+func (rm *resourceManager) deletePolicyShard(
+	ctx context.Context,
+	r *resource,
+	arn string,
+) (err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.deletePolicyShard")
+	defer func() { exit(err) }()
+
+	shard, err := rm.getPolicyShard(ctx, r.ko, arn)
+	if err != nil || shard == nil {
+		return err
+	}
+	if !shard.owned {
+		rlog.Info("not deleting policy that is not a shard of this policy", "arn", arn)
+		return nil
+	}
+	if err = rm.detachFromAllEntities(ctx, r, arn); err != nil {
+		return err
+	}
+	if err = rm.deleteNonDefaultPolicyVersions(ctx, arn); err != nil {
+		return err
+	}
+	_, err = rm.sdkapi.DeletePolicy(ctx, &svcsdk.DeletePolicyInput{PolicyArn: &arn})
+	rm.metrics.RecordAPICall("DELETE", "DeletePolicy", err)
+	if err != nil && !isNoSuchEntity(err) {
+		return err
+	}
+	recordPolicyEvent(ctx, r.ko, "ShardDeleted", "Deleted policy shard %s", arn)
+	return nil
+}
+
+// recordPolicyEvent records an Event of the supplied reason on the supplied
+// Policy, logging instead when no event recorder is available.
+func recordPolicyEvent(
+	ctx context.Context,
+	ko *svcapitypes.Policy,
+	reason string,
+	format string,
+	args ...interface{},
+) {
+	recorder, err := commonutil.EventRecorder()
+	if err != nil {
+		ackrtlog.FromContext(ctx).Info("unable to record event", "reason", reason, "error", err.Error())
+		return
+	}
+	recorder.Eventf(ko, corev1.EventTypeNormal, reason, format, args...)
+}
+
+// isNoSuchEntity returns true if the supplied error is an IAM NoSuchEntity
+// error.
+func isNoSuchEntity(err error) bool {
+	var awsErr smithy.APIError
+	return err != nil && errors.As(err, &awsErr) && awsErr.ErrorCode() == "NoSuchEntity"
+}
@@ -0,0 +1,198 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	ackrequeue "github.com/aws-controllers-k8s/runtime/pkg/requeue"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+// oversizedPolicyDocument returns a policy document of the supplied number of
+// statements that cannot be merged.
+func oversizedPolicyDocument(statements int) *string {
+	res := []string{}
+	for i := 0; i < statements; i++ {
+		res = append(res, fmt.Sprintf(
+			`{"Effect": "Allow", "Action": "s3:GetObject%d", "Resource": "arn:${ack:partition}:s3:::bucket-%d/*"}`, i, i,
+		))
+	}
+	return aws.String(`{"Version": "2012-10-17", "Statement": [` + strings.Join(res, ", ") + `]}`)
+}
+
+// reconcile reads the supplied desired Policy and updates it if it differs
+// from the latest one, as the reconciler would do, and returns the Policy
+// with the resulting status.
+func reconcile(t *testing.T, rm *resourceManager, desired *resource) (*resource, error) {
+	ctx := context.TODO()
+	res, err := rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest := rm.concreteResource(res)
+	delta := newResourceDelta(desired, latest)
+	if !delta.DifferentAt("Spec") {
+		return latest, nil
+	}
+	res, err = rm.Update(ctx, desired, latest, delta)
+	return rm.concreteResource(res), err
+}
+
+func TestShardARN(t *testing.T) {
+	rm := &resourceManager{awsAccountID: "123456789012", awsPartition: "aws-cn"}
+	ko := policyWithRefs(nil)
+	assert.Equal(t, "arn:aws-cn:iam::123456789012:policy/reader-part2", rm.shardARN(ko, 2))
+
+	ko.Spec.Path = aws.String("/team-a/")
+	assert.Equal(t, "arn:aws-cn:iam::123456789012:policy/team-a/reader-part3", rm.shardARN(ko, 3))
+}
+
+func TestPolicyDocuments(t *testing.T) {
+	rm := &resourceManager{awsAccountID: "123456789012", awsPartition: "aws"}
+	statements := []string{}
+	for i := 0; i < 200; i++ {
+		statements = append(statements, fmt.Sprintf(
+			`{"Effect": "Allow", "Action": "s3:GetObject%d", "Resource": "arn:${ack:partition}:s3:::bucket-%d/*"}`, i, i,
+		))
+	}
+	ko := policyWithRefs(nil)
+	ko.Spec.PolicyDocument = aws.String(`{"Version": "2012-10-17", "Statement": [` + strings.Join(statements, ", ") + `]}`)
+
+	// Without splitOversizedDocument the rendered document is used as is.
+	docs, err := rm.policyDocuments(&resource{ko})
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Contains(t, docs[0], `"Effect": "Allow"`)
+	assert.NotContains(t, docs[0], "${ack:")
+
+	ko.Spec.SplitOversizedDocument = aws.Bool(true)
+	docs, err = rm.policyDocuments(&resource{ko})
+	require.NoError(t, err)
+	assert.Greater(t, len(docs), 1)
+	for _, doc := range docs {
+		assert.LessOrEqual(t, commonutil.PolicyDocumentSize(doc), commonutil.ManagedPolicyMaxSize)
+	}
+	primary, err := rm.primaryPolicyDocument(&resource{ko})
+	require.NoError(t, err)
+	assert.Equal(t, docs[0], *primary)
+}
+
+func TestResourceManager_Shards(t *testing.T) {
+	ctx := context.TODO()
+	commonutil.SetEventRecorder(record.NewFakeRecorder(100))
+	rm := newTestResourceManager(t)
+	shardTags := func(arn string) map[string]string {
+		out, err := rm.sdkapi.GetPolicy(ctx, &svcsdk.GetPolicyInput{PolicyArn: &arn})
+		require.NoError(t, err)
+		tags := map[string]string{}
+		for _, tag := range out.Policy.Tags {
+			tags[*tag.Key] = *tag.Value
+		}
+		return tags
+	}
+
+	desired := &resource{&svcapitypes.Policy{Spec: svcapitypes.PolicySpec{
+		Name:                   aws.String("large"),
+		Path:                   aws.String("/"),
+		PolicyDocument:         oversizedPolicyDocument(200),
+		SplitOversizedDocument: aws.Bool(true),
+		Tags:                   []*svcapitypes.Tag{{Key: aws.String("team"), Value: aws.String("iam")}},
+	}}}
+	docs, err := rm.policyDocuments(desired)
+	require.NoError(t, err)
+	require.Greater(t, len(docs), 1)
+
+	// The shards are created on update, once the Policy has an ARN.
+	res, err := rm.Create(ctx, desired)
+	var requeueErr *ackrequeue.RequeueNeeded
+	require.ErrorAs(t, err, &requeueErr)
+	desired.ko.Status = rm.concreteResource(res).ko.Status
+	arn := string(*desired.ko.Status.ACKResourceMetadata.ARN)
+	_, err = rm.sdkapi.GetPolicy(ctx, &svcsdk.GetPolicyInput{PolicyArn: aws.String(rm.shardARN(desired.ko, 2))})
+	assert.ErrorContains(t, err, "NoSuchEntity")
+
+	latest, err := reconcile(t, rm, desired)
+	require.NoError(t, err)
+	require.Len(t, latest.ko.Status.ShardARNs, len(docs)-1)
+	for i, shardARN := range latest.ko.Status.ShardARNs {
+		assert.Equal(t, rm.shardARN(desired.ko, i+2), *shardARN)
+		assert.Equal(t, map[string]string{policyShardOwnerTagKey: arn, "team": "iam"}, shardTags(*shardARN))
+	}
+	// The Policy's own document was left as is.
+	assert.Equal(t, "v1", *latest.ko.Status.DefaultVersionID)
+
+	// Once in sync, the shards are only read.
+	desired.ko.Status = latest.ko.Status
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	assert.False(t, newResourceDelta(desired, rm.concreteResource(res)).DifferentAt("Spec"))
+	assert.Equal(t, latest.ko.Status.ShardARNs, rm.concreteResource(res).ko.Status.ShardARNs)
+
+	// Shards no longer needed are deleted.
+	desired.ko.Spec.PolicyDocument = policyDocument("s3:GetObject")
+	latest, err = reconcile(t, rm, desired)
+	require.NoError(t, err)
+	assert.Empty(t, latest.ko.Status.ShardARNs)
+	_, err = rm.sdkapi.GetPolicy(ctx, &svcsdk.GetPolicyInput{PolicyArn: aws.String(rm.shardARN(desired.ko, 2))})
+	assert.ErrorContains(t, err, "NoSuchEntity")
+}
+
+func TestResourceManager_ShardsNotOwned(t *testing.T) {
+	ctx := context.TODO()
+	commonutil.SetEventRecorder(record.NewFakeRecorder(100))
+	rm := newTestResourceManager(t)
+
+	// A policy named like a shard of the Policy, which the controller did not
+	// create.
+	existing, err := rm.sdkapi.CreatePolicy(ctx, &svcsdk.CreatePolicyInput{
+		PolicyName:     aws.String("large-part2"),
+		PolicyDocument: policyDocument("s3:PutObject"),
+	})
+	require.NoError(t, err)
+
+	desired := &resource{&svcapitypes.Policy{Spec: svcapitypes.PolicySpec{
+		Name:                   aws.String("large"),
+		PolicyDocument:         oversizedPolicyDocument(200),
+		SplitOversizedDocument: aws.Bool(true),
+	}}}
+	res, err := rm.Create(ctx, desired)
+	var requeueErr *ackrequeue.RequeueNeeded
+	require.ErrorAs(t, err, &requeueErr)
+	desired.ko.Status = rm.concreteResource(res).ko.Status
+
+	latest, err := reconcile(t, rm, desired)
+	require.Error(t, err)
+	require.NotNil(t, ackcondition.Terminal(latest))
+	assert.Contains(t, *ackcondition.Terminal(latest).Message, "is not a shard of this policy")
+
+	// Deleting the Policy leaves the policy it does not own in place.
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	assert.Empty(t, rm.concreteResource(res).ko.Status.ShardARNs)
+	_, err = rm.Delete(ctx, rm.concreteResource(res))
+	require.NoError(t, err)
+	pv, err := rm.getPolicyVersion(ctx, *existing.Policy.Arn, "v1")
+	require.NoError(t, err)
+	assert.JSONEq(t, *policyDocument("s3:PutObject"), pv.document)
+}
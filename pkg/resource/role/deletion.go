@@ -0,0 +1,242 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package role
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	ackrequeue "github.com/aws-controllers-k8s/runtime/pkg/requeue"
+	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
+	ackutil "github.com/aws-controllers-k8s/runtime/pkg/util"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	corev1 "k8s.io/api/core/v1"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+const (
+	// deletionBlockedReason is the reason of the ACK.Advisory condition
+	// listing the policies that block the deletion of a Role.
+	deletionBlockedReason = "UndeclaredPoliciesAttached"
+	// deletionBlockedRequeueDelay is how long to wait before checking again
+	// whether the policies blocking the deletion of a Role were detached.
+	deletionBlockedRequeueDelay = 30 * time.Second
+)
+
+// cleanupBeforeDeletion removes everything that makes DeleteRole fail with
+// DeleteConflict: the Role's instance profile memberships, its permissions
+// boundary and its managed and inline policies. The instance profile managed
+// through Spec.InstanceProfile, recorded in Status.InstanceProfileARN, is
+// deleted as well.
+//
+// The supplied resource holds the state observed in AWS. Policies attached
+// out of band have already been checked against the declared ones by
+// checkDeletionPolicies when the Role was read, right before its deletion,
+// and deletionBlocked refuses to go on while they are attached.
+//
+// Every failing step is reported in an error naming it, so that the Role's
+// conditions say what blocks the deletion.
+func (rm *resourceManager) cleanupBeforeDeletion(
+	ctx context.Context,
+	r *resource,
+) (err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.cleanupBeforeDeletion")
+	defer func() { exit(err) }()
+
+	if err = deletionBlocked(r); err != nil {
+		return err
+	}
+	if err = rm.removeFromInstanceProfiles(ctx, r); err != nil {
+		return err
+	}
+	if err = rm.deleteInstanceProfile(ctx, r); err != nil {
+		return err
+	}
+	if r.ko.Spec.PermissionsBoundary != nil && *r.ko.Spec.PermissionsBoundary != "" {
+		if err = rm.deleteRolePermissionsBoundary(ctx, r); err != nil {
+			return fmt.Errorf("removing permissions boundary %s: %w", *r.ko.Spec.PermissionsBoundary, err)
+		}
+		recordRoleEvent(ctx, r, "PermissionsBoundaryRemoved",
+			"Removed permissions boundary %s", *r.ko.Spec.PermissionsBoundary)
+	}
+
+	roleCpy := r.ko.DeepCopy()
+	roleCpy.Spec.Policies = nil
+	roleCpy.Spec.AWSManagedPolicies = nil
+	if err = rm.syncManagedPolicies(ctx, &resource{ko: roleCpy}, r); err != nil {
+		return fmt.Errorf("detaching managed policies: %w", err)
+	}
+	for _, p := range r.ko.Spec.Policies {
+		recordRoleEvent(ctx, r, "PolicyDetached", "Detached policy %s", *p)
+	}
+	roleCpy.Spec.InlinePolicies = map[string]*string{}
+	if err = rm.syncInlinePolicies(ctx, &resource{ko: roleCpy}, r); err != nil {
+		return fmt.Errorf("deleting inline policies: %w", err)
+	}
+	inline := make([]string, 0, len(r.ko.Spec.InlinePolicies))
+	for name := range r.ko.Spec.InlinePolicies {
+		inline = append(inline, name)
+	}
+	sort.Strings(inline)
+	for _, name := range inline {
+		recordRoleEvent(ctx, r, "InlinePolicyDeleted", "Deleted inline policy %s", name)
+	}
+	return nil
+}
+
+// checkDeletionPolicies returns an error listing the policies attached to
+// the observed Role but not declared in its spec when the Role is being
+// deleted and does not set Spec.ForceDetachPolicies. It is called when the
+// Role is read, since the runtime reads a Role right before deleting it and
+// only hands the observed state to Delete.
+//
+// When some policy references of the declared Role cannot be resolved,
+// typically because the referenced Policies are being deleted along with it,
+// every attached policy is considered declared.
+func checkDeletionPolicies(
+	declared *svcapitypes.Role,
+	observed *svcapitypes.Role,
+) error {
+	if observed.DeletionTimestamp == nil ||
+		(observed.Spec.ForceDetachPolicies != nil && *observed.Spec.ForceDetachPolicies) {
+		return nil
+	}
+	if len(declared.Spec.Policies) < len(declared.Spec.PolicyRefs) {
+		return nil
+	}
+	policies, inline := unownedRolePolicies(declared, observed)
+	if len(policies) == 0 && len(inline) == 0 {
+		return nil
+	}
+	return unownedPoliciesError(policies, inline)
+}
+
+// setDeletionBlocked reports the error of checkDeletionPolicies in an
+// ACK.Advisory condition of the observed Role, or clears it when err is nil.
+func setDeletionBlocked(observed *svcapitypes.Role, err error) {
+	commonutil.SetAdvisoryFromError(&resource{observed}, deletionBlockedReason, err)
+}
+
+// deletionBlocked returns an error requeueing the deletion of the supplied
+// Role while the ACK.Advisory condition set by setDeletionBlocked lists
+// policies blocking it. Delete also copies it to the ACK.Recoverable
+// condition.
+func deletionBlocked(r *resource) error {
+	c := ackcondition.AdvisoryWithReason(r, deletionBlockedReason)
+	if c == nil || c.Status != corev1.ConditionTrue {
+		return nil
+	}
+	return ackrequeue.NeededAfter(errors.New(aws.ToString(c.Message)), deletionBlockedRequeueDelay)
+}
+
+// unownedRolePolicies returns the managed policy ARNs and inline policy names
+// attached to the observed Role but not declared in its spec.
+func unownedRolePolicies(
+	declared *svcapitypes.Role,
+	observed *svcapitypes.Role,
+) (policies []string, inline []string) {
+	for _, p := range observed.Spec.Policies {
+		if p != nil && !ackutil.InStringPs(*p, declared.Spec.Policies) {
+			policies = append(policies, *p)
+		}
+	}
+	for name := range observed.Spec.InlinePolicies {
+		if _, ok := declared.Spec.InlinePolicies[name]; !ok {
+			inline = append(inline, name)
+		}
+	}
+	sort.Strings(inline)
+	return policies, inline
+}
+
+// unownedPoliciesError describes the policies blocking the deletion of a Role
+// that does not set Spec.ForceDetachPolicies.
+func unownedPoliciesError(policies []string, inline []string) error {
+	parts := []string{}
+	if len(policies) > 0 {
+		parts = append(parts, fmt.Sprintf("managed policies [%s]", strings.Join(policies, ", ")))
+	}
+	if len(inline) > 0 {
+		parts = append(parts, fmt.Sprintf("inline policies [%s]", strings.Join(inline, ", ")))
+	}
+	return fmt.Errorf(
+		"role has %s attached that are not declared in its spec; "+
+			"detach them or set forceDetachPolicies to true",
+		strings.Join(parts, " and "),
+	)
+}
+
+// removeFromInstanceProfiles calls the ListInstanceProfilesForRole API and
+// removes the supplied Role from every instance profile it belongs to.
+func (rm *resourceManager) removeFromInstanceProfiles(
+	ctx context.Context,
+	r *resource,
+) (err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.removeFromInstanceProfiles")
+	defer func() { exit(err) }()
+
+	input := &svcsdk.ListInstanceProfilesForRoleInput{RoleName: r.ko.Spec.Name}
+	profiles := []string{}
+	paginator := svcsdk.NewListInstanceProfilesForRolePaginator(rm.sdkapi, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		rm.metrics.RecordAPICall("READ_MANY", "ListInstanceProfilesForRole", err)
+		if err != nil {
+			return fmt.Errorf("listing instance profiles: %w", err)
+		}
+		for _, p := range page.InstanceProfiles {
+			profiles = append(profiles, *p.InstanceProfileName)
+		}
+	}
+	for _, name := range profiles {
+		_, err = rm.sdkapi.RemoveRoleFromInstanceProfile(ctx, &svcsdk.RemoveRoleFromInstanceProfileInput{
+			InstanceProfileName: &name,
+			RoleName:            r.ko.Spec.Name,
+		})
+		rm.metrics.RecordAPICall("DELETE", "RemoveRoleFromInstanceProfile", err)
+		if err != nil {
+			return fmt.Errorf("removing role from instance profile %s: %w", name, err)
+		}
+		recordRoleEvent(ctx, r, "RemovedFromInstanceProfile",
+			"Removed role from instance profile %s", name)
+	}
+	return nil
+}
+
+// recordRoleEvent records a Normal Event about the supplied Role. Failing to
+// get an event recorder is logged and otherwise ignored.
+func recordRoleEvent(
+	ctx context.Context,
+	r *resource,
+	reason string,
+	format string,
+	args ...interface{},
+) {
+	recorder, err := commonutil.EventRecorder()
+	if err != nil {
+		ackrtlog.FromContext(ctx).Info("unable to record event", "reason", reason, "error", err.Error())
+		return
+	}
+	recorder.Eventf(r.ko, corev1.EventTypeNormal, reason, format, args...)
+}
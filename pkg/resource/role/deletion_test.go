@@ -0,0 +1,157 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package role
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackrequeue "github.com/aws-controllers-k8s/runtime/pkg/requeue"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+func roleWithPolicies(policies []string, inline ...string) *svcapitypes.Role {
+	ko := &svcapitypes.Role{}
+	ko.Spec.Policies = aws.StringSlice(policies)
+	ko.Spec.InlinePolicies = map[string]*string{}
+	for _, name := range inline {
+		ko.Spec.InlinePolicies[name] = aws.String("{}")
+	}
+	return ko
+}
+
+func TestUnownedRolePolicies(t *testing.T) {
+	declared := roleWithPolicies([]string{"arn:aws:iam::aws:policy/A"}, "declared")
+	observed := roleWithPolicies(
+		[]string{"arn:aws:iam::aws:policy/A", "arn:aws:iam::aws:policy/B"},
+		"oob2", "declared", "oob1",
+	)
+
+	policies, inline := unownedRolePolicies(declared, observed)
+	assert.Equal(t, []string{"arn:aws:iam::aws:policy/B"}, policies)
+	assert.Equal(t, []string{"oob1", "oob2"}, inline)
+}
+
+func TestCheckDeletionPolicies(t *testing.T) {
+	declared := roleWithPolicies([]string{"arn:aws:iam::aws:policy/A"})
+	observed := roleWithPolicies([]string{"arn:aws:iam::aws:policy/A", "arn:aws:iam::aws:policy/B"})
+
+	// Policies attached out of band only matter when deleting.
+	assert.NoError(t, checkDeletionPolicies(declared, observed))
+
+	observed.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	assert.ErrorContains(t, checkDeletionPolicies(declared, observed), "arn:aws:iam::aws:policy/B")
+
+	// Unresolved references make every policy count as declared.
+	unresolved := roleWithPolicies(nil)
+	unresolved.Spec.PolicyRefs = []*ackv1alpha1.AWSResourceReferenceWrapper{
+		{From: &ackv1alpha1.AWSResourceReference{Name: aws.String("deleted-policy")}},
+	}
+	assert.NoError(t, checkDeletionPolicies(unresolved, observed))
+
+	observed.Spec.ForceDetachPolicies = aws.Bool(true)
+	assert.NoError(t, checkDeletionPolicies(declared, observed))
+}
+
+func TestResourceManager_DeleteWithUnownedPolicies(t *testing.T) {
+	ctx := context.TODO()
+	rm := newTestResourceManager(t)
+	recorder := record.NewFakeRecorder(10)
+	commonutil.SetEventRecorder(recorder)
+
+	desired := &resource{ko: &svcapitypes.Role{
+		Spec: svcapitypes.RoleSpec{
+			Name:                     aws.String("test-role"),
+			AssumeRolePolicyDocument: aws.String(testTrustPolicy),
+			InlinePolicies:           map[string]*string{"declared": aws.String(testInlinePolicy)},
+		},
+	}}
+	res, err := rm.Create(ctx, desired)
+	var requeueErr *ackrequeue.RequeueNeeded
+	require.ErrorAs(t, err, &requeueErr)
+	desired.SetStatus(rm.concreteResource(res))
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest := rm.concreteResource(res)
+	_, err = rm.Update(ctx, desired, latest, newResourceDelta(desired, latest))
+	require.NoError(t, err)
+	_, err = rm.sdkapi.PutRolePolicy(ctx, &svcsdk.PutRolePolicyInput{
+		RoleName:       aws.String("test-role"),
+		PolicyName:     aws.String("out-of-band"),
+		PolicyDocument: aws.String(testInlinePolicy),
+	})
+	require.NoError(t, err)
+
+	// The runtime reads the Role right before deleting it. While a policy
+	// is attached out of band, the deletion is requeued and the Role's
+	// conditions list the policy.
+	desired.ko.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	res, err = rm.Delete(ctx, res)
+	var requeueAfterErr *ackrequeue.RequeueNeededAfter
+	require.ErrorAs(t, err, &requeueAfterErr)
+	assert.Equal(t, deletionBlockedRequeueDelay, requeueAfterErr.Duration())
+	require.NotNil(t, res)
+	cond := ackcondition.AdvisoryWithReason(res, deletionBlockedReason)
+	require.NotNil(t, cond)
+	assert.Contains(t, aws.ToString(cond.Message), "inline policies [out-of-band]")
+	cond = ackcondition.Recoverable(res)
+	require.NotNil(t, cond)
+	assert.Equal(t, corev1.ConditionTrue, cond.Status)
+	assert.Contains(t, aws.ToString(cond.Message), "inline policies [out-of-band]")
+	_, err = rm.sdkapi.GetRole(ctx, &svcsdk.GetRoleInput{RoleName: aws.String("test-role")})
+	require.NoError(t, err)
+
+	desired.ko.Spec.ForceDetachPolicies = aws.Bool(true)
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	assert.Nil(t, ackcondition.AdvisoryWithReason(res, deletionBlockedReason))
+	_, err = rm.Delete(ctx, res)
+	require.NoError(t, err)
+	_, err = rm.ReadOne(ctx, desired)
+	assert.Equal(t, ackerr.NotFound, err)
+
+	events := []string{}
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	assert.Equal(t, []string{
+		"Normal InlinePolicyDeleted Deleted inline policy declared",
+		"Normal InlinePolicyDeleted Deleted inline policy out-of-band",
+	}, events)
+}
+
+func TestUnownedPoliciesError(t *testing.T) {
+	err := unownedPoliciesError([]string{"arn:aws:iam::aws:policy/B"}, []string{"oob"})
+	assert.EqualError(t, err,
+		"role has managed policies [arn:aws:iam::aws:policy/B] and inline policies [oob] "+
+			"attached that are not declared in its spec; detach them or set forceDetachPolicies to true")
+
+	err = unownedPoliciesError(nil, []string{"oob"})
+	assert.ErrorContains(t, err, "role has inline policies [oob] attached")
+}
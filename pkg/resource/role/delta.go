@@ -43,11 +43,11 @@ func newResourceDelta(
 	}
 	customPreCompare(delta, a, b)
 
-	if ackcompare.HasNilDifference(a.ko.Spec.AssumeRolePolicyDocument, b.ko.Spec.AssumeRolePolicyDocument) {
-		delta.Add("Spec.AssumeRolePolicyDocument", a.ko.Spec.AssumeRolePolicyDocument, b.ko.Spec.AssumeRolePolicyDocument)
-	} else if a.ko.Spec.AssumeRolePolicyDocument != nil && b.ko.Spec.AssumeRolePolicyDocument != nil {
-		if equal, err := ackcompare.IAMPolicyDocumentEqual(*a.ko.Spec.AssumeRolePolicyDocument, *b.ko.Spec.AssumeRolePolicyDocument); err != nil || !equal {
-			delta.Add("Spec.AssumeRolePolicyDocument", a.ko.Spec.AssumeRolePolicyDocument, b.ko.Spec.AssumeRolePolicyDocument)
+	if len(a.ko.Spec.AWSManagedPolicies) != len(b.ko.Spec.AWSManagedPolicies) {
+		delta.Add("Spec.AWSManagedPolicies", a.ko.Spec.AWSManagedPolicies, b.ko.Spec.AWSManagedPolicies)
+	} else if len(a.ko.Spec.AWSManagedPolicies) > 0 {
+		if !ackcompare.SliceStringPEqual(a.ko.Spec.AWSManagedPolicies, b.ko.Spec.AWSManagedPolicies) {
+			delta.Add("Spec.AWSManagedPolicies", a.ko.Spec.AWSManagedPolicies, b.ko.Spec.AWSManagedPolicies)
 		}
 	}
 	if ackcompare.HasNilDifference(a.ko.Spec.Description, b.ko.Spec.Description) {
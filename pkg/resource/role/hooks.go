@@ -15,13 +15,14 @@ package role
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 
 	ackcompare "github.com/aws-controllers-k8s/runtime/pkg/compare"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
 	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
 	ackutil "github.com/aws-controllers-k8s/runtime/pkg/util"
 	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
-	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/samber/lo"
 
 	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
@@ -92,16 +93,23 @@ func (rm *resourceManager) syncManagedPolicies(
 	toAdd := []*string{}
 	toDelete := []*string{}
 
-	existingPolicies := latest.ko.Spec.Policies
+	desiredPolicies, err := rm.managedPolicyARNs(ctx, desired.ko)
+	if err != nil {
+		return err
+	}
+	existingPolicies, err := rm.managedPolicyARNs(ctx, latest.ko)
+	if err != nil {
+		return err
+	}
 
-	for _, p := range desired.ko.Spec.Policies {
+	for _, p := range desiredPolicies {
 		if !ackutil.InStringPs(*p, existingPolicies) {
 			toAdd = append(toAdd, p)
 		}
 	}
 
 	for _, p := range existingPolicies {
-		if !ackutil.InStringPs(*p, desired.ko.Spec.Policies) {
+		if !ackutil.InStringPs(*p, desiredPolicies) {
 			toDelete = append(toDelete, p)
 		}
 	}
@@ -203,6 +211,10 @@ func (rm *resourceManager) syncInlinePolicies(
 	exit := rlog.Trace("rm.syncInlinePolicies")
 	defer func() { exit(err) }()
 
+	if err = rm.checkInlinePoliciesSize(desired); err != nil {
+		return err
+	}
+
 	existingPolicies := latest.ko.Spec.InlinePolicies
 
 	existingPairs := lo.ToPairs(
@@ -302,6 +314,101 @@ func (rm *resourceManager) getInlinePolicies(
 	return res, nil
 }
 
+// policyVariables returns the values of the ${ack:...} variables of the
+// inline policy documents of the supplied Role.
+func (rm *resourceManager) policyVariables(r *resource) commonutil.PolicyVariables {
+	return commonutil.NewPolicyVariables(
+		string(rm.awsAccountID), string(rm.awsPartition), string(rm.awsRegion), r.ko,
+	)
+}
+
+// compactInlinePolicies returns true if the inline policy documents of the
+// supplied Role are to be minified and their statements merged.
+func compactInlinePolicies(r *resource) bool {
+	return r.ko.Spec.CompactInlinePolicies != nil && *r.ko.Spec.CompactInlinePolicies
+}
+
+// renderedInlinePolicy returns the supplied inline policy document of the
+// Role as it is put to IAM: with its ${ack:...} variables substituted and,
+// with Spec.CompactInlinePolicies, compacted.
+func (rm *resourceManager) renderedInlinePolicy(r *resource, doc string) (string, error) {
+	doc, err := commonutil.RenderPolicyDocument(doc, rm.policyVariables(r))
+	if err != nil || !compactInlinePolicies(r) {
+		return doc, err
+	}
+	return commonutil.CompactPolicyDocument(doc)
+}
+
+// renderedInlinePolicies returns the inline policy documents of the supplied
+// Role as they are put to IAM.
+func (rm *resourceManager) renderedInlinePolicies(r *resource) (map[string]*string, error) {
+	if r.ko.Spec.InlinePolicies == nil {
+		return nil, nil
+	}
+	res := make(map[string]*string, len(r.ko.Spec.InlinePolicies))
+	for name, doc := range r.ko.Spec.InlinePolicies {
+		if doc == nil {
+			res[name] = nil
+			continue
+		}
+		rendered, err := rm.renderedInlinePolicy(r, *doc)
+		if err != nil {
+			return nil, fmt.Errorf("inline policy %q: %w", name, err)
+		}
+		res[name] = &rendered
+	}
+	return res, nil
+}
+
+// checkInlinePoliciesSize returns a terminal error if, with
+// Spec.CompactInlinePolicies, the compacted inline policy documents of the
+// supplied Role still exceed IAM's aggregate size limit, rather than letting
+// PutRolePolicy fail with LimitExceeded part way through.
+func (rm *resourceManager) checkInlinePoliciesSize(r *resource) error {
+	if !compactInlinePolicies(r) {
+		return nil
+	}
+	rendered, err := rm.renderedInlinePolicies(r)
+	if err != nil {
+		return ackerr.NewTerminalError(err)
+	}
+	size := 0
+	for _, doc := range rendered {
+		if doc != nil {
+			size += commonutil.PolicyDocumentSize(*doc)
+		}
+	}
+	if size > commonutil.RoleInlinePoliciesMaxSize {
+		return ackerr.NewTerminalError(fmt.Errorf(
+			"inline policies total %d characters once compacted, over the limit of %d; "+
+				"move statements to a Policy with splitOversizedDocument set",
+			size, commonutil.RoleInlinePoliciesMaxSize,
+		))
+	}
+	return nil
+}
+
+// setRenderedInlinePolicies records the inline policy documents rendered from
+// the declared Role in Status.RenderedInlinePolicies and, for every inline
+// policy read from IAM that matches its rendering, keeps the declared
+// document in the Spec so that its variables are not reported as a
+// difference.
+func (rm *resourceManager) setRenderedInlinePolicies(
+	declared *resource,
+	ko *svcapitypes.Role,
+) {
+	rendered, err := rm.renderedInlinePolicies(declared)
+	if err != nil {
+		// The error is reported when the inline policies are synced.
+		ko.Status.RenderedInlinePolicies = nil
+		return
+	}
+	ko.Status.RenderedInlinePolicies = rendered
+	ko.Spec.InlinePolicies = commonutil.DeclaredPolicyDocuments(
+		declared.ko.Spec.InlinePolicies, rendered, ko.Spec.InlinePolicies,
+	)
+}
+
 // addInlinePolicy adds the supplied inline Policy to the supplied Role
 // resource
 func (rm *resourceManager) addInlinePolicy(
@@ -321,6 +428,9 @@ func (rm *resourceManager) addInlinePolicy(
 	if err != nil {
 		return err
 	}
+	if cleanedDoc, err = rm.renderedInlinePolicy(r, cleanedDoc); err != nil {
+		return ackerr.NewTerminalError(fmt.Errorf("inline policy %q: %w", policyName, err))
+	}
 	input.PolicyDocument = &cleanedDoc
 	_, err = rm.sdkapi.PutRolePolicy(ctx, input)
 	rm.metrics.RecordAPICall("UPDATE", "PutRolePolicy", err)
@@ -347,7 +457,8 @@ func (rm *resourceManager) removeInlinePolicy(
 }
 
 // putAssumeRolePolicies calls the IAM API to set a given role's
-// assume role policy document.
+// assume role policy document, including the statements rendered from
+// Spec.ServiceAccountTrust and Spec.TrustPresets.
 func (rm *resourceManager) putAssumeRolePolicy(
 	ctx context.Context,
 	r *resource,
@@ -356,9 +467,14 @@ func (rm *resourceManager) putAssumeRolePolicy(
 	exit := rlog.Trace("rm.putAssumeRolePolicy")
 	defer func() { exit(err) }()
 
+	doc, err := renderedAssumeRolePolicyDocument(r, string(rm.awsPartition))
+	if err != nil {
+		return err
+	}
+
 	input := &svcsdk.UpdateAssumeRolePolicyInput{
 		RoleName:       r.ko.Spec.Name,
-		PolicyDocument: r.ko.Spec.AssumeRolePolicyDocument,
+		PolicyDocument: doc,
 	}
 	_, err = rm.sdkapi.UpdateAssumeRolePolicy(ctx, input)
 	rm.metrics.RecordAPICall("UPDATE", "UpdateAssumeRolePolicy", err)
@@ -373,6 +489,9 @@ func customPreCompare(
 	b *resource,
 ) {
 	compareTags(delta, a, b)
+	compareAssumeRolePolicyDocument(delta, a, b)
+	compareServiceAccountBindings(delta, a, b)
+	compareInstanceProfile(delta, a, b)
 }
 
 // compareTags is a custom comparison function for comparing lists of Tag
@@ -391,9 +510,8 @@ func compareTags(
 	}
 }
 
-// syncTags examines the Tags in the supplied Role and calls the ListRoleTags,
-// TagRole and UntagRole APIs to ensure that the set of associated Tags  stays
-// in sync with the Role.Spec.Tags
+// syncTags calls the TagRole and UntagRole APIs to ensure that the set of
+// Tags associated with the Role stays in sync with the Role.Spec.Tags
 func (rm *resourceManager) syncTags(
 	ctx context.Context,
 	desired *resource,
@@ -402,59 +520,11 @@ func (rm *resourceManager) syncTags(
 	rlog := ackrtlog.FromContext(ctx)
 	exit := rlog.Trace("rm.syncTags")
 	defer func() { exit(err) }()
-	toAdd := []*svcapitypes.Tag{}
-	toDelete := []*svcapitypes.Tag{}
-
-	existingTags := latest.ko.Spec.Tags
-
-	for _, t := range desired.ko.Spec.Tags {
-		if !inTags(*t.Key, *t.Value, existingTags) {
-			toAdd = append(toAdd, t)
-		}
-	}
-
-	for _, t := range existingTags {
-		if !inTags(*t.Key, *t.Value, desired.ko.Spec.Tags) {
-			toDelete = append(toDelete, t)
-		}
-	}
 
-	if len(toAdd) > 0 {
-		for _, t := range toAdd {
-			rlog.Debug("adding tag to role", "key", *t.Key, "value", *t.Value)
-		}
-		if err = rm.addTags(ctx, desired, toAdd); err != nil {
-			return err
-		}
-	}
-	if len(toDelete) > 0 {
-		for _, t := range toDelete {
-			rlog.Debug("removing tag from role", "key", *t.Key, "value", *t.Value)
-		}
-		if err = rm.removeTags(ctx, desired, toDelete); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-// inTags returns true if the supplied key and value can be found in the
-// supplied list of Tag structs.
-//
-// TODO(jaypipes): When we finally standardize Tag handling in ACK, move this
-// to the ACK common runtime/ or pkg/ repos
-func inTags(
-	key string,
-	value string,
-	tags []*svcapitypes.Tag,
-) bool {
-	for _, t := range tags {
-		if *t.Key == key && *t.Value == value {
-			return true
-		}
-	}
-	return false
+	return commonutil.SyncTags(
+		ctx, rm.sdkapi, rm.metrics, commonutil.ARNResourceTypeRole,
+		desired.ko.Spec.Name, desired.ko.Spec.Tags, latest.ko.Spec.Tags,
+	)
 }
 
 // getTags returns the list of tags to the Role
@@ -489,52 +559,96 @@ func (rm *resourceManager) getTags(
 	return res, err
 }
 
-// addTags adds the supplied Tags to the supplied Role resource
-func (rm *resourceManager) addTags(
-	ctx context.Context,
-	r *resource,
-	tags []*svcapitypes.Tag,
-) (err error) {
-	rlog := ackrtlog.FromContext(ctx)
-	exit := rlog.Trace("rm.addTag")
-	defer func() { exit(err) }()
+func decodeDocument(encoded string) (string, error) {
+	return url.QueryUnescape(encoded)
+}
 
-	input := &svcsdk.TagRoleInput{}
-	input.RoleName = r.ko.Spec.Name
-	inTags := []svcsdktypes.Tag{}
-	for _, t := range tags {
-		inTags = append(inTags, svcsdktypes.Tag{Key: t.Key, Value: t.Value})
+// syncServiceLastAccessed refreshes the service last accessed (Access
+// Advisor) report in the status of the supplied Role. It is best-effort:
+// failures are reported as a condition, and the report is left alone while
+// the Role is being deleted.
+func (rm *resourceManager) syncServiceLastAccessed(
+	ctx context.Context,
+	ko *svcapitypes.Role,
+) {
+	if ko.DeletionTimestamp != nil ||
+		ko.Status.ACKResourceMetadata == nil || ko.Status.ACKResourceMetadata.ARN == nil {
+		return
 	}
-	input.Tags = inTags
-
-	_, err = rm.sdkapi.TagRole(ctx, input)
-	rm.metrics.RecordAPICall("UPDATE", "TagRole", err)
-	return err
+	report, err := commonutil.SyncServiceLastAccessed(
+		ctx, rm.sdkapi, rm.metrics, string(*ko.Status.ACKResourceMetadata.ARN),
+		ko.Spec.ServiceLastAccessedGranularity, ko.Status.ServiceLastAccessed,
+	)
+	if err != nil {
+		ackrtlog.FromContext(ctx).Debug("failed to refresh service last accessed report", "error", err)
+	}
+	ko.Status.ServiceLastAccessed = report
+	commonutil.SetServiceLastAccessedConditions(&resource{ko}, report, err)
 }
 
-// removeTags removes the supplied Tags from the supplied Role resource
-func (rm *resourceManager) removeTags(
+// declarePolicyShards adds to Spec.Policies of the supplied declared Role the
+// shards of the Policies it references in Spec.PolicyRefs, so that they are
+// attached and detached along with them.
+func (rm *resourceManager) declarePolicyShards(
 	ctx context.Context,
-	r *resource,
-	tags []*svcapitypes.Tag,
-) (err error) {
-	rlog := ackrtlog.FromContext(ctx)
-	exit := rlog.Trace("rm.removeTag")
-	defer func() { exit(err) }()
-
-	input := &svcsdk.UntagRoleInput{}
-	input.RoleName = r.ko.Spec.Name
-	inTagKeys := []string{}
-	for _, t := range tags {
-		inTagKeys = append(inTagKeys, *t.Key)
+	ko *svcapitypes.Role,
+) error {
+	policies, err := commonutil.AppendPolicyShardARNs(
+		ctx, ko.Namespace, ko.Spec.PolicyRefs, ko.Spec.Policies,
+	)
+	if err != nil {
+		return err
 	}
-	input.TagKeys = inTagKeys
+	ko.Spec.Policies = policies
+	return nil
+}
 
-	_, err = rm.sdkapi.UntagRole(ctx, input)
-	rm.metrics.RecordAPICall("UPDATE", "UntagRole", err)
-	return err
+// managedPolicyARNs returns the ARNs of the managed policies the supplied
+// Role declares, both in Spec.Policies and, by name, in
+// Spec.AWSManagedPolicies.
+func (rm *resourceManager) managedPolicyARNs(
+	ctx context.Context,
+	ko *svcapitypes.Role,
+) ([]*string, error) {
+	if len(ko.Spec.AWSManagedPolicies) == 0 {
+		return ko.Spec.Policies, nil
+	}
+	arns, err := commonutil.AWSManagedPolicyARNs(
+		ctx, rm.sdkapi, rm.metrics, string(rm.awsPartition), ko.Spec.AWSManagedPolicies,
+	)
+	if err != nil {
+		return nil, err
+	}
+	res := append([]*string{}, ko.Spec.Policies...)
+	for _, arn := range arns {
+		if !ackutil.InStringPs(*arn, res) {
+			res = append(res, arn)
+		}
+	}
+	return res, nil
 }
 
-func decodeDocument(encoded string) (string, error) {
-	return url.QueryUnescape(encoded)
+// splitAWSManagedPolicies moves the policy ARNs read into Spec.Policies that
+// the Role declares by name into Spec.AWSManagedPolicies, so that both fields
+// of the latest state compare against the desired ones. When the names
+// cannot be resolved, none are moved and the error surfaces on update.
+func (rm *resourceManager) splitAWSManagedPolicies(
+	ctx context.Context,
+	ko *svcapitypes.Role,
+) {
+	names := ko.Spec.AWSManagedPolicies
+	ko.Spec.AWSManagedPolicies = nil
+	if len(names) == 0 {
+		return
+	}
+	arns, err := commonutil.AWSManagedPolicyARNs(
+		ctx, rm.sdkapi, rm.metrics, string(rm.awsPartition), names,
+	)
+	if err != nil {
+		ackrtlog.FromContext(ctx).Info("unable to resolve AWS managed policies", "error", err.Error())
+		return
+	}
+	ko.Spec.Policies, ko.Spec.AWSManagedPolicies = commonutil.SplitAWSManagedPolicies(
+		ko.Spec.Policies, names, arns,
+	)
 }
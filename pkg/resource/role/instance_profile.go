@@ -0,0 +1,340 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package role
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	ackcompare "github.com/aws-controllers-k8s/runtime/pkg/compare"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	smithy "github.com/aws/smithy-go"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+const (
+	// defaultInstanceProfilePath is the path of the instance profiles
+	// created for a Role that does not set one.
+	defaultInstanceProfilePath = "/"
+	// instanceProfileOwnerTagKey tags the instance profiles created for a
+	// Role with the name of the Role. A Role only manages, and deletes, the
+	// instance profile carrying it, so that an instance profile created out
+	// of band or by an InstanceProfile resource is never taken over.
+	instanceProfileOwnerTagKey = "iam.services.k8s.aws/owner-role"
+)
+
+// instanceProfileEnabled returns true if the supplied Role asks the controller
+// to manage an instance profile for it.
+func instanceProfileEnabled(ko *svcapitypes.Role) bool {
+	ip := ko.Spec.InstanceProfile
+	return ip != nil && ip.Create != nil && *ip.Create
+}
+
+// instanceProfileName returns the name of the instance profile managed for
+// the supplied Role, which defaults to the name of the Role.
+func instanceProfileName(ko *svcapitypes.Role) *string {
+	if ip := ko.Spec.InstanceProfile; ip != nil && ip.Name != nil && *ip.Name != "" {
+		return ip.Name
+	}
+	return ko.Spec.Name
+}
+
+// instanceProfilePath returns the path of the instance profile managed for
+// the supplied Role, which defaults to "/".
+func instanceProfilePath(ko *svcapitypes.Role) *string {
+	if ip := ko.Spec.InstanceProfile; ip != nil && ip.Path != nil && *ip.Path != "" {
+		return ip.Path
+	}
+	return aws.String(defaultInstanceProfilePath)
+}
+
+// instanceProfileTags returns the tags requested for the instance profile of
+// the supplied Role, without the ownership tag the controller sets itself.
+func instanceProfileTags(ko *svcapitypes.Role) []*svcapitypes.Tag {
+	res := []*svcapitypes.Tag{}
+	if ko.Spec.InstanceProfile == nil {
+		return res
+	}
+	for _, t := range ko.Spec.InstanceProfile.Tags {
+		if t != nil && t.Key != nil && *t.Key != instanceProfileOwnerTagKey {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// observeInstanceProfile reads the instance profile managed for the supplied
+// Role, which is the one recorded in Status.InstanceProfileARN, or the one
+// requested in Spec.InstanceProfile if none is recorded yet. Status.
+// InstanceProfileARN is set if it exists and carries the Role's ownership
+// tag, and cleared otherwise.
+//
+// Unless the instance profile is in the requested state, Spec.
+// InstanceProfile is replaced with the observed one, Create telling whether
+// the Role belongs to it, so that compareInstanceProfile reports the
+// difference. Nothing is written to IAM here: syncInstanceProfile brings the
+// instance profile in line on update.
+func (rm *resourceManager) observeInstanceProfile(
+	ctx context.Context,
+	ko *svcapitypes.Role,
+) (err error) {
+	var name *string
+	switch {
+	case ko.Status.InstanceProfileARN != nil:
+		arn := *ko.Status.InstanceProfileARN
+		name = aws.String(arn[strings.LastIndex(arn, "/")+1:])
+	case instanceProfileEnabled(ko):
+		name = instanceProfileName(ko)
+	default:
+		return nil
+	}
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.observeInstanceProfile")
+	defer func() { exit(err) }()
+
+	resp, err := rm.sdkapi.GetInstanceProfile(ctx, &svcsdk.GetInstanceProfileInput{
+		InstanceProfileName: name,
+	})
+	rm.metrics.RecordAPICall("READ_ONE", "GetInstanceProfile", err)
+	var awsErr smithy.APIError
+	if errors.As(err, &awsErr) && awsErr.ErrorCode() == "NoSuchEntity" {
+		ko.Status.InstanceProfileARN = nil
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading instance profile %s: %w", *name, err)
+	}
+	profile := resp.InstanceProfile
+	if !ownsInstanceProfile(ko, profile) {
+		ko.Status.InstanceProfileARN = nil
+		return nil
+	}
+	ko.Status.InstanceProfileARN = profile.Arn
+
+	observed := &svcapitypes.RoleInstanceProfile{
+		Create: aws.Bool(instanceProfileHasRole(profile, *ko.Spec.Name)),
+		Name:   profile.InstanceProfileName,
+		Path:   profile.Path,
+		Tags:   []*svcapitypes.Tag{},
+	}
+	for _, t := range profile.Tags {
+		if *t.Key != instanceProfileOwnerTagKey {
+			observed.Tags = append(observed.Tags, &svcapitypes.Tag{Key: t.Key, Value: t.Value})
+		}
+	}
+	latest := ko.DeepCopy()
+	latest.Spec.InstanceProfile = observed
+	if !instanceProfileInSync(ko, latest) {
+		ko.Spec.InstanceProfile = observed
+	}
+	return nil
+}
+
+// instanceProfileInSync returns true if the instance profile observed in the
+// supplied latest Role is the one requested by the supplied desired Role.
+func instanceProfileInSync(desired *svcapitypes.Role, latest *svcapitypes.Role) bool {
+	if !instanceProfileEnabled(desired) {
+		return latest.Status.InstanceProfileARN == nil
+	}
+	if latest.Status.InstanceProfileARN == nil || !instanceProfileEnabled(latest) {
+		return false
+	}
+	return *instanceProfileName(desired) == *instanceProfileName(latest) &&
+		*instanceProfilePath(desired) == *instanceProfilePath(latest) &&
+		commonutil.EqualTags(instanceProfileTags(desired), instanceProfileTags(latest))
+}
+
+// compareInstanceProfile compares the instance profile requested by the
+// desired Role with the one observed by observeInstanceProfile.
+func compareInstanceProfile(
+	delta *ackcompare.Delta,
+	a *resource,
+	b *resource,
+) {
+	if !instanceProfileInSync(a.ko, b.ko) {
+		delta.Add("Spec.InstanceProfile", a.ko.Spec.InstanceProfile, b.ko.Spec.InstanceProfile)
+	}
+}
+
+// syncInstanceProfile makes the instance profile managed for the supplied
+// latest Role match the one requested by the supplied desired Role, and
+// records its ARN in the desired Status.InstanceProfileARN.
+//
+// The managed instance profile is detached and deleted when it is no longer
+// requested, or when its name or path changes, as neither can be updated. A
+// new instance profile is created with the Role's ownership tag, and an
+// existing instance profile without it is reported as a terminal error
+// instead of being taken over.
+func (rm *resourceManager) syncInstanceProfile(
+	ctx context.Context,
+	desired *resource,
+	latest *resource,
+) (err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.syncInstanceProfile")
+	defer func() { exit(err) }()
+
+	observed := latest.ko.Spec.InstanceProfile
+	owned := latest.ko.Status.InstanceProfileARN != nil
+	if owned && (!instanceProfileEnabled(desired.ko) ||
+		*instanceProfileName(desired.ko) != *instanceProfileName(latest.ko) ||
+		*instanceProfilePath(desired.ko) != *instanceProfilePath(latest.ko)) {
+		if err = rm.deleteInstanceProfile(ctx, latest); err != nil {
+			return err
+		}
+		owned = false
+	}
+	if !instanceProfileEnabled(desired.ko) {
+		desired.ko.Status.InstanceProfileARN = nil
+		return nil
+	}
+
+	name := instanceProfileName(desired.ko)
+	arn := latest.ko.Status.InstanceProfileARN
+	hasRole := owned && instanceProfileEnabled(latest.ko)
+	if !owned {
+		if arn, err = rm.createInstanceProfile(ctx, desired.ko); err != nil {
+			return err
+		}
+	} else if err = commonutil.SyncTags(
+		ctx, rm.sdkapi, rm.metrics, commonutil.ARNResourceTypeInstanceProfile,
+		name, instanceProfileTags(desired.ko), observed.Tags,
+	); err != nil {
+		return fmt.Errorf("tagging instance profile %s: %w", *name, err)
+	}
+	if !hasRole {
+		_, err = rm.sdkapi.AddRoleToInstanceProfile(ctx, &svcsdk.AddRoleToInstanceProfileInput{
+			InstanceProfileName: name,
+			RoleName:            desired.ko.Spec.Name,
+		})
+		rm.metrics.RecordAPICall("UPDATE", "AddRoleToInstanceProfile", err)
+		if err != nil {
+			return fmt.Errorf("adding role to instance profile %s: %w", *name, err)
+		}
+	}
+	desired.ko.Status.InstanceProfileARN = arn
+	return nil
+}
+
+// createInstanceProfile calls the CreateInstanceProfile API for the instance
+// profile requested by the supplied Role, tagged with the Role's ownership
+// tag, and returns its ARN.
+func (rm *resourceManager) createInstanceProfile(
+	ctx context.Context,
+	ko *svcapitypes.Role,
+) (*string, error) {
+	name := instanceProfileName(ko)
+	tags := append(sdkTags(instanceProfileTags(ko)), svcsdktypes.Tag{
+		Key:   aws.String(instanceProfileOwnerTagKey),
+		Value: ko.Spec.Name,
+	})
+	resp, err := rm.sdkapi.CreateInstanceProfile(ctx, &svcsdk.CreateInstanceProfileInput{
+		InstanceProfileName: name,
+		Path:                instanceProfilePath(ko),
+		Tags:                tags,
+	})
+	rm.metrics.RecordAPICall("CREATE", "CreateInstanceProfile", err)
+	var awsErr smithy.APIError
+	if errors.As(err, &awsErr) && awsErr.ErrorCode() == "EntityAlreadyExists" {
+		return nil, ackerr.NewTerminalError(fmt.Errorf(
+			"instance profile %s already exists and is not owned by the role; "+
+				"delete it, adopt it as an InstanceProfile or set instanceProfile.name", *name,
+		))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating instance profile %s: %w", *name, err)
+	}
+	return resp.InstanceProfile.Arn, nil
+}
+
+// deleteInstanceProfile removes the supplied Role from the instance profile
+// recorded in its Status.InstanceProfileARN, and deletes the instance
+// profile.
+func (rm *resourceManager) deleteInstanceProfile(
+	ctx context.Context,
+	r *resource,
+) (err error) {
+	if r.ko.Status.InstanceProfileARN == nil {
+		return nil
+	}
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.deleteInstanceProfile")
+	defer func() { exit(err) }()
+
+	arn := *r.ko.Status.InstanceProfileARN
+	name := arn[strings.LastIndex(arn, "/")+1:]
+	_, err = rm.sdkapi.RemoveRoleFromInstanceProfile(ctx, &svcsdk.RemoveRoleFromInstanceProfileInput{
+		InstanceProfileName: &name,
+		RoleName:            r.ko.Spec.Name,
+	})
+	rm.metrics.RecordAPICall("DELETE", "RemoveRoleFromInstanceProfile", err)
+	var awsErr smithy.APIError
+	if err != nil && !(errors.As(err, &awsErr) && awsErr.ErrorCode() == "NoSuchEntity") {
+		return fmt.Errorf("removing role from instance profile %s: %w", name, err)
+	}
+	_, err = rm.sdkapi.DeleteInstanceProfile(ctx, &svcsdk.DeleteInstanceProfileInput{
+		InstanceProfileName: &name,
+	})
+	rm.metrics.RecordAPICall("DELETE", "DeleteInstanceProfile", err)
+	if errors.As(err, &awsErr) && awsErr.ErrorCode() == "NoSuchEntity" {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("deleting instance profile %s: %w", name, err)
+	}
+	recordRoleEvent(ctx, r, "InstanceProfileDeleted", "Deleted instance profile %s", name)
+	return nil
+}
+
+// ownsInstanceProfile returns true if the supplied instance profile carries
+// the ownership tag of the supplied Role.
+func ownsInstanceProfile(ko *svcapitypes.Role, profile *svcsdktypes.InstanceProfile) bool {
+	for _, t := range profile.Tags {
+		if aws.ToString(t.Key) == instanceProfileOwnerTagKey {
+			return aws.ToString(t.Value) == *ko.Spec.Name
+		}
+	}
+	return false
+}
+
+// instanceProfileHasRole returns true if the named role belongs to the
+// supplied instance profile.
+func instanceProfileHasRole(profile *svcsdktypes.InstanceProfile, roleName string) bool {
+	for _, r := range profile.Roles {
+		if r.RoleName != nil && *r.RoleName == roleName {
+			return true
+		}
+	}
+	return false
+}
+
+// sdkTags converts the supplied tags into their aws-sdk-go-v2 shape.
+func sdkTags(tags []*svcapitypes.Tag) []svcsdktypes.Tag {
+	res := []svcsdktypes.Tag{}
+	for _, t := range tags {
+		if t == nil || t.Key == nil {
+			continue
+		}
+		res = append(res, svcsdktypes.Tag{Key: t.Key, Value: t.Value})
+	}
+	return res
+}
@@ -0,0 +1,214 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package role
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackrequeue "github.com/aws-controllers-k8s/runtime/pkg/requeue"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+func TestInstanceProfileName(t *testing.T) {
+	ko := &svcapitypes.Role{}
+	ko.Spec.Name = aws.String("node-role")
+	assert.False(t, instanceProfileEnabled(ko))
+
+	ko.Spec.InstanceProfile = &svcapitypes.RoleInstanceProfile{Create: aws.Bool(true)}
+	assert.True(t, instanceProfileEnabled(ko))
+	assert.Equal(t, "node-role", *instanceProfileName(ko))
+
+	ko.Spec.InstanceProfile.Name = aws.String("node-profile")
+	assert.Equal(t, "node-profile", *instanceProfileName(ko))
+}
+
+func TestInstanceProfileHasRole(t *testing.T) {
+	profile := &svcsdktypes.InstanceProfile{
+		Roles: []svcsdktypes.Role{{RoleName: aws.String("node-role")}},
+	}
+	assert.True(t, instanceProfileHasRole(profile, "node-role"))
+	assert.False(t, instanceProfileHasRole(profile, "other-role"))
+	assert.False(t, instanceProfileHasRole(&svcsdktypes.InstanceProfile{}, "node-role"))
+}
+
+// reconcile reads the supplied desired Role and updates it if it differs from
+// the latest one, as the reconciler would do, and returns the Role with the
+// resulting status and conditions.
+func reconcile(t *testing.T, rm *resourceManager, desired *resource) (*resource, error) {
+	ctx := context.TODO()
+	res, err := rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest := rm.concreteResource(res)
+	delta := newResourceDelta(desired, latest)
+	if !delta.DifferentAt("Spec") {
+		return latest, nil
+	}
+	res, err = rm.Update(ctx, desired, latest, delta)
+	return rm.concreteResource(res), err
+}
+
+func TestResourceManager_InstanceProfile(t *testing.T) {
+	ctx := context.TODO()
+	rm := newTestResourceManager(t)
+	getProfile := func(name string) *svcsdktypes.InstanceProfile {
+		out, err := rm.sdkapi.GetInstanceProfile(ctx, &svcsdk.GetInstanceProfileInput{
+			InstanceProfileName: aws.String(name),
+		})
+		require.NoError(t, err)
+		return out.InstanceProfile
+	}
+
+	desired := &resource{ko: &svcapitypes.Role{
+		Spec: svcapitypes.RoleSpec{
+			Name:                     aws.String("node-role"),
+			AssumeRolePolicyDocument: aws.String(testTrustPolicy),
+			InstanceProfile: &svcapitypes.RoleInstanceProfile{
+				Create: aws.Bool(true),
+				Tags:   []*svcapitypes.Tag{{Key: aws.String("team"), Value: aws.String("nodes")}},
+			},
+		},
+	}}
+	// The instance profile is only created by the update following the
+	// creation of the Role.
+	res, err := rm.Create(ctx, desired)
+	var requeueErr *ackrequeue.RequeueNeeded
+	require.ErrorAs(t, err, &requeueErr)
+	desired.SetStatus(rm.concreteResource(res))
+	_, err = rm.sdkapi.GetInstanceProfile(ctx, &svcsdk.GetInstanceProfileInput{
+		InstanceProfileName: aws.String("node-role"),
+	})
+	assert.ErrorContains(t, err, "NoSuchEntity")
+
+	latest, err := reconcile(t, rm, desired)
+	require.NoError(t, err)
+	desired.SetStatus(latest)
+	profile := getProfile("node-role")
+	assert.Equal(t, profile.Arn, desired.ko.Status.InstanceProfileARN)
+	assert.True(t, instanceProfileHasRole(profile, "node-role"))
+	assert.ElementsMatch(t, []svcsdktypes.Tag{
+		{Key: aws.String("team"), Value: aws.String("nodes")},
+		{Key: aws.String(instanceProfileOwnerTagKey), Value: aws.String("node-role")},
+	}, profile.Tags)
+
+	// Reading the Role writes nothing, and finds it in sync.
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	assert.False(t, newResourceDelta(desired, rm.concreteResource(res)).DifferentAt("Spec.InstanceProfile"))
+	assert.Equal(t, desired.ko.Spec.InstanceProfile, rm.concreteResource(res).ko.Spec.InstanceProfile)
+
+	// Stale tags are removed, and a Role removed out of band is added back.
+	desired.ko.Spec.InstanceProfile.Tags = []*svcapitypes.Tag{{Key: aws.String("env"), Value: aws.String("prod")}}
+	_, err = rm.sdkapi.RemoveRoleFromInstanceProfile(ctx, &svcsdk.RemoveRoleFromInstanceProfileInput{
+		InstanceProfileName: aws.String("node-role"),
+		RoleName:            aws.String("node-role"),
+	})
+	require.NoError(t, err)
+	latest, err = reconcile(t, rm, desired)
+	require.NoError(t, err)
+	desired.SetStatus(latest)
+	profile = getProfile("node-role")
+	assert.True(t, instanceProfileHasRole(profile, "node-role"))
+	assert.ElementsMatch(t, []svcsdktypes.Tag{
+		{Key: aws.String("env"), Value: aws.String("prod")},
+		{Key: aws.String(instanceProfileOwnerTagKey), Value: aws.String("node-role")},
+	}, profile.Tags)
+
+	// Renaming the instance profile replaces it.
+	desired.ko.Spec.InstanceProfile.Name = aws.String("node-profile")
+	latest, err = reconcile(t, rm, desired)
+	require.NoError(t, err)
+	desired.SetStatus(latest)
+	_, err = rm.sdkapi.GetInstanceProfile(ctx, &svcsdk.GetInstanceProfileInput{
+		InstanceProfileName: aws.String("node-role"),
+	})
+	assert.ErrorContains(t, err, "NoSuchEntity")
+	assert.Equal(t, getProfile("node-profile").Arn, desired.ko.Status.InstanceProfileARN)
+
+	// Unsetting create deletes the instance profile.
+	desired.ko.Spec.InstanceProfile.Create = aws.Bool(false)
+	latest, err = reconcile(t, rm, desired)
+	require.NoError(t, err)
+	desired.SetStatus(latest)
+	assert.Nil(t, desired.ko.Status.InstanceProfileARN)
+	_, err = rm.sdkapi.GetInstanceProfile(ctx, &svcsdk.GetInstanceProfileInput{
+		InstanceProfileName: aws.String("node-profile"),
+	})
+	assert.ErrorContains(t, err, "NoSuchEntity")
+
+	// Deleting the Role deletes its instance profile.
+	desired.ko.Spec.InstanceProfile = &svcapitypes.RoleInstanceProfile{Create: aws.Bool(true)}
+	latest, err = reconcile(t, rm, desired)
+	require.NoError(t, err)
+	desired.SetStatus(latest)
+	require.NotNil(t, desired.ko.Status.InstanceProfileARN)
+	desired.ko.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	_, err = rm.Delete(ctx, res)
+	require.NoError(t, err)
+	_, err = rm.sdkapi.GetInstanceProfile(ctx, &svcsdk.GetInstanceProfileInput{
+		InstanceProfileName: aws.String("node-role"),
+	})
+	assert.ErrorContains(t, err, "NoSuchEntity")
+}
+
+func TestResourceManager_InstanceProfileNotOwned(t *testing.T) {
+	ctx := context.TODO()
+	rm := newTestResourceManager(t)
+
+	_, err := rm.sdkapi.CreateInstanceProfile(ctx, &svcsdk.CreateInstanceProfileInput{
+		InstanceProfileName: aws.String("node-role"),
+	})
+	require.NoError(t, err)
+	desired := &resource{ko: &svcapitypes.Role{
+		Spec: svcapitypes.RoleSpec{
+			Name:                     aws.String("node-role"),
+			AssumeRolePolicyDocument: aws.String(testTrustPolicy),
+			InstanceProfile:          &svcapitypes.RoleInstanceProfile{Create: aws.Bool(true)},
+		},
+	}}
+	res, err := rm.Create(ctx, desired)
+	var requeueErr *ackrequeue.RequeueNeeded
+	require.ErrorAs(t, err, &requeueErr)
+	desired.SetStatus(rm.concreteResource(res))
+
+	// An instance profile without the Role's ownership tag is not taken over,
+	// nor deleted with the Role.
+	latest, err := reconcile(t, rm, desired)
+	assert.Equal(t, ackerr.Terminal, err)
+	require.NotNil(t, ackcondition.Terminal(latest))
+	assert.Contains(t, *ackcondition.Terminal(latest).Message, "instance profile node-role already exists")
+
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	assert.Nil(t, rm.concreteResource(res).ko.Status.InstanceProfileARN)
+	_, err = rm.Delete(ctx, res)
+	require.NoError(t, err)
+	out, err := rm.sdkapi.GetInstanceProfile(ctx, &svcsdk.GetInstanceProfileInput{
+		InstanceProfileName: aws.String("node-role"),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, out.InstanceProfile.Roles)
+}
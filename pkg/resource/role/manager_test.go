@@ -0,0 +1,148 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package role
+
+import (
+	"context"
+	"testing"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackmetrics "github.com/aws-controllers-k8s/runtime/pkg/metrics"
+	ackrequeue "github.com/aws-controllers-k8s/runtime/pkg/requeue"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+const (
+	testTrustPolicy  = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole"}]}`
+	testInlinePolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`
+)
+
+func newTestResourceManager(t *testing.T) *resourceManager {
+	rm, err := newResourceManager(
+		ackcfg.Config{}, fakeiam.New().Config(), logr.Discard(), ackmetrics.NewMetrics("iam"),
+		nil, fakeiam.AccountID, fakeiam.Region,
+	)
+	require.NoError(t, err)
+	return rm
+}
+
+func TestResourceManager_Lifecycle(t *testing.T) {
+	ctx := context.TODO()
+	rm := newTestResourceManager(t)
+
+	desired := &resource{ko: &svcapitypes.Role{
+		Spec: svcapitypes.RoleSpec{
+			Name:                     aws.String("test-role"),
+			AssumeRolePolicyDocument: aws.String(testTrustPolicy),
+			AWSManagedPolicies:       []*string{aws.String("ReadOnlyAccess")},
+			InlinePolicies:           map[string]*string{"s3": aws.String(testInlinePolicy)},
+		},
+	}}
+	// Create requeues, so that the policies are attached by the following
+	// Update, as the reconciler would do.
+	res, err := rm.Create(ctx, desired)
+	var requeueErr *ackrequeue.RequeueNeeded
+	require.ErrorAs(t, err, &requeueErr)
+	created := rm.concreteResource(res)
+	assert.Equal(
+		t,
+		ackv1alpha1.AWSResourceName("arn:aws:iam::"+fakeiam.AccountID+":role/test-role"),
+		*created.ko.Status.ACKResourceMetadata.ARN,
+	)
+
+	desired.SetStatus(created)
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest := rm.concreteResource(res)
+	_, err = rm.Update(ctx, desired, latest, newResourceDelta(desired, latest))
+	require.NoError(t, err)
+
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest = rm.concreteResource(res)
+	assert.JSONEq(t, testTrustPolicy, *latest.ko.Spec.AssumeRolePolicyDocument)
+	assert.Equal(t, []*string{aws.String("ReadOnlyAccess")}, latest.ko.Spec.AWSManagedPolicies)
+	assert.Empty(t, latest.ko.Spec.Policies)
+	require.Contains(t, latest.ko.Spec.InlinePolicies, "s3")
+	assert.JSONEq(t, testInlinePolicy, *latest.ko.Spec.InlinePolicies["s3"])
+
+	// IAM refuses to delete the role while it has policies, until an Update
+	// removes them.
+	_, err = rm.sdkapi.DeleteRole(ctx, &svcsdk.DeleteRoleInput{RoleName: aws.String("test-role")})
+	assert.ErrorContains(t, err, "DeleteConflict")
+
+	desired = rm.concreteResource(latest.DeepCopy())
+	desired.ko.Spec.AWSManagedPolicies = nil
+	desired.ko.Spec.InlinePolicies = nil
+	_, err = rm.Update(ctx, desired, latest, newResourceDelta(desired, latest))
+	require.NoError(t, err)
+	_, err = rm.sdkapi.DeleteRole(ctx, &svcsdk.DeleteRoleInput{RoleName: aws.String("test-role")})
+	require.NoError(t, err)
+	_, err = rm.ReadOne(ctx, latest)
+	assert.Equal(t, ackerr.NotFound, err)
+}
+
+func TestResourceManager_ReadOne_ServiceLastAccessed(t *testing.T) {
+	ctx := context.TODO()
+	rm := newTestResourceManager(t)
+
+	desired := &resource{ko: &svcapitypes.Role{
+		Spec: svcapitypes.RoleSpec{
+			Name:                     aws.String("test-role"),
+			AssumeRolePolicyDocument: aws.String(testTrustPolicy),
+		},
+	}}
+	res, err := rm.Create(ctx, desired)
+	var requeueErr *ackrequeue.RequeueNeeded
+	require.ErrorAs(t, err, &requeueErr)
+	desired.SetStatus(rm.concreteResource(res))
+
+	// A job is started, and the Role is not synced until it is collected.
+	desired.ko.Spec.ServiceLastAccessedGranularity = aws.String("SERVICE_LEVEL")
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest := rm.concreteResource(res)
+	require.NotNil(t, latest.ko.Status.ServiceLastAccessed)
+	assert.NotNil(t, latest.ko.Status.ServiceLastAccessed.JobID)
+	require.NotNil(t, ackcondition.Synced(latest))
+	assert.Equal(t, corev1.ConditionFalse, ackcondition.Synced(latest).Status)
+
+	desired.SetStatus(latest)
+	desired.ko.Status.Conditions = nil
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest = rm.concreteResource(res)
+	assert.Nil(t, latest.ko.Status.ServiceLastAccessed.JobID)
+	assert.NotNil(t, latest.ko.Status.ServiceLastAccessed.JobCompletionDate)
+	assert.Nil(t, ackcondition.Synced(latest))
+
+	// Failures are reported without failing the read.
+	desired.SetStatus(latest)
+	desired.ko.Spec.ServiceLastAccessedGranularity = aws.String("RESOURCE_LEVEL")
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	assert.NotNil(t, ackcondition.AdvisoryWithReason(res, commonutil.ServiceLastAccessedFailedReason))
+}
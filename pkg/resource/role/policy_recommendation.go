@@ -0,0 +1,269 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package role
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+// policyRecommendationFailedReason is the reason of the ACK.Advisory
+// condition reporting that the policy recommendation could not be computed.
+const policyRecommendationFailedReason = "PolicyRecommendationFailed"
+
+// syncPolicyRecommendation proposes a trimmed policy document for the
+// supplied Role from its service last accessed report, dropping the services
+// not used within Spec.PolicyRecommendationUnusedDays. The recommendation is
+// only written to Status.PolicyRecommendation and never applied.
+//
+// A recommendation is computed once per completed report, since it requires
+// fetching the documents of every attached managed policy, including the AWS
+// managed policies declared by name. It is best-effort: failures are reported
+// as a condition and the previous recommendation is kept.
+func (rm *resourceManager) syncPolicyRecommendation(
+	ctx context.Context,
+	ko *svcapitypes.Role,
+) {
+	days := ko.Spec.PolicyRecommendationUnusedDays
+	report := ko.Status.ServiceLastAccessed
+	if days == nil {
+		ko.Status.PolicyRecommendation = nil
+		return
+	}
+	if ko.DeletionTimestamp != nil || report == nil || report.JobCompletionDate == nil {
+		return
+	}
+	current := ko.Status.PolicyRecommendation
+	if current != nil && current.ReportCompletionDate != nil &&
+		current.ReportCompletionDate.Equal(report.JobCompletionDate) &&
+		current.UnusedDays != nil && *current.UnusedDays == *days {
+		return
+	}
+	recommendation, err := rm.recommendPolicy(ctx, ko, *days)
+	if err != nil {
+		ackrtlog.FromContext(ctx).Debug("failed to compute policy recommendation", "error", err)
+	} else {
+		ko.Status.PolicyRecommendation = recommendation
+	}
+	commonutil.SetAdvisoryFromError(&resource{ko}, policyRecommendationFailedReason, err)
+}
+
+// recommendPolicy computes the policy recommendation of the supplied Role
+// from the documents of its managed and inline policies.
+func (rm *resourceManager) recommendPolicy(
+	ctx context.Context,
+	ko *svcapitypes.Role,
+	days int64,
+) (recommendation *svcapitypes.PolicyRecommendation, err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.recommendPolicy")
+	defer func() { exit(err) }()
+
+	arns, err := rm.managedPolicyARNs(ctx, ko)
+	if err != nil {
+		return nil, err
+	}
+	docs := []string{}
+	for _, arn := range arns {
+		doc, err := rm.getManagedPolicyDocument(ctx, arn)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	names := make([]string, 0, len(ko.Spec.InlinePolicies))
+	for name := range ko.Spec.InlinePolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if doc := ko.Spec.InlinePolicies[name]; doc != nil {
+			docs = append(docs, *doc)
+		}
+	}
+
+	report := ko.Status.ServiceLastAccessed
+	unusedFor := time.Duration(days) * 24 * time.Hour
+	doc, unused, err := recommendPolicy(docs, report.ServicesLastAccessed, time.Now().Add(-unusedFor))
+	if err != nil {
+		return nil, err
+	}
+	now := metav1.Now()
+	return &svcapitypes.PolicyRecommendation{
+		GeneratedAt:          &now,
+		PolicyDocument:       &doc,
+		ReportCompletionDate: report.JobCompletionDate.DeepCopy(),
+		UnusedDays:           &days,
+		UnusedServices:       unused,
+	}, nil
+}
+
+// getManagedPolicyDocument returns the decoded default version document of
+// the managed policy with the supplied ARN.
+func (rm *resourceManager) getManagedPolicyDocument(
+	ctx context.Context,
+	arn *string,
+) (doc string, err error) {
+	policy, err := rm.sdkapi.GetPolicy(ctx, &svcsdk.GetPolicyInput{PolicyArn: arn})
+	rm.metrics.RecordAPICall("READ_ONE", "GetPolicy", err)
+	if err != nil {
+		return "", err
+	}
+	version, err := rm.sdkapi.GetPolicyVersion(ctx, &svcsdk.GetPolicyVersionInput{
+		PolicyArn: arn,
+		VersionId: policy.Policy.DefaultVersionId,
+	})
+	rm.metrics.RecordAPICall("READ_ONE", "GetPolicyVersion", err)
+	if err != nil {
+		return "", err
+	}
+	if version.PolicyVersion == nil || version.PolicyVersion.Document == nil {
+		return "", fmt.Errorf("policy %s has no default version document", *arn)
+	}
+	return decodeDocument(*version.PolicyVersion.Document)
+}
+
+// recommendPolicy merges the statements of the supplied policy documents into
+// a single document, dropping Allow actions of services not authenticated to
+// since the supplied cutoff. Deny and NotAction statements are kept as is, as
+// are actions of services absent from the report. A statement allowing "*"
+// becomes a NotAction statement excluding the unused services, so that it
+// still allows the services absent from the report. It returns the
+// recommended document and the sorted namespaces of the dropped services.
+func recommendPolicy(
+	docs []string,
+	services []*svcapitypes.ServiceLastAccessed,
+	cutoff time.Time,
+) (string, []*string, error) {
+	used := map[string]bool{}
+	for _, s := range services {
+		if s == nil || s.ServiceNamespace == nil {
+			continue
+		}
+		used[*s.ServiceNamespace] = s.LastAuthenticated != nil && s.LastAuthenticated.After(cutoff)
+	}
+	unusedActions := []interface{}{}
+	for ns, ok := range used {
+		if !ok {
+			unusedActions = append(unusedActions, ns+":*")
+		}
+	}
+	sort.Slice(unusedActions, func(i, j int) bool {
+		return unusedActions[i].(string) < unusedActions[j].(string)
+	})
+
+	unused := map[string]bool{}
+	statements := []interface{}{}
+	for i, doc := range docs {
+		parsed := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(doc), &parsed); err != nil {
+			return "", nil, fmt.Errorf("decoding policy document %d: %w", i, err)
+		}
+		for _, s := range statementList(parsed["Statement"]) {
+			stmt, ok := s.(map[string]interface{})
+			if !ok || stmt["Effect"] != "Allow" || stmt["Action"] == nil {
+				statements = append(statements, s)
+				continue
+			}
+			trimmed := map[string]interface{}{}
+			for k, v := range stmt {
+				trimmed[k] = v
+			}
+			if allowsAllActions(stmt["Action"]) {
+				if len(unusedActions) > 0 {
+					delete(trimmed, "Action")
+					trimmed["NotAction"] = unusedActions
+					for ns, inUse := range used {
+						if !inUse {
+							unused[ns] = true
+						}
+					}
+				}
+				statements = append(statements, trimmed)
+				continue
+			}
+			actions := []interface{}{}
+			for _, a := range statementList(stmt["Action"]) {
+				action, ok := a.(string)
+				if !ok {
+					actions = append(actions, a)
+					continue
+				}
+				ns := strings.ToLower(strings.SplitN(action, ":", 2)[0])
+				if inUse, tracked := used[ns]; tracked && !inUse {
+					unused[ns] = true
+					continue
+				}
+				actions = append(actions, action)
+			}
+			if len(actions) == 0 {
+				continue
+			}
+			trimmed["Action"] = actions
+			statements = append(statements, trimmed)
+		}
+	}
+
+	out, err := json.Marshal(map[string]interface{}{
+		"Version":   defaultPolicyVersion,
+		"Statement": statements,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	namespaces := make([]string, 0, len(unused))
+	for ns := range unused {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	unusedServices := make([]*string, 0, len(namespaces))
+	for i := range namespaces {
+		unusedServices = append(unusedServices, &namespaces[i])
+	}
+	return string(out), unusedServices, nil
+}
+
+// allowsAllActions returns true if the supplied Action element holds "*".
+func allowsAllActions(v interface{}) bool {
+	for _, a := range statementList(v) {
+		if a == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// statementList returns the supplied policy element as a list. Statement and
+// Action may be either a single value or a list of values.
+func statementList(v interface{}) []interface{} {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return t
+	default:
+		return []interface{}{t}
+	}
+}
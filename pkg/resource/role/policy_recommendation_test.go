@@ -0,0 +1,122 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package role
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ackcompare "github.com/aws-controllers-k8s/runtime/pkg/compare"
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackmetrics "github.com/aws-controllers-k8s/runtime/pkg/metrics"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+)
+
+func TestRecommendPolicy(t *testing.T) {
+	now := time.Now()
+	cutoff := now.Add(-90 * 24 * time.Hour)
+	services := []*svcapitypes.ServiceLastAccessed{
+		{ServiceNamespace: aws.String("s3"), LastAuthenticated: &metav1.Time{Time: now.Add(-time.Hour)}},
+		{ServiceNamespace: aws.String("sqs"), LastAuthenticated: &metav1.Time{Time: now.Add(-365 * 24 * time.Hour)}},
+		{ServiceNamespace: aws.String("ec2")},
+	}
+	docs := []string{
+		`{
+			"Version": "2012-10-17",
+			"Statement": [
+				{"Sid": "Data", "Effect": "Allow", "Action": ["s3:GetObject", "sqs:SendMessage"], "Resource": "*"},
+				{"Effect": "Allow", "Action": "ec2:DescribeInstances", "Resource": "*"},
+				{"Effect": "Deny", "Action": "sqs:DeleteQueue", "Resource": "*"}
+			]
+		}`,
+		`{
+			"Version": "2012-10-17",
+			"Statement": {"Effect": "Allow", "Action": ["*", "kms:Decrypt"], "Resource": "*"}
+		}`,
+	}
+
+	got, unused, err := recommendPolicy(docs, services, cutoff)
+	require.NoError(t, err)
+	want := `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{"Sid": "Data", "Effect": "Allow", "Action": ["s3:GetObject"], "Resource": "*"},
+			{"Effect": "Deny", "Action": "sqs:DeleteQueue", "Resource": "*"},
+			{"Effect": "Allow", "NotAction": ["ec2:*", "sqs:*"], "Resource": "*"}
+		]
+	}`
+	equal, err := ackcompare.IAMPolicyDocumentEqual(want, got)
+	require.NoError(t, err)
+	assert.True(t, equal, got)
+	assert.Equal(t, []string{"ec2", "sqs"}, aws.ToStringSlice(unused))
+}
+
+func TestRecommendPolicy_InvalidDocument(t *testing.T) {
+	_, _, err := recommendPolicy([]string{"not json"}, nil, time.Now())
+	assert.Error(t, err)
+}
+
+func TestSyncPolicyRecommendation(t *testing.T) {
+	ctx := context.TODO()
+	backend := fakeiam.New()
+	backend.AddAWSManagedPolicy(
+		"AmazonSQSFullAccess",
+		`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sqs:*","Resource":"*"}]}`,
+	)
+	rm, err := newResourceManager(
+		ackcfg.Config{Partition: "aws"}, backend.Config(), logr.Discard(), ackmetrics.NewMetrics("iam"),
+		nil, fakeiam.AccountID, fakeiam.Region,
+	)
+	require.NoError(t, err)
+
+	now := metav1.Now()
+	ko := &svcapitypes.Role{
+		Spec: svcapitypes.RoleSpec{
+			AWSManagedPolicies:             []*string{aws.String("AmazonSQSFullAccess")},
+			PolicyRecommendationUnusedDays: aws.Int64(90),
+		},
+		Status: svcapitypes.RoleStatus{
+			ServiceLastAccessed: &svcapitypes.ServiceLastAccessedReport{
+				JobCompletionDate: &now,
+				ServicesLastAccessed: []*svcapitypes.ServiceLastAccessed{
+					{ServiceNamespace: aws.String("sqs")},
+				},
+			},
+		},
+	}
+	// The AWS managed policies declared by name are part of the
+	// recommendation.
+	rm.syncPolicyRecommendation(ctx, ko)
+	require.NotNil(t, ko.Status.PolicyRecommendation)
+	assert.Equal(t, []string{"sqs"}, aws.ToStringSlice(ko.Status.PolicyRecommendation.UnusedServices))
+	assert.Nil(t, ackcondition.AdvisoryWithReason(&resource{ko}, policyRecommendationFailedReason))
+
+	// Failures are reported and keep the previous recommendation.
+	previous := ko.Status.PolicyRecommendation
+	ko.Status.PolicyRecommendation = previous.DeepCopy()
+	ko.Status.PolicyRecommendation.UnusedDays = aws.Int64(30)
+	ko.Spec.Policies = []*string{aws.String("arn:aws:iam::" + fakeiam.AccountID + ":policy/missing")}
+	rm.syncPolicyRecommendation(ctx, ko)
+	assert.Equal(t, int64(30), *ko.Status.PolicyRecommendation.UnusedDays)
+	assert.NotNil(t, ackcondition.AdvisoryWithReason(&resource{ko}, policyRecommendationFailedReason))
+}
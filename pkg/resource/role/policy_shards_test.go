@@ -0,0 +1,95 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package role
+
+import (
+	"context"
+	"testing"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackrequeue "github.com/aws-controllers-k8s/runtime/pkg/requeue"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+func TestResourceManager_PolicyShards(t *testing.T) {
+	ctx := context.TODO()
+	rm := newTestResourceManager(t)
+	arns := []*string{}
+	for _, name := range []string{"large", "large-part2"} {
+		out, err := rm.sdkapi.CreatePolicy(ctx, &svcsdk.CreatePolicyInput{
+			PolicyName:     aws.String(name),
+			PolicyDocument: aws.String(testInlinePolicy),
+		})
+		require.NoError(t, err)
+		arns = append(arns, out.Policy.Arn)
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, svcapitypes.AddToScheme(scheme))
+	commonutil.SetAPIReader(fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&svcapitypes.Policy{
+			ObjectMeta: metav1.ObjectMeta{Name: "large", Namespace: "team-a"},
+			Status:     svcapitypes.PolicyStatus{ShardARNs: arns[1:]},
+		},
+	).Build())
+
+	// The Policy ARN is set from the reference by ResolveReferences.
+	desired := &resource{ko: &svcapitypes.Role{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-role", Namespace: "team-a"},
+		Spec: svcapitypes.RoleSpec{
+			Name:                     aws.String("test-role"),
+			AssumeRolePolicyDocument: aws.String(testTrustPolicy),
+			Policies:                 []*string{arns[0]},
+			PolicyRefs: []*ackv1alpha1.AWSResourceReferenceWrapper{
+				{From: &ackv1alpha1.AWSResourceReference{Name: aws.String("large")}},
+			},
+		},
+	}}
+	_, err := rm.ReadOne(ctx, desired)
+	require.Equal(t, ackerr.NotFound, err)
+	assert.Equal(t, arns, desired.ko.Spec.Policies)
+
+	res, err := rm.Create(ctx, desired)
+	var requeueErr *ackrequeue.RequeueNeeded
+	require.ErrorAs(t, err, &requeueErr)
+	desired.SetStatus(rm.concreteResource(res))
+	latest, err := reconcile(t, rm, desired)
+	require.NoError(t, err)
+	out, err := rm.sdkapi.ListAttachedRolePolicies(ctx, &svcsdk.ListAttachedRolePoliciesInput{
+		RoleName: aws.String("test-role"),
+	})
+	require.NoError(t, err)
+	attached := []*string{}
+	for _, p := range out.AttachedPolicies {
+		attached = append(attached, p.PolicyArn)
+	}
+	assert.ElementsMatch(t, arns, attached)
+
+	// The shards count as declared when the Role is deleted.
+	now := metav1.Now()
+	desired.ko.DeletionTimestamp = &now
+	_, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	_, err = rm.Delete(ctx, latest)
+	require.NoError(t, err)
+}
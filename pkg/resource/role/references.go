@@ -46,6 +46,12 @@ func (rm *resourceManager) ClearResolvedReferences(res acktypes.AWSResource) ack
 		ko.Spec.Policies = nil
 	}
 
+	for f0idx, f0iter := range ko.Spec.ServiceAccountTrust {
+		if f0iter.OIDCProviderRef != nil {
+			ko.Spec.ServiceAccountTrust[f0idx].OIDCProviderARN = nil
+		}
+	}
+
 	return &resource{ko}
 }
 
@@ -77,6 +83,12 @@ func (rm *resourceManager) ResolveReferences(
 		resourceHasReferences = resourceHasReferences || fieldHasReferences
 	}
 
+	if fieldHasReferences, err := rm.resolveReferenceForServiceAccountTrust_OIDCProviderARN(ctx, apiReader, ko); err != nil {
+		return &resource{ko}, (resourceHasReferences || fieldHasReferences), err
+	} else {
+		resourceHasReferences = resourceHasReferences || fieldHasReferences
+	}
+
 	return &resource{ko}, resourceHasReferences, err
 }
 
@@ -91,6 +103,12 @@ func validateReferenceFields(ko *svcapitypes.Role) error {
 	if len(ko.Spec.PolicyRefs) > 0 && len(ko.Spec.Policies) > 0 {
 		return ackerr.ResourceReferenceAndIDNotSupportedFor("Policies", "PolicyRefs")
 	}
+
+	for _, f0iter := range ko.Spec.ServiceAccountTrust {
+		if f0iter.OIDCProviderRef != nil && f0iter.OIDCProviderARN != nil {
+			return ackerr.ResourceReferenceAndIDNotSupportedFor("ServiceAccountTrust.OIDCProviderARN", "ServiceAccountTrust.OIDCProviderRef")
+		}
+	}
 	return nil
 }
 
@@ -226,3 +244,96 @@ func (rm *resourceManager) resolveReferenceForPolicies(
 
 	return hasReferences, nil
 }
+
+// resolveReferenceForServiceAccountTrust_OIDCProviderARN reads the resource reference, reads the status
+// of the referenced resource and sets the OIDCProviderARN from
+// referenced resource. Returns a boolean indicating whether a reference
+// contains references, or an error
+func (rm *resourceManager) resolveReferenceForServiceAccountTrust_OIDCProviderARN(
+	ctx context.Context,
+	apiReader client.Reader,
+	ko *svcapitypes.Role,
+) (hasReferences bool, err error) {
+	for f0idx, f0iter := range ko.Spec.ServiceAccountTrust {
+		if f0iter.OIDCProviderRef != nil && f0iter.OIDCProviderRef.From != nil {
+			hasReferences = true
+			arr := f0iter.OIDCProviderRef.From
+			if arr.Name == nil || *arr.Name == "" {
+				return hasReferences, fmt.Errorf("provided resource reference is nil or empty: ServiceAccountTrust.OIDCProviderRef")
+			}
+			namespace, err := ackrt.ResolveCrossNamespaceReference(
+				ctx,
+				rm.cfg.EnableCrossNamespace,
+				&ko.Status.Conditions,
+				ackrt.CrossNamespaceRefKindResource,
+				ko.ObjectMeta.GetNamespace(),
+				arr.Namespace,
+				*arr.Name,
+			)
+			if err != nil {
+				return hasReferences, err
+			}
+			obj := &svcapitypes.OpenIDConnectProvider{}
+			if err := getReferencedResourceState_OpenIDConnectProvider(ctx, apiReader, obj, *arr.Name, namespace); err != nil {
+				return hasReferences, err
+			}
+			ko.Spec.ServiceAccountTrust[f0idx].OIDCProviderARN = (*string)(obj.Status.ACKResourceMetadata.ARN)
+		}
+	}
+
+	return hasReferences, nil
+}
+
+// getReferencedResourceState_OpenIDConnectProvider looks up whether a referenced resource
+// exists and is in a ACK.ResourceSynced=True state. If the referenced resource does exist and is
+// in a Synced state, returns nil, otherwise returns `ackerr.ResourceReferenceTerminalFor` or
+// `ResourceReferenceNotSyncedFor` depending on if the resource is in a Terminal state.
+func getReferencedResourceState_OpenIDConnectProvider(
+	ctx context.Context,
+	apiReader client.Reader,
+	obj *svcapitypes.OpenIDConnectProvider,
+	name string, // the Kubernetes name of the referenced resource
+	namespace string, // the Kubernetes namespace of the referenced resource
+) error {
+	namespacedName := types.NamespacedName{
+		Namespace: namespace,
+		Name:      name,
+	}
+	err := apiReader.Get(ctx, namespacedName, obj)
+	if err != nil {
+		return err
+	}
+	var refResourceTerminal bool
+	for _, cond := range obj.Status.Conditions {
+		if cond.Type == ackv1alpha1.ConditionTypeTerminal &&
+			cond.Status == corev1.ConditionTrue {
+			return ackerr.ResourceReferenceTerminalFor(
+				"OpenIDConnectProvider",
+				namespace, name)
+		}
+	}
+	if refResourceTerminal {
+		return ackerr.ResourceReferenceTerminalFor(
+			"OpenIDConnectProvider",
+			namespace, name)
+	}
+	var refResourceSynced bool
+	for _, cond := range obj.Status.Conditions {
+		if cond.Type == ackv1alpha1.ConditionTypeResourceSynced &&
+			cond.Status == corev1.ConditionTrue {
+			refResourceSynced = true
+		}
+	}
+	if !refResourceSynced {
+		return ackerr.ResourceReferenceNotSyncedFor(
+			"OpenIDConnectProvider",
+			namespace, name)
+	}
+	if obj.Status.ACKResourceMetadata == nil || obj.Status.ACKResourceMetadata.ARN == nil {
+		return ackerr.ResourceReferenceMissingTargetFieldFor(
+			"OpenIDConnectProvider",
+			namespace, name,
+			"Status.ACKResourceMetadata.ARN")
+	}
+	return nil
+}
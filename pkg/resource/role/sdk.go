@@ -64,6 +64,11 @@ func (rm *resourceManager) sdkFind(
 	defer func() {
 		exit(err)
 	}()
+	// The shards of the Policies referenced in PolicyRefs are declared, and
+	// attached, along with them.
+	if err = rm.declarePolicyShards(ctx, r.ko); err != nil {
+		return nil, err
+	}
 	// If any required fields in the input shape are missing, AWS resource is
 	// not created yet. Return NotFound here to indicate to callers that the
 	// resource isn't yet created.
@@ -180,14 +185,22 @@ func (rm *resourceManager) sdkFind(
 	if err != nil {
 		return nil, err
 	}
+	rm.splitAWSManagedPolicies(ctx, ko)
 	ko.Spec.InlinePolicies, err = rm.getInlinePolicies(ctx, &resource{ko})
 	if err != nil {
 		return nil, err
 	}
+	rm.setRenderedInlinePolicies(r, ko)
+	setDeletionBlocked(ko, checkDeletionPolicies(r.ko, ko))
 	ko.Spec.Tags, err = rm.getTags(ctx, &resource{ko})
 	if err != nil {
 		return nil, err
 	}
+	if err = rm.observeInstanceProfile(ctx, ko); err != nil {
+		return nil, err
+	}
+	rm.syncServiceLastAccessed(ctx, ko)
+	rm.syncPolicyRecommendation(ctx, ko)
 
 	return &resource{ko}, nil
 }
@@ -232,6 +245,9 @@ func (rm *resourceManager) sdkCreate(
 	if err != nil {
 		return nil, err
 	}
+	if input.AssumeRolePolicyDocument, err = renderedAssumeRolePolicyDocument(desired, string(rm.awsPartition)); err != nil {
+		return nil, err
+	}
 
 	var resp *svcsdk.CreateRoleOutput
 	_ = resp
@@ -317,7 +333,11 @@ func (rm *resourceManager) sdkCreate(
 	}
 
 	rm.setStatusDefaults(ko)
-	if ko.Spec.AssumeRolePolicyDocument != nil {
+	if hasRenderedTrust(desired) {
+		// Keep the hand-written trust policy in the spec. The statements
+		// rendered from ServiceAccountTrust and TrustPresets only live in IAM.
+		ko.Spec.AssumeRolePolicyDocument = desired.ko.Spec.AssumeRolePolicyDocument
+	} else if ko.Spec.AssumeRolePolicyDocument != nil {
 		if doc, err := decodeDocument(*ko.Spec.AssumeRolePolicyDocument); err != nil {
 			return nil, err
 		} else {
@@ -391,7 +411,7 @@ func (rm *resourceManager) sdkUpdate(
 	defer func() {
 		exit(err)
 	}()
-	if delta.DifferentAt("Spec.Policies") {
+	if delta.DifferentAt("Spec.Policies") || delta.DifferentAt("Spec.AWSManagedPolicies") {
 		err = rm.syncManagedPolicies(ctx, desired, latest)
 		if err != nil {
 			return nil, err
@@ -421,7 +441,19 @@ func (rm *resourceManager) sdkUpdate(
 			return nil, err
 		}
 	}
-	if !delta.DifferentExcept("Spec.Tags", "Spec.Policies", "Spec.InlinePolicies", "Spec.PermissionsBoundary", "Spec.AssumeRolePolicyDocument") {
+	if delta.DifferentAt("Spec.ServiceAccountBindings") {
+		err = rm.syncServiceAccountBindings(ctx, desired, latest)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if delta.DifferentAt("Spec.InstanceProfile") {
+		err = rm.syncInstanceProfile(ctx, desired, latest)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !delta.DifferentExcept("Spec.Tags", "Spec.Policies", "Spec.AWSManagedPolicies", "Spec.InlinePolicies", "Spec.PermissionsBoundary", "Spec.AssumeRolePolicyDocument", "Spec.ServiceAccountBindings", "Spec.InstanceProfile") {
 		return desired, nil
 	}
 
@@ -482,15 +514,13 @@ func (rm *resourceManager) sdkDelete(
 	defer func() {
 		exit(err)
 	}()
-	// This deletes all associated managed and inline policies from the role
-	roleCpy := r.ko.DeepCopy()
-	roleCpy.Spec.Policies = nil
-	if err := rm.syncManagedPolicies(ctx, &resource{ko: roleCpy}, r); err != nil {
+	if err := rm.removeServiceAccountBindings(ctx, r); err != nil {
 		return nil, err
 	}
-	roleCpy.Spec.InlinePolicies = map[string]*string{}
-	if err := rm.syncInlinePolicies(ctx, &resource{ko: roleCpy}, r); err != nil {
-		return nil, err
+	// This removes the role from its instance profiles and deletes its
+	// permissions boundary and all associated managed and inline policies
+	if err := rm.cleanupBeforeDeletion(ctx, r); err != nil {
+		return r, err
 	}
 
 	input, err := rm.newDeleteRequestPayload(r)
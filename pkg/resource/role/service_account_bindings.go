@@ -0,0 +1,365 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package role
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	ackcompare "github.com/aws-controllers-k8s/runtime/pkg/compare"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackrt "github.com/aws-controllers-k8s/runtime/pkg/runtime"
+	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;patch
+
+const (
+	// roleARNAnnotation is read by the EKS pod identity webhook to inject
+	// IRSA credentials into the pods of a ServiceAccount.
+	roleARNAnnotation = "eks.amazonaws.com/role-arn"
+	// stsRegionalEndpointsAnnotation makes the pod identity webhook point
+	// the injected credentials at the regional STS endpoint.
+	stsRegionalEndpointsAnnotation = "eks.amazonaws.com/sts-regional-endpoints"
+)
+
+// serviceAccountBindingConflictReason is the reason of the ACK.Advisory
+// condition listing the bound ServiceAccounts that are already annotated with
+// another role.
+const serviceAccountBindingConflictReason = "ServiceAccountBindingConflict"
+
+// syncServiceAccountBindings annotates the ServiceAccounts listed in the
+// Spec.ServiceAccountBindings of the supplied desired Role with the ARN of the
+// latest one, removes the annotations of the ServiceAccounts no longer listed
+// and records the bound ServiceAccounts in Status.BoundServiceAccounts.
+func (rm *resourceManager) syncServiceAccountBindings(
+	ctx context.Context,
+	desired *resource,
+	latest *resource,
+) (err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.syncServiceAccountBindings")
+	defer func() { exit(err) }()
+
+	cs, err := commonutil.KubeClient()
+	if err != nil {
+		return err
+	}
+	return rm.applyServiceAccountBindings(ctx, cs.CoreV1(), desired, latest)
+}
+
+// applyServiceAccountBindings implements syncServiceAccountBindings with the
+// supplied ServiceAccounts client.
+func (rm *resourceManager) applyServiceAccountBindings(
+	ctx context.Context,
+	c corev1client.ServiceAccountsGetter,
+	desired *resource,
+	latest *resource,
+) error {
+	bindings, err := rm.resolveServiceAccountBindings(ctx, desired)
+	if err != nil {
+		return err
+	}
+	roleARN := string(*latest.ko.Status.ACKResourceMetadata.ARN)
+	previous := latest.ko.Status.BoundServiceAccounts
+	bound, conflicts, err := bindServiceAccounts(ctx, c, roleARN, bindings, previous)
+	removed := removedServiceAccountBindings(previous, bound)
+	if err != nil {
+		// Keep track of the previous bindings, so that they can still be
+		// unbound.
+		desired.ko.Status.BoundServiceAccounts = append(bound, removed...)
+		return err
+	}
+	var conflictErr error
+	if len(conflicts) > 0 {
+		conflictErr = fmt.Errorf(
+			"service accounts already annotated with another role were not bound: %s",
+			strings.Join(conflicts, ", "),
+		)
+	}
+	commonutil.SetAdvisoryFromError(desired, serviceAccountBindingConflictReason, conflictErr)
+
+	if err := unbindServiceAccounts(ctx, c, roleARN, removed); err != nil {
+		desired.ko.Status.BoundServiceAccounts = append(bound, removed...)
+		return err
+	}
+	desired.ko.Status.BoundServiceAccounts = bound
+	return nil
+}
+
+// removeServiceAccountBindings removes the annotations set by
+// syncServiceAccountBindings from the ServiceAccounts recorded in
+// Status.BoundServiceAccounts.
+func (rm *resourceManager) removeServiceAccountBindings(
+	ctx context.Context,
+	r *resource,
+) (err error) {
+	if len(r.ko.Status.BoundServiceAccounts) == 0 ||
+		r.ko.Status.ACKResourceMetadata == nil ||
+		r.ko.Status.ACKResourceMetadata.ARN == nil {
+		return nil
+	}
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.removeServiceAccountBindings")
+	defer func() { exit(err) }()
+
+	cs, err := commonutil.KubeClient()
+	if err != nil {
+		return err
+	}
+	return unbindServiceAccounts(
+		ctx, cs.CoreV1(), string(*r.ko.Status.ACKResourceMetadata.ARN),
+		r.ko.Status.BoundServiceAccounts,
+	)
+}
+
+// resolveServiceAccountBindings returns the Spec.ServiceAccountBindings of the
+// supplied Role with their namespace resolved. Binding a ServiceAccount of
+// another namespace is subject to the controller's cross-namespace policy.
+func (rm *resourceManager) resolveServiceAccountBindings(
+	ctx context.Context,
+	r *resource,
+) ([]*svcapitypes.ServiceAccountBinding, error) {
+	bindings := []*svcapitypes.ServiceAccountBinding{}
+	for i, b := range r.ko.Spec.ServiceAccountBindings {
+		if b == nil || b.Name == nil || *b.Name == "" {
+			return nil, ackerr.NewTerminalError(
+				fmt.Errorf("serviceAccountBindings[%d]: name is required", i),
+			)
+		}
+		namespace, err := ackrt.ResolveCrossNamespaceReference(
+			ctx,
+			rm.cfg.EnableCrossNamespace,
+			&r.ko.Status.Conditions,
+			ackrt.CrossNamespaceRefKindResource,
+			r.ko.Namespace,
+			b.Namespace,
+			*b.Name,
+		)
+		if err != nil {
+			return nil, ackerr.NewTerminalError(
+				fmt.Errorf("serviceAccountBindings[%d]: %w", i, err),
+			)
+		}
+		bindings = append(bindings, &svcapitypes.ServiceAccountBinding{
+			Name:                 b.Name,
+			Namespace:            &namespace,
+			STSRegionalEndpoints: b.STSRegionalEndpoints,
+		})
+	}
+	return bindings, nil
+}
+
+// bindServiceAccounts patches the role ARN annotation, and the STS regional
+// endpoints annotation when asked to, onto every ServiceAccount of the
+// supplied resolved bindings, and returns the bindings it applied. The STS
+// regional endpoints annotation is removed from the ServiceAccounts whose
+// previous binding asked for it and whose current one does not.
+//
+// ServiceAccounts that do not exist yet are skipped and picked up on a later
+// reconciliation. ServiceAccounts already annotated with another role are not
+// taken over; they are returned as conflicts, as namespace/name.
+func bindServiceAccounts(
+	ctx context.Context,
+	c corev1client.ServiceAccountsGetter,
+	roleARN string,
+	bindings []*svcapitypes.ServiceAccountBinding,
+	previous []*svcapitypes.ServiceAccountBinding,
+) (bound []*svcapitypes.ServiceAccountBinding, conflicts []string, err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	hadSTSRegionalEndpoints := map[string]bool{}
+	for _, b := range previous {
+		if b != nil && b.Name != nil && b.Namespace != nil {
+			hadSTSRegionalEndpoints[serviceAccountKey(b)] = stsRegionalEndpoints(b)
+		}
+	}
+	bound = []*svcapitypes.ServiceAccountBinding{}
+	for _, b := range bindings {
+		namespace, name := *b.Namespace, *b.Name
+		sa, err := c.ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			rlog.Info("bound service account not found", "namespace", namespace, "name", name)
+			continue
+		}
+		if err != nil {
+			return bound, conflicts, err
+		}
+		if current := sa.Annotations[roleARNAnnotation]; current != "" && current != roleARN {
+			conflicts = append(conflicts, namespace+"/"+name)
+			continue
+		}
+		annotations := map[string]interface{}{}
+		if sa.Annotations[roleARNAnnotation] != roleARN {
+			annotations[roleARNAnnotation] = roleARN
+		}
+		if stsRegionalEndpoints(b) {
+			if sa.Annotations[stsRegionalEndpointsAnnotation] != "true" {
+				annotations[stsRegionalEndpointsAnnotation] = "true"
+			}
+		} else if hadSTSRegionalEndpoints[serviceAccountKey(b)] {
+			annotations[stsRegionalEndpointsAnnotation] = nil
+		}
+		if err := patchServiceAccountAnnotations(ctx, c, namespace, name, annotations); err != nil {
+			return bound, conflicts, err
+		}
+		bound = append(bound, b)
+	}
+	return bound, conflicts, nil
+}
+
+// unbindServiceAccounts removes the annotations set by bindServiceAccounts
+// from the ServiceAccounts of the supplied resolved bindings. ServiceAccounts
+// annotated with a different role ARN are left untouched.
+func unbindServiceAccounts(
+	ctx context.Context,
+	c corev1client.ServiceAccountsGetter,
+	roleARN string,
+	bindings []*svcapitypes.ServiceAccountBinding,
+) error {
+	for _, b := range bindings {
+		if b == nil || b.Name == nil || b.Namespace == nil {
+			continue
+		}
+		namespace, name := *b.Namespace, *b.Name
+		sa, err := c.ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if sa.Annotations[roleARNAnnotation] != roleARN {
+			continue
+		}
+		annotations := map[string]interface{}{roleARNAnnotation: nil}
+		if stsRegionalEndpoints(b) {
+			annotations[stsRegionalEndpointsAnnotation] = nil
+		}
+		if err := patchServiceAccountAnnotations(ctx, c, namespace, name, annotations); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removedServiceAccountBindings returns the previously bound ServiceAccounts
+// that are not bound anymore.
+func removedServiceAccountBindings(
+	previous []*svcapitypes.ServiceAccountBinding,
+	current []*svcapitypes.ServiceAccountBinding,
+) []*svcapitypes.ServiceAccountBinding {
+	kept := map[string]bool{}
+	for _, b := range current {
+		kept[serviceAccountKey(b)] = true
+	}
+	removed := []*svcapitypes.ServiceAccountBinding{}
+	for _, b := range previous {
+		if b != nil && b.Name != nil && b.Namespace != nil && !kept[serviceAccountKey(b)] {
+			removed = append(removed, b)
+		}
+	}
+	return removed
+}
+
+// compareServiceAccountBindings compares the ServiceAccount bindings of the
+// desired resource with the ones recorded as bound in the latest resource.
+// A binding whose ServiceAccount is missing or annotated with another role
+// keeps showing up as a difference, so that it is retried.
+func compareServiceAccountBindings(
+	delta *ackcompare.Delta,
+	a *resource,
+	b *resource,
+) {
+	desired := map[string]bool{}
+	for _, sab := range a.ko.Spec.ServiceAccountBindings {
+		if sab == nil || sab.Name == nil {
+			continue
+		}
+		namespace := a.ko.Namespace
+		if sab.Namespace != nil && *sab.Namespace != "" {
+			namespace = *sab.Namespace
+		}
+		desired[serviceAccountBindingKey(&svcapitypes.ServiceAccountBinding{
+			Name:                 sab.Name,
+			Namespace:            &namespace,
+			STSRegionalEndpoints: sab.STSRegionalEndpoints,
+		})] = true
+	}
+	bound := map[string]bool{}
+	for _, sab := range b.ko.Status.BoundServiceAccounts {
+		if sab != nil && sab.Name != nil && sab.Namespace != nil {
+			bound[serviceAccountBindingKey(sab)] = true
+		}
+	}
+	if len(desired) != len(a.ko.Spec.ServiceAccountBindings) || !reflect.DeepEqual(desired, bound) {
+		delta.Add(
+			"Spec.ServiceAccountBindings",
+			a.ko.Spec.ServiceAccountBindings, b.ko.Status.BoundServiceAccounts,
+		)
+	}
+}
+
+// serviceAccountKey identifies the ServiceAccount of a resolved binding as
+// namespace/name.
+func serviceAccountKey(b *svcapitypes.ServiceAccountBinding) string {
+	return *b.Namespace + "/" + *b.Name
+}
+
+// serviceAccountBindingKey identifies a resolved binding by its
+// ServiceAccount and its STS regional endpoints flag.
+func serviceAccountBindingKey(b *svcapitypes.ServiceAccountBinding) string {
+	return fmt.Sprintf("%s/%t", serviceAccountKey(b), stsRegionalEndpoints(b))
+}
+
+// stsRegionalEndpoints returns true if the supplied binding asks for the STS
+// regional endpoints annotation.
+func stsRegionalEndpoints(b *svcapitypes.ServiceAccountBinding) bool {
+	return b.STSRegionalEndpoints != nil && *b.STSRegionalEndpoints
+}
+
+// patchServiceAccountAnnotations merge-patches the supplied annotations onto
+// a ServiceAccount. A nil value removes the annotation.
+func patchServiceAccountAnnotations(
+	ctx context.Context,
+	c corev1client.ServiceAccountsGetter,
+	namespace string,
+	name string,
+	annotations map[string]interface{},
+) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.ServiceAccounts(namespace).Patch(
+		ctx, name, types.MergePatchType, patch, metav1.PatchOptions{},
+	)
+	return err
+}
@@ -0,0 +1,183 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package role
+
+import (
+	"context"
+	"testing"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+const testRoleARN = "arn:aws:iam::123456789012:role/test-role"
+
+func roleWithServiceAccountBindings(bindings ...*svcapitypes.ServiceAccountBinding) *resource {
+	arn := ackv1alpha1.AWSResourceName(testRoleARN)
+	return &resource{
+		ko: &svcapitypes.Role{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"},
+			Spec: svcapitypes.RoleSpec{
+				Name:                   aws.String("test-role"),
+				ServiceAccountBindings: bindings,
+			},
+			Status: svcapitypes.RoleStatus{
+				ACKResourceMetadata: &ackv1alpha1.ResourceMetadata{ARN: &arn},
+			},
+		},
+	}
+}
+
+func serviceAccount(namespace, name string, annotations map[string]string) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Annotations: annotations,
+		},
+	}
+}
+
+// bindingsOf returns the bound ServiceAccounts of the supplied Role, as
+// namespace/name.
+func bindingsOf(r *resource) []string {
+	keys := []string{}
+	for _, b := range r.ko.Status.BoundServiceAccounts {
+		keys = append(keys, serviceAccountKey(b))
+	}
+	return keys
+}
+
+func getAnnotations(t *testing.T, cs *fake.Clientset, namespace, name string) map[string]string {
+	sa, err := cs.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	return sa.Annotations
+}
+
+func TestApplyServiceAccountBindings(t *testing.T) {
+	ctx := context.TODO()
+	rm := &resourceManager{cfg: ackcfg.Config{EnableCrossNamespace: true}}
+	cs := fake.NewSimpleClientset(
+		serviceAccount("team-a", "app", nil),
+		serviceAccount("team-b", "worker", map[string]string{"keep": "me"}),
+	)
+	desired := roleWithServiceAccountBindings(
+		&svcapitypes.ServiceAccountBinding{Name: aws.String("app")},
+		&svcapitypes.ServiceAccountBinding{
+			Name:                 aws.String("worker"),
+			Namespace:            aws.String("team-b"),
+			STSRegionalEndpoints: aws.Bool(true),
+		},
+		// Not created yet, skipped.
+		&svcapitypes.ServiceAccountBinding{Name: aws.String("missing")},
+	)
+	latest := desired.DeepCopy().(*resource)
+
+	require.NoError(t, rm.applyServiceAccountBindings(ctx, cs.CoreV1(), desired, latest))
+	assert.Equal(t, []string{"team-a/app", "team-b/worker"}, bindingsOf(desired))
+	assert.Equal(t, map[string]string{roleARNAnnotation: testRoleARN}, getAnnotations(t, cs, "team-a", "app"))
+	assert.Equal(t, map[string]string{
+		"keep":                         "me",
+		roleARNAnnotation:              testRoleARN,
+		stsRegionalEndpointsAnnotation: "true",
+	}, getAnnotations(t, cs, "team-b", "worker"))
+	// The missing ServiceAccount is retried.
+	assert.True(t, newResourceDelta(desired, desired).DifferentAt("Spec.ServiceAccountBindings"))
+
+	// Removing an entry removes its annotations.
+	latest = desired.DeepCopy().(*resource)
+	desired.ko.Spec.ServiceAccountBindings = desired.ko.Spec.ServiceAccountBindings[:1]
+	assert.True(t, newResourceDelta(desired, latest).DifferentAt("Spec.ServiceAccountBindings"))
+	require.NoError(t, rm.applyServiceAccountBindings(ctx, cs.CoreV1(), desired, latest))
+	assert.Equal(t, []string{"team-a/app"}, bindingsOf(desired))
+	assert.Equal(t, map[string]string{"keep": "me"}, getAnnotations(t, cs, "team-b", "worker"))
+	assert.False(t, newResourceDelta(desired, desired).DifferentAt("Spec.ServiceAccountBindings"))
+
+	// Deleting the Role removes the remaining annotations.
+	require.NoError(t, unbindServiceAccounts(ctx, cs.CoreV1(), testRoleARN, desired.ko.Status.BoundServiceAccounts))
+	assert.Empty(t, getAnnotations(t, cs, "team-a", "app"))
+}
+
+func TestApplyServiceAccountBindings_STSRegionalEndpointsRemoved(t *testing.T) {
+	ctx := context.TODO()
+	rm := &resourceManager{}
+	cs := fake.NewSimpleClientset(serviceAccount("team-a", "app", nil))
+	desired := roleWithServiceAccountBindings(&svcapitypes.ServiceAccountBinding{
+		Name:                 aws.String("app"),
+		STSRegionalEndpoints: aws.Bool(true),
+	})
+	require.NoError(t, rm.applyServiceAccountBindings(ctx, cs.CoreV1(), desired, desired.DeepCopy().(*resource)))
+
+	latest := desired.DeepCopy().(*resource)
+	desired.ko.Spec.ServiceAccountBindings[0].STSRegionalEndpoints = nil
+	assert.True(t, newResourceDelta(desired, latest).DifferentAt("Spec.ServiceAccountBindings"))
+	require.NoError(t, rm.applyServiceAccountBindings(ctx, cs.CoreV1(), desired, latest))
+	assert.Equal(t, map[string]string{roleARNAnnotation: testRoleARN}, getAnnotations(t, cs, "team-a", "app"))
+}
+
+func TestApplyServiceAccountBindings_OtherRole(t *testing.T) {
+	ctx := context.TODO()
+	rm := &resourceManager{}
+	other := map[string]string{roleARNAnnotation: "arn:aws:iam::123456789012:role/other"}
+	cs := fake.NewSimpleClientset(serviceAccount("team-a", "app", other))
+	desired := roleWithServiceAccountBindings(&svcapitypes.ServiceAccountBinding{Name: aws.String("app")})
+
+	require.NoError(t, rm.applyServiceAccountBindings(ctx, cs.CoreV1(), desired, desired.DeepCopy().(*resource)))
+	assert.Equal(t, other, getAnnotations(t, cs, "team-a", "app"))
+	assert.Empty(t, desired.ko.Status.BoundServiceAccounts)
+	c := ackcondition.AdvisoryWithReason(desired, serviceAccountBindingConflictReason)
+	require.NotNil(t, c)
+	assert.Contains(t, *c.Message, "team-a/app")
+
+	// Unbinding leaves the other role's annotation alone.
+	bindings := []*svcapitypes.ServiceAccountBinding{{Name: aws.String("app"), Namespace: aws.String("team-a")}}
+	require.NoError(t, unbindServiceAccounts(ctx, cs.CoreV1(), testRoleARN, bindings))
+	assert.Equal(t, other, getAnnotations(t, cs, "team-a", "app"))
+
+	// The condition is cleared once the conflict is resolved.
+	_, err := cs.CoreV1().ServiceAccounts("team-a").Update(ctx, serviceAccount("team-a", "app", nil), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, rm.applyServiceAccountBindings(ctx, cs.CoreV1(), desired, desired.DeepCopy().(*resource)))
+	assert.Nil(t, ackcondition.AdvisoryWithReason(desired, serviceAccountBindingConflictReason))
+	assert.Equal(t, []string{"team-a/app"}, bindingsOf(desired))
+}
+
+func TestApplyServiceAccountBindings_CrossNamespace(t *testing.T) {
+	ctx := context.TODO()
+	cs := fake.NewSimpleClientset(serviceAccount("team-b", "worker", nil))
+	desired := roleWithServiceAccountBindings(&svcapitypes.ServiceAccountBinding{
+		Name:      aws.String("worker"),
+		Namespace: aws.String("team-b"),
+	})
+
+	rm := &resourceManager{cfg: ackcfg.Config{EnableCrossNamespace: false}}
+	err := rm.applyServiceAccountBindings(ctx, cs.CoreV1(), desired, desired.DeepCopy().(*resource))
+	var termErr *ackerr.TerminalError
+	assert.ErrorAs(t, err, &termErr)
+	assert.Empty(t, getAnnotations(t, cs, "team-b", "worker"))
+
+	rm = &resourceManager{cfg: ackcfg.Config{EnableCrossNamespace: true}}
+	require.NoError(t, rm.applyServiceAccountBindings(ctx, cs.CoreV1(), desired, desired.DeepCopy().(*resource)))
+	assert.Equal(t, map[string]string{roleARNAnnotation: testRoleARN}, getAnnotations(t, cs, "team-b", "worker"))
+}
@@ -0,0 +1,303 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package role
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	ackcompare "github.com/aws-controllers-k8s/runtime/pkg/compare"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+const (
+	// TrustPresetEKSPodIdentity trusts EKS Pod Identity.
+	TrustPresetEKSPodIdentity = "eksPodIdentity"
+	// TrustPresetEC2 trusts EC2, e.g. for instance profiles.
+	TrustPresetEC2 = "ec2"
+	// TrustPresetLambda trusts Lambda execution.
+	TrustPresetLambda = "lambda"
+	// TrustPresetService trusts the service principal named in the preset's
+	// Service field.
+	TrustPresetService = "service"
+)
+
+const (
+	// trustStatementSidPrefix prefixes the Sid of every trust policy
+	// statement rendered by the controller. Statements carrying it are
+	// dropped from the hand-written document before rendering, so that a
+	// document read back from IAM renders to itself.
+	trustStatementSidPrefix = "ACKTrust"
+	// serviceAccountTrustSidPrefix prefixes the Sid of the statements
+	// rendered from Spec.ServiceAccountTrust.
+	serviceAccountTrustSidPrefix = trustStatementSidPrefix + "ServiceAccount"
+	// trustPresetSidPrefix prefixes the Sid of the statements rendered from
+	// Spec.TrustPresets.
+	trustPresetSidPrefix = trustStatementSidPrefix + "Preset"
+
+	// serviceAccountTrustAudience is the audience projected into IRSA
+	// service account tokens.
+	serviceAccountTrustAudience = "sts.amazonaws.com"
+	// oidcProviderARNResourcePrefix precedes the issuer in the resource
+	// part of an OpenIDConnectProvider ARN.
+	oidcProviderARNResourcePrefix = ":oidc-provider/"
+	// defaultPolicyVersion is the policy language version used when the
+	// trust policy is rendered from scratch.
+	defaultPolicyVersion = "2012-10-17"
+)
+
+// trustStatements returns the trust policy statements rendered from the
+// ServiceAccountTrust and TrustPresets entries of the supplied resource, for a
+// role in the supplied partition.
+func trustStatements(r *resource, partition string) ([]interface{}, error) {
+	statements, err := serviceAccountTrustStatements(r)
+	if err != nil {
+		return nil, err
+	}
+	presets, err := trustPresetStatements(r, partition)
+	if err != nil {
+		return nil, err
+	}
+	return append(statements, presets...), nil
+}
+
+// hasRenderedTrust returns true if the trust policy of the supplied resource
+// contains statements rendered by the controller.
+func hasRenderedTrust(r *resource) bool {
+	return len(r.ko.Spec.ServiceAccountTrust) > 0 || len(r.ko.Spec.TrustPresets) > 0
+}
+
+// serviceAccountTrustStatements returns the trust policy statements rendered
+// from the ServiceAccountTrust entries of the supplied resource.
+func serviceAccountTrustStatements(r *resource) ([]interface{}, error) {
+	statements := []interface{}{}
+	for i, sat := range r.ko.Spec.ServiceAccountTrust {
+		if sat == nil || sat.Name == nil || *sat.Name == "" {
+			return nil, fmt.Errorf("serviceAccountTrust[%d]: name is required", i)
+		}
+		if sat.OIDCProviderARN == nil || *sat.OIDCProviderARN == "" {
+			return nil, fmt.Errorf(
+				"serviceAccountTrust[%d]: one of oidcProviderARN or oidcProviderRef is required", i,
+			)
+		}
+		providerARN := *sat.OIDCProviderARN
+		idx := strings.Index(providerARN, oidcProviderARNResourcePrefix)
+		if !strings.HasPrefix(providerARN, "arn:") || idx < 0 {
+			return nil, fmt.Errorf(
+				"serviceAccountTrust[%d]: %q is not an OpenIDConnectProvider ARN", i, providerARN,
+			)
+		}
+		issuer := providerARN[idx+len(oidcProviderARNResourcePrefix):]
+		namespace := r.ko.Namespace
+		if sat.Namespace != nil && *sat.Namespace != "" {
+			namespace = *sat.Namespace
+		}
+		statements = append(statements, map[string]interface{}{
+			"Sid":    fmt.Sprintf("%s%d", serviceAccountTrustSidPrefix, i),
+			"Effect": "Allow",
+			"Principal": map[string]interface{}{
+				"Federated": providerARN,
+			},
+			"Action": "sts:AssumeRoleWithWebIdentity",
+			"Condition": map[string]interface{}{
+				"StringEquals": map[string]interface{}{
+					issuer + ":sub": fmt.Sprintf("system:serviceaccount:%s:%s", namespace, *sat.Name),
+					issuer + ":aud": serviceAccountTrustAudience,
+				},
+			},
+		})
+	}
+	return statements, nil
+}
+
+// trustPresetStatements returns the trust policy statements rendered from the
+// TrustPresets entries of the supplied resource. The EC2 and Lambda service
+// principals carry the DNS suffix of the supplied partition, while EKS Pod
+// Identity uses pods.eks.amazonaws.com in every partition.
+func trustPresetStatements(r *resource, partition string) ([]interface{}, error) {
+	statements := []interface{}{}
+	dnsSuffix := commonutil.PartitionDNSSuffix(partition)
+	for i, tp := range r.ko.Spec.TrustPresets {
+		if tp == nil || tp.Preset == nil {
+			return nil, fmt.Errorf("trustPresets[%d]: preset is required", i)
+		}
+		service := ""
+		actions := []interface{}{"sts:AssumeRole"}
+		switch *tp.Preset {
+		case TrustPresetEKSPodIdentity:
+			service = "pods.eks.amazonaws.com"
+			// EKS Pod Identity tags the session with the pod's attributes.
+			actions = append(actions, "sts:TagSession")
+		case TrustPresetEC2:
+			service = "ec2." + dnsSuffix
+		case TrustPresetLambda:
+			service = "lambda." + dnsSuffix
+		case TrustPresetService:
+			if tp.Service == nil || *tp.Service == "" {
+				return nil, fmt.Errorf(
+					"trustPresets[%d]: service is required for preset %q", i, TrustPresetService,
+				)
+			}
+			service = *tp.Service
+		default:
+			return nil, fmt.Errorf(
+				"trustPresets[%d]: invalid preset %q, must be one of %q, %q, %q or %q",
+				i, *tp.Preset, TrustPresetEKSPodIdentity, TrustPresetEC2,
+				TrustPresetLambda, TrustPresetService,
+			)
+		}
+		if *tp.Preset != TrustPresetService && tp.Service != nil {
+			return nil, fmt.Errorf(
+				"trustPresets[%d]: service is only supported for preset %q", i, TrustPresetService,
+			)
+		}
+		statement := map[string]interface{}{
+			"Sid":    fmt.Sprintf("%s%d", trustPresetSidPrefix, i),
+			"Effect": "Allow",
+			"Principal": map[string]interface{}{
+				"Service": service,
+			},
+			"Action": actions,
+		}
+		if tp.SourceAccount != nil && *tp.SourceAccount != "" {
+			statement["Condition"] = map[string]interface{}{
+				"StringEquals": map[string]interface{}{
+					"aws:SourceAccount": *tp.SourceAccount,
+				},
+			}
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+// renderAssumeRolePolicyDocument returns the trust policy to send to IAM for
+// the supplied resource: Spec.AssumeRolePolicyDocument merged with the
+// statements rendered from Spec.ServiceAccountTrust and Spec.TrustPresets.
+//
+// Spec.AssumeRolePolicyDocument is overwritten with the document read back
+// from IAM, so it may still hold statements rendered from entries that have
+// since been removed. Those are always dropped, even when there is nothing
+// left to render, so that removing the last entry revokes its trust. A
+// document left without any statement is returned as nil.
+func renderAssumeRolePolicyDocument(r *resource, partition string) (*string, error) {
+	statements, err := trustStatements(r, partition)
+	if err != nil {
+		return nil, err
+	}
+	handWritten := r.ko.Spec.AssumeRolePolicyDocument
+	if handWritten == nil || *handWritten == "" {
+		if len(statements) == 0 {
+			return handWritten, nil
+		}
+		handWritten = nil
+	}
+
+	doc := map[string]interface{}{"Version": defaultPolicyVersion}
+	if handWritten != nil {
+		if err := json.Unmarshal([]byte(*handWritten), &doc); err != nil {
+			if len(statements) == 0 {
+				// Leave it to IAM to report the malformed document.
+				return handWritten, nil
+			}
+			return nil, fmt.Errorf("decoding assumeRolePolicyDocument: %w", err)
+		}
+	}
+	// Statement may be a single object or a list of objects.
+	existing := []interface{}{}
+	switch s := doc["Statement"].(type) {
+	case []interface{}:
+		existing = s
+	case map[string]interface{}:
+		existing = []interface{}{s}
+	}
+	merged := []interface{}{}
+	for _, s := range existing {
+		if m, ok := s.(map[string]interface{}); ok {
+			if sid, ok := m["Sid"].(string); ok && strings.HasPrefix(sid, trustStatementSidPrefix) {
+				continue
+			}
+		}
+		merged = append(merged, s)
+	}
+	if len(statements) == 0 {
+		if len(merged) == len(existing) {
+			return handWritten, nil
+		}
+		if len(merged) == 0 {
+			return nil, nil
+		}
+	}
+	doc["Statement"] = append(merged, statements...)
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	rendered := string(out)
+	return &rendered, nil
+}
+
+// renderedAssumeRolePolicyDocument returns the trust policy to send to IAM
+// for the supplied desired resource, reporting rendering failures as terminal
+// errors since they can only be fixed by changing the spec.
+func renderedAssumeRolePolicyDocument(desired *resource, partition string) (*string, error) {
+	doc, err := renderAssumeRolePolicyDocument(desired, partition)
+	if err != nil {
+		return nil, ackerr.NewTerminalError(err)
+	}
+	if doc == nil {
+		return nil, ackerr.NewTerminalError(fmt.Errorf(
+			"one of assumeRolePolicyDocument, serviceAccountTrust or trustPresets is required",
+		))
+	}
+	return doc, nil
+}
+
+// compareAssumeRolePolicyDocument compares the trust policy rendered from
+// the desired resource with the one IAM returned for the latest resource.
+func compareAssumeRolePolicyDocument(
+	delta *ackcompare.Delta,
+	a *resource,
+	b *resource,
+) {
+	desired, err := renderAssumeRolePolicyDocument(a, resourcePartition(a, b))
+	if err != nil {
+		delta.Add("Spec.AssumeRolePolicyDocument", a.ko.Spec.AssumeRolePolicyDocument, b.ko.Spec.AssumeRolePolicyDocument)
+		return
+	}
+	if ackcompare.HasNilDifference(desired, b.ko.Spec.AssumeRolePolicyDocument) {
+		delta.Add("Spec.AssumeRolePolicyDocument", a.ko.Spec.AssumeRolePolicyDocument, b.ko.Spec.AssumeRolePolicyDocument)
+	} else if desired != nil && b.ko.Spec.AssumeRolePolicyDocument != nil {
+		if equal, err := ackcompare.IAMPolicyDocumentEqual(*desired, *b.ko.Spec.AssumeRolePolicyDocument); err != nil || !equal {
+			delta.Add("Spec.AssumeRolePolicyDocument", a.ko.Spec.AssumeRolePolicyDocument, b.ko.Spec.AssumeRolePolicyDocument)
+		}
+	}
+}
+
+// resourcePartition returns the partition recorded in the status of the
+// latest resource, or of the desired one, for the comparisons that have no
+// resource manager at hand. sdkFind always records it in the latest resource.
+func resourcePartition(desired *resource, latest *resource) string {
+	for _, r := range []*resource{latest, desired} {
+		meta := r.ko.Status.ACKResourceMetadata
+		if meta != nil && meta.Partition != nil && *meta.Partition != "" {
+			return string(*meta.Partition)
+		}
+	}
+	return ""
+}
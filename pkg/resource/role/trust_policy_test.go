@@ -0,0 +1,280 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package role
+
+import (
+	"testing"
+
+	ackcompare "github.com/aws-controllers-k8s/runtime/pkg/compare"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+const testOIDCProviderARN = "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-west-2.amazonaws.com/id/EXAMPLE"
+
+func roleWithServiceAccountTrust(doc *string, trust ...*svcapitypes.ServiceAccountTrust) *resource {
+	return &resource{
+		ko: &svcapitypes.Role{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"},
+			Spec: svcapitypes.RoleSpec{
+				Name:                     aws.String("test-role"),
+				AssumeRolePolicyDocument: doc,
+				ServiceAccountTrust:      trust,
+			},
+		},
+	}
+}
+
+func TestRenderAssumeRolePolicyDocument(t *testing.T) {
+	r := roleWithServiceAccountTrust(nil, &svcapitypes.ServiceAccountTrust{
+		Name:            aws.String("app"),
+		OIDCProviderARN: aws.String(testOIDCProviderARN),
+	})
+
+	got, err := renderAssumeRolePolicyDocument(r, "aws")
+	require.NoError(t, err)
+	want := `{
+		"Version": "2012-10-17",
+		"Statement": [{
+			"Sid": "ACKTrustServiceAccount0",
+			"Effect": "Allow",
+			"Principal": {"Federated": "` + testOIDCProviderARN + `"},
+			"Action": "sts:AssumeRoleWithWebIdentity",
+			"Condition": {"StringEquals": {
+				"oidc.eks.us-west-2.amazonaws.com/id/EXAMPLE:sub": "system:serviceaccount:team-a:app",
+				"oidc.eks.us-west-2.amazonaws.com/id/EXAMPLE:aud": "sts.amazonaws.com"
+			}}
+		}]
+	}`
+	equal, err := ackcompare.IAMPolicyDocumentEqual(want, *got)
+	require.NoError(t, err)
+	assert.True(t, equal, *got)
+}
+
+func TestRenderAssumeRolePolicyDocument_Merge(t *testing.T) {
+	handWritten := `{"Version":"2012-10-17","Statement":{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole"}}`
+	r := roleWithServiceAccountTrust(aws.String(handWritten), &svcapitypes.ServiceAccountTrust{
+		Name:            aws.String("app"),
+		Namespace:       aws.String("team-b"),
+		OIDCProviderARN: aws.String(testOIDCProviderARN),
+	})
+
+	got, err := renderAssumeRolePolicyDocument(r, "aws")
+	require.NoError(t, err)
+	assert.Contains(t, *got, `"ec2.amazonaws.com"`)
+	assert.Contains(t, *got, `system:serviceaccount:team-b:app`)
+
+	// A document read back from IAM renders to itself.
+	r.ko.Spec.AssumeRolePolicyDocument = got
+	again, err := renderAssumeRolePolicyDocument(r, "aws")
+	require.NoError(t, err)
+	equal, err := ackcompare.IAMPolicyDocumentEqual(*got, *again)
+	require.NoError(t, err)
+	assert.True(t, equal)
+}
+
+func TestRenderAssumeRolePolicyDocument_RemoveLastServiceAccountTrust(t *testing.T) {
+	handWritten := `{"Version":"2012-10-17","Statement":{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole"}}`
+	r := roleWithServiceAccountTrust(aws.String(handWritten), &svcapitypes.ServiceAccountTrust{
+		Name:            aws.String("app"),
+		OIDCProviderARN: aws.String(testOIDCProviderARN),
+	})
+	merged, err := renderAssumeRolePolicyDocument(r, "aws")
+	require.NoError(t, err)
+
+	// The merged document is read back from IAM into the spec, then the
+	// last entry is removed.
+	r.ko.Spec.AssumeRolePolicyDocument = merged
+	r.ko.Spec.ServiceAccountTrust = nil
+	got, err := renderAssumeRolePolicyDocument(r, "aws")
+	require.NoError(t, err)
+	equal, err := ackcompare.IAMPolicyDocumentEqual(handWritten, *got)
+	require.NoError(t, err)
+	assert.True(t, equal, *got)
+
+	latest := roleWithServiceAccountTrust(merged)
+	assert.True(t, newResourceDelta(r, latest).DifferentAt("Spec.AssumeRolePolicyDocument"))
+
+	// Without a hand-written document, nothing is left to trust.
+	r = roleWithServiceAccountTrust(nil, &svcapitypes.ServiceAccountTrust{
+		Name:            aws.String("app"),
+		OIDCProviderARN: aws.String(testOIDCProviderARN),
+	})
+	merged, err = renderAssumeRolePolicyDocument(r, "aws")
+	require.NoError(t, err)
+	r.ko.Spec.AssumeRolePolicyDocument = merged
+	r.ko.Spec.ServiceAccountTrust = nil
+	got, err = renderAssumeRolePolicyDocument(r, "aws")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	_, err = renderedAssumeRolePolicyDocument(r, "aws")
+	assert.Error(t, err)
+}
+
+func TestRenderAssumeRolePolicyDocument_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		trust *svcapitypes.ServiceAccountTrust
+	}{
+		{
+			name:  "missing name",
+			trust: &svcapitypes.ServiceAccountTrust{OIDCProviderARN: aws.String(testOIDCProviderARN)},
+		},
+		{
+			name:  "missing provider",
+			trust: &svcapitypes.ServiceAccountTrust{Name: aws.String("app")},
+		},
+		{
+			name: "not an OIDC provider ARN",
+			trust: &svcapitypes.ServiceAccountTrust{
+				Name:            aws.String("app"),
+				OIDCProviderARN: aws.String("arn:aws:iam::123456789012:role/app"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := renderAssumeRolePolicyDocument(roleWithServiceAccountTrust(nil, tt.trust), "aws")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewResourceDelta_ServiceAccountTrust(t *testing.T) {
+	desired := roleWithServiceAccountTrust(nil, &svcapitypes.ServiceAccountTrust{
+		Name:            aws.String("app"),
+		OIDCProviderARN: aws.String(testOIDCProviderARN),
+	})
+	rendered, err := renderAssumeRolePolicyDocument(desired, "aws")
+	require.NoError(t, err)
+
+	latest := roleWithServiceAccountTrust(rendered, desired.ko.Spec.ServiceAccountTrust...)
+	assert.False(t, newResourceDelta(desired, latest).DifferentAt("Spec.AssumeRolePolicyDocument"))
+
+	desired.ko.Spec.ServiceAccountTrust[0].Name = aws.String("other")
+	assert.True(t, newResourceDelta(desired, latest).DifferentAt("Spec.AssumeRolePolicyDocument"))
+}
+
+func TestRenderAssumeRolePolicyDocument_TrustPresets(t *testing.T) {
+	r := roleWithServiceAccountTrust(nil)
+	r.ko.Spec.TrustPresets = []*svcapitypes.TrustPreset{
+		{Preset: aws.String(TrustPresetEKSPodIdentity)},
+		{
+			Preset:        aws.String(TrustPresetService),
+			Service:       aws.String("states.amazonaws.com"),
+			SourceAccount: aws.String("123456789012"),
+		},
+	}
+
+	got, err := renderAssumeRolePolicyDocument(r, "aws")
+	require.NoError(t, err)
+	want := `{
+		"Version": "2012-10-17",
+		"Statement": [{
+			"Sid": "ACKTrustPreset0",
+			"Effect": "Allow",
+			"Principal": {"Service": "pods.eks.amazonaws.com"},
+			"Action": ["sts:AssumeRole", "sts:TagSession"]
+		}, {
+			"Sid": "ACKTrustPreset1",
+			"Effect": "Allow",
+			"Principal": {"Service": "states.amazonaws.com"},
+			"Action": ["sts:AssumeRole"],
+			"Condition": {"StringEquals": {"aws:SourceAccount": "123456789012"}}
+		}]
+	}`
+	equal, err := ackcompare.IAMPolicyDocumentEqual(want, *got)
+	require.NoError(t, err)
+	assert.True(t, equal, *got)
+}
+
+func TestRenderAssumeRolePolicyDocument_TrustPresetsPartition(t *testing.T) {
+	r := roleWithServiceAccountTrust(nil)
+	r.ko.Spec.TrustPresets = []*svcapitypes.TrustPreset{
+		{Preset: aws.String(TrustPresetEC2)},
+		{Preset: aws.String(TrustPresetLambda)},
+	}
+
+	got, err := renderAssumeRolePolicyDocument(r, "aws-cn")
+	require.NoError(t, err)
+	want := `{
+		"Version": "2012-10-17",
+		"Statement": [{
+			"Sid": "ACKTrustPreset0",
+			"Effect": "Allow",
+			"Principal": {"Service": "ec2.amazonaws.com.cn"},
+			"Action": ["sts:AssumeRole"]
+		}, {
+			"Sid": "ACKTrustPreset1",
+			"Effect": "Allow",
+			"Principal": {"Service": "lambda.amazonaws.com.cn"},
+			"Action": ["sts:AssumeRole"]
+		}]
+	}`
+	equal, err := ackcompare.IAMPolicyDocumentEqual(want, *got)
+	require.NoError(t, err)
+	assert.True(t, equal, *got)
+}
+
+func TestRenderAssumeRolePolicyDocument_RemoveLastTrustPreset(t *testing.T) {
+	handWritten := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::123456789012:root"},"Action":"sts:AssumeRole"}]}`
+	r := roleWithServiceAccountTrust(aws.String(handWritten))
+	r.ko.Spec.TrustPresets = []*svcapitypes.TrustPreset{{Preset: aws.String(TrustPresetEC2)}}
+	merged, err := renderAssumeRolePolicyDocument(r, "aws")
+	require.NoError(t, err)
+	assert.Contains(t, *merged, `"ec2.amazonaws.com"`)
+
+	r.ko.Spec.AssumeRolePolicyDocument = merged
+	r.ko.Spec.TrustPresets = nil
+	got, err := renderAssumeRolePolicyDocument(r, "aws")
+	require.NoError(t, err)
+	equal, err := ackcompare.IAMPolicyDocumentEqual(handWritten, *got)
+	require.NoError(t, err)
+	assert.True(t, equal, *got)
+}
+
+func TestRenderAssumeRolePolicyDocument_InvalidTrustPresets(t *testing.T) {
+	tests := []struct {
+		name   string
+		preset *svcapitypes.TrustPreset
+	}{
+		{
+			name:   "unknown preset",
+			preset: &svcapitypes.TrustPreset{Preset: aws.String("ecs")},
+		},
+		{
+			name:   "service preset without service",
+			preset: &svcapitypes.TrustPreset{Preset: aws.String(TrustPresetService)},
+		},
+		{
+			name: "service on a fixed preset",
+			preset: &svcapitypes.TrustPreset{
+				Preset:  aws.String(TrustPresetLambda),
+				Service: aws.String("states.amazonaws.com"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := roleWithServiceAccountTrust(nil)
+			r.ko.Spec.TrustPresets = []*svcapitypes.TrustPreset{tt.preset}
+			_, err := renderAssumeRolePolicyDocument(r, "aws")
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package service_linked_role
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	ackrequeue "github.com/aws-controllers-k8s/runtime/pkg/requeue"
+	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/smithy-go"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	// errDeletionInProgress is returned while IAM deletes a service-linked
+	// role.
+	errDeletionInProgress = errors.New("service-linked role deletion in progress")
+	// deletionTaskRequeueDelay is the delay between two polls of the status
+	// of a deletion task.
+	deletionTaskRequeueDelay = 10 * time.Second
+	// deletionRetryDelay is the delay before the deletion of a service-linked
+	// role is retried after its deletion task failed.
+	deletionRetryDelay = time.Minute
+)
+
+// deletionTaskFailedReason is the reason of the ACK.Recoverable condition
+// describing why the deletion task of a service-linked role failed.
+const deletionTaskFailedReason = "DeletionTaskFailed"
+
+// awaitDeletionTask polls the status of the task deleting the supplied
+// service-linked role, recorded in Status.DeletionTaskID. It requeues the
+// resource until the task succeeds. When the task fails, the reasons IAM
+// reports, such as the resources still using the role, are set in an
+// ACK.Recoverable condition with the deletionTaskFailedReason reason and the
+// deletion is retried.
+func (rm *resourceManager) awaitDeletionTask(
+	ctx context.Context,
+	r *resource,
+) (latest *resource, err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.awaitDeletionTask")
+	defer func() {
+		exit(err)
+	}()
+
+	resp, err := rm.sdkapi.GetServiceLinkedRoleDeletionStatus(
+		ctx,
+		&svcsdk.GetServiceLinkedRoleDeletionStatusInput{
+			DeletionTaskId: r.ko.Status.DeletionTaskID,
+		},
+	)
+	rm.metrics.RecordAPICall("READ_ONE", "GetServiceLinkedRoleDeletionStatus", err)
+	if err != nil {
+		var awsErr smithy.APIError
+		if errors.As(err, &awsErr) && awsErr.ErrorCode() == "NoSuchEntity" {
+			// The task has expired; delete the role again.
+			ko := r.ko.DeepCopy()
+			ko.Status.DeletionTaskID = nil
+			return &resource{ko}, ackrequeue.NeededAfter(errDeletionInProgress, deletionTaskRequeueDelay)
+		}
+		return r, err
+	}
+
+	switch resp.Status {
+	case svcsdktypes.DeletionTaskStatusTypeSucceeded:
+		return nil, nil
+	case svcsdktypes.DeletionTaskStatusTypeFailed:
+		ko := r.ko.DeepCopy()
+		ko.Status.DeletionTaskID = nil
+		message := fmt.Sprintf("service-linked role deletion failed: %s", deletionFailureReason(resp.Reason))
+		ackcondition.SetRecoverable(&resource{ko}, corev1.ConditionTrue, &message, aws.String(deletionTaskFailedReason))
+		return &resource{ko}, ackrequeue.NeededAfter(errors.New(message), deletionRetryDelay)
+	default:
+		return r, ackrequeue.NeededAfter(errDeletionInProgress, deletionTaskRequeueDelay)
+	}
+}
+
+// deletionFailureReason returns a description of the supplied reason of a
+// failed deletion task, listing the resources still using the role per
+// region.
+func deletionFailureReason(reason *svcsdktypes.DeletionTaskFailureReasonType) string {
+	if reason == nil {
+		return "no reason reported"
+	}
+	parts := []string{}
+	if reason.Reason != nil && *reason.Reason != "" {
+		parts = append(parts, *reason.Reason)
+	}
+	for _, usage := range reason.RoleUsageList {
+		region := "unknown region"
+		if usage.Region != nil {
+			region = *usage.Region
+		}
+		parts = append(parts, fmt.Sprintf(
+			"used in %s by %s", region, strings.Join(usage.Resources, ", "),
+		))
+	}
+	if len(parts) == 0 {
+		return "no reason reported"
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,90 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package service_linked_role
+
+import (
+	"context"
+	"testing"
+
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackmetrics "github.com/aws-controllers-k8s/runtime/pkg/metrics"
+	ackrequeue "github.com/aws-controllers-k8s/runtime/pkg/requeue"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+)
+
+func TestDeletionFailureReason(t *testing.T) {
+	assert.Equal(t, "no reason reported", deletionFailureReason(nil))
+	assert.Equal(t, "no reason reported", deletionFailureReason(&svcsdktypes.DeletionTaskFailureReasonType{}))
+
+	reason := &svcsdktypes.DeletionTaskFailureReasonType{
+		Reason: aws.String("role in use"),
+		RoleUsageList: []svcsdktypes.RoleUsageType{
+			{
+				Region:    aws.String("us-west-2"),
+				Resources: []string{"arn:aws:ecs:us-west-2:123456789012:cluster/a", "arn:aws:ecs:us-west-2:123456789012:cluster/b"},
+			},
+			{Resources: []string{"arn:aws:ecs:eu-west-1:123456789012:cluster/c"}},
+		},
+	}
+	assert.Equal(t,
+		"role in use; "+
+			"used in us-west-2 by arn:aws:ecs:us-west-2:123456789012:cluster/a, arn:aws:ecs:us-west-2:123456789012:cluster/b; "+
+			"used in unknown region by arn:aws:ecs:eu-west-1:123456789012:cluster/c",
+		deletionFailureReason(reason),
+	)
+}
+
+func TestResourceManager_DeletionTaskFailed(t *testing.T) {
+	backend := fakeiam.New()
+	rm, err := newResourceManager(
+		ackcfg.Config{}, backend.Config(), logr.Discard(), ackmetrics.NewMetrics("iam"),
+		nil, fakeiam.AccountID, fakeiam.Region,
+	)
+	require.NoError(t, err)
+	backend.FailDeletionTask("task/aws-service-role/ecs.amazonaws.com/AWSServiceRoleForECS/1", &svcsdktypes.DeletionTaskFailureReasonType{
+		RoleUsageList: []svcsdktypes.RoleUsageType{
+			{Region: aws.String("us-west-2"), Resources: []string{"arn:aws:ecs:us-west-2:123456789012:cluster/a"}},
+		},
+	})
+
+	res, err := rm.Delete(context.TODO(), &resource{&svcapitypes.ServiceLinkedRole{
+		Spec: svcapitypes.ServiceLinkedRoleSpec{AWSServiceName: aws.String("ecs.amazonaws.com")},
+		Status: svcapitypes.ServiceLinkedRoleStatus{
+			DeletionTaskID: aws.String("task/aws-service-role/ecs.amazonaws.com/AWSServiceRoleForECS/1"),
+		},
+	}})
+	var requeueErr *ackrequeue.RequeueNeededAfter
+	require.ErrorAs(t, err, &requeueErr)
+	assert.Equal(t, deletionRetryDelay, requeueErr.Duration())
+	require.NotNil(t, res)
+	assert.Nil(t, rm.concreteResource(res).ko.Status.DeletionTaskID)
+
+	cond := ackcondition.Recoverable(res)
+	require.NotNil(t, cond)
+	assert.Equal(t, corev1.ConditionTrue, cond.Status)
+	assert.Equal(t, deletionTaskFailedReason, aws.ToString(cond.Reason))
+	assert.Equal(t,
+		"service-linked role deletion failed: used in us-west-2 by arn:aws:ecs:us-west-2:123456789012:cluster/a",
+		aws.ToString(cond.Message),
+	)
+}
@@ -40,6 +40,7 @@ func newResourceDelta(
 		delta.Add("", a, b)
 		return delta
 	}
+	compareTags(delta, a, b)
 
 	if ackcompare.HasNilDifference(a.ko.Spec.AWSServiceName, b.ko.Spec.AWSServiceName) {
 		delta.Add("Spec.AWSServiceName", a.ko.Spec.AWSServiceName, b.ko.Spec.AWSServiceName)
@@ -3,12 +3,17 @@ package service_linked_role
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
 	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
 	ackcompare "github.com/aws-controllers-k8s/runtime/pkg/compare"
 	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackrt "github.com/aws-controllers-k8s/runtime/pkg/runtime"
 	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/smithy-go"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,6 +29,22 @@ func (rm *resourceManager) customGetServiceLinkedRole(
 		exit(err)
 	}()
 
+	// A service-linked role that AWS created on its own, or that was created
+	// outside of the controller, is only adopted by its service name when
+	// the adoption-policy annotation asks for it. Otherwise the ServiceLinkedRole
+	// creates its role and never takes over, or deletes, one it did not create.
+	if r.ko.Status.RoleName == nil && r.ko.Spec.AWSServiceName != nil && adoptsExistingRole(r) {
+		roleName, err := rm.findServiceLinkedRoleName(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		if roleName != nil {
+			ko := r.ko.DeepCopy()
+			ko.Status.RoleName = roleName
+			r = &resource{ko}
+		}
+	}
+
 	// If any required fields in the input shape are missing, AWS resource is
 	// not created yet. Return NotFound here to indicate to callers that the
 	// resource isn't yet created.
@@ -96,6 +117,15 @@ func (rm *resourceManager) customGetServiceLinkedRole(
 	} else {
 		ko.Status.RoleName = nil
 	}
+	if resp.Role.Tags != nil {
+		tags := []*svcapitypes.Tag{}
+		for _, t := range resp.Role.Tags {
+			tags = append(tags, &svcapitypes.Tag{Key: t.Key, Value: t.Value})
+		}
+		ko.Spec.Tags = tags
+	} else {
+		ko.Spec.Tags = nil
+	}
 
 	rm.setStatusDefaults(ko)
 
@@ -140,6 +170,17 @@ func (rm *resourceManager) customUpdateServiceLinkedRole(
 	defer func() {
 		exit(err)
 	}()
+	if delta.DifferentAt("Spec.Tags") {
+		if err = rm.syncTags(ctx, desired, latest); err != nil {
+			return nil, err
+		}
+	}
+	if !delta.DifferentExcept("Spec.Tags") {
+		ko := desired.ko.DeepCopy()
+		rm.setStatusDefaults(ko)
+		return &resource{ko}, nil
+	}
+
 	input, err := rm.newUpdateRequestPayload(ctx, desired, delta)
 	if err != nil {
 		return nil, err
@@ -177,3 +218,94 @@ func (rm *resourceManager) newUpdateRequestPayload(
 
 	return res, nil
 }
+
+// adoptsExistingRole returns true if the supplied ServiceLinkedRole carries
+// the adoption-policy annotation, asking to adopt an existing role.
+func adoptsExistingRole(r *resource) bool {
+	policy, err := ackrt.GetAdoptionPolicy(r)
+	return err == nil && policy != ""
+}
+
+// findServiceLinkedRoleName returns the name of the existing service-linked
+// role for the service and custom suffix of the supplied resource, or nil if
+// there is none. Service-linked roles live under the
+// /aws-service-role/<service principal>/ path, and a custom suffix is
+// appended to their name after an underscore.
+func (rm *resourceManager) findServiceLinkedRoleName(
+	ctx context.Context,
+	r *resource,
+) (roleName *string, err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.findServiceLinkedRoleName")
+	defer func() {
+		exit(err)
+	}()
+
+	input := &svcsdk.ListRolesInput{
+		PathPrefix: aws.String(serviceLinkedRolePath(*r.ko.Spec.AWSServiceName)),
+	}
+	paginator := svcsdk.NewListRolesPaginator(rm.sdkapi, input)
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		rm.metrics.RecordAPICall("READ_MANY", "ListRoles", err)
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range resp.Roles {
+			if role.RoleName != nil && serviceLinkedRoleNameMatches(*role.RoleName, r.ko.Spec.CustomSuffix) {
+				return role.RoleName, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// serviceLinkedRolePath returns the path of the service-linked roles of the
+// supplied service principal.
+func serviceLinkedRolePath(awsServiceName string) string {
+	return fmt.Sprintf("/aws-service-role/%s/", awsServiceName)
+}
+
+// serviceLinkedRoleNameMatches returns true if the supplied service-linked
+// role name carries the supplied custom suffix, or no suffix at all when
+// customSuffix is nil.
+func serviceLinkedRoleNameMatches(roleName string, customSuffix *string) bool {
+	if customSuffix == nil || *customSuffix == "" {
+		return !strings.Contains(roleName, "_")
+	}
+	return strings.HasSuffix(roleName, "_"+*customSuffix)
+}
+
+// syncTags calls the TagRole and UntagRole APIs to ensure that the set of
+// Tags associated with the service-linked role stays in sync with the
+// ServiceLinkedRole.Spec.Tags
+func (rm *resourceManager) syncTags(
+	ctx context.Context,
+	desired *resource,
+	latest *resource,
+) (err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.syncTags")
+	defer func() { exit(err) }()
+
+	return commonutil.SyncTags(
+		ctx, rm.sdkapi, rm.metrics, commonutil.ARNResourceTypeRole,
+		latest.ko.Status.RoleName, desired.ko.Spec.Tags, latest.ko.Spec.Tags,
+	)
+}
+
+// compareTags is a custom comparison function for comparing lists of Tag
+// structs where the order of the structs in the list is not important.
+func compareTags(
+	delta *ackcompare.Delta,
+	a *resource,
+	b *resource,
+) {
+	if len(a.ko.Spec.Tags) != len(b.ko.Spec.Tags) {
+		delta.Add("Spec.Tags", a.ko.Spec.Tags, b.ko.Spec.Tags)
+	} else if len(a.ko.Spec.Tags) > 0 {
+		if !commonutil.EqualTags(a.ko.Spec.Tags, b.ko.Spec.Tags) {
+			delta.Add("Spec.Tags", a.ko.Spec.Tags, b.ko.Spec.Tags)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package service_linked_role
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+func TestServiceLinkedRoleNameMatches(t *testing.T) {
+	assert.Equal(t, "/aws-service-role/autoscaling.amazonaws.com/", serviceLinkedRolePath("autoscaling.amazonaws.com"))
+
+	assert.True(t, serviceLinkedRoleNameMatches("AWSServiceRoleForAutoScaling", nil))
+	assert.True(t, serviceLinkedRoleNameMatches("AWSServiceRoleForAutoScaling", aws.String("")))
+	assert.False(t, serviceLinkedRoleNameMatches("AWSServiceRoleForAutoScaling_team-a", nil))
+	assert.True(t, serviceLinkedRoleNameMatches("AWSServiceRoleForAutoScaling_team-a", aws.String("team-a")))
+	assert.False(t, serviceLinkedRoleNameMatches("AWSServiceRoleForAutoScaling_team-b", aws.String("team-a")))
+	assert.False(t, serviceLinkedRoleNameMatches("AWSServiceRoleForAutoScaling", aws.String("team-a")))
+}
+
+func TestPopulateResourceFromAnnotation(t *testing.T) {
+	r := &resource{&svcapitypes.ServiceLinkedRole{}}
+	require.NoError(t, r.PopulateResourceFromAnnotation(map[string]string{
+		"roleName": "AWSServiceRoleForAutoScaling",
+	}))
+	assert.Equal(t, "AWSServiceRoleForAutoScaling", *r.ko.Status.RoleName)
+
+	// The role name is looked up from the service name when it is omitted.
+	r = &resource{&svcapitypes.ServiceLinkedRole{}}
+	require.NoError(t, r.PopulateResourceFromAnnotation(map[string]string{
+		"awsServiceName": "autoscaling.amazonaws.com",
+	}))
+	assert.Equal(t, "autoscaling.amazonaws.com", *r.ko.Spec.AWSServiceName)
+	assert.Nil(t, r.ko.Status.RoleName)
+
+	r = &resource{&svcapitypes.ServiceLinkedRole{}}
+	assert.Error(t, r.PopulateResourceFromAnnotation(map[string]string{}))
+}
+
+func TestCompareTags(t *testing.T) {
+	a := &resource{&svcapitypes.ServiceLinkedRole{Spec: svcapitypes.ServiceLinkedRoleSpec{
+		Tags: []*svcapitypes.Tag{
+			{Key: aws.String("team"), Value: aws.String("a")},
+			{Key: aws.String("env"), Value: aws.String("prod")},
+		},
+	}}}
+	b := a.ko.DeepCopy()
+	b.Spec.Tags[0], b.Spec.Tags[1] = b.Spec.Tags[1], b.Spec.Tags[0]
+	assert.False(t, newResourceDelta(a, &resource{b}).DifferentAt("Spec.Tags"))
+
+	b.Spec.Tags[0].Value = aws.String("dev")
+	assert.True(t, newResourceDelta(a, &resource{b}).DifferentAt("Spec.Tags"))
+}
@@ -0,0 +1,76 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package service_linked_role
+
+import (
+	"context"
+	"testing"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackmetrics "github.com/aws-controllers-k8s/runtime/pkg/metrics"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+)
+
+func newTestResourceManager(t *testing.T) *resourceManager {
+	rm, err := newResourceManager(
+		ackcfg.Config{}, fakeiam.New().Config(), logr.Discard(), ackmetrics.NewMetrics("iam"),
+		nil, fakeiam.AccountID, fakeiam.Region,
+	)
+	require.NoError(t, err)
+	return rm
+}
+
+func TestResourceManager_AdoptByServiceName(t *testing.T) {
+	ctx := context.TODO()
+	rm := newTestResourceManager(t)
+
+	// A role AWS created on its own when the service was first used.
+	_, err := rm.sdkapi.CreateServiceLinkedRole(ctx, &svcsdk.CreateServiceLinkedRoleInput{
+		AWSServiceName: aws.String("autoscaling.amazonaws.com"),
+	})
+	require.NoError(t, err)
+
+	// Without the adoption-policy annotation, the existing role is not
+	// taken over, and creating another one fails.
+	desired := &resource{&svcapitypes.ServiceLinkedRole{
+		Spec: svcapitypes.ServiceLinkedRoleSpec{AWSServiceName: aws.String("autoscaling.amazonaws.com")},
+	}}
+	_, err = rm.ReadOne(ctx, desired)
+	assert.Equal(t, ackerr.NotFound, err)
+	res, err := rm.Create(ctx, desired)
+	require.Error(t, err)
+	require.NotNil(t, ackcondition.Terminal(res))
+	assert.Contains(t, *ackcondition.Terminal(res).Message, "has been taken")
+
+	desired.ko.Annotations = map[string]string{ackv1alpha1.AnnotationAdoptionPolicy: "adopt"}
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest := rm.concreteResource(res)
+	assert.Equal(t, "AWSServiceRoleForAutoscaling", *latest.ko.Status.RoleName)
+
+	// Roles with another custom suffix are not adopted.
+	desired.ko.Spec.CustomSuffix = aws.String("team-a")
+	_, err = rm.ReadOne(ctx, desired)
+	assert.Equal(t, ackerr.NotFound, err)
+}
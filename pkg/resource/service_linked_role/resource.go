@@ -98,9 +98,16 @@ func (r *resource) SetIdentifiers(identifier *ackv1alpha1.AWSIdentifiers) error
 
 // PopulateResourceFromAnnotation populates the fields passed from adoption annotation
 func (r *resource) PopulateResourceFromAnnotation(fields map[string]string) error {
+	if tmp, ok := fields["awsServiceName"]; ok {
+		r.ko.Spec.AWSServiceName = &tmp
+	}
 	tmp, ok := fields["roleName"]
 	if !ok {
-		return ackerrors.NewTerminalError(fmt.Errorf("required field missing: roleName"))
+		// The role is then looked up by its service name.
+		if r.ko.Spec.AWSServiceName != nil {
+			return nil
+		}
+		return ackerrors.NewTerminalError(fmt.Errorf("required field missing: roleName or awsServiceName"))
 	}
 	r.ko.Status.RoleName = &tmp
 
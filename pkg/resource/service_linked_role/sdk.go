@@ -167,12 +167,19 @@ func (rm *resourceManager) sdkCreate(
 			}
 			f10 = append(f10, f10elem)
 		}
-		ko.Status.Tags = f10
+		ko.Spec.Tags = f10
 	} else {
-		ko.Status.Tags = nil
+		ko.Spec.Tags = nil
 	}
 
 	rm.setStatusDefaults(ko)
+	if len(desired.ko.Spec.Tags) > 0 {
+		if err = rm.syncTags(ctx, desired, &resource{ko}); err != nil {
+			return nil, err
+		}
+		ko.Spec.Tags = desired.ko.Spec.Tags
+	}
+
 	return &resource{ko}, nil
 }
 
@@ -218,6 +225,10 @@ func (rm *resourceManager) sdkDelete(
 	defer func() {
 		exit(err)
 	}()
+	if r.ko.Status.DeletionTaskID != nil {
+		return rm.awaitDeletionTask(ctx, r)
+	}
+
 	input, err := rm.newDeleteRequestPayload(r)
 	if err != nil {
 		return nil, err
@@ -226,6 +237,11 @@ func (rm *resourceManager) sdkDelete(
 	_ = resp
 	resp, err = rm.sdkapi.DeleteServiceLinkedRole(ctx, input)
 	rm.metrics.RecordAPICall("DELETE", "DeleteServiceLinkedRole", err)
+	if err == nil && resp.DeletionTaskId != nil {
+		ko := r.ko.DeepCopy()
+		ko.Status.DeletionTaskID = resp.DeletionTaskId
+		return &resource{ko}, ackrequeue.NeededAfter(errDeletionInProgress, deletionTaskRequeueDelay)
+	}
 	return nil, err
 }
 
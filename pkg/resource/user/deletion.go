@@ -0,0 +1,378 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	smithy "github.com/aws/smithy-go"
+	corev1 "k8s.io/api/core/v1"
+
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+const (
+	// DeletionCleanupPoliciesOnly is the default deletion cleanup. Only the
+	// managed and inline policies of the User are removed before it is
+	// deleted.
+	DeletionCleanupPoliciesOnly = "policiesOnly"
+	// DeletionCleanupFull makes the controller remove everything IAM
+	// requires to be gone before DeleteUser: the login profile, access keys,
+	// signing certificates, SSH public keys, service-specific credentials,
+	// MFA devices, policies and group memberships.
+	DeletionCleanupFull = "full"
+)
+
+// virtualMFADeviceSerialMarker identifies the serial numbers of virtual MFA
+// devices, which are ARNs, as opposed to those of hardware devices.
+const virtualMFADeviceSerialMarker = ":mfa/"
+
+// deletionCleanupIsFull returns true if everything blocking DeleteUser is to
+// be removed before the supplied User is deleted. An unknown deletion cleanup
+// is reported as a terminal error.
+func deletionCleanupIsFull(r *resource) (bool, error) {
+	if r.ko.Spec.DeletionCleanup == nil {
+		return false, nil
+	}
+	switch *r.ko.Spec.DeletionCleanup {
+	case "", DeletionCleanupPoliciesOnly:
+		return false, nil
+	case DeletionCleanupFull:
+		return true, nil
+	default:
+		return false, ackerr.NewTerminalError(fmt.Errorf(
+			"invalid deletionCleanup %q, must be one of %q or %q",
+			*r.ko.Spec.DeletionCleanup, DeletionCleanupPoliciesOnly, DeletionCleanupFull,
+		))
+	}
+}
+
+// cleanupBeforeDeletion removes the managed and inline policies of the
+// supplied User. With the full deletion cleanup, the User's credentials, MFA
+// devices and group memberships are removed too, in the order documented for
+// deleting an IAM user. An Event is recorded for each removal and every
+// failing step is reported in an error naming it.
+func (rm *resourceManager) cleanupBeforeDeletion(
+	ctx context.Context,
+	r *resource,
+) (err error) {
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("rm.cleanupBeforeDeletion")
+	defer func() { exit(err) }()
+
+	full, err := deletionCleanupIsFull(r)
+	if err != nil {
+		return err
+	}
+	if full {
+		if err = rm.deleteLoginProfile(ctx, r); err != nil {
+			return fmt.Errorf("deleting login profile: %w", err)
+		}
+		if err = rm.deleteAccessKeys(ctx, r); err != nil {
+			return fmt.Errorf("deleting access keys: %w", err)
+		}
+		if err = rm.deleteSigningCertificates(ctx, r); err != nil {
+			return fmt.Errorf("deleting signing certificates: %w", err)
+		}
+		if err = rm.deleteSSHPublicKeys(ctx, r); err != nil {
+			return fmt.Errorf("deleting SSH public keys: %w", err)
+		}
+		if err = rm.deleteServiceSpecificCredentials(ctx, r); err != nil {
+			return fmt.Errorf("deleting service-specific credentials: %w", err)
+		}
+		if err = rm.deleteMFADevices(ctx, r); err != nil {
+			return fmt.Errorf("deleting MFA devices: %w", err)
+		}
+	}
+
+	userCpy := r.ko.DeepCopy()
+	userCpy.Spec.InlinePolicies = map[string]*string{}
+	if err = rm.syncInlinePolicies(ctx, &resource{ko: userCpy}, r); err != nil {
+		return fmt.Errorf("deleting inline policies: %w", err)
+	}
+	userCpy.Spec.Policies = nil
+	userCpy.Spec.AWSManagedPolicies = nil
+	if err = rm.syncManagedPolicies(ctx, &resource{ko: userCpy}, r); err != nil {
+		return fmt.Errorf("detaching managed policies: %w", err)
+	}
+
+	if full {
+		if err = rm.removeFromGroups(ctx, r); err != nil {
+			return fmt.Errorf("removing group memberships: %w", err)
+		}
+	}
+	return nil
+}
+
+// deleteLoginProfile calls the DeleteLoginProfile API for the supplied User.
+// A User without a console password is not an error.
+func (rm *resourceManager) deleteLoginProfile(
+	ctx context.Context,
+	r *resource,
+) error {
+	_, err := rm.sdkapi.DeleteLoginProfile(ctx, &svcsdk.DeleteLoginProfileInput{
+		UserName: r.ko.Spec.Name,
+	})
+	rm.metrics.RecordAPICall("DELETE", "DeleteLoginProfile", err)
+	if isNoSuchEntity(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	recordUserEvent(ctx, r, "LoginProfileDeleted", "Deleted console password")
+	return nil
+}
+
+// deleteAccessKeys deletes every access key of the supplied User.
+func (rm *resourceManager) deleteAccessKeys(
+	ctx context.Context,
+	r *resource,
+) error {
+	ids := []string{}
+	paginator := svcsdk.NewListAccessKeysPaginator(rm.sdkapi, &svcsdk.ListAccessKeysInput{
+		UserName: r.ko.Spec.Name,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		rm.metrics.RecordAPICall("READ_MANY", "ListAccessKeys", err)
+		if err != nil {
+			return err
+		}
+		for _, k := range page.AccessKeyMetadata {
+			ids = append(ids, *k.AccessKeyId)
+		}
+	}
+	for _, id := range ids {
+		_, err := rm.sdkapi.DeleteAccessKey(ctx, &svcsdk.DeleteAccessKeyInput{
+			UserName:    r.ko.Spec.Name,
+			AccessKeyId: &id,
+		})
+		rm.metrics.RecordAPICall("DELETE", "DeleteAccessKey", err)
+		if err != nil {
+			return err
+		}
+		recordUserEvent(ctx, r, "AccessKeyDeleted", "Deleted access key %s", id)
+	}
+	return nil
+}
+
+// deleteSigningCertificates deletes every signing certificate of the
+// supplied User.
+func (rm *resourceManager) deleteSigningCertificates(
+	ctx context.Context,
+	r *resource,
+) error {
+	ids := []string{}
+	paginator := svcsdk.NewListSigningCertificatesPaginator(rm.sdkapi, &svcsdk.ListSigningCertificatesInput{
+		UserName: r.ko.Spec.Name,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		rm.metrics.RecordAPICall("READ_MANY", "ListSigningCertificates", err)
+		if err != nil {
+			return err
+		}
+		for _, c := range page.Certificates {
+			ids = append(ids, *c.CertificateId)
+		}
+	}
+	for _, id := range ids {
+		_, err := rm.sdkapi.DeleteSigningCertificate(ctx, &svcsdk.DeleteSigningCertificateInput{
+			UserName:      r.ko.Spec.Name,
+			CertificateId: &id,
+		})
+		rm.metrics.RecordAPICall("DELETE", "DeleteSigningCertificate", err)
+		if err != nil {
+			return err
+		}
+		recordUserEvent(ctx, r, "SigningCertificateDeleted", "Deleted signing certificate %s", id)
+	}
+	return nil
+}
+
+// deleteSSHPublicKeys deletes every SSH public key of the supplied User.
+func (rm *resourceManager) deleteSSHPublicKeys(
+	ctx context.Context,
+	r *resource,
+) error {
+	ids := []string{}
+	paginator := svcsdk.NewListSSHPublicKeysPaginator(rm.sdkapi, &svcsdk.ListSSHPublicKeysInput{
+		UserName: r.ko.Spec.Name,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		rm.metrics.RecordAPICall("READ_MANY", "ListSSHPublicKeys", err)
+		if err != nil {
+			return err
+		}
+		for _, k := range page.SSHPublicKeys {
+			ids = append(ids, *k.SSHPublicKeyId)
+		}
+	}
+	for _, id := range ids {
+		_, err := rm.sdkapi.DeleteSSHPublicKey(ctx, &svcsdk.DeleteSSHPublicKeyInput{
+			UserName:       r.ko.Spec.Name,
+			SSHPublicKeyId: &id,
+		})
+		rm.metrics.RecordAPICall("DELETE", "DeleteSSHPublicKey", err)
+		if err != nil {
+			return err
+		}
+		recordUserEvent(ctx, r, "SSHPublicKeyDeleted", "Deleted SSH public key %s", id)
+	}
+	return nil
+}
+
+// deleteServiceSpecificCredentials deletes every service-specific credential,
+// such as CodeCommit Git credentials, of the supplied User.
+func (rm *resourceManager) deleteServiceSpecificCredentials(
+	ctx context.Context,
+	r *resource,
+) error {
+	resp, err := rm.sdkapi.ListServiceSpecificCredentials(ctx, &svcsdk.ListServiceSpecificCredentialsInput{
+		UserName: r.ko.Spec.Name,
+	})
+	rm.metrics.RecordAPICall("READ_MANY", "ListServiceSpecificCredentials", err)
+	if err != nil {
+		return err
+	}
+	for _, c := range resp.ServiceSpecificCredentials {
+		_, err = rm.sdkapi.DeleteServiceSpecificCredential(ctx, &svcsdk.DeleteServiceSpecificCredentialInput{
+			UserName:                    r.ko.Spec.Name,
+			ServiceSpecificCredentialId: c.ServiceSpecificCredentialId,
+		})
+		rm.metrics.RecordAPICall("DELETE", "DeleteServiceSpecificCredential", err)
+		if err != nil {
+			return err
+		}
+		recordUserEvent(ctx, r, "ServiceSpecificCredentialDeleted",
+			"Deleted %s credential %s", *c.ServiceName, *c.ServiceSpecificCredentialId)
+	}
+	return nil
+}
+
+// deleteMFADevices deactivates every MFA device of the supplied User, and
+// deletes the virtual ones.
+func (rm *resourceManager) deleteMFADevices(
+	ctx context.Context,
+	r *resource,
+) error {
+	serials := []string{}
+	paginator := svcsdk.NewListMFADevicesPaginator(rm.sdkapi, &svcsdk.ListMFADevicesInput{
+		UserName: r.ko.Spec.Name,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		rm.metrics.RecordAPICall("READ_MANY", "ListMFADevices", err)
+		if err != nil {
+			return err
+		}
+		for _, d := range page.MFADevices {
+			serials = append(serials, *d.SerialNumber)
+		}
+	}
+	for _, serial := range serials {
+		_, err := rm.sdkapi.DeactivateMFADevice(ctx, &svcsdk.DeactivateMFADeviceInput{
+			UserName:     r.ko.Spec.Name,
+			SerialNumber: &serial,
+		})
+		rm.metrics.RecordAPICall("UPDATE", "DeactivateMFADevice", err)
+		if err != nil {
+			return err
+		}
+		recordUserEvent(ctx, r, "MFADeviceDeactivated", "Deactivated MFA device %s", serial)
+		if !isVirtualMFADevice(serial) {
+			continue
+		}
+		_, err = rm.sdkapi.DeleteVirtualMFADevice(ctx, &svcsdk.DeleteVirtualMFADeviceInput{
+			SerialNumber: &serial,
+		})
+		rm.metrics.RecordAPICall("DELETE", "DeleteVirtualMFADevice", err)
+		if err != nil {
+			return err
+		}
+		recordUserEvent(ctx, r, "MFADeviceDeleted", "Deleted virtual MFA device %s", serial)
+	}
+	return nil
+}
+
+// removeFromGroups removes the supplied User from every group it belongs to.
+func (rm *resourceManager) removeFromGroups(
+	ctx context.Context,
+	r *resource,
+) error {
+	groups := []string{}
+	paginator := svcsdk.NewListGroupsForUserPaginator(rm.sdkapi, &svcsdk.ListGroupsForUserInput{
+		UserName: r.ko.Spec.Name,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		rm.metrics.RecordAPICall("READ_MANY", "ListGroupsForUser", err)
+		if err != nil {
+			return err
+		}
+		for _, g := range page.Groups {
+			groups = append(groups, *g.GroupName)
+		}
+	}
+	for _, name := range groups {
+		_, err := rm.sdkapi.RemoveUserFromGroup(ctx, &svcsdk.RemoveUserFromGroupInput{
+			UserName:  r.ko.Spec.Name,
+			GroupName: &name,
+		})
+		rm.metrics.RecordAPICall("DELETE", "RemoveUserFromGroup", err)
+		if err != nil {
+			return err
+		}
+		recordUserEvent(ctx, r, "RemovedFromGroup", "Removed user from group %s", name)
+	}
+	return nil
+}
+
+// isVirtualMFADevice returns true if the supplied MFA device serial number is
+// the ARN of a virtual MFA device.
+func isVirtualMFADevice(serial string) bool {
+	return strings.HasPrefix(serial, "arn:") && strings.Contains(serial, virtualMFADeviceSerialMarker)
+}
+
+// isNoSuchEntity returns true if the supplied error is an IAM NoSuchEntity
+// error.
+func isNoSuchEntity(err error) bool {
+	var awsErr smithy.APIError
+	return errors.As(err, &awsErr) && awsErr.ErrorCode() == "NoSuchEntity"
+}
+
+// recordUserEvent records a Normal Event about the supplied User. Failing to
+// get an event recorder is logged and otherwise ignored.
+func recordUserEvent(
+	ctx context.Context,
+	r *resource,
+	reason string,
+	format string,
+	args ...interface{},
+) {
+	recorder, err := commonutil.EventRecorder()
+	if err != nil {
+		ackrtlog.FromContext(ctx).Info("unable to record event", "reason", reason, "error", err.Error())
+		return
+	}
+	recorder.Eventf(r.ko, corev1.EventTypeNormal, reason, format, args...)
+}
@@ -0,0 +1,180 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package user
+
+import (
+	"context"
+	"testing"
+
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackmetrics "github.com/aws-controllers-k8s/runtime/pkg/metrics"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+const testPolicyDocument = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`
+
+func newTestResourceManager(t *testing.T) *resourceManager {
+	rm, err := newResourceManager(
+		ackcfg.Config{}, fakeiam.New().Config(), logr.Discard(), ackmetrics.NewMetrics("iam"),
+		nil, fakeiam.AccountID, fakeiam.Region,
+	)
+	require.NoError(t, err)
+	return rm
+}
+
+func userWithCleanup(cleanup *string) *resource {
+	return &resource{
+		ko: &svcapitypes.User{
+			Spec: svcapitypes.UserSpec{
+				Name:            aws.String("test-user"),
+				DeletionCleanup: cleanup,
+			},
+		},
+	}
+}
+
+func TestDeletionCleanupIsFull(t *testing.T) {
+	for _, tc := range []struct {
+		cleanup *string
+		full    bool
+	}{
+		{nil, false},
+		{aws.String(""), false},
+		{aws.String(DeletionCleanupPoliciesOnly), false},
+		{aws.String(DeletionCleanupFull), true},
+	} {
+		full, err := deletionCleanupIsFull(userWithCleanup(tc.cleanup))
+		assert.NoError(t, err)
+		assert.Equal(t, tc.full, full)
+	}
+
+	_, err := deletionCleanupIsFull(userWithCleanup(aws.String("everything")))
+	var termErr *ackerr.TerminalError
+	assert.ErrorAs(t, err, &termErr)
+}
+
+func TestIsVirtualMFADevice(t *testing.T) {
+	assert.True(t, isVirtualMFADevice("arn:aws:iam::123456789012:mfa/alice"))
+	assert.False(t, isVirtualMFADevice("GAHT12345678"))
+	assert.False(t, isVirtualMFADevice("arn:aws:iam::123456789012:u2f/user/alice/key"))
+}
+
+func TestResourceManager_DeleteWithFullCleanup(t *testing.T) {
+	ctx := context.TODO()
+	rm := newTestResourceManager(t)
+	recorder := record.NewFakeRecorder(20)
+	commonutil.SetEventRecorder(recorder)
+
+	name := aws.String("test-user")
+	_, err := rm.sdkapi.CreateUser(ctx, &svcsdk.CreateUserInput{UserName: name})
+	require.NoError(t, err)
+	_, err = rm.sdkapi.CreateLoginProfile(ctx, &svcsdk.CreateLoginProfileInput{
+		UserName: name,
+		Password: aws.String("correct-horse-battery-staple"),
+	})
+	require.NoError(t, err)
+	key, err := rm.sdkapi.CreateAccessKey(ctx, &svcsdk.CreateAccessKeyInput{UserName: name})
+	require.NoError(t, err)
+	cert, err := rm.sdkapi.UploadSigningCertificate(ctx, &svcsdk.UploadSigningCertificateInput{
+		UserName:        name,
+		CertificateBody: aws.String("-----BEGIN CERTIFICATE-----"),
+	})
+	require.NoError(t, err)
+	sshKey, err := rm.sdkapi.UploadSSHPublicKey(ctx, &svcsdk.UploadSSHPublicKeyInput{
+		UserName:         name,
+		SSHPublicKeyBody: aws.String("ssh-rsa AAAA"),
+	})
+	require.NoError(t, err)
+	cred, err := rm.sdkapi.CreateServiceSpecificCredential(ctx, &svcsdk.CreateServiceSpecificCredentialInput{
+		UserName:    name,
+		ServiceName: aws.String("codecommit.amazonaws.com"),
+	})
+	require.NoError(t, err)
+	mfa, err := rm.sdkapi.CreateVirtualMFADevice(ctx, &svcsdk.CreateVirtualMFADeviceInput{
+		VirtualMFADeviceName: aws.String("test-user"),
+	})
+	require.NoError(t, err)
+	serial := mfa.VirtualMFADevice.SerialNumber
+	_, err = rm.sdkapi.EnableMFADevice(ctx, &svcsdk.EnableMFADeviceInput{
+		UserName:            name,
+		SerialNumber:        serial,
+		AuthenticationCode1: aws.String("123456"),
+		AuthenticationCode2: aws.String("654321"),
+	})
+	require.NoError(t, err)
+	_, err = rm.sdkapi.CreateGroup(ctx, &svcsdk.CreateGroupInput{GroupName: aws.String("test-group")})
+	require.NoError(t, err)
+	_, err = rm.sdkapi.AddUserToGroup(ctx, &svcsdk.AddUserToGroupInput{
+		UserName:  name,
+		GroupName: aws.String("test-group"),
+	})
+	require.NoError(t, err)
+	_, err = rm.sdkapi.PutUserPolicy(ctx, &svcsdk.PutUserPolicyInput{
+		UserName:       name,
+		PolicyName:     aws.String("s3"),
+		PolicyDocument: aws.String(testPolicyDocument),
+	})
+	require.NoError(t, err)
+
+	// IAM refuses to delete the user until everything is removed, which only
+	// the full deletion cleanup does. As the runtime does, the user is read
+	// before it is deleted.
+	latest, err := rm.ReadOne(ctx, userWithCleanup(nil))
+	require.NoError(t, err)
+	_, err = rm.Delete(ctx, latest)
+	assert.ErrorContains(t, err, "DeleteConflict")
+	// Draining the events of the inline policy deletion.
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+
+	latest, err = rm.ReadOne(ctx, userWithCleanup(aws.String(DeletionCleanupFull)))
+	require.NoError(t, err)
+	_, err = rm.Delete(ctx, latest)
+	require.NoError(t, err)
+	_, err = rm.sdkapi.GetUser(ctx, &svcsdk.GetUserInput{UserName: name})
+	assert.True(t, isNoSuchEntity(err))
+	// The virtual MFA device is deleted with the user.
+	_, err = rm.sdkapi.DeleteVirtualMFADevice(ctx, &svcsdk.DeleteVirtualMFADeviceInput{SerialNumber: serial})
+	assert.True(t, isNoSuchEntity(err))
+	members, err := rm.sdkapi.GetGroup(ctx, &svcsdk.GetGroupInput{GroupName: aws.String("test-group")})
+	require.NoError(t, err)
+	assert.Empty(t, members.Users)
+
+	events := []string{}
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	assert.Equal(t, []string{
+		"Normal LoginProfileDeleted Deleted console password",
+		"Normal AccessKeyDeleted Deleted access key " + *key.AccessKey.AccessKeyId,
+		"Normal SigningCertificateDeleted Deleted signing certificate " + *cert.Certificate.CertificateId,
+		"Normal SSHPublicKeyDeleted Deleted SSH public key " + *sshKey.SSHPublicKey.SSHPublicKeyId,
+		"Normal ServiceSpecificCredentialDeleted Deleted codecommit.amazonaws.com credential " +
+			*cred.ServiceSpecificCredential.ServiceSpecificCredentialId,
+		"Normal MFADeviceDeactivated Deactivated MFA device " + *serial,
+		"Normal MFADeviceDeleted Deleted virtual MFA device " + *serial,
+		"Normal RemovedFromGroup Removed user from group test-group",
+	}, events)
+}
@@ -43,6 +43,13 @@ func newResourceDelta(
 	}
 	compareTags(delta, a, b)
 
+	if len(a.ko.Spec.AWSManagedPolicies) != len(b.ko.Spec.AWSManagedPolicies) {
+		delta.Add("Spec.AWSManagedPolicies", a.ko.Spec.AWSManagedPolicies, b.ko.Spec.AWSManagedPolicies)
+	} else if len(a.ko.Spec.AWSManagedPolicies) > 0 {
+		if !ackcompare.SliceStringPEqual(a.ko.Spec.AWSManagedPolicies, b.ko.Spec.AWSManagedPolicies) {
+			delta.Add("Spec.AWSManagedPolicies", a.ko.Spec.AWSManagedPolicies, b.ko.Spec.AWSManagedPolicies)
+		}
+	}
 	if len(a.ko.Spec.InlinePolicies) != len(b.ko.Spec.InlinePolicies) {
 		delta.Add("Spec.InlinePolicies", a.ko.Spec.InlinePolicies, b.ko.Spec.InlinePolicies)
 	} else if len(a.ko.Spec.InlinePolicies) > 0 {
@@ -15,13 +15,14 @@ package user
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 
 	ackcompare "github.com/aws-controllers-k8s/runtime/pkg/compare"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
 	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
 	ackutil "github.com/aws-controllers-k8s/runtime/pkg/util"
 	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
-	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/samber/lo"
 
 	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
@@ -94,16 +95,23 @@ func (rm *resourceManager) syncManagedPolicies(
 	toAdd := []*string{}
 	toDelete := []*string{}
 
-	existingPolicies := latest.ko.Spec.Policies
+	desiredPolicies, err := rm.managedPolicyARNs(ctx, desired.ko)
+	if err != nil {
+		return err
+	}
+	existingPolicies, err := rm.managedPolicyARNs(ctx, latest.ko)
+	if err != nil {
+		return err
+	}
 
-	for _, p := range desired.ko.Spec.Policies {
+	for _, p := range desiredPolicies {
 		if !ackutil.InStringPs(*p, existingPolicies) {
 			toAdd = append(toAdd, p)
 		}
 	}
 
 	for _, p := range existingPolicies {
-		if !ackutil.InStringPs(*p, desired.ko.Spec.Policies) {
+		if !ackutil.InStringPs(*p, desiredPolicies) {
 			toDelete = append(toDelete, p)
 		}
 	}
@@ -309,6 +317,37 @@ func (rm *resourceManager) getInlinePolicies(
 	return res, nil
 }
 
+// policyVariables returns the values of the ${ack:...} variables of the
+// inline policy documents of the supplied User.
+func (rm *resourceManager) policyVariables(r *resource) commonutil.PolicyVariables {
+	return commonutil.NewPolicyVariables(
+		string(rm.awsAccountID), string(rm.awsPartition), string(rm.awsRegion), r.ko,
+	)
+}
+
+// setRenderedInlinePolicies records the inline policy documents rendered from
+// the declared User in Status.RenderedInlinePolicies and, for every inline
+// policy read from IAM that matches its rendering, keeps the declared
+// document in the Spec so that its variables are not reported as a
+// difference.
+func (rm *resourceManager) setRenderedInlinePolicies(
+	declared *resource,
+	ko *svcapitypes.User,
+) {
+	rendered, err := commonutil.RenderPolicyDocuments(
+		declared.ko.Spec.InlinePolicies, rm.policyVariables(declared),
+	)
+	if err != nil {
+		// The error is reported when the inline policies are synced.
+		ko.Status.RenderedInlinePolicies = nil
+		return
+	}
+	ko.Status.RenderedInlinePolicies = rendered
+	ko.Spec.InlinePolicies = commonutil.DeclaredPolicyDocuments(
+		declared.ko.Spec.InlinePolicies, rendered, ko.Spec.InlinePolicies,
+	)
+}
+
 // addInlinePolicy adds the supplied inline Policy to the supplied User
 // resource
 func (rm *resourceManager) addInlinePolicy(
@@ -328,6 +367,9 @@ func (rm *resourceManager) addInlinePolicy(
 	if err != nil {
 		return err
 	}
+	if cleanedDoc, err = commonutil.RenderPolicyDocument(cleanedDoc, rm.policyVariables(r)); err != nil {
+		return ackerr.NewTerminalError(fmt.Errorf("inline policy %q: %w", policyName, err))
+	}
 	input.PolicyDocument = &cleanedDoc
 	_, err = rm.sdkapi.PutUserPolicy(ctx, input)
 	rm.metrics.RecordAPICall("UPDATE", "PutUserPolicy", err)
@@ -369,9 +411,8 @@ func compareTags(
 	}
 }
 
-// syncTags examines the Tags in the supplied User and calls the ListUserTags,
-// TagUser and UntagUser APIs to ensure that the set of associated Tags  stays
-// in sync with the User.Spec.Tags
+// syncTags calls the TagUser and UntagUser APIs to ensure that the set of
+// Tags associated with the User stays in sync with the User.Spec.Tags
 func (rm *resourceManager) syncTags(
 	ctx context.Context,
 	desired *resource,
@@ -379,62 +420,12 @@ func (rm *resourceManager) syncTags(
 ) (err error) {
 	rlog := ackrtlog.FromContext(ctx)
 	exit := rlog.Trace("rm.syncTags")
-	defer func() {
-		exit(err)
-	}()
-	toAdd := []*svcapitypes.Tag{}
-	toDelete := []*svcapitypes.Tag{}
-
-	existingTags := latest.ko.Spec.Tags
-
-	for _, t := range desired.ko.Spec.Tags {
-		if !inTags(*t.Key, *t.Value, existingTags) {
-			toAdd = append(toAdd, t)
-		}
-	}
-
-	for _, t := range existingTags {
-		if !inTags(*t.Key, *t.Value, desired.ko.Spec.Tags) {
-			toDelete = append(toDelete, t)
-		}
-	}
-
-	if len(toAdd) > 0 {
-		for _, t := range toAdd {
-			rlog.Debug("adding tag to user", "key", *t.Key, "value", *t.Value)
-		}
-		if err = rm.addTags(ctx, desired, toAdd); err != nil {
-			return err
-		}
-	}
-	if len(toDelete) > 0 {
-		for _, t := range toDelete {
-			rlog.Debug("removing tag from user", "key", *t.Key, "value", *t.Value)
-		}
-		if err = rm.removeTags(ctx, desired, toDelete); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
+	defer func() { exit(err) }()
 
-// inTags returns true if the supplied key and value can be found in the
-// supplied list of Tag structs.
-//
-// TODO(jaypipes): When we finally standardize Tag handling in ACK, move this
-// to the ACK common runtime/ or pkg/ repos
-func inTags(
-	key string,
-	value string,
-	tags []*svcapitypes.Tag,
-) bool {
-	for _, t := range tags {
-		if *t.Key == key && *t.Value == value {
-			return true
-		}
-	}
-	return false
+	return commonutil.SyncTags(
+		ctx, rm.sdkapi, rm.metrics, commonutil.ARNResourceTypeUser,
+		desired.ko.Spec.Name, desired.ko.Spec.Tags, latest.ko.Spec.Tags,
+	)
 }
 
 // getTags returns the list of tags to the User
@@ -470,54 +461,96 @@ func (rm *resourceManager) getTags(
 	return res, err
 }
 
-// addTags adds the supplied Tags to the supplied User resource
-func (rm *resourceManager) addTags(
-	ctx context.Context,
-	r *resource,
-	tags []*svcapitypes.Tag,
-) (err error) {
-	rlog := ackrtlog.FromContext(ctx)
-	exit := rlog.Trace("rm.addTag")
-	defer func() { exit(err) }()
+func decodeDocument(encoded string) (string, error) {
+	return url.QueryUnescape(encoded)
+}
 
-	input := &svcsdk.TagUserInput{}
-	input.UserName = r.ko.Spec.Name
-	inTags := []svcsdktypes.Tag{}
-	for _, t := range tags {
-		inTags = append(inTags, svcsdktypes.Tag{Key: t.Key, Value: t.Value})
+// syncServiceLastAccessed refreshes the service last accessed (Access
+// Advisor) report in the status of the supplied User. It is best-effort:
+// failures are reported as a condition, and the report is left alone while
+// the User is being deleted.
+func (rm *resourceManager) syncServiceLastAccessed(
+	ctx context.Context,
+	ko *svcapitypes.User,
+) {
+	if ko.DeletionTimestamp != nil ||
+		ko.Status.ACKResourceMetadata == nil || ko.Status.ACKResourceMetadata.ARN == nil {
+		return
 	}
-	input.Tags = inTags
-
-	_, err = rm.sdkapi.TagUser(ctx, input)
-	rm.metrics.RecordAPICall("UPDATE", "TagUser", err)
-	return err
+	report, err := commonutil.SyncServiceLastAccessed(
+		ctx, rm.sdkapi, rm.metrics, string(*ko.Status.ACKResourceMetadata.ARN),
+		ko.Spec.ServiceLastAccessedGranularity, ko.Status.ServiceLastAccessed,
+	)
+	if err != nil {
+		ackrtlog.FromContext(ctx).Debug("failed to refresh service last accessed report", "error", err)
+	}
+	ko.Status.ServiceLastAccessed = report
+	commonutil.SetServiceLastAccessedConditions(&resource{ko}, report, err)
 }
 
-// removeTags removes the supplied Tags from the supplied User resource
-func (rm *resourceManager) removeTags(
+// declarePolicyShards adds to Spec.Policies of the supplied declared User the
+// shards of the Policies it references in Spec.PolicyRefs, so that they are
+// attached and detached along with them.
+func (rm *resourceManager) declarePolicyShards(
 	ctx context.Context,
-	r *resource,
-	tags []*svcapitypes.Tag,
-) (err error) {
-	rlog := ackrtlog.FromContext(ctx)
-	exit := rlog.Trace("rm.removeTag")
-	defer func() {
-		exit(err)
-	}()
-
-	input := &svcsdk.UntagUserInput{}
-	input.UserName = r.ko.Spec.Name
-	inTagKeys := []string{}
-	for _, t := range tags {
-		inTagKeys = append(inTagKeys, *t.Key)
+	ko *svcapitypes.User,
+) error {
+	policies, err := commonutil.AppendPolicyShardARNs(
+		ctx, ko.Namespace, ko.Spec.PolicyRefs, ko.Spec.Policies,
+	)
+	if err != nil {
+		return err
 	}
-	input.TagKeys = inTagKeys
+	ko.Spec.Policies = policies
+	return nil
+}
 
-	_, err = rm.sdkapi.UntagUser(ctx, input)
-	rm.metrics.RecordAPICall("UPDATE", "UntagUser", err)
-	return err
+// managedPolicyARNs returns the ARNs of the managed policies the supplied
+// User declares, both in Spec.Policies and, by name, in
+// Spec.AWSManagedPolicies.
+func (rm *resourceManager) managedPolicyARNs(
+	ctx context.Context,
+	ko *svcapitypes.User,
+) ([]*string, error) {
+	if len(ko.Spec.AWSManagedPolicies) == 0 {
+		return ko.Spec.Policies, nil
+	}
+	arns, err := commonutil.AWSManagedPolicyARNs(
+		ctx, rm.sdkapi, rm.metrics, string(rm.awsPartition), ko.Spec.AWSManagedPolicies,
+	)
+	if err != nil {
+		return nil, err
+	}
+	res := append([]*string{}, ko.Spec.Policies...)
+	for _, arn := range arns {
+		if !ackutil.InStringPs(*arn, res) {
+			res = append(res, arn)
+		}
+	}
+	return res, nil
 }
 
-func decodeDocument(encoded string) (string, error) {
-	return url.QueryUnescape(encoded)
+// splitAWSManagedPolicies moves the policy ARNs read into Spec.Policies that
+// the User declares by name into Spec.AWSManagedPolicies, so that both fields
+// of the latest state compare against the desired ones. When the names
+// cannot be resolved, none are moved and the error surfaces on update.
+func (rm *resourceManager) splitAWSManagedPolicies(
+	ctx context.Context,
+	ko *svcapitypes.User,
+) {
+	names := ko.Spec.AWSManagedPolicies
+	ko.Spec.AWSManagedPolicies = nil
+	if len(names) == 0 {
+		return
+	}
+	arns, err := commonutil.AWSManagedPolicyARNs(
+		ctx, rm.sdkapi, rm.metrics, string(rm.awsPartition), names,
+	)
+	if err != nil {
+		ackrtlog.FromContext(ctx).Info("unable to resolve AWS managed policies", "error", err.Error())
+		return
+	}
+	ko.Spec.Policies, ko.Spec.AWSManagedPolicies = commonutil.SplitAWSManagedPolicies(
+		ko.Spec.Policies, names, arns,
+	)
 }
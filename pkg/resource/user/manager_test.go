@@ -0,0 +1,94 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package user
+
+import (
+	"context"
+	"testing"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+	commonutil "github.com/aws-controllers-k8s/iam-controller/pkg/util"
+)
+
+func TestResourceManager_Lifecycle(t *testing.T) {
+	ctx := context.TODO()
+	rm := newTestResourceManager(t)
+	commonutil.SetEventRecorder(record.NewFakeRecorder(10))
+
+	desired := &resource{ko: &svcapitypes.User{
+		Spec: svcapitypes.UserSpec{
+			Name:               aws.String("test-user"),
+			AWSManagedPolicies: []*string{aws.String("ReadOnlyAccess")},
+			InlinePolicies:     map[string]*string{"s3": aws.String(testPolicyDocument)},
+			Tags:               []*svcapitypes.Tag{{Key: aws.String("team"), Value: aws.String("a")}},
+		},
+	}}
+	res, err := rm.Create(ctx, desired)
+	require.NoError(t, err)
+	created := rm.concreteResource(res)
+	assert.Equal(
+		t,
+		ackv1alpha1.AWSResourceName("arn:aws:iam::"+fakeiam.AccountID+":user/test-user"),
+		*created.ko.Status.ACKResourceMetadata.ARN,
+	)
+
+	// The policies are attached by the Update following the creation, as
+	// the reconciler would do.
+	desired.SetStatus(created)
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest := rm.concreteResource(res)
+	_, err = rm.Update(ctx, desired, latest, newResourceDelta(desired, latest))
+	require.NoError(t, err)
+
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest = rm.concreteResource(res)
+	assert.Equal(t, []*string{aws.String("ReadOnlyAccess")}, latest.ko.Spec.AWSManagedPolicies)
+	require.Contains(t, latest.ko.Spec.InlinePolicies, "s3")
+	assert.JSONEq(t, testPolicyDocument, *latest.ko.Spec.InlinePolicies["s3"])
+	assert.Equal(t, "a", *latest.ko.Spec.Tags[0].Value)
+
+	// The policies are detached and the tag updated in place.
+	desired = rm.concreteResource(latest.DeepCopy())
+	desired.ko.Spec.AWSManagedPolicies = nil
+	desired.ko.Spec.InlinePolicies = nil
+	desired.ko.Spec.Tags = []*svcapitypes.Tag{{Key: aws.String("team"), Value: aws.String("b")}}
+	_, err = rm.Update(ctx, desired, latest, newResourceDelta(desired, latest))
+	require.NoError(t, err)
+
+	res, err = rm.ReadOne(ctx, desired)
+	require.NoError(t, err)
+	latest = rm.concreteResource(res)
+	assert.Empty(t, latest.ko.Spec.AWSManagedPolicies)
+	assert.Empty(t, latest.ko.Spec.Policies)
+	assert.Empty(t, latest.ko.Spec.InlinePolicies)
+	require.Len(t, latest.ko.Spec.Tags, 1)
+	assert.Equal(t, "b", *latest.ko.Spec.Tags[0].Value)
+	delta := newResourceDelta(desired, latest)
+	assert.Empty(t, delta.Differences)
+
+	_, err = rm.Delete(ctx, latest)
+	require.NoError(t, err)
+	_, err = rm.ReadOne(ctx, latest)
+	assert.Equal(t, ackerr.NotFound, err)
+}
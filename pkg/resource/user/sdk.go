@@ -63,6 +63,11 @@ func (rm *resourceManager) sdkFind(
 	defer func() {
 		exit(err)
 	}()
+	// The shards of the Policies referenced in PolicyRefs are declared, and
+	// attached, along with them.
+	if err = rm.declarePolicyShards(ctx, r.ko); err != nil {
+		return nil, err
+	}
 	// If any required fields in the input shape are missing, AWS resource is
 	// not created yet. Return NotFound here to indicate to callers that the
 	// resource isn't yet created.
@@ -150,15 +155,18 @@ func (rm *resourceManager) sdkFind(
 	} else {
 		ko.Spec.Policies = policies
 	}
+	rm.splitAWSManagedPolicies(ctx, ko)
 	ko.Spec.InlinePolicies, err = rm.getInlinePolicies(ctx, &resource{ko})
 	if err != nil {
 		return nil, err
 	}
+	rm.setRenderedInlinePolicies(r, ko)
 	if tags, err := rm.getTags(ctx, &resource{ko}); err != nil {
 		return nil, err
 	} else {
 		ko.Spec.Tags = tags
 	}
+	rm.syncServiceLastAccessed(ctx, ko)
 
 	return &resource{ko}, nil
 }
@@ -324,7 +332,7 @@ func (rm *resourceManager) sdkUpdate(
 	defer func() {
 		exit(err)
 	}()
-	if delta.DifferentAt("Spec.Policies") {
+	if delta.DifferentAt("Spec.Policies") || delta.DifferentAt("Spec.AWSManagedPolicies") {
 		err = rm.syncManagedPolicies(ctx, desired, latest)
 		if err != nil {
 			return nil, err
@@ -348,7 +356,7 @@ func (rm *resourceManager) sdkUpdate(
 			return nil, err
 		}
 	}
-	if !delta.DifferentExcept("Spec.Tags", "Spec.Policies", "Spec.InlinePolicies", "Spec.PermissionsBoundary") {
+	if !delta.DifferentExcept("Spec.Tags", "Spec.Policies", "Spec.AWSManagedPolicies", "Spec.InlinePolicies", "Spec.PermissionsBoundary") {
 		return desired, nil
 	}
 
@@ -409,14 +417,9 @@ func (rm *resourceManager) sdkDelete(
 	defer func() {
 		exit(err)
 	}()
-	// This deletes all associated managed and inline policies from the user
-	userCpy := r.ko.DeepCopy()
-	userCpy.Spec.Policies = nil
-	if err := rm.syncManagedPolicies(ctx, &resource{ko: userCpy}, r); err != nil {
-		return nil, err
-	}
-	userCpy.Spec.InlinePolicies = map[string]*string{}
-	if err := rm.syncInlinePolicies(ctx, &resource{ko: userCpy}, r); err != nil {
+	// This deletes all associated managed and inline policies from the user,
+	// and with the full deletion cleanup everything else blocking DeleteUser
+	if err := rm.cleanupBeforeDeletion(ctx, r); err != nil {
 		return nil, err
 	}
 
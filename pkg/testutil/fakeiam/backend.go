@@ -0,0 +1,388 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package fakeiam is an in-memory implementation of the subset of the IAM API
+// used by the resource managers of the controller. It enforces the IAM
+// semantics the controller relies on, such as NoSuchEntity, DeleteConflict,
+// EntityAlreadyExists and the policy version and size limits, so resource
+// managers can be driven end to end in go test:
+//
+//	backend := fakeiam.New()
+//	rm, err := factory.ManagerFor(cfg, backend.Config(), ...)
+//
+// List operations return every matching entity in a single page.
+package fakeiam
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+const (
+	// AccountID is the ID of the AWS account of a Backend.
+	AccountID = "123456789012"
+	// Region is the region of the aws.Config returned by Backend.Config.
+	Region = "us-west-2"
+)
+
+// Backend holds the IAM resources of a single account in memory. It is safe
+// for concurrent use.
+type Backend struct {
+	mu sync.Mutex
+
+	roles            map[string]*role
+	users            map[string]*user
+	groups           map[string]*group
+	instanceProfiles map[string]*instanceProfile
+	// policies are indexed by ARN.
+	policies map[string]*policy
+	// oidcProviders are indexed by ARN.
+	oidcProviders map[string]*oidcProvider
+	// deletionTasks are the statuses of the service-linked role deletion
+	// tasks, indexed by ID.
+	deletionTasks map[string]svcsdktypes.DeletionTaskStatusType
+	// deletionTaskFailures are the reasons of the failed deletion tasks,
+	// indexed by ID.
+	deletionTaskFailures map[string]*svcsdktypes.DeletionTaskFailureReasonType
+	// lastAccessedJobs are the granularities of the service last accessed
+	// details jobs, indexed by job ID.
+	lastAccessedJobs map[string]svcsdktypes.AccessAdvisorUsageGranularityType
+	// virtualMFADevices are the virtual MFA devices, indexed by serial
+	// number.
+	virtualMFADevices map[string]*virtualMFADevice
+
+	ops     map[string]handler
+	serial  int
+	lastNow time.Time
+}
+
+// handler runs an IAM operation on a Backend whose lock is held.
+type handler func(input interface{}) (interface{}, error)
+
+// op returns the handler of an operation implemented by f.
+func op[I any, O any](f func(I) (O, error)) handler {
+	return func(input interface{}) (interface{}, error) {
+		in, ok := input.(I)
+		if !ok {
+			var want I
+			return nil, fmt.Errorf("fakeiam: got input %T, want %T", input, want)
+		}
+		return f(in)
+	}
+}
+
+// New returns an empty Backend, holding only a few AWS managed policies.
+func New() *Backend {
+	b := &Backend{
+		roles:            map[string]*role{},
+		users:            map[string]*user{},
+		groups:           map[string]*group{},
+		instanceProfiles: map[string]*instanceProfile{},
+		policies:         map[string]*policy{},
+		oidcProviders:    map[string]*oidcProvider{},
+		deletionTasks:    map[string]svcsdktypes.DeletionTaskStatusType{},
+		lastAccessedJobs: map[string]svcsdktypes.AccessAdvisorUsageGranularityType{},
+
+		deletionTaskFailures: map[string]*svcsdktypes.DeletionTaskFailureReasonType{},
+		virtualMFADevices:    map[string]*virtualMFADevice{},
+	}
+	b.ops = map[string]handler{
+		// Roles
+		"CreateRole":                    op(b.createRole),
+		"GetRole":                       op(b.getRole),
+		"UpdateRole":                    op(b.updateRole),
+		"UpdateAssumeRolePolicy":        op(b.updateAssumeRolePolicy),
+		"DeleteRole":                    op(b.deleteRole),
+		"ListRoles":                     op(b.listRoles),
+		"PutRolePermissionsBoundary":    op(b.putRolePermissionsBoundary),
+		"DeleteRolePermissionsBoundary": op(b.deleteRolePermissionsBoundary),
+		"AttachRolePolicy":              op(b.attachRolePolicy),
+		"DetachRolePolicy":              op(b.detachRolePolicy),
+		"ListAttachedRolePolicies":      op(b.listAttachedRolePolicies),
+		"PutRolePolicy":                 op(b.putRolePolicy),
+		"GetRolePolicy":                 op(b.getRolePolicy),
+		"DeleteRolePolicy":              op(b.deleteRolePolicy),
+		"ListRolePolicies":              op(b.listRolePolicies),
+		"TagRole":                       op(b.tagRole),
+		"UntagRole":                     op(b.untagRole),
+		"ListRoleTags":                  op(b.listRoleTags),
+		"ListInstanceProfilesForRole":   op(b.listInstanceProfilesForRole),
+
+		// Service-linked roles
+		"CreateServiceLinkedRole":            op(b.createServiceLinkedRole),
+		"DeleteServiceLinkedRole":            op(b.deleteServiceLinkedRole),
+		"GetServiceLinkedRoleDeletionStatus": op(b.getServiceLinkedRoleDeletionStatus),
+		"GenerateServiceLastAccessedDetails": op(b.generateServiceLastAccessedDetails),
+		"GetServiceLastAccessedDetails":      op(b.getServiceLastAccessedDetails),
+
+		// Users
+		"CreateUser":                    op(b.createUser),
+		"GetUser":                       op(b.getUser),
+		"UpdateUser":                    op(b.updateUser),
+		"DeleteUser":                    op(b.deleteUser),
+		"ListUsers":                     op(b.listUsers),
+		"PutUserPermissionsBoundary":    op(b.putUserPermissionsBoundary),
+		"DeleteUserPermissionsBoundary": op(b.deleteUserPermissionsBoundary),
+		"AttachUserPolicy":              op(b.attachUserPolicy),
+		"DetachUserPolicy":              op(b.detachUserPolicy),
+		"ListAttachedUserPolicies":      op(b.listAttachedUserPolicies),
+		"PutUserPolicy":                 op(b.putUserPolicy),
+		"GetUserPolicy":                 op(b.getUserPolicy),
+		"DeleteUserPolicy":              op(b.deleteUserPolicy),
+		"ListUserPolicies":              op(b.listUserPolicies),
+		"TagUser":                       op(b.tagUser),
+		"UntagUser":                     op(b.untagUser),
+		"ListUserTags":                  op(b.listUserTags),
+		"ListGroupsForUser":             op(b.listGroupsForUser),
+
+		// User credentials
+		"CreateLoginProfile":              op(b.createLoginProfile),
+		"CreateAccessKey":                 op(b.createAccessKey),
+		"UploadSigningCertificate":        op(b.uploadSigningCertificate),
+		"UploadSSHPublicKey":              op(b.uploadSSHPublicKey),
+		"CreateServiceSpecificCredential": op(b.createServiceSpecificCredential),
+		"CreateVirtualMFADevice":          op(b.createVirtualMFADevice),
+		"EnableMFADevice":                 op(b.enableMFADevice),
+		"ListAccessKeys":                  op(b.listAccessKeys),
+		"ListSigningCertificates":         op(b.listSigningCertificates),
+		"ListSSHPublicKeys":               op(b.listSSHPublicKeys),
+		"ListServiceSpecificCredentials":  op(b.listServiceSpecificCredentials),
+		"ListMFADevices":                  op(b.listMFADevices),
+		"DeleteLoginProfile":              op(b.deleteLoginProfile),
+		"DeleteAccessKey":                 op(b.deleteAccessKey),
+		"DeleteSigningCertificate":        op(b.deleteSigningCertificate),
+		"DeleteSSHPublicKey":              op(b.deleteSSHPublicKey),
+		"DeleteServiceSpecificCredential": op(b.deleteServiceSpecificCredential),
+		"DeactivateMFADevice":             op(b.deactivateMFADevice),
+		"DeleteVirtualMFADevice":          op(b.deleteVirtualMFADevice),
+
+		// Groups
+		"CreateGroup":               op(b.createGroup),
+		"GetGroup":                  op(b.getGroup),
+		"UpdateGroup":               op(b.updateGroup),
+		"DeleteGroup":               op(b.deleteGroup),
+		"ListGroups":                op(b.listGroups),
+		"AddUserToGroup":            op(b.addUserToGroup),
+		"RemoveUserFromGroup":       op(b.removeUserFromGroup),
+		"AttachGroupPolicy":         op(b.attachGroupPolicy),
+		"DetachGroupPolicy":         op(b.detachGroupPolicy),
+		"ListAttachedGroupPolicies": op(b.listAttachedGroupPolicies),
+		"PutGroupPolicy":            op(b.putGroupPolicy),
+		"GetGroupPolicy":            op(b.getGroupPolicy),
+		"DeleteGroupPolicy":         op(b.deleteGroupPolicy),
+		"ListGroupPolicies":         op(b.listGroupPolicies),
+
+		// Managed policies
+		"CreatePolicy":            op(b.createPolicy),
+		"GetPolicy":               op(b.getPolicy),
+		"DeletePolicy":            op(b.deletePolicy),
+		"ListPolicies":            op(b.listPolicies),
+		"CreatePolicyVersion":     op(b.createPolicyVersion),
+		"GetPolicyVersion":        op(b.getPolicyVersion),
+		"DeletePolicyVersion":     op(b.deletePolicyVersion),
+		"ListPolicyVersions":      op(b.listPolicyVersions),
+		"SetDefaultPolicyVersion": op(b.setDefaultPolicyVersion),
+		"ListEntitiesForPolicy":   op(b.listEntitiesForPolicy),
+		"TagPolicy":               op(b.tagPolicy),
+		"UntagPolicy":             op(b.untagPolicy),
+		"ListPolicyTags":          op(b.listPolicyTags),
+
+		// Instance profiles
+		"CreateInstanceProfile":         op(b.createInstanceProfile),
+		"GetInstanceProfile":            op(b.getInstanceProfile),
+		"DeleteInstanceProfile":         op(b.deleteInstanceProfile),
+		"ListInstanceProfiles":          op(b.listInstanceProfiles),
+		"AddRoleToInstanceProfile":      op(b.addRoleToInstanceProfile),
+		"RemoveRoleFromInstanceProfile": op(b.removeRoleFromInstanceProfile),
+		"TagInstanceProfile":            op(b.tagInstanceProfile),
+		"UntagInstanceProfile":          op(b.untagInstanceProfile),
+		"ListInstanceProfileTags":       op(b.listInstanceProfileTags),
+
+		// OpenID Connect providers
+		"CreateOpenIDConnectProvider":             op(b.createOpenIDConnectProvider),
+		"GetOpenIDConnectProvider":                op(b.getOpenIDConnectProvider),
+		"DeleteOpenIDConnectProvider":             op(b.deleteOpenIDConnectProvider),
+		"ListOpenIDConnectProviders":              op(b.listOpenIDConnectProviders),
+		"AddClientIDToOpenIDConnectProvider":      op(b.addClientIDToOpenIDConnectProvider),
+		"RemoveClientIDFromOpenIDConnectProvider": op(b.removeClientIDFromOpenIDConnectProvider),
+		"UpdateOpenIDConnectProviderThumbprint":   op(b.updateOpenIDConnectProviderThumbprint),
+		"TagOpenIDConnectProvider":                op(b.tagOpenIDConnectProvider),
+		"UntagOpenIDConnectProvider":              op(b.untagOpenIDConnectProvider),
+		"ListOpenIDConnectProviderTags":           op(b.listOpenIDConnectProviderTags),
+	}
+	for _, name := range []string{"AdministratorAccess", "PowerUserAccess", "ReadOnlyAccess"} {
+		b.AddAWSManagedPolicy(name, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"*","Resource":"*"}]}`)
+	}
+	return b
+}
+
+// Config returns an aws.Config whose IAM clients call the Backend instead of
+// AWS. It can be passed to the ManagerFor method of the resource manager
+// factories.
+func (b *Backend) Config() aws.Config {
+	return aws.Config{
+		Region:      Region,
+		Credentials: aws.AnonymousCredentials{},
+		APIOptions:  []func(*middleware.Stack) error{b.addMiddleware},
+	}
+}
+
+// addMiddleware adds to the supplied operation stack a middleware running the
+// operation on the Backend. It comes after the validation of the input, and
+// short-circuits the serialization of the request and the HTTP round trip.
+func (b *Backend) addMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc(
+		"FakeIAMBackend",
+		func(
+			ctx context.Context,
+			in middleware.InitializeInput,
+			next middleware.InitializeHandler,
+		) (middleware.InitializeOutput, middleware.Metadata, error) {
+			out, err := b.Invoke(ctx, middleware.GetOperationName(ctx), in.Parameters)
+			return middleware.InitializeOutput{Result: out}, middleware.Metadata{}, err
+		},
+	), middleware.After)
+}
+
+// Invoke runs the IAM operation of the supplied name with the supplied input,
+// a pointer to the operation's svcsdk input structure, and returns a pointer to
+// its svcsdk output structure.
+func (b *Backend) Invoke(
+	ctx context.Context,
+	operation string,
+	input interface{},
+) (interface{}, error) {
+	h, ok := b.ops[operation]
+	if !ok {
+		return nil, fmt.Errorf("fakeiam: operation %s is not implemented", operation)
+	}
+	if v := reflect.ValueOf(input); v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, fmt.Errorf("fakeiam: got input %T for operation %s", input, operation)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return h(input)
+}
+
+// Operations returns the names of the IAM operations the Backend implements,
+// sorted.
+func (b *Backend) Operations() []string {
+	res := make([]string, 0, len(b.ops))
+	for name := range b.ops {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// now returns the current time, truncated to the second like IAM dates and
+// always after the previously returned time, so that the entities created in
+// a row are ordered by creation date.
+func (b *Backend) now() time.Time {
+	now := time.Now().UTC().Truncate(time.Second)
+	if !now.After(b.lastNow) {
+		now = b.lastNow.Add(time.Second)
+	}
+	b.lastNow = now
+	return now
+}
+
+// newID returns a new unique ID with the supplied prefix, such as AROA for
+// roles, in the format of IAM IDs.
+func (b *Backend) newID(prefix string) string {
+	b.serial++
+	return fmt.Sprintf("%s%017d", prefix, b.serial)
+}
+
+// arn returns the ARN of the IAM resource of the supplied type, path and
+// name.
+func arn(resourceType string, path string, name string) string {
+	return fmt.Sprintf("arn:aws:iam::%s:%s%s%s", AccountID, resourceType, path, name)
+}
+
+// key returns the index of the entity of the supplied name. IAM names are
+// unique regardless of case.
+func key(name *string) string {
+	return strings.ToLower(aws.ToString(name))
+}
+
+// pathOrDefault validates the supplied path and returns it, or / if it is
+// nil.
+func pathOrDefault(path *string) (string, error) {
+	if path == nil {
+		return "/", nil
+	}
+	p := *path
+	if !strings.HasPrefix(p, "/") || !strings.HasSuffix(p, "/") || len(p) > 512 {
+		return "", validationError("path %q must begin and end with / and be at most 512 characters", p)
+	}
+	return p, nil
+}
+
+// hasPathPrefix returns true if the supplied path starts with the supplied
+// prefix, or if no prefix is supplied.
+func hasPathPrefix(path string, prefix *string) bool {
+	return prefix == nil || strings.HasPrefix(path, *prefix)
+}
+
+// policySize returns the size IAM accounts a policy document for against its
+// quotas, which excludes whitespace.
+func policySize(doc string) int {
+	n := 0
+	for _, r := range doc {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// page returns the bounds, in a list of items, of the page starting at the
+// supplied marker and holding at most maxItems items, 100 by default.
+// Markers are the decimal offsets of the first item of their page.
+func page(marker *string, maxItems *int32) (start int, end int, err error) {
+	if marker != nil {
+		if start, err = strconv.Atoi(*marker); err != nil || start < 0 {
+			return 0, 0, invalidInput("Invalid Marker %q.", *marker)
+		}
+	}
+	size := 100
+	if maxItems != nil {
+		if *maxItems < 1 || *maxItems > 1000 {
+			return 0, 0, validationError("MaxItems must be between 1 and 1000.")
+		}
+		size = int(*maxItems)
+	}
+	return start, start + size, nil
+}
+
+// sortedKeys returns the keys of the supplied map, sorted.
+func sortedKeys[V any](m map[string]V) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,359 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fakeiam_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+)
+
+const (
+	testDocument = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`
+	trustPolicy  = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole"}]}`
+)
+
+// errorCode returns the code of the API error returned by an operation, or
+// the empty string.
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return ""
+}
+
+func newClient() *svcsdk.Client {
+	return svcsdk.NewFromConfig(fakeiam.New().Config())
+}
+
+func createPolicy(t *testing.T, client *svcsdk.Client, name string) string {
+	out, err := client.CreatePolicy(context.TODO(), &svcsdk.CreatePolicyInput{
+		PolicyName:     aws.String(name),
+		PolicyDocument: aws.String(testDocument),
+	})
+	require.NoError(t, err)
+	return *out.Policy.Arn
+}
+
+func createRole(t *testing.T, client *svcsdk.Client, name string) {
+	_, err := client.CreateRole(context.TODO(), &svcsdk.CreateRoleInput{
+		RoleName:                 aws.String(name),
+		AssumeRolePolicyDocument: aws.String(trustPolicy),
+	})
+	require.NoError(t, err)
+}
+
+func TestInvoke_UnknownOperation(t *testing.T) {
+	backend := fakeiam.New()
+
+	_, err := backend.Invoke(context.TODO(), "CreateSAMLProvider", &svcsdk.CreateSAMLProviderInput{})
+	assert.ErrorContains(t, err, "not implemented")
+	_, err = backend.Invoke(context.TODO(), "GetRole", svcsdk.GetRoleInput{})
+	assert.Error(t, err)
+	assert.Contains(t, backend.Operations(), "CreatePolicyVersion")
+}
+
+func TestRole(t *testing.T) {
+	ctx := context.TODO()
+	client := newClient()
+	createRole(t, client, "MyRole")
+
+	_, err := client.CreateRole(ctx, &svcsdk.CreateRoleInput{
+		RoleName:                 aws.String("myrole"),
+		AssumeRolePolicyDocument: aws.String(trustPolicy),
+	})
+	assert.Equal(t, "EntityAlreadyExists", errorCode(err))
+
+	out, err := client.GetRole(ctx, &svcsdk.GetRoleInput{RoleName: aws.String("MyRole")})
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("arn:aws:iam::%s:role/MyRole", fakeiam.AccountID), *out.Role.Arn)
+	doc, err := url.QueryUnescape(*out.Role.AssumeRolePolicyDocument)
+	require.NoError(t, err)
+	assert.Equal(t, trustPolicy, doc)
+	assert.Equal(t, int32(3600), *out.Role.MaxSessionDuration)
+
+	_, err = client.GetRole(ctx, &svcsdk.GetRoleInput{RoleName: aws.String("Other")})
+	assert.Equal(t, "NoSuchEntity", errorCode(err))
+}
+
+func TestRole_DeleteConflict(t *testing.T) {
+	ctx := context.TODO()
+	client := newClient()
+	policyARN := createPolicy(t, client, "MyPolicy")
+	createRole(t, client, "MyRole")
+
+	_, err := client.AttachRolePolicy(ctx, &svcsdk.AttachRolePolicyInput{
+		RoleName:  aws.String("MyRole"),
+		PolicyArn: aws.String(policyARN),
+	})
+	require.NoError(t, err)
+
+	_, err = client.DeleteRole(ctx, &svcsdk.DeleteRoleInput{RoleName: aws.String("MyRole")})
+	assert.Equal(t, "DeleteConflict", errorCode(err))
+	_, err = client.DeletePolicy(ctx, &svcsdk.DeletePolicyInput{PolicyArn: aws.String(policyARN)})
+	assert.Equal(t, "DeleteConflict", errorCode(err))
+
+	policy, err := client.GetPolicy(ctx, &svcsdk.GetPolicyInput{PolicyArn: aws.String(policyARN)})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), *policy.Policy.AttachmentCount)
+	entities, err := client.ListEntitiesForPolicy(ctx, &svcsdk.ListEntitiesForPolicyInput{
+		PolicyArn: aws.String(policyARN),
+	})
+	require.NoError(t, err)
+	require.Len(t, entities.PolicyRoles, 1)
+	assert.Equal(t, "MyRole", *entities.PolicyRoles[0].RoleName)
+
+	_, err = client.DetachRolePolicy(ctx, &svcsdk.DetachRolePolicyInput{
+		RoleName:  aws.String("MyRole"),
+		PolicyArn: aws.String(policyARN),
+	})
+	require.NoError(t, err)
+	_, err = client.DeleteRole(ctx, &svcsdk.DeleteRoleInput{RoleName: aws.String("MyRole")})
+	require.NoError(t, err)
+	_, err = client.DeletePolicy(ctx, &svcsdk.DeletePolicyInput{PolicyArn: aws.String(policyARN)})
+	require.NoError(t, err)
+	_, err = client.DeleteRole(ctx, &svcsdk.DeleteRoleInput{RoleName: aws.String("MyRole")})
+	assert.Equal(t, "NoSuchEntity", errorCode(err))
+}
+
+func TestRole_InlinePolicyQuota(t *testing.T) {
+	ctx := context.TODO()
+	client := newClient()
+	createRole(t, client, "MyRole")
+
+	// Whitespace does not count towards the quota.
+	padded := strings.Replace(testDocument, `"Resource"`, strings.Repeat(" ", 20000)+`"Resource"`, 1)
+	_, err := client.PutRolePolicy(ctx, &svcsdk.PutRolePolicyInput{
+		RoleName:       aws.String("MyRole"),
+		PolicyName:     aws.String("padded"),
+		PolicyDocument: aws.String(padded),
+	})
+	require.NoError(t, err)
+
+	large := fmt.Sprintf(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"arn:aws:s3:::%s"}]}`,
+		strings.Repeat("a", 10240))
+	_, err = client.PutRolePolicy(ctx, &svcsdk.PutRolePolicyInput{
+		RoleName:       aws.String("MyRole"),
+		PolicyName:     aws.String("large"),
+		PolicyDocument: aws.String(large),
+	})
+	assert.Equal(t, "LimitExceeded", errorCode(err))
+
+	_, err = client.PutRolePolicy(ctx, &svcsdk.PutRolePolicyInput{
+		RoleName:       aws.String("MyRole"),
+		PolicyName:     aws.String("malformed"),
+		PolicyDocument: aws.String("{"),
+	})
+	assert.Equal(t, "MalformedPolicyDocument", errorCode(err))
+
+	names, err := client.ListRolePolicies(ctx, &svcsdk.ListRolePoliciesInput{RoleName: aws.String("MyRole")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"padded"}, names.PolicyNames)
+}
+
+func TestPolicyVersions(t *testing.T) {
+	ctx := context.TODO()
+	client := newClient()
+	policyARN := createPolicy(t, client, "MyPolicy")
+
+	for i := 2; i <= 5; i++ {
+		out, err := client.CreatePolicyVersion(ctx, &svcsdk.CreatePolicyVersionInput{
+			PolicyArn:      aws.String(policyARN),
+			PolicyDocument: aws.String(testDocument),
+			SetAsDefault:   true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("v%d", i), *out.PolicyVersion.VersionId)
+	}
+	_, err := client.CreatePolicyVersion(ctx, &svcsdk.CreatePolicyVersionInput{
+		PolicyArn:      aws.String(policyARN),
+		PolicyDocument: aws.String(testDocument),
+	})
+	assert.Equal(t, "LimitExceeded", errorCode(err))
+
+	_, err = client.DeletePolicyVersion(ctx, &svcsdk.DeletePolicyVersionInput{
+		PolicyArn: aws.String(policyARN),
+		VersionId: aws.String("v5"),
+	})
+	assert.Equal(t, "DeleteConflict", errorCode(err))
+	_, err = client.DeletePolicy(ctx, &svcsdk.DeletePolicyInput{PolicyArn: aws.String(policyARN)})
+	assert.Equal(t, "DeleteConflict", errorCode(err))
+
+	_, err = client.DeletePolicyVersion(ctx, &svcsdk.DeletePolicyVersionInput{
+		PolicyArn: aws.String(policyARN),
+		VersionId: aws.String("v1"),
+	})
+	require.NoError(t, err)
+	out, err := client.CreatePolicyVersion(ctx, &svcsdk.CreatePolicyVersionInput{
+		PolicyArn:      aws.String(policyARN),
+		PolicyDocument: aws.String(testDocument),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "v6", *out.PolicyVersion.VersionId)
+	assert.False(t, out.PolicyVersion.IsDefaultVersion)
+
+	versions, err := client.ListPolicyVersions(ctx, &svcsdk.ListPolicyVersionsInput{PolicyArn: aws.String(policyARN)})
+	require.NoError(t, err)
+	ids := []string{}
+	for _, v := range versions.Versions {
+		ids = append(ids, *v.VersionId)
+	}
+	assert.Equal(t, []string{"v6", "v5", "v4", "v3", "v2"}, ids)
+}
+
+func TestListPolicies_Scope(t *testing.T) {
+	ctx := context.TODO()
+	client := newClient()
+	createPolicy(t, client, "MyPolicy")
+
+	local, err := client.ListPolicies(ctx, &svcsdk.ListPoliciesInput{Scope: svcsdktypes.PolicyScopeTypeLocal})
+	require.NoError(t, err)
+	require.Len(t, local.Policies, 1)
+	assert.Equal(t, "MyPolicy", *local.Policies[0].PolicyName)
+
+	all, err := client.ListPolicies(ctx, &svcsdk.ListPoliciesInput{})
+	require.NoError(t, err)
+	assert.Len(t, all.Policies, 4)
+
+	_, err = client.DeletePolicy(ctx, &svcsdk.DeletePolicyInput{
+		PolicyArn: aws.String("arn:aws:iam::aws:policy/ReadOnlyAccess"),
+	})
+	assert.Equal(t, "UnmodifiableEntity", errorCode(err))
+}
+
+func TestInstanceProfile(t *testing.T) {
+	ctx := context.TODO()
+	client := newClient()
+	createRole(t, client, "RoleA")
+	createRole(t, client, "RoleB")
+	_, err := client.CreateInstanceProfile(ctx, &svcsdk.CreateInstanceProfileInput{
+		InstanceProfileName: aws.String("MyProfile"),
+	})
+	require.NoError(t, err)
+
+	_, err = client.AddRoleToInstanceProfile(ctx, &svcsdk.AddRoleToInstanceProfileInput{
+		InstanceProfileName: aws.String("MyProfile"),
+		RoleName:            aws.String("RoleA"),
+	})
+	require.NoError(t, err)
+	_, err = client.AddRoleToInstanceProfile(ctx, &svcsdk.AddRoleToInstanceProfileInput{
+		InstanceProfileName: aws.String("MyProfile"),
+		RoleName:            aws.String("RoleB"),
+	})
+	assert.Equal(t, "LimitExceeded", errorCode(err))
+
+	_, err = client.DeleteRole(ctx, &svcsdk.DeleteRoleInput{RoleName: aws.String("RoleA")})
+	assert.Equal(t, "DeleteConflict", errorCode(err))
+	_, err = client.DeleteInstanceProfile(ctx, &svcsdk.DeleteInstanceProfileInput{
+		InstanceProfileName: aws.String("MyProfile"),
+	})
+	assert.Equal(t, "DeleteConflict", errorCode(err))
+
+	profiles, err := client.ListInstanceProfilesForRole(ctx, &svcsdk.ListInstanceProfilesForRoleInput{
+		RoleName: aws.String("RoleA"),
+	})
+	require.NoError(t, err)
+	require.Len(t, profiles.InstanceProfiles, 1)
+	require.Len(t, profiles.InstanceProfiles[0].Roles, 1)
+	assert.Equal(t, "RoleA", *profiles.InstanceProfiles[0].Roles[0].RoleName)
+
+	_, err = client.RemoveRoleFromInstanceProfile(ctx, &svcsdk.RemoveRoleFromInstanceProfileInput{
+		InstanceProfileName: aws.String("MyProfile"),
+		RoleName:            aws.String("RoleB"),
+	})
+	assert.Equal(t, "NoSuchEntity", errorCode(err))
+	_, err = client.RemoveRoleFromInstanceProfile(ctx, &svcsdk.RemoveRoleFromInstanceProfileInput{
+		InstanceProfileName: aws.String("MyProfile"),
+		RoleName:            aws.String("RoleA"),
+	})
+	require.NoError(t, err)
+	_, err = client.DeleteInstanceProfile(ctx, &svcsdk.DeleteInstanceProfileInput{
+		InstanceProfileName: aws.String("MyProfile"),
+	})
+	require.NoError(t, err)
+}
+
+func TestGroup_DeleteConflict(t *testing.T) {
+	ctx := context.TODO()
+	client := newClient()
+	_, err := client.CreateGroup(ctx, &svcsdk.CreateGroupInput{GroupName: aws.String("MyGroup")})
+	require.NoError(t, err)
+	_, err = client.CreateUser(ctx, &svcsdk.CreateUserInput{UserName: aws.String("MyUser")})
+	require.NoError(t, err)
+	_, err = client.AddUserToGroup(ctx, &svcsdk.AddUserToGroupInput{
+		GroupName: aws.String("MyGroup"),
+		UserName:  aws.String("MyUser"),
+	})
+	require.NoError(t, err)
+
+	_, err = client.DeleteGroup(ctx, &svcsdk.DeleteGroupInput{GroupName: aws.String("MyGroup")})
+	assert.Equal(t, "DeleteConflict", errorCode(err))
+	_, err = client.DeleteUser(ctx, &svcsdk.DeleteUserInput{UserName: aws.String("MyUser")})
+	assert.Equal(t, "DeleteConflict", errorCode(err))
+
+	group, err := client.GetGroup(ctx, &svcsdk.GetGroupInput{GroupName: aws.String("MyGroup")})
+	require.NoError(t, err)
+	require.Len(t, group.Users, 1)
+	assert.Equal(t, "MyUser", *group.Users[0].UserName)
+}
+
+func TestOpenIDConnectProvider(t *testing.T) {
+	ctx := context.TODO()
+	client := newClient()
+	input := &svcsdk.CreateOpenIDConnectProviderInput{
+		Url:            aws.String("https://oidc.example.com/id/1"),
+		ClientIDList:   []string{"sts.amazonaws.com"},
+		ThumbprintList: []string{strings.Repeat("0", 40)},
+	}
+	created, err := client.CreateOpenIDConnectProvider(ctx, input)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		fmt.Sprintf("arn:aws:iam::%s:oidc-provider/oidc.example.com/id/1", fakeiam.AccountID),
+		*created.OpenIDConnectProviderArn,
+	)
+	_, err = client.CreateOpenIDConnectProvider(ctx, input)
+	assert.Equal(t, "EntityAlreadyExists", errorCode(err))
+
+	_, err = client.AddClientIDToOpenIDConnectProvider(ctx, &svcsdk.AddClientIDToOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: created.OpenIDConnectProviderArn,
+		ClientID:                 aws.String("my-app"),
+	})
+	require.NoError(t, err)
+
+	out, err := client.GetOpenIDConnectProvider(ctx, &svcsdk.GetOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: created.OpenIDConnectProviderArn,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "oidc.example.com/id/1", *out.Url)
+	assert.Equal(t, []string{"sts.amazonaws.com", "my-app"}, out.ClientIDList)
+
+	_, err = client.CreateOpenIDConnectProvider(ctx, &svcsdk.CreateOpenIDConnectProviderInput{
+		Url: aws.String("http://oidc.example.com"),
+	})
+	assert.Equal(t, "ValidationError", errorCode(err))
+}
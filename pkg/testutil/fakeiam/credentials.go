@@ -0,0 +1,349 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fakeiam
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// credentials are the credentials and MFA devices of a user. Each list holds
+// IDs, or serial numbers for MFA devices, in creation order.
+type credentials struct {
+	loginProfile               bool
+	accessKeys                 []string
+	signingCertificates        []string
+	sshPublicKeys              []string
+	serviceSpecificCredentials []string
+	mfaDevices                 []string
+}
+
+// deleteConflict returns the error of DeleteUser when the user still has
+// credentials or MFA devices.
+func (c *credentials) deleteConflict() error {
+	if c.loginProfile || len(c.accessKeys) > 0 || len(c.signingCertificates) > 0 ||
+		len(c.sshPublicKeys) > 0 || len(c.serviceSpecificCredentials) > 0 || len(c.mfaDevices) > 0 {
+		return deleteConflict("Cannot delete entity, must delete login profile, access keys, signing certificates, SSH public keys, service specific credentials and MFA devices first.")
+	}
+	return nil
+}
+
+// virtualMFADevice is a virtual MFA device, possibly assigned to a user.
+type virtualMFADevice struct {
+	serial string
+	// user is the key of the user the device is enabled for, if any.
+	user string
+}
+
+// remove removes id from the supplied list, returning false if it is absent.
+func remove(list *[]string, id string) bool {
+	for i, v := range *list {
+		if v == id {
+			*list = append((*list)[:i:i], (*list)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Backend) createLoginProfile(in *svcsdk.CreateLoginProfileInput) (*svcsdk.CreateLoginProfileOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	if u.loginProfile {
+		return nil, entityAlreadyExists("Login Profile for user %s already exists.", u.name)
+	}
+	u.loginProfile = true
+	return &svcsdk.CreateLoginProfileOutput{LoginProfile: &svcsdktypes.LoginProfile{
+		CreateDate: aws.Time(b.now()),
+		UserName:   aws.String(u.name),
+	}}, nil
+}
+
+func (b *Backend) deleteLoginProfile(in *svcsdk.DeleteLoginProfileInput) (*svcsdk.DeleteLoginProfileOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	if !u.loginProfile {
+		return nil, noSuchEntity("Login Profile for User %s cannot be found.", u.name)
+	}
+	u.loginProfile = false
+	return &svcsdk.DeleteLoginProfileOutput{}, nil
+}
+
+func (b *Backend) createAccessKey(in *svcsdk.CreateAccessKeyInput) (*svcsdk.CreateAccessKeyOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	if len(u.accessKeys) >= 2 {
+		return nil, limitExceeded("Cannot exceed quota for AccessKeysPerUser: 2")
+	}
+	id := b.newID("AKIA")
+	u.accessKeys = append(u.accessKeys, id)
+	return &svcsdk.CreateAccessKeyOutput{AccessKey: &svcsdktypes.AccessKey{
+		AccessKeyId:     aws.String(id),
+		CreateDate:      aws.Time(b.now()),
+		SecretAccessKey: aws.String("secret-" + id),
+		Status:          svcsdktypes.StatusTypeActive,
+		UserName:        aws.String(u.name),
+	}}, nil
+}
+
+func (b *Backend) listAccessKeys(in *svcsdk.ListAccessKeysInput) (*svcsdk.ListAccessKeysOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	res := []svcsdktypes.AccessKeyMetadata{}
+	for _, id := range u.accessKeys {
+		res = append(res, svcsdktypes.AccessKeyMetadata{
+			AccessKeyId: aws.String(id),
+			Status:      svcsdktypes.StatusTypeActive,
+			UserName:    aws.String(u.name),
+		})
+	}
+	return &svcsdk.ListAccessKeysOutput{AccessKeyMetadata: res}, nil
+}
+
+func (b *Backend) deleteAccessKey(in *svcsdk.DeleteAccessKeyInput) (*svcsdk.DeleteAccessKeyOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	if !remove(&u.accessKeys, aws.ToString(in.AccessKeyId)) {
+		return nil, noSuchEntity("The Access Key with id %s cannot be found.", aws.ToString(in.AccessKeyId))
+	}
+	return &svcsdk.DeleteAccessKeyOutput{}, nil
+}
+
+func (b *Backend) uploadSigningCertificate(in *svcsdk.UploadSigningCertificateInput) (*svcsdk.UploadSigningCertificateOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	id := b.newID("CERT")
+	u.signingCertificates = append(u.signingCertificates, id)
+	return &svcsdk.UploadSigningCertificateOutput{Certificate: &svcsdktypes.SigningCertificate{
+		CertificateBody: in.CertificateBody,
+		CertificateId:   aws.String(id),
+		Status:          svcsdktypes.StatusTypeActive,
+		UserName:        aws.String(u.name),
+	}}, nil
+}
+
+func (b *Backend) listSigningCertificates(in *svcsdk.ListSigningCertificatesInput) (*svcsdk.ListSigningCertificatesOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	res := []svcsdktypes.SigningCertificate{}
+	for _, id := range u.signingCertificates {
+		res = append(res, svcsdktypes.SigningCertificate{
+			CertificateBody: aws.String(""),
+			CertificateId:   aws.String(id),
+			Status:          svcsdktypes.StatusTypeActive,
+			UserName:        aws.String(u.name),
+		})
+	}
+	return &svcsdk.ListSigningCertificatesOutput{Certificates: res}, nil
+}
+
+func (b *Backend) deleteSigningCertificate(in *svcsdk.DeleteSigningCertificateInput) (*svcsdk.DeleteSigningCertificateOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	if !remove(&u.signingCertificates, aws.ToString(in.CertificateId)) {
+		return nil, noSuchEntity("The Certificate with id %s cannot be found.", aws.ToString(in.CertificateId))
+	}
+	return &svcsdk.DeleteSigningCertificateOutput{}, nil
+}
+
+func (b *Backend) uploadSSHPublicKey(in *svcsdk.UploadSSHPublicKeyInput) (*svcsdk.UploadSSHPublicKeyOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	id := b.newID("APKA")
+	u.sshPublicKeys = append(u.sshPublicKeys, id)
+	return &svcsdk.UploadSSHPublicKeyOutput{SSHPublicKey: &svcsdktypes.SSHPublicKey{
+		Fingerprint:      aws.String(id),
+		SSHPublicKeyBody: in.SSHPublicKeyBody,
+		SSHPublicKeyId:   aws.String(id),
+		Status:           svcsdktypes.StatusTypeActive,
+		UserName:         aws.String(u.name),
+	}}, nil
+}
+
+func (b *Backend) listSSHPublicKeys(in *svcsdk.ListSSHPublicKeysInput) (*svcsdk.ListSSHPublicKeysOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	res := []svcsdktypes.SSHPublicKeyMetadata{}
+	for _, id := range u.sshPublicKeys {
+		res = append(res, svcsdktypes.SSHPublicKeyMetadata{
+			SSHPublicKeyId: aws.String(id),
+			Status:         svcsdktypes.StatusTypeActive,
+			UploadDate:     aws.Time(b.now()),
+			UserName:       aws.String(u.name),
+		})
+	}
+	return &svcsdk.ListSSHPublicKeysOutput{SSHPublicKeys: res}, nil
+}
+
+func (b *Backend) deleteSSHPublicKey(in *svcsdk.DeleteSSHPublicKeyInput) (*svcsdk.DeleteSSHPublicKeyOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	if !remove(&u.sshPublicKeys, aws.ToString(in.SSHPublicKeyId)) {
+		return nil, noSuchEntity("The Public Key with id %s cannot be found.", aws.ToString(in.SSHPublicKeyId))
+	}
+	return &svcsdk.DeleteSSHPublicKeyOutput{}, nil
+}
+
+func (b *Backend) createServiceSpecificCredential(in *svcsdk.CreateServiceSpecificCredentialInput) (*svcsdk.CreateServiceSpecificCredentialOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	id := b.newID("ACCA")
+	u.serviceSpecificCredentials = append(u.serviceSpecificCredentials, id)
+	return &svcsdk.CreateServiceSpecificCredentialOutput{ServiceSpecificCredential: &svcsdktypes.ServiceSpecificCredential{
+		CreateDate:                  aws.Time(b.now()),
+		ServiceName:                 in.ServiceName,
+		ServicePassword:             aws.String("password-" + id),
+		ServiceSpecificCredentialId: aws.String(id),
+		ServiceUserName:             aws.String(u.name + "-at-" + AccountID),
+		Status:                      svcsdktypes.StatusTypeActive,
+		UserName:                    aws.String(u.name),
+	}}, nil
+}
+
+func (b *Backend) listServiceSpecificCredentials(in *svcsdk.ListServiceSpecificCredentialsInput) (*svcsdk.ListServiceSpecificCredentialsOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	res := []svcsdktypes.ServiceSpecificCredentialMetadata{}
+	for _, id := range u.serviceSpecificCredentials {
+		res = append(res, svcsdktypes.ServiceSpecificCredentialMetadata{
+			CreateDate:                  aws.Time(b.now()),
+			ServiceName:                 aws.String("codecommit.amazonaws.com"),
+			ServiceSpecificCredentialId: aws.String(id),
+			ServiceUserName:             aws.String(u.name + "-at-" + AccountID),
+			Status:                      svcsdktypes.StatusTypeActive,
+			UserName:                    aws.String(u.name),
+		})
+	}
+	return &svcsdk.ListServiceSpecificCredentialsOutput{ServiceSpecificCredentials: res}, nil
+}
+
+func (b *Backend) deleteServiceSpecificCredential(in *svcsdk.DeleteServiceSpecificCredentialInput) (*svcsdk.DeleteServiceSpecificCredentialOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	if !remove(&u.serviceSpecificCredentials, aws.ToString(in.ServiceSpecificCredentialId)) {
+		return nil, noSuchEntity("No such credential %s.", aws.ToString(in.ServiceSpecificCredentialId))
+	}
+	return &svcsdk.DeleteServiceSpecificCredentialOutput{}, nil
+}
+
+func (b *Backend) createVirtualMFADevice(in *svcsdk.CreateVirtualMFADeviceInput) (*svcsdk.CreateVirtualMFADeviceOutput, error) {
+	path, err := pathOrDefault(in.Path)
+	if err != nil {
+		return nil, err
+	}
+	serial := arn("mfa", path, aws.ToString(in.VirtualMFADeviceName))
+	if _, ok := b.virtualMFADevices[serial]; ok {
+		return nil, entityAlreadyExists("MFADevice entity at the same path and name already exists.")
+	}
+	b.virtualMFADevices[serial] = &virtualMFADevice{serial: serial}
+	return &svcsdk.CreateVirtualMFADeviceOutput{VirtualMFADevice: &svcsdktypes.VirtualMFADevice{
+		SerialNumber: aws.String(serial),
+	}}, nil
+}
+
+func (b *Backend) enableMFADevice(in *svcsdk.EnableMFADeviceInput) (*svcsdk.EnableMFADeviceOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	serial := aws.ToString(in.SerialNumber)
+	for _, s := range u.mfaDevices {
+		if s == serial {
+			return nil, entityAlreadyExists("MFA Device is already in use.")
+		}
+	}
+	// Serial numbers that are not ARNs are those of hardware devices, which
+	// are not modeled.
+	if d, ok := b.virtualMFADevices[serial]; ok {
+		if d.user != "" {
+			return nil, entityAlreadyExists("MFA Device is already in use.")
+		}
+		d.user = key(in.UserName)
+	}
+	u.mfaDevices = append(u.mfaDevices, serial)
+	return &svcsdk.EnableMFADeviceOutput{}, nil
+}
+
+func (b *Backend) listMFADevices(in *svcsdk.ListMFADevicesInput) (*svcsdk.ListMFADevicesOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	res := []svcsdktypes.MFADevice{}
+	for _, serial := range u.mfaDevices {
+		res = append(res, svcsdktypes.MFADevice{
+			EnableDate:   aws.Time(b.now()),
+			SerialNumber: aws.String(serial),
+			UserName:     aws.String(u.name),
+		})
+	}
+	return &svcsdk.ListMFADevicesOutput{MFADevices: res}, nil
+}
+
+func (b *Backend) deactivateMFADevice(in *svcsdk.DeactivateMFADeviceInput) (*svcsdk.DeactivateMFADeviceOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	serial := aws.ToString(in.SerialNumber)
+	if !remove(&u.mfaDevices, serial) {
+		return nil, noSuchEntity("MFA Device with serial number %s does not exist.", serial)
+	}
+	if d, ok := b.virtualMFADevices[serial]; ok {
+		d.user = ""
+	}
+	return &svcsdk.DeactivateMFADeviceOutput{}, nil
+}
+
+func (b *Backend) deleteVirtualMFADevice(in *svcsdk.DeleteVirtualMFADeviceInput) (*svcsdk.DeleteVirtualMFADeviceOutput, error) {
+	serial := aws.ToString(in.SerialNumber)
+	d, ok := b.virtualMFADevices[serial]
+	if !ok {
+		return nil, noSuchEntity("VirtualMFADevice with serial number %s doesn't exist.", serial)
+	}
+	if d.user != "" {
+		return nil, deleteConflict("MFA Device %s is still in use by a user.", serial)
+	}
+	delete(b.virtualMFADevices, serial)
+	return &svcsdk.DeleteVirtualMFADeviceOutput{}, nil
+}
@@ -0,0 +1,69 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fakeiam
+
+import (
+	"fmt"
+
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/smithy-go"
+)
+
+// The errors below are the ones IAM returns, with the same error codes, so
+// that callers can tell them apart with smithy.APIError.
+
+func noSuchEntity(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return &svcsdktypes.NoSuchEntityException{Message: &msg}
+}
+
+func entityAlreadyExists(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return &svcsdktypes.EntityAlreadyExistsException{Message: &msg}
+}
+
+func deleteConflict(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return &svcsdktypes.DeleteConflictException{Message: &msg}
+}
+
+func limitExceeded(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return &svcsdktypes.LimitExceededException{Message: &msg}
+}
+
+func invalidInput(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return &svcsdktypes.InvalidInputException{Message: &msg}
+}
+
+func malformedPolicyDocument(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return &svcsdktypes.MalformedPolicyDocumentException{Message: &msg}
+}
+
+func unmodifiableEntity(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return &svcsdktypes.UnmodifiableEntityException{Message: &msg}
+}
+
+// validationError is returned when a parameter does not match the
+// constraints of the IAM API model, which the SDK does not validate.
+func validationError(format string, args ...interface{}) error {
+	return &smithy.GenericAPIError{
+		Code:    "ValidationError",
+		Message: fmt.Sprintf(format, args...),
+		Fault:   smithy.FaultClient,
+	}
+}
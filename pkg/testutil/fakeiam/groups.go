@@ -0,0 +1,234 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fakeiam
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// groupInlineQuota is the aggregate size of the inline policies of a group.
+const groupInlineQuota = 5120
+
+type group struct {
+	principal
+	name string
+	path string
+	id   string
+	arn  string
+	// members are the keys of the users of the group.
+	members    map[string]bool
+	createDate time.Time
+}
+
+func (g *group) output() *svcsdktypes.Group {
+	return &svcsdktypes.Group{
+		Arn:        aws.String(g.arn),
+		CreateDate: aws.Time(g.createDate),
+		GroupId:    aws.String(g.id),
+		GroupName:  aws.String(g.name),
+		Path:       aws.String(g.path),
+	}
+}
+
+// group returns the group of the supplied name.
+func (b *Backend) group(name *string) (*group, error) {
+	g, ok := b.groups[key(name)]
+	if !ok {
+		return nil, noSuchEntity("The group with name %s cannot be found.", aws.ToString(name))
+	}
+	return g, nil
+}
+
+func (b *Backend) createGroup(in *svcsdk.CreateGroupInput) (*svcsdk.CreateGroupOutput, error) {
+	if _, ok := b.groups[key(in.GroupName)]; ok {
+		return nil, entityAlreadyExists("Group with name %s already exists.", *in.GroupName)
+	}
+	path, err := pathOrDefault(in.Path)
+	if err != nil {
+		return nil, err
+	}
+	g := &group{
+		principal:  newPrincipal("group", groupInlineQuota),
+		name:       *in.GroupName,
+		path:       path,
+		id:         b.newID("AGPA"),
+		arn:        arn("group", path, *in.GroupName),
+		members:    map[string]bool{},
+		createDate: b.now(),
+	}
+	b.groups[key(in.GroupName)] = g
+	return &svcsdk.CreateGroupOutput{Group: g.output()}, nil
+}
+
+func (b *Backend) getGroup(in *svcsdk.GetGroupInput) (*svcsdk.GetGroupOutput, error) {
+	g, err := b.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	users := []svcsdktypes.User{}
+	for _, k := range sortedKeys(g.members) {
+		out := b.users[k].output()
+		out.Tags = nil
+		out.PermissionsBoundary = nil
+		users = append(users, *out)
+	}
+	return &svcsdk.GetGroupOutput{Group: g.output(), Users: users}, nil
+}
+
+func (b *Backend) updateGroup(in *svcsdk.UpdateGroupInput) (*svcsdk.UpdateGroupOutput, error) {
+	g, err := b.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	if in.NewPath != nil {
+		path, err := pathOrDefault(in.NewPath)
+		if err != nil {
+			return nil, err
+		}
+		g.path = path
+	}
+	if in.NewGroupName != nil && key(in.NewGroupName) != key(in.GroupName) {
+		if _, ok := b.groups[key(in.NewGroupName)]; ok {
+			return nil, entityAlreadyExists("Group with name %s already exists.", *in.NewGroupName)
+		}
+		delete(b.groups, key(in.GroupName))
+		b.groups[key(in.NewGroupName)] = g
+	}
+	if in.NewGroupName != nil {
+		g.name = *in.NewGroupName
+	}
+	g.arn = arn("group", g.path, g.name)
+	return &svcsdk.UpdateGroupOutput{}, nil
+}
+
+func (b *Backend) deleteGroup(in *svcsdk.DeleteGroupInput) (*svcsdk.DeleteGroupOutput, error) {
+	g, err := b.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	if len(g.members) > 0 {
+		return nil, deleteConflict("Cannot delete entity, must remove users from group first.")
+	}
+	if err := g.deleteConflict(); err != nil {
+		return nil, err
+	}
+	delete(b.groups, key(in.GroupName))
+	return &svcsdk.DeleteGroupOutput{}, nil
+}
+
+func (b *Backend) listGroups(in *svcsdk.ListGroupsInput) (*svcsdk.ListGroupsOutput, error) {
+	res := []svcsdktypes.Group{}
+	for _, k := range sortedKeys(b.groups) {
+		g := b.groups[k]
+		if hasPathPrefix(g.path, in.PathPrefix) {
+			res = append(res, *g.output())
+		}
+	}
+	return &svcsdk.ListGroupsOutput{Groups: res}, nil
+}
+
+func (b *Backend) addUserToGroup(in *svcsdk.AddUserToGroupInput) (*svcsdk.AddUserToGroupOutput, error) {
+	g, err := b.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := b.user(in.UserName); err != nil {
+		return nil, err
+	}
+	g.members[key(in.UserName)] = true
+	return &svcsdk.AddUserToGroupOutput{}, nil
+}
+
+func (b *Backend) removeUserFromGroup(in *svcsdk.RemoveUserFromGroupInput) (*svcsdk.RemoveUserFromGroupOutput, error) {
+	g, err := b.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := b.user(in.UserName); err != nil {
+		return nil, err
+	}
+	if !g.members[key(in.UserName)] {
+		return nil, noSuchEntity("User %s is not in group %s.", *in.UserName, g.name)
+	}
+	delete(g.members, key(in.UserName))
+	return &svcsdk.RemoveUserFromGroupOutput{}, nil
+}
+
+func (b *Backend) attachGroupPolicy(in *svcsdk.AttachGroupPolicyInput) (*svcsdk.AttachGroupPolicyOutput, error) {
+	g, err := b.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.AttachGroupPolicyOutput{}, b.attach(&g.principal, in.PolicyArn)
+}
+
+func (b *Backend) detachGroupPolicy(in *svcsdk.DetachGroupPolicyInput) (*svcsdk.DetachGroupPolicyOutput, error) {
+	g, err := b.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.DetachGroupPolicyOutput{}, g.detach(in.PolicyArn)
+}
+
+func (b *Backend) listAttachedGroupPolicies(in *svcsdk.ListAttachedGroupPoliciesInput) (*svcsdk.ListAttachedGroupPoliciesOutput, error) {
+	g, err := b.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.ListAttachedGroupPoliciesOutput{AttachedPolicies: b.listAttached(&g.principal, in.PathPrefix)}, nil
+}
+
+func (b *Backend) putGroupPolicy(in *svcsdk.PutGroupPolicyInput) (*svcsdk.PutGroupPolicyOutput, error) {
+	g, err := b.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.PutGroupPolicyOutput{}, g.putInline(g.name, in.PolicyName, in.PolicyDocument)
+}
+
+func (b *Backend) getGroupPolicy(in *svcsdk.GetGroupPolicyInput) (*svcsdk.GetGroupPolicyOutput, error) {
+	g, err := b.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := g.getInline(in.PolicyName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.GetGroupPolicyOutput{
+		GroupName:      aws.String(g.name),
+		PolicyDocument: doc,
+		PolicyName:     aws.String(*in.PolicyName),
+	}, nil
+}
+
+func (b *Backend) deleteGroupPolicy(in *svcsdk.DeleteGroupPolicyInput) (*svcsdk.DeleteGroupPolicyOutput, error) {
+	g, err := b.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.DeleteGroupPolicyOutput{}, g.deleteInline(in.PolicyName)
+}
+
+func (b *Backend) listGroupPolicies(in *svcsdk.ListGroupPoliciesInput) (*svcsdk.ListGroupPoliciesOutput, error) {
+	g, err := b.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.ListGroupPoliciesOutput{PolicyNames: g.listInline()}, nil
+}
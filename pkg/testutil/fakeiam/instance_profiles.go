@@ -0,0 +1,180 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fakeiam
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+type instanceProfile struct {
+	name string
+	path string
+	id   string
+	arn  string
+	// role is the key of the role of the instance profile, or empty. An
+	// instance profile holds at most one role.
+	role       string
+	tags       []svcsdktypes.Tag
+	createDate time.Time
+}
+
+func (b *Backend) instanceProfileOutput(ip *instanceProfile) *svcsdktypes.InstanceProfile {
+	roles := []svcsdktypes.Role{}
+	if r, ok := b.roles[ip.role]; ok {
+		out := r.output()
+		out.Tags = nil
+		out.PermissionsBoundary = nil
+		roles = append(roles, *out)
+	}
+	return &svcsdktypes.InstanceProfile{
+		Arn:                 aws.String(ip.arn),
+		CreateDate:          aws.Time(ip.createDate),
+		InstanceProfileId:   aws.String(ip.id),
+		InstanceProfileName: aws.String(ip.name),
+		Path:                aws.String(ip.path),
+		Roles:               roles,
+		Tags:                copyTags(ip.tags),
+	}
+}
+
+// instanceProfile returns the instance profile of the supplied name.
+func (b *Backend) instanceProfile(name *string) (*instanceProfile, error) {
+	ip, ok := b.instanceProfiles[key(name)]
+	if !ok {
+		return nil, noSuchEntity("Instance Profile %s cannot be found.", aws.ToString(name))
+	}
+	return ip, nil
+}
+
+func (b *Backend) createInstanceProfile(in *svcsdk.CreateInstanceProfileInput) (*svcsdk.CreateInstanceProfileOutput, error) {
+	if _, ok := b.instanceProfiles[key(in.InstanceProfileName)]; ok {
+		return nil, entityAlreadyExists("Instance Profile %s already exists.", *in.InstanceProfileName)
+	}
+	path, err := pathOrDefault(in.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTags(in.Tags); err != nil {
+		return nil, err
+	}
+	ip := &instanceProfile{
+		name:       *in.InstanceProfileName,
+		path:       path,
+		id:         b.newID("AIPA"),
+		arn:        arn("instance-profile", path, *in.InstanceProfileName),
+		tags:       copyTags(in.Tags),
+		createDate: b.now(),
+	}
+	b.instanceProfiles[key(in.InstanceProfileName)] = ip
+	return &svcsdk.CreateInstanceProfileOutput{InstanceProfile: b.instanceProfileOutput(ip)}, nil
+}
+
+func (b *Backend) getInstanceProfile(in *svcsdk.GetInstanceProfileInput) (*svcsdk.GetInstanceProfileOutput, error) {
+	ip, err := b.instanceProfile(in.InstanceProfileName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.GetInstanceProfileOutput{InstanceProfile: b.instanceProfileOutput(ip)}, nil
+}
+
+func (b *Backend) deleteInstanceProfile(in *svcsdk.DeleteInstanceProfileInput) (*svcsdk.DeleteInstanceProfileOutput, error) {
+	ip, err := b.instanceProfile(in.InstanceProfileName)
+	if err != nil {
+		return nil, err
+	}
+	if ip.role != "" {
+		return nil, deleteConflict("Cannot delete entity, must remove roles from instance profile first.")
+	}
+	delete(b.instanceProfiles, key(in.InstanceProfileName))
+	return &svcsdk.DeleteInstanceProfileOutput{}, nil
+}
+
+func (b *Backend) listInstanceProfiles(in *svcsdk.ListInstanceProfilesInput) (*svcsdk.ListInstanceProfilesOutput, error) {
+	res := []svcsdktypes.InstanceProfile{}
+	for _, k := range sortedKeys(b.instanceProfiles) {
+		ip := b.instanceProfiles[k]
+		if !hasPathPrefix(ip.path, in.PathPrefix) {
+			continue
+		}
+		// ListInstanceProfiles does not return tags.
+		out := b.instanceProfileOutput(ip)
+		out.Tags = nil
+		res = append(res, *out)
+	}
+	return &svcsdk.ListInstanceProfilesOutput{InstanceProfiles: res}, nil
+}
+
+func (b *Backend) addRoleToInstanceProfile(in *svcsdk.AddRoleToInstanceProfileInput) (*svcsdk.AddRoleToInstanceProfileOutput, error) {
+	ip, err := b.instanceProfile(in.InstanceProfileName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := b.role(in.RoleName); err != nil {
+		return nil, err
+	}
+	if ip.role != "" {
+		return nil, limitExceeded("Cannot exceed quota for InstanceSessionsPerInstanceProfile: 1")
+	}
+	ip.role = key(in.RoleName)
+	return &svcsdk.AddRoleToInstanceProfileOutput{}, nil
+}
+
+func (b *Backend) removeRoleFromInstanceProfile(in *svcsdk.RemoveRoleFromInstanceProfileInput) (*svcsdk.RemoveRoleFromInstanceProfileOutput, error) {
+	ip, err := b.instanceProfile(in.InstanceProfileName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := b.role(in.RoleName); err != nil {
+		return nil, err
+	}
+	if ip.role != key(in.RoleName) {
+		return nil, noSuchEntity("Role %s in Instance Profile %s cannot be found.", *in.RoleName, ip.name)
+	}
+	ip.role = ""
+	return &svcsdk.RemoveRoleFromInstanceProfileOutput{}, nil
+}
+
+func (b *Backend) tagInstanceProfile(in *svcsdk.TagInstanceProfileInput) (*svcsdk.TagInstanceProfileOutput, error) {
+	ip, err := b.instanceProfile(in.InstanceProfileName)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := tag(ip.tags, in.Tags)
+	if err != nil {
+		return nil, err
+	}
+	ip.tags = tags
+	return &svcsdk.TagInstanceProfileOutput{}, nil
+}
+
+func (b *Backend) untagInstanceProfile(in *svcsdk.UntagInstanceProfileInput) (*svcsdk.UntagInstanceProfileOutput, error) {
+	ip, err := b.instanceProfile(in.InstanceProfileName)
+	if err != nil {
+		return nil, err
+	}
+	ip.tags = untag(ip.tags, in.TagKeys)
+	return &svcsdk.UntagInstanceProfileOutput{}, nil
+}
+
+func (b *Backend) listInstanceProfileTags(in *svcsdk.ListInstanceProfileTagsInput) (*svcsdk.ListInstanceProfileTagsOutput, error) {
+	ip, err := b.instanceProfile(in.InstanceProfileName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.ListInstanceProfileTagsOutput{Tags: nonNilTags(ip.tags)}, nil
+}
@@ -0,0 +1,169 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fakeiam
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+type oidcProvider struct {
+	// url is the URL of the provider, without its https:// scheme, as
+	// returned by GetOpenIDConnectProvider.
+	url         string
+	arn         string
+	clientIDs   []string
+	thumbprints []string
+	tags        []svcsdktypes.Tag
+	createDate  time.Time
+}
+
+// oidcProvider returns the OpenID Connect provider of the supplied ARN.
+func (b *Backend) oidcProvider(providerARN *string) (*oidcProvider, error) {
+	p, ok := b.oidcProviders[aws.ToString(providerARN)]
+	if !ok {
+		return nil, noSuchEntity("OpenIDConnect Provider not found for arn %s", aws.ToString(providerARN))
+	}
+	return p, nil
+}
+
+func (b *Backend) createOpenIDConnectProvider(in *svcsdk.CreateOpenIDConnectProviderInput) (*svcsdk.CreateOpenIDConnectProviderOutput, error) {
+	url, ok := strings.CutPrefix(aws.ToString(in.Url), "https://")
+	if !ok || url == "" {
+		return nil, validationError("Url must begin with https://")
+	}
+	providerARN := fmt.Sprintf("arn:aws:iam::%s:oidc-provider/%s", AccountID, url)
+	if _, ok := b.oidcProviders[providerARN]; ok {
+		return nil, entityAlreadyExists("Provider with url %s already exists.", *in.Url)
+	}
+	if err := validateTags(in.Tags); err != nil {
+		return nil, err
+	}
+	b.oidcProviders[providerARN] = &oidcProvider{
+		url:         url,
+		arn:         providerARN,
+		clientIDs:   append([]string{}, in.ClientIDList...),
+		thumbprints: append([]string{}, in.ThumbprintList...),
+		tags:        copyTags(in.Tags),
+		createDate:  b.now(),
+	}
+	return &svcsdk.CreateOpenIDConnectProviderOutput{
+		OpenIDConnectProviderArn: aws.String(providerARN),
+		Tags:                     copyTags(in.Tags),
+	}, nil
+}
+
+func (b *Backend) getOpenIDConnectProvider(in *svcsdk.GetOpenIDConnectProviderInput) (*svcsdk.GetOpenIDConnectProviderOutput, error) {
+	p, err := b.oidcProvider(in.OpenIDConnectProviderArn)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.GetOpenIDConnectProviderOutput{
+		ClientIDList:   append([]string{}, p.clientIDs...),
+		CreateDate:     aws.Time(p.createDate),
+		Tags:           copyTags(p.tags),
+		ThumbprintList: append([]string{}, p.thumbprints...),
+		Url:            aws.String(p.url),
+	}, nil
+}
+
+func (b *Backend) deleteOpenIDConnectProvider(in *svcsdk.DeleteOpenIDConnectProviderInput) (*svcsdk.DeleteOpenIDConnectProviderOutput, error) {
+	p, err := b.oidcProvider(in.OpenIDConnectProviderArn)
+	if err != nil {
+		return nil, err
+	}
+	delete(b.oidcProviders, p.arn)
+	return &svcsdk.DeleteOpenIDConnectProviderOutput{}, nil
+}
+
+func (b *Backend) listOpenIDConnectProviders(in *svcsdk.ListOpenIDConnectProvidersInput) (*svcsdk.ListOpenIDConnectProvidersOutput, error) {
+	res := []svcsdktypes.OpenIDConnectProviderListEntry{}
+	for _, k := range sortedKeys(b.oidcProviders) {
+		res = append(res, svcsdktypes.OpenIDConnectProviderListEntry{Arn: aws.String(k)})
+	}
+	return &svcsdk.ListOpenIDConnectProvidersOutput{OpenIDConnectProviderList: res}, nil
+}
+
+func (b *Backend) addClientIDToOpenIDConnectProvider(in *svcsdk.AddClientIDToOpenIDConnectProviderInput) (*svcsdk.AddClientIDToOpenIDConnectProviderOutput, error) {
+	p, err := b.oidcProvider(in.OpenIDConnectProviderArn)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range p.clientIDs {
+		if id == *in.ClientID {
+			return &svcsdk.AddClientIDToOpenIDConnectProviderOutput{}, nil
+		}
+	}
+	p.clientIDs = append(p.clientIDs, *in.ClientID)
+	return &svcsdk.AddClientIDToOpenIDConnectProviderOutput{}, nil
+}
+
+func (b *Backend) removeClientIDFromOpenIDConnectProvider(in *svcsdk.RemoveClientIDFromOpenIDConnectProviderInput) (*svcsdk.RemoveClientIDFromOpenIDConnectProviderOutput, error) {
+	p, err := b.oidcProvider(in.OpenIDConnectProviderArn)
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, id := range p.clientIDs {
+		if id != *in.ClientID {
+			ids = append(ids, id)
+		}
+	}
+	p.clientIDs = ids
+	return &svcsdk.RemoveClientIDFromOpenIDConnectProviderOutput{}, nil
+}
+
+func (b *Backend) updateOpenIDConnectProviderThumbprint(in *svcsdk.UpdateOpenIDConnectProviderThumbprintInput) (*svcsdk.UpdateOpenIDConnectProviderThumbprintOutput, error) {
+	p, err := b.oidcProvider(in.OpenIDConnectProviderArn)
+	if err != nil {
+		return nil, err
+	}
+	p.thumbprints = append([]string{}, in.ThumbprintList...)
+	return &svcsdk.UpdateOpenIDConnectProviderThumbprintOutput{}, nil
+}
+
+func (b *Backend) tagOpenIDConnectProvider(in *svcsdk.TagOpenIDConnectProviderInput) (*svcsdk.TagOpenIDConnectProviderOutput, error) {
+	p, err := b.oidcProvider(in.OpenIDConnectProviderArn)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := tag(p.tags, in.Tags)
+	if err != nil {
+		return nil, err
+	}
+	p.tags = tags
+	return &svcsdk.TagOpenIDConnectProviderOutput{}, nil
+}
+
+func (b *Backend) untagOpenIDConnectProvider(in *svcsdk.UntagOpenIDConnectProviderInput) (*svcsdk.UntagOpenIDConnectProviderOutput, error) {
+	p, err := b.oidcProvider(in.OpenIDConnectProviderArn)
+	if err != nil {
+		return nil, err
+	}
+	p.tags = untag(p.tags, in.TagKeys)
+	return &svcsdk.UntagOpenIDConnectProviderOutput{}, nil
+}
+
+func (b *Backend) listOpenIDConnectProviderTags(in *svcsdk.ListOpenIDConnectProviderTagsInput) (*svcsdk.ListOpenIDConnectProviderTagsOutput, error) {
+	p, err := b.oidcProvider(in.OpenIDConnectProviderArn)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.ListOpenIDConnectProviderTagsOutput{Tags: nonNilTags(p.tags)}, nil
+}
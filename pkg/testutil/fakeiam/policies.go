@@ -0,0 +1,455 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fakeiam
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+const (
+	// managedPolicyQuota is the size of the document of a managed policy.
+	managedPolicyQuota = 6144
+	// maxPolicyVersions is the number of versions a managed policy can have.
+	maxPolicyVersions = 5
+)
+
+type policy struct {
+	name        string
+	path        string
+	id          string
+	arn         string
+	description *string
+	// awsManaged is true for the AWS managed policies, which can only be
+	// attached.
+	awsManaged bool
+	// versions are the versions of the policy, in creation order.
+	versions       []*policyVersion
+	defaultVersion string
+	// lastVersion is the number of the last version created. Version IDs
+	// are not reused.
+	lastVersion int
+	tags        []svcsdktypes.Tag
+	createDate  time.Time
+	updateDate  time.Time
+}
+
+type policyVersion struct {
+	id         string
+	document   string
+	createDate time.Time
+}
+
+// AddAWSManagedPolicy adds the AWS managed policy of the supplied name and
+// document to the Backend, so that it can be attached by ARN and looked up by
+// name.
+func (b *Backend) AddAWSManagedPolicy(name string, document string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	p := &policy{
+		name:           name,
+		path:           "/",
+		id:             b.newID("ANPA"),
+		arn:            fmt.Sprintf("arn:aws:iam::aws:policy/%s", name),
+		awsManaged:     true,
+		versions:       []*policyVersion{{id: "v1", document: document, createDate: now}},
+		defaultVersion: "v1",
+		lastVersion:    1,
+		createDate:     now,
+		updateDate:     now,
+	}
+	b.policies[p.arn] = p
+}
+
+func (b *Backend) policyOutput(p *policy) *svcsdktypes.Policy {
+	return &svcsdktypes.Policy{
+		Arn:                           aws.String(p.arn),
+		AttachmentCount:               aws.Int32(b.attachmentCount(p.arn)),
+		CreateDate:                    aws.Time(p.createDate),
+		DefaultVersionId:              aws.String(p.defaultVersion),
+		Description:                   p.description,
+		IsAttachable:                  true,
+		Path:                          aws.String(p.path),
+		PermissionsBoundaryUsageCount: aws.Int32(b.boundaryUsageCount(p.arn)),
+		PolicyId:                      aws.String(p.id),
+		PolicyName:                    aws.String(p.name),
+		Tags:                          copyTags(p.tags),
+		UpdateDate:                    aws.Time(p.updateDate),
+	}
+}
+
+// policy returns the managed policy of the supplied ARN.
+func (b *Backend) policy(policyARN *string) (*policy, error) {
+	p, ok := b.policies[aws.ToString(policyARN)]
+	if !ok {
+		return nil, noSuchEntity("Policy %s was not found.", aws.ToString(policyARN))
+	}
+	return p, nil
+}
+
+// customerPolicy returns the customer managed policy of the supplied ARN.
+func (b *Backend) customerPolicy(policyARN *string) (*policy, error) {
+	p, err := b.policy(policyARN)
+	if err != nil {
+		return nil, err
+	}
+	if p.awsManaged {
+		return nil, unmodifiableEntity("Policy %s is an AWS managed policy.", p.arn)
+	}
+	return p, nil
+}
+
+// version returns the version of the supplied ID of the policy.
+func (p *policy) version(id *string) (int, error) {
+	for i, v := range p.versions {
+		if v.id == aws.ToString(id) {
+			return i, nil
+		}
+	}
+	return 0, noSuchEntity("Policy %s version %s does not exist or is not attachable.", p.arn, aws.ToString(id))
+}
+
+// attachmentCount returns the number of roles, users and groups the managed
+// policy of the supplied ARN is attached to.
+func (b *Backend) attachmentCount(policyARN string) int32 {
+	n := int32(0)
+	for _, p := range b.principals() {
+		for _, a := range p.attached {
+			if a == policyARN {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// boundaryUsageCount returns the number of roles and users the managed policy
+// of the supplied ARN is the permissions boundary of.
+func (b *Backend) boundaryUsageCount(policyARN string) int32 {
+	n := int32(0)
+	for _, r := range b.roles {
+		if r.boundary == policyARN {
+			n++
+		}
+	}
+	for _, u := range b.users {
+		if u.boundary == policyARN {
+			n++
+		}
+	}
+	return n
+}
+
+// principals returns the policies of every role, user and group.
+func (b *Backend) principals() []*principal {
+	res := []*principal{}
+	for _, r := range b.roles {
+		res = append(res, &r.principal)
+	}
+	for _, u := range b.users {
+		res = append(res, &u.principal)
+	}
+	for _, g := range b.groups {
+		res = append(res, &g.principal)
+	}
+	return res
+}
+
+func validateManagedPolicyDocument(doc *string) error {
+	if err := validateDocument(doc); err != nil {
+		return err
+	}
+	if policySize(*doc) > managedPolicyQuota {
+		return limitExceeded("Cannot exceed quota for PolicySize: %d", managedPolicyQuota)
+	}
+	return nil
+}
+
+func (b *Backend) createPolicy(in *svcsdk.CreatePolicyInput) (*svcsdk.CreatePolicyOutput, error) {
+	path, err := pathOrDefault(in.Path)
+	if err != nil {
+		return nil, err
+	}
+	policyARN := arn("policy", path, *in.PolicyName)
+	if _, ok := b.policies[policyARN]; ok {
+		return nil, entityAlreadyExists("A policy called %s already exists. Duplicate names are not allowed.", *in.PolicyName)
+	}
+	if err := validateManagedPolicyDocument(in.PolicyDocument); err != nil {
+		return nil, err
+	}
+	if err := validateTags(in.Tags); err != nil {
+		return nil, err
+	}
+	now := b.now()
+	p := &policy{
+		name:           *in.PolicyName,
+		path:           path,
+		id:             b.newID("ANPA"),
+		arn:            policyARN,
+		description:    in.Description,
+		versions:       []*policyVersion{{id: "v1", document: *in.PolicyDocument, createDate: now}},
+		defaultVersion: "v1",
+		lastVersion:    1,
+		tags:           copyTags(in.Tags),
+		createDate:     now,
+		updateDate:     now,
+	}
+	b.policies[policyARN] = p
+	out := b.policyOutput(p)
+	// CreatePolicy does not return the description.
+	out.Description = nil
+	return &svcsdk.CreatePolicyOutput{Policy: out}, nil
+}
+
+func (b *Backend) getPolicy(in *svcsdk.GetPolicyInput) (*svcsdk.GetPolicyOutput, error) {
+	p, err := b.policy(in.PolicyArn)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.GetPolicyOutput{Policy: b.policyOutput(p)}, nil
+}
+
+func (b *Backend) deletePolicy(in *svcsdk.DeletePolicyInput) (*svcsdk.DeletePolicyOutput, error) {
+	p, err := b.customerPolicy(in.PolicyArn)
+	if err != nil {
+		return nil, err
+	}
+	if b.attachmentCount(p.arn) > 0 || b.boundaryUsageCount(p.arn) > 0 {
+		return nil, deleteConflict("Cannot delete a policy attached to entities.")
+	}
+	if len(p.versions) > 1 {
+		return nil, deleteConflict(
+			"This policy has more than one version. Before you delete a policy, " +
+				"you must delete the policy's versions. The default version is deleted with the policy.",
+		)
+	}
+	delete(b.policies, p.arn)
+	return &svcsdk.DeletePolicyOutput{}, nil
+}
+
+func (b *Backend) listPolicies(in *svcsdk.ListPoliciesInput) (*svcsdk.ListPoliciesOutput, error) {
+	res := []svcsdktypes.Policy{}
+	for _, k := range sortedKeys(b.policies) {
+		p := b.policies[k]
+		switch {
+		case in.Scope == svcsdktypes.PolicyScopeTypeAws && !p.awsManaged,
+			in.Scope == svcsdktypes.PolicyScopeTypeLocal && p.awsManaged,
+			!hasPathPrefix(p.path, in.PathPrefix),
+			in.OnlyAttached && b.attachmentCount(p.arn) == 0:
+			continue
+		}
+		// ListPolicies does not return descriptions and tags.
+		out := b.policyOutput(p)
+		out.Description = nil
+		out.Tags = nil
+		res = append(res, *out)
+	}
+	return &svcsdk.ListPoliciesOutput{Policies: res}, nil
+}
+
+func (b *Backend) createPolicyVersion(in *svcsdk.CreatePolicyVersionInput) (*svcsdk.CreatePolicyVersionOutput, error) {
+	p, err := b.customerPolicy(in.PolicyArn)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.versions) >= maxPolicyVersions {
+		return nil, limitExceeded(
+			"A managed policy can have up to %d versions. Before you create a new version, "+
+				"you must delete an existing version.", maxPolicyVersions,
+		)
+	}
+	if err := validateManagedPolicyDocument(in.PolicyDocument); err != nil {
+		return nil, err
+	}
+	p.lastVersion++
+	v := &policyVersion{
+		id:         fmt.Sprintf("v%d", p.lastVersion),
+		document:   *in.PolicyDocument,
+		createDate: b.now(),
+	}
+	p.versions = append(p.versions, v)
+	if in.SetAsDefault {
+		p.defaultVersion = v.id
+	}
+	p.updateDate = v.createDate
+	return &svcsdk.CreatePolicyVersionOutput{PolicyVersion: &svcsdktypes.PolicyVersion{
+		CreateDate:       aws.Time(v.createDate),
+		IsDefaultVersion: p.defaultVersion == v.id,
+		VersionId:        aws.String(v.id),
+	}}, nil
+}
+
+func (b *Backend) getPolicyVersion(in *svcsdk.GetPolicyVersionInput) (*svcsdk.GetPolicyVersionOutput, error) {
+	p, err := b.policy(in.PolicyArn)
+	if err != nil {
+		return nil, err
+	}
+	i, err := p.version(in.VersionId)
+	if err != nil {
+		return nil, err
+	}
+	v := p.versions[i]
+	return &svcsdk.GetPolicyVersionOutput{PolicyVersion: &svcsdktypes.PolicyVersion{
+		CreateDate:       aws.Time(v.createDate),
+		Document:         encodeDocument(v.document),
+		IsDefaultVersion: p.defaultVersion == v.id,
+		VersionId:        aws.String(v.id),
+	}}, nil
+}
+
+func (b *Backend) deletePolicyVersion(in *svcsdk.DeletePolicyVersionInput) (*svcsdk.DeletePolicyVersionOutput, error) {
+	p, err := b.customerPolicy(in.PolicyArn)
+	if err != nil {
+		return nil, err
+	}
+	i, err := p.version(in.VersionId)
+	if err != nil {
+		return nil, err
+	}
+	if p.versions[i].id == p.defaultVersion {
+		return nil, deleteConflict("Cannot delete the default version of a policy.")
+	}
+	p.versions = append(p.versions[:i:i], p.versions[i+1:]...)
+	return &svcsdk.DeletePolicyVersionOutput{}, nil
+}
+
+func (b *Backend) listPolicyVersions(in *svcsdk.ListPolicyVersionsInput) (*svcsdk.ListPolicyVersionsOutput, error) {
+	p, err := b.policy(in.PolicyArn)
+	if err != nil {
+		return nil, err
+	}
+	// Versions are listed from the most recent one, without their document.
+	res := []svcsdktypes.PolicyVersion{}
+	for i := len(p.versions) - 1; i >= 0; i-- {
+		v := p.versions[i]
+		res = append(res, svcsdktypes.PolicyVersion{
+			CreateDate:       aws.Time(v.createDate),
+			IsDefaultVersion: p.defaultVersion == v.id,
+			VersionId:        aws.String(v.id),
+		})
+	}
+	return &svcsdk.ListPolicyVersionsOutput{Versions: res}, nil
+}
+
+func (b *Backend) setDefaultPolicyVersion(in *svcsdk.SetDefaultPolicyVersionInput) (*svcsdk.SetDefaultPolicyVersionOutput, error) {
+	p, err := b.customerPolicy(in.PolicyArn)
+	if err != nil {
+		return nil, err
+	}
+	i, err := p.version(in.VersionId)
+	if err != nil {
+		return nil, err
+	}
+	p.defaultVersion = p.versions[i].id
+	return &svcsdk.SetDefaultPolicyVersionOutput{}, nil
+}
+
+func (b *Backend) listEntitiesForPolicy(in *svcsdk.ListEntitiesForPolicyInput) (*svcsdk.ListEntitiesForPolicyOutput, error) {
+	p, err := b.policy(in.PolicyArn)
+	if err != nil {
+		return nil, err
+	}
+	uses := func(pr *principal, boundaryARN string) bool {
+		if in.PolicyUsageFilter != svcsdktypes.PolicyUsageTypePermissionsBoundary {
+			for _, a := range pr.attached {
+				if a == p.arn {
+					return true
+				}
+			}
+		}
+		if in.PolicyUsageFilter != svcsdktypes.PolicyUsageTypePermissionsPolicy {
+			return boundaryARN == p.arn
+		}
+		return false
+	}
+	filter := func(kind svcsdktypes.EntityType) bool {
+		return in.EntityFilter == "" || in.EntityFilter == kind
+	}
+
+	// Entities are listed in group, role, user order, and paginated with the
+	// offset of the next entity as marker.
+	start, end, err := page(in.Marker, in.MaxItems)
+	if err != nil {
+		return nil, err
+	}
+	out := &svcsdk.ListEntitiesForPolicyOutput{
+		PolicyGroups: []svcsdktypes.PolicyGroup{},
+		PolicyRoles:  []svcsdktypes.PolicyRole{},
+		PolicyUsers:  []svcsdktypes.PolicyUser{},
+	}
+	n := 0
+	inPage := func() bool {
+		n++
+		return n > start && n <= end
+	}
+	for _, k := range sortedKeys(b.groups) {
+		g := b.groups[k]
+		if filter(svcsdktypes.EntityTypeGroup) && hasPathPrefix(g.path, in.PathPrefix) && uses(&g.principal, "") && inPage() {
+			out.PolicyGroups = append(out.PolicyGroups, svcsdktypes.PolicyGroup{GroupId: aws.String(g.id), GroupName: aws.String(g.name)})
+		}
+	}
+	for _, k := range sortedKeys(b.roles) {
+		r := b.roles[k]
+		if filter(svcsdktypes.EntityTypeRole) && hasPathPrefix(r.path, in.PathPrefix) && uses(&r.principal, r.boundary) && inPage() {
+			out.PolicyRoles = append(out.PolicyRoles, svcsdktypes.PolicyRole{RoleId: aws.String(r.id), RoleName: aws.String(r.name)})
+		}
+	}
+	for _, k := range sortedKeys(b.users) {
+		u := b.users[k]
+		if filter(svcsdktypes.EntityTypeUser) && hasPathPrefix(u.path, in.PathPrefix) && uses(&u.principal, u.boundary) && inPage() {
+			out.PolicyUsers = append(out.PolicyUsers, svcsdktypes.PolicyUser{UserId: aws.String(u.id), UserName: aws.String(u.name)})
+		}
+	}
+	if n > end {
+		out.IsTruncated = true
+		out.Marker = aws.String(strconv.Itoa(end))
+	}
+	return out, nil
+}
+
+func (b *Backend) tagPolicy(in *svcsdk.TagPolicyInput) (*svcsdk.TagPolicyOutput, error) {
+	p, err := b.customerPolicy(in.PolicyArn)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := tag(p.tags, in.Tags)
+	if err != nil {
+		return nil, err
+	}
+	p.tags = tags
+	return &svcsdk.TagPolicyOutput{}, nil
+}
+
+func (b *Backend) untagPolicy(in *svcsdk.UntagPolicyInput) (*svcsdk.UntagPolicyOutput, error) {
+	p, err := b.customerPolicy(in.PolicyArn)
+	if err != nil {
+		return nil, err
+	}
+	p.tags = untag(p.tags, in.TagKeys)
+	return &svcsdk.UntagPolicyOutput{}, nil
+}
+
+func (b *Backend) listPolicyTags(in *svcsdk.ListPolicyTagsInput) (*svcsdk.ListPolicyTagsOutput, error) {
+	p, err := b.policy(in.PolicyArn)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.ListPolicyTagsOutput{Tags: nonNilTags(p.tags)}, nil
+}
@@ -0,0 +1,272 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fakeiam
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+const (
+	// maxAttachedPolicies is the number of managed policies that can be
+	// attached to a role, user or group.
+	maxAttachedPolicies = 10
+	// maxTags is the number of tags an IAM resource can carry.
+	maxTags = 50
+)
+
+// principal holds the policies of a role, user or group.
+type principal struct {
+	// kind is the kind of the principal, as IAM names it in its error
+	// messages: role, user or group.
+	kind string
+	// inlineQuota is the maximum aggregate size of the inline policies of
+	// the principal.
+	inlineQuota int
+	// inline are the inline policy documents, indexed by policy name.
+	inline map[string]string
+	// attached are the ARNs of the attached managed policies, in attach
+	// order.
+	attached []string
+}
+
+func newPrincipal(kind string, inlineQuota int) principal {
+	return principal{kind: kind, inlineQuota: inlineQuota, inline: map[string]string{}}
+}
+
+// attach attaches the managed policy of the supplied ARN.
+func (b *Backend) attach(p *principal, policyARN *string) error {
+	pol, ok := b.policies[aws.ToString(policyARN)]
+	if !ok {
+		return noSuchEntity("Policy %s does not exist or is not attachable.", aws.ToString(policyARN))
+	}
+	for _, a := range p.attached {
+		if a == pol.arn {
+			return nil
+		}
+	}
+	if len(p.attached) >= maxAttachedPolicies {
+		return limitExceeded("Cannot exceed quota for PoliciesPer%s: %d.", strings.ToUpper(p.kind[:1])+p.kind[1:], maxAttachedPolicies)
+	}
+	p.attached = append(p.attached, pol.arn)
+	return nil
+}
+
+// detach detaches the managed policy of the supplied ARN.
+func (p *principal) detach(policyARN *string) error {
+	for i, a := range p.attached {
+		if a == aws.ToString(policyARN) {
+			p.attached = append(p.attached[:i:i], p.attached[i+1:]...)
+			return nil
+		}
+	}
+	return noSuchEntity("Policy %s was not found.", aws.ToString(policyARN))
+}
+
+// listAttached returns the attached managed policies whose path starts with
+// the supplied prefix.
+func (b *Backend) listAttached(p *principal, pathPrefix *string) []svcsdktypes.AttachedPolicy {
+	res := []svcsdktypes.AttachedPolicy{}
+	for _, a := range p.attached {
+		pol := b.policies[a]
+		if !hasPathPrefix(pol.path, pathPrefix) {
+			continue
+		}
+		res = append(res, svcsdktypes.AttachedPolicy{
+			PolicyArn:  aws.String(pol.arn),
+			PolicyName: aws.String(pol.name),
+		})
+	}
+	return res
+}
+
+// putInline creates or replaces the inline policy of the supplied name,
+// within the aggregate size quota of the principal's inline policies.
+func (p *principal) putInline(principalName string, policyName *string, doc *string) error {
+	if err := validateDocument(doc); err != nil {
+		return err
+	}
+	size := policySize(*doc)
+	for name, d := range p.inline {
+		if name != *policyName {
+			size += policySize(d)
+		}
+	}
+	if size > p.inlineQuota {
+		return limitExceeded("Maximum policy size of %d bytes exceeded for %s %s", p.inlineQuota, p.kind, principalName)
+	}
+	p.inline[*policyName] = *doc
+	return nil
+}
+
+// getInline returns the URL-encoded document of the inline policy of the
+// supplied name.
+func (p *principal) getInline(policyName *string) (*string, error) {
+	doc, ok := p.inline[aws.ToString(policyName)]
+	if !ok {
+		return nil, noSuchEntity("The %s policy with name %s cannot be found.", p.kind, aws.ToString(policyName))
+	}
+	return encodeDocument(doc), nil
+}
+
+// deleteInline deletes the inline policy of the supplied name.
+func (p *principal) deleteInline(policyName *string) error {
+	if _, ok := p.inline[aws.ToString(policyName)]; !ok {
+		return noSuchEntity("The %s policy with name %s cannot be found.", p.kind, aws.ToString(policyName))
+	}
+	delete(p.inline, *policyName)
+	return nil
+}
+
+// listInline returns the names of the inline policies, sorted.
+func (p *principal) listInline() []string {
+	return sortedKeys(p.inline)
+}
+
+// deleteConflict returns the DeleteConflict error IAM returns when the
+// principal is deleted while it still has policies, or nil.
+func (p *principal) deleteConflict() error {
+	if len(p.attached) > 0 {
+		return deleteConflict("Cannot delete entity, must detach all policies first.")
+	}
+	if len(p.inline) > 0 {
+		return deleteConflict("Cannot delete entity, must delete policies first.")
+	}
+	return nil
+}
+
+// boundaryARN validates the supplied permissions boundary, which must be an
+// existing managed policy, and returns its ARN.
+func (b *Backend) boundaryARN(policyARN *string) (string, error) {
+	if policyARN == nil {
+		return "", nil
+	}
+	if _, ok := b.policies[*policyARN]; !ok {
+		return "", noSuchEntity("Scope ARN: %s does not exist or is not attachable.", *policyARN)
+	}
+	return *policyARN, nil
+}
+
+// boundary returns the permissions boundary of the supplied ARN, or nil.
+func boundary(policyARN string) *svcsdktypes.AttachedPermissionsBoundary {
+	if policyARN == "" {
+		return nil
+	}
+	return &svcsdktypes.AttachedPermissionsBoundary{
+		PermissionsBoundaryArn:  aws.String(policyARN),
+		PermissionsBoundaryType: svcsdktypes.PermissionsBoundaryAttachmentTypePolicy,
+	}
+}
+
+// validateDocument returns MalformedPolicyDocument if the supplied policy
+// document is not a JSON object.
+func validateDocument(doc *string) error {
+	var v map[string]interface{}
+	if err := json.Unmarshal([]byte(aws.ToString(doc)), &v); err != nil {
+		return malformedPolicyDocument("Syntax errors in policy.")
+	}
+	return nil
+}
+
+// encodeDocument returns the supplied policy document URL-encoded, as IAM
+// returns them.
+func encodeDocument(doc string) *string {
+	return aws.String(url.QueryEscape(doc))
+}
+
+// validateTags returns an error if the supplied tags exceed the tag quota or
+// hold duplicate keys.
+func validateTags(tags []svcsdktypes.Tag) error {
+	if len(tags) > maxTags {
+		return limitExceeded("The number of tags has reached the maximum limit.")
+	}
+	seen := map[string]bool{}
+	for _, t := range tags {
+		k := aws.ToString(t.Key)
+		if seen[k] {
+			return invalidInput("Duplicate tag keys found. Please note that Tag keys are case insensitive.")
+		}
+		seen[k] = true
+	}
+	return nil
+}
+
+// tag adds the supplied tags to the supplied ones, overwriting the values of
+// the existing keys.
+func tag(tags []svcsdktypes.Tag, add []svcsdktypes.Tag) ([]svcsdktypes.Tag, error) {
+	if err := validateTags(add); err != nil {
+		return nil, err
+	}
+	res := copyTags(tags)
+	for _, t := range add {
+		found := false
+		for i := range res {
+			if aws.ToString(res[i].Key) == aws.ToString(t.Key) {
+				res[i].Value = aws.String(aws.ToString(t.Value))
+				found = true
+			}
+		}
+		if !found {
+			res = append(res, svcsdktypes.Tag{Key: aws.String(aws.ToString(t.Key)), Value: aws.String(aws.ToString(t.Value))})
+		}
+	}
+	if len(res) > maxTags {
+		return nil, limitExceeded("The number of tags has reached the maximum limit.")
+	}
+	return res, nil
+}
+
+// untag removes the tags of the supplied keys from the supplied ones.
+func untag(tags []svcsdktypes.Tag, keys []string) []svcsdktypes.Tag {
+	res := []svcsdktypes.Tag{}
+	for _, t := range tags {
+		removed := false
+		for _, k := range keys {
+			if aws.ToString(t.Key) == k {
+				removed = true
+			}
+		}
+		if !removed {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// copyTags returns a copy of the supplied tags, or nil if there are none, as
+// IAM omits empty tag lists.
+func copyTags(tags []svcsdktypes.Tag) []svcsdktypes.Tag {
+	if len(tags) == 0 {
+		return nil
+	}
+	res := make([]svcsdktypes.Tag, 0, len(tags))
+	for _, t := range tags {
+		res = append(res, svcsdktypes.Tag{Key: aws.String(aws.ToString(t.Key)), Value: aws.String(aws.ToString(t.Value))})
+	}
+	return res
+}
+
+// nonNilTags returns a copy of the supplied tags, or an empty list if there
+// are none, as the List*Tags operations return.
+func nonNilTags(tags []svcsdktypes.Tag) []svcsdktypes.Tag {
+	res := copyTags(tags)
+	if res == nil {
+		res = []svcsdktypes.Tag{}
+	}
+	return res
+}
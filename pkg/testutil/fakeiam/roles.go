@@ -0,0 +1,469 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fakeiam
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+const (
+	// roleInlineQuota is the aggregate size of the inline policies of a
+	// role.
+	roleInlineQuota = 10240
+	// defaultMaxSessionDuration is the maximum session duration of a role, in
+	// seconds, when none is set.
+	defaultMaxSessionDuration = 3600
+	// serviceLinkedRolePathPrefix is the path prefix of service-linked roles.
+	serviceLinkedRolePathPrefix = "/aws-service-role/"
+)
+
+type role struct {
+	principal
+	name               string
+	path               string
+	id                 string
+	arn                string
+	assumeRolePolicy   string
+	description        *string
+	maxSessionDuration int32
+	boundary           string
+	tags               []svcsdktypes.Tag
+	createDate         time.Time
+}
+
+// serviceLinked returns true if the role is a service-linked role, which can
+// only be modified and deleted by its service.
+func (r *role) serviceLinked() bool {
+	return strings.HasPrefix(r.path, serviceLinkedRolePathPrefix)
+}
+
+func (r *role) output() *svcsdktypes.Role {
+	return &svcsdktypes.Role{
+		Arn:                      aws.String(r.arn),
+		AssumeRolePolicyDocument: encodeDocument(r.assumeRolePolicy),
+		CreateDate:               aws.Time(r.createDate),
+		Description:              r.description,
+		MaxSessionDuration:       aws.Int32(r.maxSessionDuration),
+		Path:                     aws.String(r.path),
+		PermissionsBoundary:      boundary(r.boundary),
+		RoleId:                   aws.String(r.id),
+		RoleName:                 aws.String(r.name),
+		Tags:                     copyTags(r.tags),
+	}
+}
+
+// role returns the role of the supplied name.
+func (b *Backend) role(name *string) (*role, error) {
+	r, ok := b.roles[key(name)]
+	if !ok {
+		return nil, noSuchEntity("The role with name %s cannot be found.", aws.ToString(name))
+	}
+	return r, nil
+}
+
+// modifiableRole returns the role of the supplied name, if it is not a
+// service-linked role.
+func (b *Backend) modifiableRole(name *string) (*role, error) {
+	r, err := b.role(name)
+	if err != nil {
+		return nil, err
+	}
+	if r.serviceLinked() {
+		return nil, unmodifiableEntity("Cannot perform the operation on the protected role '%s' - this role is only modifiable by AWS", r.name)
+	}
+	return r, nil
+}
+
+func validateMaxSessionDuration(d *int32) error {
+	if d != nil && (*d < 3600 || *d > 43200) {
+		return validationError("maxSessionDuration must be between 3600 and 43200 seconds")
+	}
+	return nil
+}
+
+func (b *Backend) createRole(in *svcsdk.CreateRoleInput) (*svcsdk.CreateRoleOutput, error) {
+	if _, ok := b.roles[key(in.RoleName)]; ok {
+		return nil, entityAlreadyExists("Role with name %s already exists.", *in.RoleName)
+	}
+	path, err := pathOrDefault(in.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDocument(in.AssumeRolePolicyDocument); err != nil {
+		return nil, err
+	}
+	if err := validateMaxSessionDuration(in.MaxSessionDuration); err != nil {
+		return nil, err
+	}
+	boundary, err := b.boundaryARN(in.PermissionsBoundary)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTags(in.Tags); err != nil {
+		return nil, err
+	}
+	r := &role{
+		principal:          newPrincipal("role", roleInlineQuota),
+		name:               *in.RoleName,
+		path:               path,
+		id:                 b.newID("AROA"),
+		arn:                arn("role", path, *in.RoleName),
+		assumeRolePolicy:   *in.AssumeRolePolicyDocument,
+		description:        in.Description,
+		maxSessionDuration: aws.ToInt32(in.MaxSessionDuration),
+		boundary:           boundary,
+		tags:               copyTags(in.Tags),
+		createDate:         b.now(),
+	}
+	if r.maxSessionDuration == 0 {
+		r.maxSessionDuration = defaultMaxSessionDuration
+	}
+	b.roles[key(in.RoleName)] = r
+	return &svcsdk.CreateRoleOutput{Role: r.output()}, nil
+}
+
+func (b *Backend) getRole(in *svcsdk.GetRoleInput) (*svcsdk.GetRoleOutput, error) {
+	r, err := b.role(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.GetRoleOutput{Role: r.output()}, nil
+}
+
+func (b *Backend) updateRole(in *svcsdk.UpdateRoleInput) (*svcsdk.UpdateRoleOutput, error) {
+	r, err := b.role(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateMaxSessionDuration(in.MaxSessionDuration); err != nil {
+		return nil, err
+	}
+	if in.Description != nil {
+		r.description = aws.String(*in.Description)
+	}
+	if in.MaxSessionDuration != nil {
+		r.maxSessionDuration = *in.MaxSessionDuration
+	}
+	return &svcsdk.UpdateRoleOutput{}, nil
+}
+
+func (b *Backend) updateAssumeRolePolicy(in *svcsdk.UpdateAssumeRolePolicyInput) (*svcsdk.UpdateAssumeRolePolicyOutput, error) {
+	r, err := b.modifiableRole(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDocument(in.PolicyDocument); err != nil {
+		return nil, err
+	}
+	r.assumeRolePolicy = *in.PolicyDocument
+	return &svcsdk.UpdateAssumeRolePolicyOutput{}, nil
+}
+
+func (b *Backend) deleteRole(in *svcsdk.DeleteRoleInput) (*svcsdk.DeleteRoleOutput, error) {
+	r, err := b.modifiableRole(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.deleteConflict(); err != nil {
+		return nil, err
+	}
+	for _, ip := range b.instanceProfiles {
+		if ip.role == key(in.RoleName) {
+			return nil, deleteConflict("Cannot delete entity, must remove roles from instance profile first.")
+		}
+	}
+	delete(b.roles, key(in.RoleName))
+	return &svcsdk.DeleteRoleOutput{}, nil
+}
+
+func (b *Backend) listRoles(in *svcsdk.ListRolesInput) (*svcsdk.ListRolesOutput, error) {
+	res := []svcsdktypes.Role{}
+	for _, k := range sortedKeys(b.roles) {
+		r := b.roles[k]
+		if !hasPathPrefix(r.path, in.PathPrefix) {
+			continue
+		}
+		// ListRoles does not return tags and permissions boundaries.
+		out := r.output()
+		out.Tags = nil
+		out.PermissionsBoundary = nil
+		res = append(res, *out)
+	}
+	return &svcsdk.ListRolesOutput{Roles: res}, nil
+}
+
+func (b *Backend) putRolePermissionsBoundary(in *svcsdk.PutRolePermissionsBoundaryInput) (*svcsdk.PutRolePermissionsBoundaryOutput, error) {
+	r, err := b.modifiableRole(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	boundary, err := b.boundaryARN(in.PermissionsBoundary)
+	if err != nil {
+		return nil, err
+	}
+	r.boundary = boundary
+	return &svcsdk.PutRolePermissionsBoundaryOutput{}, nil
+}
+
+func (b *Backend) deleteRolePermissionsBoundary(in *svcsdk.DeleteRolePermissionsBoundaryInput) (*svcsdk.DeleteRolePermissionsBoundaryOutput, error) {
+	r, err := b.modifiableRole(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	r.boundary = ""
+	return &svcsdk.DeleteRolePermissionsBoundaryOutput{}, nil
+}
+
+func (b *Backend) attachRolePolicy(in *svcsdk.AttachRolePolicyInput) (*svcsdk.AttachRolePolicyOutput, error) {
+	r, err := b.modifiableRole(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.AttachRolePolicyOutput{}, b.attach(&r.principal, in.PolicyArn)
+}
+
+func (b *Backend) detachRolePolicy(in *svcsdk.DetachRolePolicyInput) (*svcsdk.DetachRolePolicyOutput, error) {
+	r, err := b.modifiableRole(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.DetachRolePolicyOutput{}, r.detach(in.PolicyArn)
+}
+
+func (b *Backend) listAttachedRolePolicies(in *svcsdk.ListAttachedRolePoliciesInput) (*svcsdk.ListAttachedRolePoliciesOutput, error) {
+	r, err := b.role(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.ListAttachedRolePoliciesOutput{AttachedPolicies: b.listAttached(&r.principal, in.PathPrefix)}, nil
+}
+
+func (b *Backend) putRolePolicy(in *svcsdk.PutRolePolicyInput) (*svcsdk.PutRolePolicyOutput, error) {
+	r, err := b.modifiableRole(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.PutRolePolicyOutput{}, r.putInline(r.name, in.PolicyName, in.PolicyDocument)
+}
+
+func (b *Backend) getRolePolicy(in *svcsdk.GetRolePolicyInput) (*svcsdk.GetRolePolicyOutput, error) {
+	r, err := b.role(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := r.getInline(in.PolicyName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.GetRolePolicyOutput{
+		PolicyDocument: doc,
+		PolicyName:     aws.String(*in.PolicyName),
+		RoleName:       aws.String(r.name),
+	}, nil
+}
+
+func (b *Backend) deleteRolePolicy(in *svcsdk.DeleteRolePolicyInput) (*svcsdk.DeleteRolePolicyOutput, error) {
+	r, err := b.modifiableRole(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.DeleteRolePolicyOutput{}, r.deleteInline(in.PolicyName)
+}
+
+func (b *Backend) listRolePolicies(in *svcsdk.ListRolePoliciesInput) (*svcsdk.ListRolePoliciesOutput, error) {
+	r, err := b.role(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.ListRolePoliciesOutput{PolicyNames: r.listInline()}, nil
+}
+
+func (b *Backend) tagRole(in *svcsdk.TagRoleInput) (*svcsdk.TagRoleOutput, error) {
+	r, err := b.role(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := tag(r.tags, in.Tags)
+	if err != nil {
+		return nil, err
+	}
+	r.tags = tags
+	return &svcsdk.TagRoleOutput{}, nil
+}
+
+func (b *Backend) untagRole(in *svcsdk.UntagRoleInput) (*svcsdk.UntagRoleOutput, error) {
+	r, err := b.role(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	r.tags = untag(r.tags, in.TagKeys)
+	return &svcsdk.UntagRoleOutput{}, nil
+}
+
+func (b *Backend) listRoleTags(in *svcsdk.ListRoleTagsInput) (*svcsdk.ListRoleTagsOutput, error) {
+	r, err := b.role(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.ListRoleTagsOutput{Tags: nonNilTags(r.tags)}, nil
+}
+
+func (b *Backend) listInstanceProfilesForRole(in *svcsdk.ListInstanceProfilesForRoleInput) (*svcsdk.ListInstanceProfilesForRoleOutput, error) {
+	if _, err := b.role(in.RoleName); err != nil {
+		return nil, err
+	}
+	res := []svcsdktypes.InstanceProfile{}
+	for _, k := range sortedKeys(b.instanceProfiles) {
+		ip := b.instanceProfiles[k]
+		if ip.role == key(in.RoleName) {
+			res = append(res, *b.instanceProfileOutput(ip))
+		}
+	}
+	return &svcsdk.ListInstanceProfilesForRoleOutput{InstanceProfiles: res}, nil
+}
+
+// serviceLinkedRoleName returns the name of the service-linked role of the
+// supplied service and suffix, such as AWSServiceRoleForElasticbeanstalk_x
+// for elasticbeanstalk.amazonaws.com and x.
+func serviceLinkedRoleName(service string, suffix *string) string {
+	prefix, _, _ := strings.Cut(service, ".")
+	name := "AWSServiceRoleFor" + strings.ToUpper(prefix[:1]) + prefix[1:]
+	if suffix != nil && *suffix != "" {
+		name += "_" + *suffix
+	}
+	return name
+}
+
+func (b *Backend) createServiceLinkedRole(in *svcsdk.CreateServiceLinkedRoleInput) (*svcsdk.CreateServiceLinkedRoleOutput, error) {
+	service := aws.ToString(in.AWSServiceName)
+	if !strings.HasSuffix(service, ".amazonaws.com") || strings.HasPrefix(service, ".") {
+		return nil, invalidInput("Invalid service name: %s", service)
+	}
+	name := serviceLinkedRoleName(service, in.CustomSuffix)
+	if _, ok := b.roles[key(&name)]; ok {
+		return nil, invalidInput("Service role name %s has been taken in this account, please try a different suffix.", name)
+	}
+	path := serviceLinkedRolePathPrefix + service + "/"
+	r := &role{
+		principal: newPrincipal("role", roleInlineQuota),
+		name:      name,
+		path:      path,
+		id:        b.newID("AROA"),
+		arn:       arn("role", path, name),
+		assumeRolePolicy: fmt.Sprintf(
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"%s"},"Action":"sts:AssumeRole"}]}`,
+			service,
+		),
+		description:        in.Description,
+		maxSessionDuration: defaultMaxSessionDuration,
+		createDate:         b.now(),
+	}
+	b.roles[key(&name)] = r
+	return &svcsdk.CreateServiceLinkedRoleOutput{Role: r.output()}, nil
+}
+
+func (b *Backend) deleteServiceLinkedRole(in *svcsdk.DeleteServiceLinkedRoleInput) (*svcsdk.DeleteServiceLinkedRoleOutput, error) {
+	r, err := b.role(in.RoleName)
+	if err != nil {
+		return nil, err
+	}
+	if !r.serviceLinked() {
+		return nil, invalidInput("Role %s is not a service-linked role.", r.name)
+	}
+	// The role is deleted right away, and its deletion task succeeds.
+	delete(b.roles, key(in.RoleName))
+	task := fmt.Sprintf("task%s%s/%s", r.path, r.name, b.newID(""))
+	b.deletionTasks[task] = svcsdktypes.DeletionTaskStatusTypeSucceeded
+	return &svcsdk.DeleteServiceLinkedRoleOutput{DeletionTaskId: aws.String(task)}, nil
+}
+
+func (b *Backend) getServiceLinkedRoleDeletionStatus(in *svcsdk.GetServiceLinkedRoleDeletionStatusInput) (*svcsdk.GetServiceLinkedRoleDeletionStatusOutput, error) {
+	status, ok := b.deletionTasks[aws.ToString(in.DeletionTaskId)]
+	if !ok {
+		return nil, noSuchEntity("Deletion task %s cannot be found.", aws.ToString(in.DeletionTaskId))
+	}
+	return &svcsdk.GetServiceLinkedRoleDeletionStatusOutput{
+		Status: status,
+		Reason: b.deletionTaskFailures[aws.ToString(in.DeletionTaskId)],
+	}, nil
+}
+
+// FailDeletionTask adds a failed service-linked role deletion task of the
+// supplied ID and failure reason to the Backend, as when the role is still
+// used by resources of its service.
+func (b *Backend) FailDeletionTask(task string, reason *svcsdktypes.DeletionTaskFailureReasonType) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deletionTasks[task] = svcsdktypes.DeletionTaskStatusTypeFailed
+	b.deletionTaskFailures[task] = reason
+}
+
+func (b *Backend) generateServiceLastAccessedDetails(in *svcsdk.GenerateServiceLastAccessedDetailsInput) (*svcsdk.GenerateServiceLastAccessedDetailsOutput, error) {
+	if !b.exists(aws.ToString(in.Arn)) {
+		return nil, noSuchEntity("Entity %s cannot be found.", aws.ToString(in.Arn))
+	}
+	job := b.newID("job-")
+	granularity := in.Granularity
+	if granularity == "" {
+		granularity = svcsdktypes.AccessAdvisorUsageGranularityTypeServiceLevel
+	}
+	b.lastAccessedJobs[job] = granularity
+	return &svcsdk.GenerateServiceLastAccessedDetailsOutput{JobId: aws.String(job)}, nil
+}
+
+func (b *Backend) getServiceLastAccessedDetails(in *svcsdk.GetServiceLastAccessedDetailsInput) (*svcsdk.GetServiceLastAccessedDetailsOutput, error) {
+	granularity, ok := b.lastAccessedJobs[aws.ToString(in.JobId)]
+	if !ok {
+		return nil, noSuchEntity("Job %s cannot be found.", aws.ToString(in.JobId))
+	}
+	// Nothing ever accesses the fake services.
+	now := b.now()
+	return &svcsdk.GetServiceLastAccessedDetailsOutput{
+		JobStatus:            svcsdktypes.JobStatusTypeCompleted,
+		JobType:              granularity,
+		JobCreationDate:      aws.Time(now),
+		JobCompletionDate:    aws.Time(now),
+		ServicesLastAccessed: []svcsdktypes.ServiceLastAccessed{},
+	}, nil
+}
+
+// exists returns true if the role, user, group or managed policy of the
+// supplied ARN exists.
+func (b *Backend) exists(resourceARN string) bool {
+	if _, ok := b.policies[resourceARN]; ok {
+		return true
+	}
+	for _, r := range b.roles {
+		if r.arn == resourceARN {
+			return true
+		}
+	}
+	for _, u := range b.users {
+		if u.arn == resourceARN {
+			return true
+		}
+	}
+	for _, g := range b.groups {
+		if g.arn == resourceARN {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fakeiam
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// userInlineQuota is the aggregate size of the inline policies of a user.
+const userInlineQuota = 2048
+
+// user is an IAM user.
+type user struct {
+	principal
+	credentials
+	name       string
+	path       string
+	id         string
+	arn        string
+	boundary   string
+	tags       []svcsdktypes.Tag
+	createDate time.Time
+}
+
+func (u *user) output() *svcsdktypes.User {
+	return &svcsdktypes.User{
+		Arn:                 aws.String(u.arn),
+		CreateDate:          aws.Time(u.createDate),
+		Path:                aws.String(u.path),
+		PermissionsBoundary: boundary(u.boundary),
+		Tags:                copyTags(u.tags),
+		UserId:              aws.String(u.id),
+		UserName:            aws.String(u.name),
+	}
+}
+
+// user returns the user of the supplied name.
+func (b *Backend) user(name *string) (*user, error) {
+	u, ok := b.users[key(name)]
+	if !ok {
+		return nil, noSuchEntity("The user with name %s cannot be found.", aws.ToString(name))
+	}
+	return u, nil
+}
+
+// groupsOf returns the groups the user of the supplied name is a member of,
+// sorted by name.
+func (b *Backend) groupsOf(name *string) []*group {
+	res := []*group{}
+	for _, k := range sortedKeys(b.groups) {
+		if b.groups[k].members[key(name)] {
+			res = append(res, b.groups[k])
+		}
+	}
+	return res
+}
+
+func (b *Backend) createUser(in *svcsdk.CreateUserInput) (*svcsdk.CreateUserOutput, error) {
+	if _, ok := b.users[key(in.UserName)]; ok {
+		return nil, entityAlreadyExists("User with name %s already exists.", *in.UserName)
+	}
+	path, err := pathOrDefault(in.Path)
+	if err != nil {
+		return nil, err
+	}
+	boundary, err := b.boundaryARN(in.PermissionsBoundary)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTags(in.Tags); err != nil {
+		return nil, err
+	}
+	u := &user{
+		principal:  newPrincipal("user", userInlineQuota),
+		name:       *in.UserName,
+		path:       path,
+		id:         b.newID("AIDA"),
+		arn:        arn("user", path, *in.UserName),
+		boundary:   boundary,
+		tags:       copyTags(in.Tags),
+		createDate: b.now(),
+	}
+	b.users[key(in.UserName)] = u
+	return &svcsdk.CreateUserOutput{User: u.output()}, nil
+}
+
+func (b *Backend) getUser(in *svcsdk.GetUserInput) (*svcsdk.GetUserOutput, error) {
+	if in.UserName == nil {
+		// The caller of a Backend is not an IAM user.
+		return nil, validationError("userName is required")
+	}
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.GetUserOutput{User: u.output()}, nil
+}
+
+func (b *Backend) updateUser(in *svcsdk.UpdateUserInput) (*svcsdk.UpdateUserOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	if in.NewPath != nil {
+		path, err := pathOrDefault(in.NewPath)
+		if err != nil {
+			return nil, err
+		}
+		u.path = path
+	}
+	if in.NewUserName != nil && key(in.NewUserName) != key(in.UserName) {
+		if _, ok := b.users[key(in.NewUserName)]; ok {
+			return nil, entityAlreadyExists("User with name %s already exists.", *in.NewUserName)
+		}
+		for _, g := range b.groupsOf(in.UserName) {
+			delete(g.members, key(in.UserName))
+			g.members[key(in.NewUserName)] = true
+		}
+		delete(b.users, key(in.UserName))
+		b.users[key(in.NewUserName)] = u
+	}
+	if in.NewUserName != nil {
+		u.name = *in.NewUserName
+	}
+	u.arn = arn("user", u.path, u.name)
+	return &svcsdk.UpdateUserOutput{}, nil
+}
+
+func (b *Backend) deleteUser(in *svcsdk.DeleteUserInput) (*svcsdk.DeleteUserOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.principal.deleteConflict(); err != nil {
+		return nil, err
+	}
+	if err := u.credentials.deleteConflict(); err != nil {
+		return nil, err
+	}
+	if len(b.groupsOf(in.UserName)) > 0 {
+		return nil, deleteConflict("Cannot delete entity, must remove users from group first.")
+	}
+	delete(b.users, key(in.UserName))
+	return &svcsdk.DeleteUserOutput{}, nil
+}
+
+func (b *Backend) listUsers(in *svcsdk.ListUsersInput) (*svcsdk.ListUsersOutput, error) {
+	res := []svcsdktypes.User{}
+	for _, k := range sortedKeys(b.users) {
+		u := b.users[k]
+		if !hasPathPrefix(u.path, in.PathPrefix) {
+			continue
+		}
+		// ListUsers does not return tags and permissions boundaries.
+		out := u.output()
+		out.Tags = nil
+		out.PermissionsBoundary = nil
+		res = append(res, *out)
+	}
+	return &svcsdk.ListUsersOutput{Users: res}, nil
+}
+
+func (b *Backend) putUserPermissionsBoundary(in *svcsdk.PutUserPermissionsBoundaryInput) (*svcsdk.PutUserPermissionsBoundaryOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	boundary, err := b.boundaryARN(in.PermissionsBoundary)
+	if err != nil {
+		return nil, err
+	}
+	u.boundary = boundary
+	return &svcsdk.PutUserPermissionsBoundaryOutput{}, nil
+}
+
+func (b *Backend) deleteUserPermissionsBoundary(in *svcsdk.DeleteUserPermissionsBoundaryInput) (*svcsdk.DeleteUserPermissionsBoundaryOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	u.boundary = ""
+	return &svcsdk.DeleteUserPermissionsBoundaryOutput{}, nil
+}
+
+func (b *Backend) attachUserPolicy(in *svcsdk.AttachUserPolicyInput) (*svcsdk.AttachUserPolicyOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.AttachUserPolicyOutput{}, b.attach(&u.principal, in.PolicyArn)
+}
+
+func (b *Backend) detachUserPolicy(in *svcsdk.DetachUserPolicyInput) (*svcsdk.DetachUserPolicyOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.DetachUserPolicyOutput{}, u.detach(in.PolicyArn)
+}
+
+func (b *Backend) listAttachedUserPolicies(in *svcsdk.ListAttachedUserPoliciesInput) (*svcsdk.ListAttachedUserPoliciesOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.ListAttachedUserPoliciesOutput{AttachedPolicies: b.listAttached(&u.principal, in.PathPrefix)}, nil
+}
+
+func (b *Backend) putUserPolicy(in *svcsdk.PutUserPolicyInput) (*svcsdk.PutUserPolicyOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.PutUserPolicyOutput{}, u.putInline(u.name, in.PolicyName, in.PolicyDocument)
+}
+
+func (b *Backend) getUserPolicy(in *svcsdk.GetUserPolicyInput) (*svcsdk.GetUserPolicyOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := u.getInline(in.PolicyName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.GetUserPolicyOutput{
+		PolicyDocument: doc,
+		PolicyName:     aws.String(*in.PolicyName),
+		UserName:       aws.String(u.name),
+	}, nil
+}
+
+func (b *Backend) deleteUserPolicy(in *svcsdk.DeleteUserPolicyInput) (*svcsdk.DeleteUserPolicyOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.DeleteUserPolicyOutput{}, u.deleteInline(in.PolicyName)
+}
+
+func (b *Backend) listUserPolicies(in *svcsdk.ListUserPoliciesInput) (*svcsdk.ListUserPoliciesOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.ListUserPoliciesOutput{PolicyNames: u.listInline()}, nil
+}
+
+func (b *Backend) tagUser(in *svcsdk.TagUserInput) (*svcsdk.TagUserOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := tag(u.tags, in.Tags)
+	if err != nil {
+		return nil, err
+	}
+	u.tags = tags
+	return &svcsdk.TagUserOutput{}, nil
+}
+
+func (b *Backend) untagUser(in *svcsdk.UntagUserInput) (*svcsdk.UntagUserOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	u.tags = untag(u.tags, in.TagKeys)
+	return &svcsdk.UntagUserOutput{}, nil
+}
+
+func (b *Backend) listUserTags(in *svcsdk.ListUserTagsInput) (*svcsdk.ListUserTagsOutput, error) {
+	u, err := b.user(in.UserName)
+	if err != nil {
+		return nil, err
+	}
+	return &svcsdk.ListUserTagsOutput{Tags: nonNilTags(u.tags)}, nil
+}
+
+func (b *Backend) listGroupsForUser(in *svcsdk.ListGroupsForUserInput) (*svcsdk.ListGroupsForUserOutput, error) {
+	if _, err := b.user(in.UserName); err != nil {
+		return nil, err
+	}
+	res := []svcsdktypes.Group{}
+	for _, g := range b.groupsOf(in.UserName) {
+		res = append(res, *g.output())
+	}
+	return &svcsdk.ListGroupsForUserOutput{Groups: res}, nil
+}
@@ -0,0 +1,77 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Resource types of the IAM resources managed by the controller, as they
+// appear in ARNs.
+const (
+	ARNResourceTypeGroup           = "group"
+	ARNResourceTypeInstanceProfile = "instance-profile"
+	ARNResourceTypeOIDCProvider    = "oidc-provider"
+	ARNResourceTypePolicy          = "policy"
+	ARNResourceTypeRole            = "role"
+	ARNResourceTypeUser            = "user"
+)
+
+// awsManagedPolicyAccount is the account of the AWS managed policies in their
+// ARNs, such as arn:aws:iam::aws:policy/ReadOnlyAccess.
+const awsManagedPolicyAccount = "aws"
+
+// IAMARN returns the ARN of an IAM resource, such as
+// arn:aws:iam::123456789012:role/path/name. IAM is a global service, so the
+// ARN carries no region.
+//
+// The path defaults to "/" and may be given with or without its leading and
+// trailing slashes. The name may itself be prefixed with a path, in which
+// case it is appended to the supplied one.
+//
+// Every ARN the controller builds goes through IAMARN. The ARNFromName
+// methods of the resource managers are emitted by the code generator, which
+// has no hook for them, and only receive a name, so they keep the generic
+// regional form and are not used by the controller.
+func IAMARN(partition, accountID, resourceType, path, name string) string {
+	path = strings.Trim(path, "/")
+	if path != "" {
+		path += "/"
+	}
+	return fmt.Sprintf(
+		"arn:%s:iam::%s:%s/%s%s",
+		partition, accountID, resourceType, path, strings.TrimPrefix(name, "/"),
+	)
+}
+
+// PartitionDNSSuffix returns the DNS suffix of the service endpoints and
+// service principals of the supplied partition, such as amazonaws.com.cn for
+// aws-cn. Unknown partitions default to amazonaws.com.
+func PartitionDNSSuffix(partition string) string {
+	switch partition {
+	case "aws-cn":
+		return "amazonaws.com.cn"
+	case "aws-iso":
+		return "c2s.ic.gov"
+	case "aws-iso-b":
+		return "sc2s.sgov.gov"
+	case "aws-iso-e":
+		return "cloud.adc-e.uk"
+	case "aws-iso-f":
+		return "csp.hci.ic.gov"
+	default:
+		return "amazonaws.com"
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIAMARN(t *testing.T) {
+	for _, tc := range []struct {
+		partition    string
+		resourceType string
+		path         string
+		name         string
+		expected     string
+	}{
+		{"aws", ARNResourceTypeRole, "", "my-role", "arn:aws:iam::123456789012:role/my-role"},
+		{"aws", ARNResourceTypeRole, "/", "my-role", "arn:aws:iam::123456789012:role/my-role"},
+		{"aws-cn", ARNResourceTypeUser, "/division/team/", "alice", "arn:aws-cn:iam::123456789012:user/division/team/alice"},
+		{"aws-us-gov", ARNResourceTypeGroup, "ops", "admins", "arn:aws-us-gov:iam::123456789012:group/ops/admins"},
+		{"aws", ARNResourceTypePolicy, "", "/service/my-policy", "arn:aws:iam::123456789012:policy/service/my-policy"},
+		{"aws", ARNResourceTypeInstanceProfile, "/", "nodes", "arn:aws:iam::123456789012:instance-profile/nodes"},
+		{
+			"aws", ARNResourceTypeOIDCProvider, "", "oidc.eks.us-west-2.amazonaws.com/id/EXAMPLE",
+			"arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-west-2.amazonaws.com/id/EXAMPLE",
+		},
+	} {
+		assert.Equal(t, tc.expected, IAMARN(tc.partition, "123456789012", tc.resourceType, tc.path, tc.name))
+	}
+}
+
+func TestPartitionDNSSuffix(t *testing.T) {
+	assert.Equal(t, "amazonaws.com", PartitionDNSSuffix("aws"))
+	assert.Equal(t, "amazonaws.com", PartitionDNSSuffix("aws-us-gov"))
+	assert.Equal(t, "amazonaws.com.cn", PartitionDNSSuffix("aws-cn"))
+	assert.Equal(t, "amazonaws.com", PartitionDNSSuffix(""))
+}
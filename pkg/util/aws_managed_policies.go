@@ -0,0 +1,227 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	smithy "github.com/aws/smithy-go"
+	"golang.org/x/sync/singleflight"
+)
+
+// awsManagedPolicyListPeriod is how often the list of AWS managed policies
+// of a partition may be fetched again to look up a name missing from the
+// cache.
+const awsManagedPolicyListPeriod = time.Hour
+
+// ManagedPolicyAPI is the subset of the IAM API used to resolve AWS managed
+// policy names into ARNs.
+type ManagedPolicyAPI interface {
+	GetPolicy(
+		context.Context,
+		*svcsdk.GetPolicyInput,
+		...func(*svcsdk.Options),
+	) (*svcsdk.GetPolicyOutput, error)
+	ListPolicies(
+		context.Context,
+		*svcsdk.ListPoliciesInput,
+		...func(*svcsdk.Options),
+	) (*svcsdk.ListPoliciesOutput, error)
+}
+
+// awsManagedPolicyCache maps "<partition>/<name>" to the ARN of an AWS
+// managed policy. AWS managed policies are shared by every account of a
+// partition, so the cache is shared by all resource managers.
+//
+// The lock only guards the maps; IAM is never called while holding it.
+// Concurrent listings of the AWS managed policies of a partition are merged
+// into one through lists.
+var awsManagedPolicyCache = struct {
+	sync.Mutex
+	arns   map[string]string
+	listed map[string]time.Time
+	lists  singleflight.Group
+}{
+	arns:   map[string]string{},
+	listed: map[string]time.Time{},
+}
+
+// AWSManagedPolicyARNs returns the ARNs, in the supplied partition, of the AWS
+// managed policies with the supplied names. A name is either a bare policy
+// name, such as AmazonEKSWorkerNodePolicy, or a name prefixed with its path,
+// such as service-role/AmazonEC2RoleforSSM.
+//
+// Names are looked up with GetPolicy at the root path first and then in the
+// list of AWS managed policies, which covers the service-role/ and
+// job-function/ paths. Names that do not match any AWS managed policy are
+// reported as a terminal error.
+func AWSManagedPolicyARNs(
+	ctx context.Context,
+	api ManagedPolicyAPI,
+	metrics APICallRecorder,
+	partition string,
+	names []*string,
+) ([]*string, error) {
+	arns := []*string{}
+	unknown := []string{}
+	for _, n := range names {
+		if n == nil {
+			continue
+		}
+		name := strings.Trim(*n, "/")
+		arn, err := lookupAWSManagedPolicy(ctx, api, metrics, partition, name)
+		if err != nil {
+			return nil, err
+		}
+		if arn == "" {
+			unknown = append(unknown, *n)
+			continue
+		}
+		arns = append(arns, &arn)
+	}
+	if len(unknown) > 0 {
+		return nil, ackerr.NewTerminalError(fmt.Errorf(
+			"unknown AWS managed policies: %s", strings.Join(unknown, ", "),
+		))
+	}
+	return arns, nil
+}
+
+// SplitAWSManagedPolicies separates the policy ARNs attached to an IAM
+// entity into those declared by name, returned as names in the order of
+// names, and the rest. arns holds the ARNs the names resolve to, in the same
+// order.
+func SplitAWSManagedPolicies(
+	attached []*string,
+	names []*string,
+	arns []*string,
+) (rest []*string, matched []*string) {
+	byARN := map[string]bool{}
+	for _, p := range attached {
+		if p != nil {
+			byARN[*p] = true
+		}
+	}
+	declared := map[string]bool{}
+	for i, arn := range arns {
+		if byARN[*arn] {
+			matched = append(matched, names[i])
+		}
+		declared[*arn] = true
+	}
+	for _, p := range attached {
+		if p != nil && !declared[*p] {
+			rest = append(rest, p)
+		}
+	}
+	return rest, matched
+}
+
+// lookupAWSManagedPolicy returns the ARN of the named AWS managed policy, or
+// an empty string if there is none.
+func lookupAWSManagedPolicy(
+	ctx context.Context,
+	api ManagedPolicyAPI,
+	metrics APICallRecorder,
+	partition string,
+	name string,
+) (string, error) {
+	key := partition + "/" + name
+	awsManagedPolicyCache.Lock()
+	arn, ok := awsManagedPolicyCache.arns[key]
+	awsManagedPolicyCache.Unlock()
+	if ok {
+		return arn, nil
+	}
+
+	arn = IAMARN(partition, awsManagedPolicyAccount, ARNResourceTypePolicy, "", name)
+	_, err := api.GetPolicy(ctx, &svcsdk.GetPolicyInput{PolicyArn: &arn})
+	metrics.RecordAPICall("READ_ONE", "GetPolicy", err)
+	if err == nil {
+		awsManagedPolicyCache.Lock()
+		awsManagedPolicyCache.arns[key] = arn
+		awsManagedPolicyCache.Unlock()
+		return arn, nil
+	}
+	var awsErr smithy.APIError
+	if !errors.As(err, &awsErr) || awsErr.ErrorCode() != "NoSuchEntity" {
+		return "", err
+	}
+
+	_, err, _ = awsManagedPolicyCache.lists.Do(partition, func() (interface{}, error) {
+		return nil, listAWSManagedPolicies(ctx, api, metrics, partition)
+	})
+	if err != nil {
+		return "", err
+	}
+	awsManagedPolicyCache.Lock()
+	defer awsManagedPolicyCache.Unlock()
+	return awsManagedPolicyCache.arns[key], nil
+}
+
+// listAWSManagedPolicies caches every AWS managed policy of the partition
+// under both its bare name and its name prefixed with its path, unless they
+// were listed less than awsManagedPolicyListPeriod ago.
+func listAWSManagedPolicies(
+	ctx context.Context,
+	api ManagedPolicyAPI,
+	metrics APICallRecorder,
+	partition string,
+) error {
+	awsManagedPolicyCache.Lock()
+	listed := awsManagedPolicyCache.listed[partition]
+	awsManagedPolicyCache.Unlock()
+	if time.Since(listed) < awsManagedPolicyListPeriod {
+		return nil
+	}
+
+	arns := map[string]string{}
+	input := &svcsdk.ListPoliciesInput{Scope: svcsdktypes.PolicyScopeTypeAws}
+	paginator := svcsdk.NewListPoliciesPaginator(api, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		metrics.RecordAPICall("READ_MANY", "ListPolicies", err)
+		if err != nil {
+			return err
+		}
+		for _, p := range page.Policies {
+			if p.PolicyName == nil || p.Arn == nil {
+				continue
+			}
+			arns[partition+"/"+*p.PolicyName] = *p.Arn
+			if p.Path != nil {
+				path := strings.Trim(*p.Path, "/")
+				if path != "" {
+					arns[partition+"/"+path+"/"+*p.PolicyName] = *p.Arn
+				}
+			}
+		}
+	}
+
+	awsManagedPolicyCache.Lock()
+	defer awsManagedPolicyCache.Unlock()
+	for k, v := range arns {
+		awsManagedPolicyCache.arns[k] = v
+	}
+	awsManagedPolicyCache.listed[partition] = time.Now()
+	return nil
+}
@@ -0,0 +1,201 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	smithy "github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeManagedPolicyAPI struct {
+	policies  []svcsdktypes.Policy
+	getCalls  int
+	listCalls int
+}
+
+func (f *fakeManagedPolicyAPI) GetPolicy(
+	_ context.Context,
+	input *svcsdk.GetPolicyInput,
+	_ ...func(*svcsdk.Options),
+) (*svcsdk.GetPolicyOutput, error) {
+	f.getCalls++
+	for i, p := range f.policies {
+		if *p.Arn == *input.PolicyArn {
+			return &svcsdk.GetPolicyOutput{Policy: &f.policies[i]}, nil
+		}
+	}
+	return nil, &smithy.GenericAPIError{Code: "NoSuchEntity"}
+}
+
+func (f *fakeManagedPolicyAPI) ListPolicies(
+	_ context.Context,
+	input *svcsdk.ListPoliciesInput,
+	_ ...func(*svcsdk.Options),
+) (*svcsdk.ListPoliciesOutput, error) {
+	f.listCalls++
+	return &svcsdk.ListPoliciesOutput{Policies: f.policies}, nil
+}
+
+func awsManagedPolicy(partition, path, name string) svcsdktypes.Policy {
+	return svcsdktypes.Policy{
+		Arn:        aws.String("arn:" + partition + ":iam::aws:policy" + path + name),
+		Path:       aws.String(path),
+		PolicyName: aws.String(name),
+	}
+}
+
+func resetAWSManagedPolicyCache() {
+	awsManagedPolicyCache.arns = map[string]string{}
+	awsManagedPolicyCache.listed = map[string]time.Time{}
+}
+
+func TestAWSManagedPolicyARNs(t *testing.T) {
+	resetAWSManagedPolicyCache()
+	api := &fakeManagedPolicyAPI{policies: []svcsdktypes.Policy{
+		awsManagedPolicy("aws-cn", "/", "AmazonEKSWorkerNodePolicy"),
+		awsManagedPolicy("aws-cn", "/service-role/", "AmazonEC2RoleforSSM"),
+		awsManagedPolicy("aws-cn", "/job-function/", "ViewOnlyAccess"),
+	}}
+
+	arns, err := AWSManagedPolicyARNs(context.TODO(), api, noopRecorder{}, "aws-cn", aws.StringSlice([]string{
+		"AmazonEKSWorkerNodePolicy", "AmazonEC2RoleforSSM", "job-function/ViewOnlyAccess",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"arn:aws-cn:iam::aws:policy/AmazonEKSWorkerNodePolicy",
+		"arn:aws-cn:iam::aws:policy/service-role/AmazonEC2RoleforSSM",
+		"arn:aws-cn:iam::aws:policy/job-function/ViewOnlyAccess",
+	}, aws.ToStringSlice(arns))
+	assert.Equal(t, 1, api.listCalls)
+
+	// Resolved names are served from the cache.
+	calls := api.getCalls
+	_, err = AWSManagedPolicyARNs(context.TODO(), api, noopRecorder{}, "aws-cn", aws.StringSlice([]string{
+		"AmazonEKSWorkerNodePolicy", "AmazonEC2RoleforSSM",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, calls, api.getCalls)
+	assert.Equal(t, 1, api.listCalls)
+}
+
+func TestAWSManagedPolicyARNs_Unknown(t *testing.T) {
+	resetAWSManagedPolicyCache()
+	api := &fakeManagedPolicyAPI{policies: []svcsdktypes.Policy{
+		awsManagedPolicy("aws", "/", "ReadOnlyAccess"),
+	}}
+
+	_, err := AWSManagedPolicyARNs(context.TODO(), api, noopRecorder{}, "aws", aws.StringSlice([]string{
+		"ReadOnlyAccess", "NoSuchPolicy",
+	}))
+	var termErr *ackerr.TerminalError
+	require.ErrorAs(t, err, &termErr)
+	assert.True(t, strings.Contains(err.Error(), "NoSuchPolicy"))
+}
+
+// blockingManagedPolicyAPI knows no policy by ARN, and blocks ListPolicies
+// until release is closed.
+type blockingManagedPolicyAPI struct {
+	policies  []svcsdktypes.Policy
+	listing   chan struct{}
+	release   chan struct{}
+	listCalls atomic.Int32
+}
+
+func (f *blockingManagedPolicyAPI) GetPolicy(
+	context.Context,
+	*svcsdk.GetPolicyInput,
+	...func(*svcsdk.Options),
+) (*svcsdk.GetPolicyOutput, error) {
+	return nil, &smithy.GenericAPIError{Code: "NoSuchEntity"}
+}
+
+func (f *blockingManagedPolicyAPI) ListPolicies(
+	context.Context,
+	*svcsdk.ListPoliciesInput,
+	...func(*svcsdk.Options),
+) (*svcsdk.ListPoliciesOutput, error) {
+	if f.listCalls.Add(1) == 1 {
+		close(f.listing)
+	}
+	<-f.release
+	return &svcsdk.ListPoliciesOutput{Policies: f.policies}, nil
+}
+
+func TestAWSManagedPolicyARNs_Concurrent(t *testing.T) {
+	resetAWSManagedPolicyCache()
+	awsManagedPolicyCache.arns["aws/ReadOnlyAccess"] = "arn:aws:iam::aws:policy/ReadOnlyAccess"
+	api := &blockingManagedPolicyAPI{
+		policies: []svcsdktypes.Policy{awsManagedPolicy("aws", "/service-role/", "AmazonEC2RoleforSSM")},
+		listing:  make(chan struct{}),
+		release:  make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	results := make([][]*string, 3)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			arns, err := AWSManagedPolicyARNs(context.TODO(), api, noopRecorder{}, "aws", aws.StringSlice([]string{
+				"AmazonEC2RoleforSSM",
+			}))
+			assert.NoError(t, err)
+			results[i] = arns
+		}()
+	}
+
+	// Cached names are resolved while the AWS managed policies are listed.
+	<-api.listing
+	arns, err := AWSManagedPolicyARNs(context.TODO(), api, noopRecorder{}, "aws", aws.StringSlice([]string{
+		"ReadOnlyAccess",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"arn:aws:iam::aws:policy/ReadOnlyAccess"}, aws.ToStringSlice(arns))
+
+	close(api.release)
+	wg.Wait()
+	for _, arns := range results {
+		assert.Equal(t, []string{"arn:aws:iam::aws:policy/service-role/AmazonEC2RoleforSSM"}, aws.ToStringSlice(arns))
+	}
+	// The AWS managed policies are listed once.
+	assert.Equal(t, int32(1), api.listCalls.Load())
+}
+
+func TestSplitAWSManagedPolicies(t *testing.T) {
+	attached := aws.StringSlice([]string{
+		"arn:aws:iam::aws:policy/ReadOnlyAccess",
+		"arn:aws:iam::123456789012:policy/custom",
+	})
+	names := aws.StringSlice([]string{"ReadOnlyAccess", "AmazonS3ReadOnlyAccess"})
+	arns := aws.StringSlice([]string{
+		"arn:aws:iam::aws:policy/ReadOnlyAccess",
+		"arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess",
+	})
+
+	rest, matched := SplitAWSManagedPolicies(attached, names, arns)
+	assert.Equal(t, []string{"arn:aws:iam::123456789012:policy/custom"}, aws.ToStringSlice(rest))
+	assert.Equal(t, []string{"ReadOnlyAccess"}, aws.ToStringSlice(matched))
+}
@@ -0,0 +1,52 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	acktypes "github.com/aws-controllers-k8s/runtime/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SetAdvisoryFromError reports the supplied error of a best-effort step as an
+// ACK.Advisory condition carrying the supplied reason, or removes that
+// condition when the error is nil.
+//
+// Steps that only observe AWS run from sdkFind, which the runtime also calls
+// right before deleting a resource. They report their failures this way
+// rather than returning them, so that they never block reconciliation or
+// deletion.
+func SetAdvisoryFromError(
+	subject acktypes.ConditionManager,
+	reason string,
+	err error,
+) {
+	if err != nil {
+		message := err.Error()
+		ackcondition.SetAdvisory(subject, corev1.ConditionTrue, &message, &reason)
+		return
+	}
+	if ackcondition.AdvisoryWithReason(subject, reason) == nil {
+		return
+	}
+	conditions := []*ackv1alpha1.Condition{}
+	for _, c := range subject.Conditions() {
+		if c.Type == ackv1alpha1.ConditionTypeAdvisory && c.Reason != nil && *c.Reason == reason {
+			continue
+		}
+		conditions = append(conditions, c)
+	}
+	subject.ReplaceConditions(conditions)
+}
@@ -0,0 +1,57 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"errors"
+	"testing"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type fakeConditionManager struct {
+	conditions []*ackv1alpha1.Condition
+}
+
+func (f *fakeConditionManager) Conditions() []*ackv1alpha1.Condition {
+	return f.conditions
+}
+
+func (f *fakeConditionManager) ReplaceConditions(conditions []*ackv1alpha1.Condition) {
+	f.conditions = conditions
+}
+
+func TestSetAdvisoryFromError(t *testing.T) {
+	subject := &fakeConditionManager{}
+	ackcondition.SetSynced(subject, corev1.ConditionTrue, nil, nil)
+	otherReason := "OtherAdvisory"
+	ackcondition.SetAdvisory(subject, corev1.ConditionTrue, nil, &otherReason)
+
+	SetAdvisoryFromError(subject, "ThumbprintDiscoveryFailed", errors.New("issuer unreachable"))
+	SetAdvisoryFromError(subject, "ThumbprintDiscoveryFailed", errors.New("issuer still unreachable"))
+	c := ackcondition.AdvisoryWithReason(subject, "ThumbprintDiscoveryFailed")
+	require.NotNil(t, c)
+	assert.Equal(t, corev1.ConditionTrue, c.Status)
+	assert.Equal(t, "issuer still unreachable", *c.Message)
+	assert.Len(t, subject.conditions, 3)
+
+	SetAdvisoryFromError(subject, "ThumbprintDiscoveryFailed", nil)
+	assert.Nil(t, ackcondition.AdvisoryWithReason(subject, "ThumbprintDiscoveryFailed"))
+	assert.NotNil(t, ackcondition.AdvisoryWithReason(subject, otherReason))
+	assert.NotNil(t, ackcondition.Synced(subject))
+}
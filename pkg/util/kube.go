@@ -0,0 +1,149 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrlrtclient "sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlrtconfig "sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// eventSourceComponent is the component recorded as the source of the
+// Events emitted by the controller.
+const eventSourceComponent = "ack-iam-controller"
+
+var (
+	kubeClientOnce sync.Once
+	kubeClient     kubernetes.Interface
+	kubeClientErr  error
+
+	schemeOnce sync.Once
+	scheme     *runtime.Scheme
+	schemeErr  error
+
+	eventRecorderOnce sync.Once
+	eventRecorder     record.EventRecorder
+	eventRecorderErr  error
+
+	apiReaderOnce sync.Once
+	apiReader     ctrlrtclient.Reader
+	apiReaderErr  error
+)
+
+// KubeClient returns a clientset talking to the API server the controller
+// runs against, built from the controller's kubeconfig.
+//
+// The resource managers are not handed a Kubernetes client by the ACK
+// runtime, so the few hooks that need one share this client.
+func KubeClient() (kubernetes.Interface, error) {
+	kubeClientOnce.Do(func() {
+		cfg, err := ctrlrtconfig.GetConfig()
+		if err != nil {
+			kubeClientErr = err
+			return
+		}
+		kubeClient, kubeClientErr = kubernetes.NewForConfig(cfg)
+	})
+	return kubeClient, kubeClientErr
+}
+
+// EventRecorder returns a recorder emitting Kubernetes Events about the
+// controller's custom resources.
+func EventRecorder() (record.EventRecorder, error) {
+	eventRecorderOnce.Do(func() {
+		cs, err := KubeClient()
+		if err != nil {
+			eventRecorderErr = err
+			return
+		}
+		s, err := newScheme()
+		if err != nil {
+			eventRecorderErr = err
+			return
+		}
+		broadcaster := record.NewBroadcaster()
+		broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{
+			Interface: cs.CoreV1().Events(""),
+		})
+		eventRecorder = broadcaster.NewRecorder(
+			s, corev1.EventSource{Component: eventSourceComponent},
+		)
+	})
+	return eventRecorder, eventRecorderErr
+}
+
+// SetEventRecorder makes EventRecorder return the supplied recorder. It lets
+// tests record Events without an API server, and must be called before any
+// Event is recorded.
+func SetEventRecorder(recorder record.EventRecorder) {
+	eventRecorderOnce.Do(func() {})
+	eventRecorder, eventRecorderErr = recorder, nil
+}
+
+// APIReader returns a reader of any resource, including those of other ACK
+// controllers, that reads straight from the API server rather than from a
+// cache.
+func APIReader() (ctrlrtclient.Reader, error) {
+	apiReaderOnce.Do(func() {
+		cfg, err := ctrlrtconfig.GetConfig()
+		if err != nil {
+			apiReaderErr = err
+			return
+		}
+		s, err := newScheme()
+		if err != nil {
+			apiReaderErr = err
+			return
+		}
+		apiReader, apiReaderErr = ctrlrtclient.New(cfg, ctrlrtclient.Options{Scheme: s})
+	})
+	return apiReader, apiReaderErr
+}
+
+// SetAPIReader makes APIReader return the supplied reader. It lets tests read
+// resources without an API server, and must be called before any resource is
+// read.
+func SetAPIReader(reader ctrlrtclient.Reader) {
+	apiReaderOnce.Do(func() {})
+	apiReader, apiReaderErr = reader, nil
+}
+
+// newScheme returns the scheme registering the core Kubernetes types and the
+// controller's custom resources.
+func newScheme() (*runtime.Scheme, error) {
+	schemeOnce.Do(func() {
+		s := runtime.NewScheme()
+		if err := clientgoscheme.AddToScheme(s); err != nil {
+			schemeErr = err
+			return
+		}
+		if err := svcapitypes.AddToScheme(s); err != nil {
+			schemeErr = err
+			return
+		}
+		scheme = s
+	})
+	return scheme, schemeErr
+}
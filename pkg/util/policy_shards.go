@@ -0,0 +1,66 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"context"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackutil "github.com/aws-controllers-k8s/runtime/pkg/util"
+	"k8s.io/apimachinery/pkg/types"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+// AppendPolicyShardARNs returns the supplied policy ARNs followed by the
+// ARNs of the shards of the Policies referenced from the supplied policy
+// references, read from their Status.ShardARNs. References without a
+// namespace refer to Policies in the supplied namespace.
+//
+// Only Policies referenced through policyRefs bring their shards: a Policy
+// whose ARN is listed in policies is attached alone.
+func AppendPolicyShardARNs(
+	ctx context.Context,
+	namespace string,
+	refs []*ackv1alpha1.AWSResourceReferenceWrapper,
+	policies []*string,
+) ([]*string, error) {
+	if len(refs) == 0 {
+		return policies, nil
+	}
+	apiReader, err := APIReader()
+	if err != nil {
+		return nil, err
+	}
+	res := policies
+	for _, ref := range refs {
+		if ref == nil || ref.From == nil || ref.From.Name == nil {
+			continue
+		}
+		name := types.NamespacedName{Namespace: namespace, Name: *ref.From.Name}
+		if ref.From.Namespace != nil && *ref.From.Namespace != "" {
+			name.Namespace = *ref.From.Namespace
+		}
+		obj := &svcapitypes.Policy{}
+		if err := apiReader.Get(ctx, name, obj); err != nil {
+			return nil, err
+		}
+		for _, arn := range obj.Status.ShardARNs {
+			if arn != nil && !ackutil.InStringPs(*arn, res) {
+				res = append(res, arn)
+			}
+		}
+	}
+	return res, nil
+}
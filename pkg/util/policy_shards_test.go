@@ -0,0 +1,75 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"context"
+	"testing"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+func TestAppendPolicyShardARNs(t *testing.T) {
+	s, err := newScheme()
+	require.NoError(t, err)
+	SetAPIReader(fake.NewClientBuilder().WithScheme(s).WithObjects(
+		&svcapitypes.Policy{
+			ObjectMeta: metav1.ObjectMeta{Name: "large", Namespace: "team-a"},
+			Status: svcapitypes.PolicyStatus{ShardARNs: []*string{
+				aws.String("arn:aws:iam::123456789012:policy/large-part2"),
+				aws.String("arn:aws:iam::123456789012:policy/large-part3"),
+			}},
+		},
+		&svcapitypes.Policy{
+			ObjectMeta: metav1.ObjectMeta{Name: "small", Namespace: "team-b"},
+		},
+	).Build())
+
+	refs := []*ackv1alpha1.AWSResourceReferenceWrapper{
+		{From: &ackv1alpha1.AWSResourceReference{Name: aws.String("large")}},
+		{From: &ackv1alpha1.AWSResourceReference{Name: aws.String("small"), Namespace: aws.String("team-b")}},
+	}
+	policies := []*string{
+		aws.String("arn:aws:iam::123456789012:policy/large"),
+		aws.String("arn:aws:iam::123456789012:policy/small"),
+	}
+	res, err := AppendPolicyShardARNs(context.TODO(), "team-a", refs, policies)
+	require.NoError(t, err)
+	assert.Equal(t, []*string{
+		aws.String("arn:aws:iam::123456789012:policy/large"),
+		aws.String("arn:aws:iam::123456789012:policy/small"),
+		aws.String("arn:aws:iam::123456789012:policy/large-part2"),
+		aws.String("arn:aws:iam::123456789012:policy/large-part3"),
+	}, res)
+
+	// Declaring the shards again leaves them listed once.
+	again, err := AppendPolicyShardARNs(context.TODO(), "team-a", refs, res)
+	require.NoError(t, err)
+	assert.Equal(t, res, again)
+
+	// Without references, the policies are returned as is.
+	res, err = AppendPolicyShardARNs(context.TODO(), "team-a", nil, policies)
+	require.NoError(t, err)
+	assert.Equal(t, policies, res)
+
+	_, err = AppendPolicyShardARNs(context.TODO(), "team-b", refs[:1], policies)
+	assert.Error(t, err)
+}
@@ -0,0 +1,328 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// ManagedPolicyMaxSize is the maximum size of a managed policy document.
+	//
+	// https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_iam-quotas.html
+	ManagedPolicyMaxSize = 6144
+	// RoleInlinePoliciesMaxSize is the maximum aggregate size of the inline
+	// policy documents of a role.
+	RoleInlinePoliciesMaxSize = 10240
+)
+
+// listStatementKeys are the statement elements holding a string or a list of
+// strings that is matched as a set.
+var listStatementKeys = []string{"Action", "NotAction", "Resource", "NotResource"}
+
+// PolicyDocumentSize returns the size IAM counts against its policy size
+// quotas for the supplied document, which excludes white space.
+func PolicyDocumentSize(doc string) int {
+	size := 0
+	for _, r := range doc {
+		if !unicode.IsSpace(r) {
+			size++
+		}
+	}
+	return size
+}
+
+// CompactPolicyDocument returns the supplied policy document minified, with
+// the statements that only differ by their Resource, and then those that
+// only differ by their Action, merged into single statements.
+func CompactPolicyDocument(doc string) (string, error) {
+	header, statements, err := decodePolicyDocument(doc)
+	if err != nil {
+		return "", err
+	}
+	return encodePolicyDocument(header, mergeStatements(statements))
+}
+
+// SplitPolicyDocument compacts the supplied policy document and, if it is
+// still larger than maxSize, splits its statements across as few documents
+// of at most maxSize as it can. Statements that are too large on their own
+// are split by their Resource, or else Action, list.
+func SplitPolicyDocument(doc string, maxSize int) ([]string, error) {
+	header, statements, err := decodePolicyDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	statements = mergeStatements(statements)
+	compact, err := encodePolicyDocument(header, statements)
+	if err != nil {
+		return nil, err
+	}
+	if PolicyDocumentSize(compact) <= maxSize {
+		return []string{compact}, nil
+	}
+
+	fits := func(statements []interface{}) (string, bool) {
+		doc, err := encodePolicyDocument(header, statements)
+		return doc, err == nil && PolicyDocumentSize(doc) <= maxSize
+	}
+	pieces := []interface{}{}
+	for i, s := range statements {
+		split, err := splitStatement(s, fits)
+		if err != nil {
+			return nil, fmt.Errorf("statement %d: %w", i, err)
+		}
+		pieces = append(pieces, split...)
+	}
+
+	docs := []string{}
+	current := []interface{}{}
+	for _, s := range pieces {
+		if _, ok := fits(append(current, s)); ok || len(current) == 0 {
+			current = append(current, s)
+			continue
+		}
+		doc, _ := fits(current)
+		docs = append(docs, doc)
+		current = []interface{}{s}
+	}
+	doc, _ = fits(current)
+	return append(docs, doc), nil
+}
+
+// splitStatement returns the supplied statement, or the statements it splits
+// into by halving its Resource, or else Action, list until each one fits.
+// NotAction and NotResource lists can not be split without changing the
+// meaning of the statement.
+func splitStatement(
+	s interface{},
+	fits func([]interface{}) (string, bool),
+) ([]interface{}, error) {
+	if _, ok := fits([]interface{}{s}); ok {
+		return []interface{}{s}, nil
+	}
+	m, ok := s.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("statement exceeds the size limit")
+	}
+	for _, key := range []string{"Resource", "Action"} {
+		values, ok := stringSet(m[key])
+		if !ok || len(values) < 2 {
+			continue
+		}
+		half := len(values) / 2
+		res := []interface{}{}
+		for _, part := range [][]string{values[:half], values[half:]} {
+			c := copyStatement(m)
+			// A Sid must be unique within a policy document.
+			delete(c, "Sid")
+			c[key] = stringSetValue(part)
+			split, err := splitStatement(c, fits)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, split...)
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("statement exceeds the size limit and has no Resource or Action list to split")
+}
+
+// decodePolicyDocument returns the elements of the supplied policy document
+// other than Statement, and its statements.
+func decodePolicyDocument(doc string) (map[string]interface{}, []interface{}, error) {
+	header := map[string]interface{}{}
+	dec := json.NewDecoder(strings.NewReader(doc))
+	dec.UseNumber()
+	if err := dec.Decode(&header); err != nil {
+		return nil, nil, fmt.Errorf("decoding policy document: %w", err)
+	}
+	statements := []interface{}{}
+	// Statement may be a single object or a list of objects.
+	switch s := header["Statement"].(type) {
+	case []interface{}:
+		statements = s
+	case map[string]interface{}:
+		statements = []interface{}{s}
+	}
+	delete(header, "Statement")
+	return header, statements, nil
+}
+
+// encodePolicyDocument returns the minified policy document made of the
+// supplied elements and statements.
+func encodePolicyDocument(header map[string]interface{}, statements []interface{}) (string, error) {
+	doc := make(map[string]interface{}, len(header)+1)
+	for k, v := range header {
+		doc[k] = v
+	}
+	doc["Statement"] = statements
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// mergeStatements merges the statements that only differ by their Resource,
+// and then those that only differ by their Action.
+func mergeStatements(statements []interface{}) []interface{} {
+	return mergeStatementsOn(mergeStatementsOn(statements, "Resource"), "Action")
+}
+
+// mergeStatementsOn merges the statements that are equal but for their Sid
+// and the supplied list element into the first of them, which lists the
+// union of their values.
+func mergeStatementsOn(statements []interface{}, key string) []interface{} {
+	res := []interface{}{}
+	for _, s := range statements {
+		m, ok := s.(map[string]interface{})
+		if !ok {
+			res = append(res, s)
+			continue
+		}
+		values, ok := stringSet(m[key])
+		if !ok {
+			res = append(res, s)
+			continue
+		}
+		merged := false
+		for _, t := range res {
+			n, ok := t.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			existing, ok := stringSet(n[key])
+			if ok && statementsEqualExcept(m, n, key) {
+				n[key] = stringSetValue(unionStrings(existing, values))
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			res = append(res, copyStatement(m))
+		}
+	}
+	return res
+}
+
+// statementsEqualExcept returns true if the supplied statements are equal
+// but for their Sid and the supplied element.
+func statementsEqualExcept(a, b map[string]interface{}, key string) bool {
+	keys := func(m map[string]interface{}) []string {
+		res := []string{}
+		for k := range m {
+			if k != key && k != "Sid" {
+				res = append(res, k)
+			}
+		}
+		sort.Strings(res)
+		return res
+	}
+	ak := keys(a)
+	if !reflect.DeepEqual(ak, keys(b)) {
+		return false
+	}
+	for _, k := range ak {
+		if isListStatementKey(k) {
+			av, aok := stringSet(a[k])
+			bv, bok := stringSet(b[k])
+			if aok && bok {
+				sort.Strings(av)
+				sort.Strings(bv)
+				if !reflect.DeepEqual(av, bv) {
+					return false
+				}
+				continue
+			}
+		}
+		if !reflect.DeepEqual(a[k], b[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isListStatementKey returns true if the supplied statement element holds a
+// string or a list of strings matched as a set.
+func isListStatementKey(key string) bool {
+	for _, k := range listStatementKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// stringSet returns the strings held by a statement element that is a
+// string or a list of strings, without duplicates.
+func stringSet(v interface{}) ([]string, bool) {
+	switch t := v.(type) {
+	case string:
+		return []string{t}, true
+	case []interface{}:
+		res := make([]string, 0, len(t))
+		for _, e := range t {
+			s, ok := e.(string)
+			if !ok {
+				return nil, false
+			}
+			res = append(res, s)
+		}
+		return unionStrings(nil, res), true
+	}
+	return nil, false
+}
+
+// stringSetValue returns the statement element holding the supplied
+// strings: a string if there is only one.
+func stringSetValue(values []string) interface{} {
+	if len(values) == 1 {
+		return values[0]
+	}
+	res := make([]interface{}, len(values))
+	for i, v := range values {
+		res[i] = v
+	}
+	return res
+}
+
+// unionStrings returns a followed by the strings of b that are not in a.
+func unionStrings(a, b []string) []string {
+	seen := map[string]bool{}
+	res := []string{}
+	for _, v := range append(append([]string{}, a...), b...) {
+		if !seen[v] {
+			seen[v] = true
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// copyStatement returns a shallow copy of the supplied statement.
+func copyStatement(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,111 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyDocumentSize(t *testing.T) {
+	assert.Equal(t, 9, PolicyDocumentSize("{\n  \"a\": \"b\"\n}"))
+}
+
+func TestCompactPolicyDocument(t *testing.T) {
+	doc := `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {"Sid": "ReadA", "Effect": "Allow", "Action": ["s3:GetObject"], "Resource": "arn:aws:s3:::a/*"},
+    {"Sid": "ReadB", "Effect": "Allow", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::b/*"},
+    {"Effect": "Allow", "Action": "s3:ListBucket", "Resource": ["arn:aws:s3:::b/*", "arn:aws:s3:::a/*"]},
+    {"Effect": "Deny", "Action": "s3:DeleteObject", "Resource": "arn:aws:s3:::a/*"},
+    {"Effect": "Allow", "NotAction": "iam:*", "Resource": "*", "Condition": {"NumericLessThan": {"s3:max-keys": 10}}}
+  ]
+}`
+	compact, err := CompactPolicyDocument(doc)
+	require.NoError(t, err)
+	assert.Equal(t, `{"Statement":[`+
+		`{"Action":["s3:GetObject","s3:ListBucket"],"Effect":"Allow","Resource":["arn:aws:s3:::a/*","arn:aws:s3:::b/*"],"Sid":"ReadA"},`+
+		`{"Action":"s3:DeleteObject","Effect":"Deny","Resource":"arn:aws:s3:::a/*"},`+
+		`{"Condition":{"NumericLessThan":{"s3:max-keys":10}},"Effect":"Allow","NotAction":"iam:*","Resource":"*"}`+
+		`],"Version":"2012-10-17"}`, compact)
+}
+
+// bucketStatements returns a policy document granting a distinct action on
+// each of n buckets, so that no statements merge.
+func bucketStatements(n int) string {
+	statements := []string{}
+	for i := 0; i < n; i++ {
+		statements = append(statements, fmt.Sprintf(
+			`{"Effect":"Allow","Action":"s3:GetObject%d","Resource":"arn:aws:s3:::bucket-with-a-long-name-%d/*"}`, i, i,
+		))
+	}
+	return `{"Version":"2012-10-17","Statement":[` + strings.Join(statements, ",") + `]}`
+}
+
+func statementCount(t *testing.T, doc string) int {
+	var parsed struct {
+		Version   string
+		Statement []map[string]interface{}
+	}
+	require.NoError(t, json.Unmarshal([]byte(doc), &parsed))
+	assert.Equal(t, "2012-10-17", parsed.Version)
+	return len(parsed.Statement)
+}
+
+func TestSplitPolicyDocument(t *testing.T) {
+	docs, err := SplitPolicyDocument(bucketStatements(3), ManagedPolicyMaxSize)
+	require.NoError(t, err)
+	assert.Len(t, docs, 1)
+
+	docs, err = SplitPolicyDocument(bucketStatements(200), ManagedPolicyMaxSize)
+	require.NoError(t, err)
+	assert.Greater(t, len(docs), 1)
+	total := 0
+	for _, doc := range docs {
+		assert.LessOrEqual(t, PolicyDocumentSize(doc), ManagedPolicyMaxSize)
+		total += statementCount(t, doc)
+	}
+	assert.Equal(t, 200, total)
+}
+
+func TestSplitPolicyDocument_LargeStatement(t *testing.T) {
+	resources := []string{}
+	for i := 0; i < 300; i++ {
+		resources = append(resources, fmt.Sprintf(`"arn:aws:s3:::bucket-with-a-long-name-%d/*"`, i))
+	}
+	doc := `{"Version":"2012-10-17","Statement":[{"Sid":"Read","Effect":"Allow","Action":"s3:GetObject","Resource":[` +
+		strings.Join(resources, ",") + `]}]}`
+
+	docs, err := SplitPolicyDocument(doc, ManagedPolicyMaxSize)
+	require.NoError(t, err)
+	assert.Greater(t, len(docs), 1)
+	seen := 0
+	for _, d := range docs {
+		assert.LessOrEqual(t, PolicyDocumentSize(d), ManagedPolicyMaxSize)
+		assert.NotContains(t, d, `"Sid"`)
+		seen += strings.Count(d, "arn:aws:s3:::")
+	}
+	assert.Equal(t, 300, seen)
+
+	// NotResource lists can not be split.
+	doc = strings.Replace(doc, `"Resource"`, `"NotResource"`, 1)
+	_, err = SplitPolicyDocument(doc, ManagedPolicyMaxSize)
+	assert.ErrorContains(t, err, "statement 0")
+}
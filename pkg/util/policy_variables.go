@@ -0,0 +1,183 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	ackcompare "github.com/aws-controllers-k8s/runtime/pkg/compare"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// policyVariableRegexp matches the ${ack:<name>} variables of a policy
+// document. IAM's own policy variables, such as ${aws:username}, use other
+// prefixes and are left untouched.
+var policyVariableRegexp = regexp.MustCompile(`\$\{ack:([^}]*)\}`)
+
+// policyReferenceRegexp matches the ${ref:<alias>} placeholders of a policy
+// document.
+var policyReferenceRegexp = regexp.MustCompile(`\$\{ref:([^}]*)\}`)
+
+// PolicyVariables holds the values substituted for the ${ack:...} variables
+// of the policy documents of a resource.
+type PolicyVariables struct {
+	AccountID    string
+	Partition    string
+	Region       string
+	Namespace    string
+	ResourceName string
+}
+
+// NewPolicyVariables returns the policy variables of the supplied Kubernetes
+// object, managed in the supplied account, partition and region.
+func NewPolicyVariables(
+	accountID string,
+	partition string,
+	region string,
+	obj metav1.Object,
+) PolicyVariables {
+	return PolicyVariables{
+		AccountID:    accountID,
+		Partition:    partition,
+		Region:       region,
+		Namespace:    obj.GetNamespace(),
+		ResourceName: obj.GetName(),
+	}
+}
+
+// lookup returns the value of the named variable and whether the name is
+// defined.
+func (v PolicyVariables) lookup(name string) (string, bool) {
+	switch name {
+	case "accountId":
+		return v.AccountID, true
+	case "partition":
+		return v.Partition, true
+	case "region":
+		return v.Region, true
+	case "namespace":
+		return v.Namespace, true
+	case "resourceName":
+		return v.ResourceName, true
+	}
+	return "", false
+}
+
+// RenderPolicyDocument returns the supplied policy document with every
+// ${ack:accountId}, ${ack:partition}, ${ack:region}, ${ack:namespace} and
+// ${ack:resourceName} variable replaced by its value. Any other ${ack:...}
+// variable is reported as an error.
+func RenderPolicyDocument(doc string, vars PolicyVariables) (string, error) {
+	return substitutePolicyPlaceholders(doc, policyVariableRegexp, "policy variables", vars.lookup)
+}
+
+// RenderPolicyReferences returns the supplied policy document with every
+// ${ref:<alias>} placeholder replaced by the value resolved for the alias.
+// Placeholders of aliases without a value are reported as an error.
+func RenderPolicyReferences(doc string, values map[string]*string) (string, error) {
+	return substitutePolicyPlaceholders(doc, policyReferenceRegexp, "resource references", func(alias string) (string, bool) {
+		v, ok := values[alias]
+		if !ok || v == nil {
+			return "", false
+		}
+		return *v, true
+	})
+}
+
+// substitutePolicyPlaceholders replaces the matches of re in doc by the value
+// lookup returns for their first submatch, and reports the matches lookup has
+// no value for as an error.
+func substitutePolicyPlaceholders(
+	doc string,
+	re *regexp.Regexp,
+	what string,
+	lookup func(string) (string, bool),
+) (string, error) {
+	unknown := map[string]bool{}
+	rendered := re.ReplaceAllStringFunc(doc, func(m string) string {
+		value, ok := lookup(re.FindStringSubmatch(m)[1])
+		if !ok {
+			unknown[m] = true
+			return m
+		}
+		return value
+	})
+	if len(unknown) > 0 {
+		names := make([]string, 0, len(unknown))
+		for n := range unknown {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("unknown %s: %s", what, strings.Join(names, ", "))
+	}
+	return rendered, nil
+}
+
+// RenderPolicyDocuments renders every policy document of the supplied map of
+// policy names to documents with RenderPolicyDocument.
+func RenderPolicyDocuments(
+	docs map[string]*string,
+	vars PolicyVariables,
+) (map[string]*string, error) {
+	if docs == nil {
+		return nil, nil
+	}
+	res := make(map[string]*string, len(docs))
+	for name, doc := range docs {
+		if doc == nil {
+			res[name] = nil
+			continue
+		}
+		rendered, err := RenderPolicyDocument(*doc, vars)
+		if err != nil {
+			return nil, fmt.Errorf("policy %q: %w", name, err)
+		}
+		res[name] = &rendered
+	}
+	return res, nil
+}
+
+// DeclaredPolicyDocument returns the declared policy document when the
+// observed one is semantically equal to its rendering, and the observed one
+// otherwise. Read hooks use it so that the variables of a declared document
+// are not reported as a difference with the document IAM returns.
+func DeclaredPolicyDocument(declared, rendered, observed *string) *string {
+	if declared == nil || rendered == nil || observed == nil {
+		return observed
+	}
+	if equal, err := ackcompare.IAMPolicyDocumentEqual(*rendered, *observed); err != nil || !equal {
+		return observed
+	}
+	return declared
+}
+
+// DeclaredPolicyDocuments applies DeclaredPolicyDocument to every observed
+// policy of the supplied maps of policy names to documents.
+func DeclaredPolicyDocuments(
+	declared map[string]*string,
+	rendered map[string]*string,
+	observed map[string]*string,
+) map[string]*string {
+	if observed == nil {
+		return nil
+	}
+	res := make(map[string]*string, len(observed))
+	for name, doc := range observed {
+		res[name] = DeclaredPolicyDocument(declared[name], rendered[name], doc)
+	}
+	return res
+}
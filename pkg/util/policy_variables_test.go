@@ -0,0 +1,80 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var testPolicyVariables = NewPolicyVariables(
+	"123456789012", "aws-us-gov", "us-gov-west-1",
+	&metav1.ObjectMeta{Namespace: "team-a", Name: "reader"},
+)
+
+func TestRenderPolicyDocument(t *testing.T) {
+	doc := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject",` +
+		`"Resource":"arn:${ack:partition}:s3:::${ack:namespace}-${ack:resourceName}/${aws:username}/*"},` +
+		`{"Effect":"Allow","Action":"sqs:*","Resource":"arn:${ack:partition}:sqs:${ack:region}:${ack:accountId}:q"}]}`
+
+	rendered, err := RenderPolicyDocument(doc, testPolicyVariables)
+	require.NoError(t, err)
+	assert.Equal(t, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject",`+
+		`"Resource":"arn:aws-us-gov:s3:::team-a-reader/${aws:username}/*"},`+
+		`{"Effect":"Allow","Action":"sqs:*","Resource":"arn:aws-us-gov:sqs:us-gov-west-1:123456789012:q"}]}`,
+		rendered)
+}
+
+func TestRenderPolicyDocument_UnknownVariable(t *testing.T) {
+	_, err := RenderPolicyDocument(`{"Resource":"${ack:cluster}/${ack:accountID}"}`, testPolicyVariables)
+	assert.EqualError(t, err, "unknown policy variables: ${ack:accountID}, ${ack:cluster}")
+}
+
+func TestRenderPolicyDocuments(t *testing.T) {
+	rendered, err := RenderPolicyDocuments(map[string]*string{
+		"a": aws.String(`{"Resource":"${ack:accountId}"}`),
+		"b": nil,
+	}, testPolicyVariables)
+	require.NoError(t, err)
+	assert.Equal(t, `{"Resource":"123456789012"}`, *rendered["a"])
+	assert.Nil(t, rendered["b"])
+
+	_, err = RenderPolicyDocuments(map[string]*string{
+		"a": aws.String(`{"Resource":"${ack:nope}"}`),
+	}, testPolicyVariables)
+	assert.ErrorContains(t, err, `policy "a"`)
+}
+
+func TestDeclaredPolicyDocument(t *testing.T) {
+	declared := aws.String(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:*","Resource":"arn:${ack:partition}:s3:::b"}]}`)
+	rendered := aws.String(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:*","Resource":"arn:aws:s3:::b"}]}`)
+	// IAM returns an equivalent document that differs in formatting.
+	observed := aws.String(`{"Version": "2012-10-17", "Statement": {"Effect": "Allow", "Action": ["s3:*"], "Resource": "arn:aws:s3:::b"}}`)
+	drifted := aws.String(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:*","Resource":"arn:aws:s3:::c"}]}`)
+
+	assert.Equal(t, declared, DeclaredPolicyDocument(declared, rendered, observed))
+	assert.Equal(t, drifted, DeclaredPolicyDocument(declared, rendered, drifted))
+	assert.Equal(t, observed, DeclaredPolicyDocument(nil, nil, observed))
+
+	docs := DeclaredPolicyDocuments(
+		map[string]*string{"p": declared},
+		map[string]*string{"p": rendered},
+		map[string]*string{"p": observed, "unmanaged": drifted},
+	)
+	assert.Equal(t, map[string]*string{"p": declared, "unmanaged": drifted}, docs)
+}
@@ -0,0 +1,227 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	ackerr "github.com/aws-controllers-k8s/runtime/pkg/errors"
+	ackrtlog "github.com/aws-controllers-k8s/runtime/pkg/runtime/log"
+	acktypes "github.com/aws-controllers-k8s/runtime/pkg/types"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+// ServiceLastAccessedRefreshPeriod is how old a service last accessed report
+// may get before a new one is generated.
+const ServiceLastAccessedRefreshPeriod = 24 * time.Hour
+
+// ServiceLastAccessedAPI is the subset of the IAM API used to generate and
+// read service last accessed (Access Advisor) reports.
+type ServiceLastAccessedAPI interface {
+	GenerateServiceLastAccessedDetails(
+		context.Context,
+		*svcsdk.GenerateServiceLastAccessedDetailsInput,
+		...func(*svcsdk.Options),
+	) (*svcsdk.GenerateServiceLastAccessedDetailsOutput, error)
+	GetServiceLastAccessedDetails(
+		context.Context,
+		*svcsdk.GetServiceLastAccessedDetailsInput,
+		...func(*svcsdk.Options),
+	) (*svcsdk.GetServiceLastAccessedDetailsOutput, error)
+}
+
+// APICallRecorder records outbound AWS API calls. It is satisfied by
+// *ackmetrics.Metrics.
+type APICallRecorder interface {
+	RecordAPICall(opType string, opID string, err error)
+}
+
+// SyncServiceLastAccessed advances the service last accessed report of the
+// IAM entity with the supplied ARN and returns the updated report.
+//
+// Reports are generated asynchronously by IAM, so each call does at most one
+// step: it collects the results of a pending job, or starts a new job when
+// the current report is older than ServiceLastAccessedRefreshPeriod or was
+// generated with a different granularity. A nil granularity disables the
+// report.
+func SyncServiceLastAccessed(
+	ctx context.Context,
+	api ServiceLastAccessedAPI,
+	metrics APICallRecorder,
+	arn string,
+	granularity *string,
+	current *svcapitypes.ServiceLastAccessedReport,
+) (report *svcapitypes.ServiceLastAccessedReport, err error) {
+	if granularity == nil || *granularity == "" {
+		return nil, nil
+	}
+	switch svcapitypes.AccessAdvisorUsageGranularityType(*granularity) {
+	case svcapitypes.AccessAdvisorUsageGranularityType_SERVICE_LEVEL,
+		svcapitypes.AccessAdvisorUsageGranularityType_ACTION_LEVEL:
+	default:
+		return current, ackerr.NewTerminalError(fmt.Errorf(
+			"invalid serviceLastAccessedGranularity %q, must be one of %q or %q",
+			*granularity,
+			svcapitypes.AccessAdvisorUsageGranularityType_SERVICE_LEVEL,
+			svcapitypes.AccessAdvisorUsageGranularityType_ACTION_LEVEL,
+		))
+	}
+	rlog := ackrtlog.FromContext(ctx)
+	exit := rlog.Trace("SyncServiceLastAccessed")
+	defer func() { exit(err) }()
+
+	report = &svcapitypes.ServiceLastAccessedReport{}
+	if current != nil {
+		report = current.DeepCopy()
+	}
+
+	if report.JobID != nil {
+		done, err := collectServiceLastAccessed(ctx, api, metrics, report)
+		if err != nil || !done {
+			return report, err
+		}
+	}
+
+	stale := report.JobCompletionDate == nil ||
+		time.Since(report.JobCompletionDate.Time) > ServiceLastAccessedRefreshPeriod ||
+		report.JobType == nil || *report.JobType != *granularity
+	if !stale {
+		return report, nil
+	}
+	resp, err := api.GenerateServiceLastAccessedDetails(
+		ctx,
+		&svcsdk.GenerateServiceLastAccessedDetailsInput{
+			Arn:         &arn,
+			Granularity: svcsdktypes.AccessAdvisorUsageGranularityType(*granularity),
+		},
+	)
+	metrics.RecordAPICall("READ_ONE", "GenerateServiceLastAccessedDetails", err)
+	if err != nil {
+		return report, err
+	}
+	report.JobID = resp.JobId
+	return report, nil
+}
+
+// collectServiceLastAccessed reads the results of the pending job of the
+// supplied report into it. It returns false while the job is in progress.
+func collectServiceLastAccessed(
+	ctx context.Context,
+	api ServiceLastAccessedAPI,
+	metrics APICallRecorder,
+	report *svcapitypes.ServiceLastAccessedReport,
+) (bool, error) {
+	rlog := ackrtlog.FromContext(ctx)
+	services := []*svcapitypes.ServiceLastAccessed{}
+	input := &svcsdk.GetServiceLastAccessedDetailsInput{JobId: report.JobID}
+	for {
+		resp, err := api.GetServiceLastAccessedDetails(ctx, input)
+		metrics.RecordAPICall("READ_ONE", "GetServiceLastAccessedDetails", err)
+		if err != nil {
+			return false, err
+		}
+		switch resp.JobStatus {
+		case svcsdktypes.JobStatusTypeInProgress:
+			return false, nil
+		case svcsdktypes.JobStatusTypeFailed:
+			msg := ""
+			if resp.Error != nil && resp.Error.Message != nil {
+				msg = *resp.Error.Message
+			}
+			rlog.Info("service last accessed job failed", "job_id", *report.JobID, "error", msg)
+			// Drop the job so that a new one is generated.
+			report.JobID = nil
+			report.JobCompletionDate = nil
+			return true, nil
+		}
+		for _, s := range resp.ServicesLastAccessed {
+			services = append(services, serviceLastAccessedFromSDK(s))
+		}
+		if !resp.IsTruncated {
+			report.JobID = nil
+			report.JobType = aws.String(string(resp.JobType))
+			report.ServicesLastAccessed = services
+			if resp.JobCompletionDate != nil {
+				report.JobCompletionDate = &metav1.Time{Time: *resp.JobCompletionDate}
+			}
+			return true, nil
+		}
+		input.Marker = resp.Marker
+	}
+}
+
+// serviceLastAccessedFromSDK converts an SDK ServiceLastAccessed into its
+// API counterpart.
+func serviceLastAccessedFromSDK(
+	s svcsdktypes.ServiceLastAccessed,
+) *svcapitypes.ServiceLastAccessed {
+	elem := &svcapitypes.ServiceLastAccessed{
+		LastAuthenticatedEntity: s.LastAuthenticatedEntity,
+		LastAuthenticatedRegion: s.LastAuthenticatedRegion,
+		ServiceName:             s.ServiceName,
+		ServiceNamespace:        s.ServiceNamespace,
+	}
+	if s.LastAuthenticated != nil {
+		elem.LastAuthenticated = &metav1.Time{Time: *s.LastAuthenticated}
+	}
+	if s.TotalAuthenticatedEntities != nil {
+		total := int64(*s.TotalAuthenticatedEntities)
+		elem.TotalAuthenticatedEntities = &total
+	}
+	for _, a := range s.TrackedActionsLastAccessed {
+		action := &svcapitypes.TrackedActionLastAccessed{
+			ActionName:         a.ActionName,
+			LastAccessedEntity: a.LastAccessedEntity,
+			LastAccessedRegion: a.LastAccessedRegion,
+		}
+		if a.LastAccessedTime != nil {
+			action.LastAccessedTime = &metav1.Time{Time: *a.LastAccessedTime}
+		}
+		elem.TrackedActionsLastAccessed = append(elem.TrackedActionsLastAccessed, action)
+	}
+	return elem
+}
+
+// ServiceLastAccessedFailedReason is the reason of the ACK.Advisory condition
+// reporting that the service last accessed report could not be refreshed.
+const ServiceLastAccessedFailedReason = "ServiceLastAccessedFailed"
+
+// SetServiceLastAccessedConditions reports the outcome of
+// SyncServiceLastAccessed on the conditions of the supplied resource.
+//
+// Errors are reported as an ACK.Advisory condition rather than returned, so
+// that a missing permission or throttling never blocks the reconciliation or
+// deletion of the IAM entity. While a job is pending, the resource is marked
+// as not synced so that the runtime requeues it shortly to collect the
+// report, instead of waiting for the next resync.
+func SetServiceLastAccessedConditions(
+	subject acktypes.ConditionManager,
+	report *svcapitypes.ServiceLastAccessedReport,
+	err error,
+) {
+	SetAdvisoryFromError(subject, ServiceLastAccessedFailedReason, err)
+	if err == nil && report != nil && report.JobID != nil {
+		msg := "service last accessed report is being generated"
+		ackcondition.SetSynced(subject, corev1.ConditionFalse, &msg, nil)
+	}
+}
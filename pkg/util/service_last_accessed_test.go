@@ -0,0 +1,162 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ackcondition "github.com/aws-controllers-k8s/runtime/pkg/condition"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+type noopRecorder struct{}
+
+func (noopRecorder) RecordAPICall(string, string, error) {}
+
+// fakeServiceLastAccessedAPI serves a single job, in pages of one service.
+type fakeServiceLastAccessedAPI struct {
+	generated []*svcsdk.GenerateServiceLastAccessedDetailsInput
+	status    svcsdktypes.JobStatusType
+	services  []svcsdktypes.ServiceLastAccessed
+}
+
+func (f *fakeServiceLastAccessedAPI) GenerateServiceLastAccessedDetails(
+	_ context.Context,
+	input *svcsdk.GenerateServiceLastAccessedDetailsInput,
+	_ ...func(*svcsdk.Options),
+) (*svcsdk.GenerateServiceLastAccessedDetailsOutput, error) {
+	f.generated = append(f.generated, input)
+	return &svcsdk.GenerateServiceLastAccessedDetailsOutput{JobId: aws.String("job-1")}, nil
+}
+
+func (f *fakeServiceLastAccessedAPI) GetServiceLastAccessedDetails(
+	_ context.Context,
+	input *svcsdk.GetServiceLastAccessedDetailsInput,
+	_ ...func(*svcsdk.Options),
+) (*svcsdk.GetServiceLastAccessedDetailsOutput, error) {
+	out := &svcsdk.GetServiceLastAccessedDetailsOutput{
+		JobStatus:         f.status,
+		JobType:           svcsdktypes.AccessAdvisorUsageGranularityTypeServiceLevel,
+		JobCompletionDate: aws.Time(time.Now()),
+	}
+	if f.status != svcsdktypes.JobStatusTypeCompleted {
+		return out, nil
+	}
+	page := 0
+	if input.Marker != nil {
+		page = 1
+	}
+	out.ServicesLastAccessed = f.services[page : page+1]
+	if page+1 < len(f.services) {
+		out.IsTruncated = true
+		out.Marker = aws.String("next")
+	}
+	return out, nil
+}
+
+func TestSyncServiceLastAccessed(t *testing.T) {
+	ctx := context.TODO()
+	arn := "arn:aws:iam::123456789012:role/test"
+	granularity := aws.String(string(svcapitypes.AccessAdvisorUsageGranularityType_SERVICE_LEVEL))
+	lastAuthenticated := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	api := &fakeServiceLastAccessedAPI{
+		status: svcsdktypes.JobStatusTypeInProgress,
+		services: []svcsdktypes.ServiceLastAccessed{
+			{ServiceName: aws.String("Amazon S3"), ServiceNamespace: aws.String("s3"), LastAuthenticated: &lastAuthenticated},
+			{ServiceName: aws.String("AWS Lambda"), ServiceNamespace: aws.String("lambda")},
+		},
+	}
+
+	// No report yet, a job is started.
+	report, err := SyncServiceLastAccessed(ctx, api, noopRecorder{}, arn, granularity, nil)
+	require.NoError(t, err)
+	require.Len(t, api.generated, 1)
+	assert.Equal(t, arn, *api.generated[0].Arn)
+	assert.Equal(t, "job-1", *report.JobID)
+
+	// The job is still running.
+	report, err = SyncServiceLastAccessed(ctx, api, noopRecorder{}, arn, granularity, report)
+	require.NoError(t, err)
+	assert.Equal(t, "job-1", *report.JobID)
+	assert.Empty(t, report.ServicesLastAccessed)
+
+	// The job completed, all pages are collected.
+	api.status = svcsdktypes.JobStatusTypeCompleted
+	report, err = SyncServiceLastAccessed(ctx, api, noopRecorder{}, arn, granularity, report)
+	require.NoError(t, err)
+	assert.Nil(t, report.JobID)
+	assert.NotNil(t, report.JobCompletionDate)
+	require.Len(t, report.ServicesLastAccessed, 2)
+	assert.Equal(t, "s3", *report.ServicesLastAccessed[0].ServiceNamespace)
+	assert.Equal(t, lastAuthenticated, report.ServicesLastAccessed[0].LastAuthenticated.Time)
+	assert.Len(t, api.generated, 1)
+
+	// A fresh report is left alone, a stale one is regenerated.
+	_, err = SyncServiceLastAccessed(ctx, api, noopRecorder{}, arn, granularity, report)
+	require.NoError(t, err)
+	assert.Len(t, api.generated, 1)
+
+	report.JobCompletionDate = &metav1.Time{Time: time.Now().Add(-2 * ServiceLastAccessedRefreshPeriod)}
+	report, err = SyncServiceLastAccessed(ctx, api, noopRecorder{}, arn, granularity, report)
+	require.NoError(t, err)
+	assert.Len(t, api.generated, 2)
+	assert.Equal(t, "job-1", *report.JobID)
+}
+
+func TestSyncServiceLastAccessed_Disabled(t *testing.T) {
+	api := &fakeServiceLastAccessedAPI{}
+	current := &svcapitypes.ServiceLastAccessedReport{JobID: aws.String("job-1")}
+
+	report, err := SyncServiceLastAccessed(context.TODO(), api, noopRecorder{}, "arn", nil, current)
+	require.NoError(t, err)
+	assert.Nil(t, report)
+	assert.Empty(t, api.generated)
+
+	_, err = SyncServiceLastAccessed(context.TODO(), api, noopRecorder{}, "arn", aws.String("RESOURCE_LEVEL"), current)
+	assert.Error(t, err)
+}
+
+func TestSetServiceLastAccessedConditions(t *testing.T) {
+	subject := &fakeConditionManager{}
+
+	// A pending job marks the resource as not synced, to requeue it.
+	SetServiceLastAccessedConditions(subject, &svcapitypes.ServiceLastAccessedReport{JobID: aws.String("job-1")}, nil)
+	require.NotNil(t, ackcondition.Synced(subject))
+	assert.Equal(t, corev1.ConditionFalse, ackcondition.Synced(subject).Status)
+
+	// Failures are reported as an advisory.
+	subject = &fakeConditionManager{}
+	SetServiceLastAccessedConditions(subject, nil, errors.New("AccessDenied"))
+	assert.Nil(t, ackcondition.Synced(subject))
+	c := ackcondition.AdvisoryWithReason(subject, ServiceLastAccessedFailedReason)
+	require.NotNil(t, c)
+	assert.Equal(t, "AccessDenied", *c.Message)
+
+	// A collected report leaves the Synced condition to the runtime and
+	// clears the advisory.
+	SetServiceLastAccessedConditions(subject, &svcapitypes.ServiceLastAccessedReport{}, nil)
+	assert.Nil(t, ackcondition.Synced(subject))
+	assert.Nil(t, ackcondition.AdvisoryWithReason(subject, ServiceLastAccessedFailedReason))
+}
@@ -0,0 +1,148 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"context"
+	"fmt"
+
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+)
+
+// MaxTagsPerRequest is the maximum number of tags, or tag keys, IAM accepts
+// in a single Tag* or Untag* call.
+const MaxTagsPerRequest = 50
+
+// TagClient is the subset of the IAM API used to tag the resources managed by
+// the controller. It is satisfied by *svcsdk.Client.
+type TagClient interface {
+	TagInstanceProfile(context.Context, *svcsdk.TagInstanceProfileInput, ...func(*svcsdk.Options)) (*svcsdk.TagInstanceProfileOutput, error)
+	UntagInstanceProfile(context.Context, *svcsdk.UntagInstanceProfileInput, ...func(*svcsdk.Options)) (*svcsdk.UntagInstanceProfileOutput, error)
+	TagOpenIDConnectProvider(context.Context, *svcsdk.TagOpenIDConnectProviderInput, ...func(*svcsdk.Options)) (*svcsdk.TagOpenIDConnectProviderOutput, error)
+	UntagOpenIDConnectProvider(context.Context, *svcsdk.UntagOpenIDConnectProviderInput, ...func(*svcsdk.Options)) (*svcsdk.UntagOpenIDConnectProviderOutput, error)
+	TagPolicy(context.Context, *svcsdk.TagPolicyInput, ...func(*svcsdk.Options)) (*svcsdk.TagPolicyOutput, error)
+	UntagPolicy(context.Context, *svcsdk.UntagPolicyInput, ...func(*svcsdk.Options)) (*svcsdk.UntagPolicyOutput, error)
+	TagRole(context.Context, *svcsdk.TagRoleInput, ...func(*svcsdk.Options)) (*svcsdk.TagRoleOutput, error)
+	UntagRole(context.Context, *svcsdk.UntagRoleInput, ...func(*svcsdk.Options)) (*svcsdk.UntagRoleOutput, error)
+	TagUser(context.Context, *svcsdk.TagUserInput, ...func(*svcsdk.Options)) (*svcsdk.TagUserOutput, error)
+	UntagUser(context.Context, *svcsdk.UntagUserInput, ...func(*svcsdk.Options)) (*svcsdk.UntagUserOutput, error)
+}
+
+// SyncTags calls the Tag* and Untag* APIs of the supplied resource type so
+// that the tags of the IAM resource identified by id go from latest to
+// desired. id is the name of roles, users and instance profiles, and the ARN
+// of policies and OpenID Connect providers.
+//
+// A tag whose value changed is only tagged again, which overwrites its value.
+// Removed keys are untagged first so that the resource never goes over the
+// IAM tag quota, and calls are batched by MaxTagsPerRequest.
+func SyncTags(
+	ctx context.Context,
+	client TagClient,
+	metrics APICallRecorder,
+	resourceType string,
+	id *string,
+	desired []*svcapitypes.Tag,
+	latest []*svcapitypes.Tag,
+) error {
+	addedOrUpdated, removed := computeTagsDelta(latest, desired)
+	for start := 0; start < len(removed); start += MaxTagsPerRequest {
+		keys := []string{}
+		for _, k := range removed[start:min(start+MaxTagsPerRequest, len(removed))] {
+			keys = append(keys, *k)
+		}
+		if err := untagResource(ctx, client, metrics, resourceType, id, keys); err != nil {
+			return err
+		}
+	}
+	for start := 0; start < len(addedOrUpdated); start += MaxTagsPerRequest {
+		tags := []svcsdktypes.Tag{}
+		for _, t := range addedOrUpdated[start:min(start+MaxTagsPerRequest, len(addedOrUpdated))] {
+			tags = append(tags, svcsdktypes.Tag{Key: t.Key, Value: t.Value})
+		}
+		if err := tagResource(ctx, client, metrics, resourceType, id, tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagResource calls the Tag* API of the supplied resource type.
+func tagResource(
+	ctx context.Context,
+	client TagClient,
+	metrics APICallRecorder,
+	resourceType string,
+	id *string,
+	tags []svcsdktypes.Tag,
+) (err error) {
+	var op string
+	switch resourceType {
+	case ARNResourceTypeInstanceProfile:
+		op = "TagInstanceProfile"
+		_, err = client.TagInstanceProfile(ctx, &svcsdk.TagInstanceProfileInput{InstanceProfileName: id, Tags: tags})
+	case ARNResourceTypeOIDCProvider:
+		op = "TagOpenIDConnectProvider"
+		_, err = client.TagOpenIDConnectProvider(ctx, &svcsdk.TagOpenIDConnectProviderInput{OpenIDConnectProviderArn: id, Tags: tags})
+	case ARNResourceTypePolicy:
+		op = "TagPolicy"
+		_, err = client.TagPolicy(ctx, &svcsdk.TagPolicyInput{PolicyArn: id, Tags: tags})
+	case ARNResourceTypeRole:
+		op = "TagRole"
+		_, err = client.TagRole(ctx, &svcsdk.TagRoleInput{RoleName: id, Tags: tags})
+	case ARNResourceTypeUser:
+		op = "TagUser"
+		_, err = client.TagUser(ctx, &svcsdk.TagUserInput{UserName: id, Tags: tags})
+	default:
+		return fmt.Errorf("tagging is not supported for resource type %q", resourceType)
+	}
+	metrics.RecordAPICall("UPDATE", op, err)
+	return err
+}
+
+// untagResource calls the Untag* API of the supplied resource type.
+func untagResource(
+	ctx context.Context,
+	client TagClient,
+	metrics APICallRecorder,
+	resourceType string,
+	id *string,
+	keys []string,
+) (err error) {
+	var op string
+	switch resourceType {
+	case ARNResourceTypeInstanceProfile:
+		op = "UntagInstanceProfile"
+		_, err = client.UntagInstanceProfile(ctx, &svcsdk.UntagInstanceProfileInput{InstanceProfileName: id, TagKeys: keys})
+	case ARNResourceTypeOIDCProvider:
+		op = "UntagOpenIDConnectProvider"
+		_, err = client.UntagOpenIDConnectProvider(ctx, &svcsdk.UntagOpenIDConnectProviderInput{OpenIDConnectProviderArn: id, TagKeys: keys})
+	case ARNResourceTypePolicy:
+		op = "UntagPolicy"
+		_, err = client.UntagPolicy(ctx, &svcsdk.UntagPolicyInput{PolicyArn: id, TagKeys: keys})
+	case ARNResourceTypeRole:
+		op = "UntagRole"
+		_, err = client.UntagRole(ctx, &svcsdk.UntagRoleInput{RoleName: id, TagKeys: keys})
+	case ARNResourceTypeUser:
+		op = "UntagUser"
+		_, err = client.UntagUser(ctx, &svcsdk.UntagUserInput{UserName: id, TagKeys: keys})
+	default:
+		return fmt.Errorf("untagging is not supported for resource type %q", resourceType)
+	}
+	metrics.RecordAPICall("UPDATE", op, err)
+	return err
+}
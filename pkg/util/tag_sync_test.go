@@ -0,0 +1,162 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+)
+
+const testTrustPolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole"}]}`
+
+// callRecorder records the IAM operations called.
+type callRecorder struct {
+	calls []string
+}
+
+func (r *callRecorder) RecordAPICall(_ string, op string, _ error) {
+	r.calls = append(r.calls, op)
+}
+
+func tag(key, value string) *svcapitypes.Tag {
+	return &svcapitypes.Tag{Key: aws.String(key), Value: aws.String(value)}
+}
+
+func sdkTags(tags []*svcapitypes.Tag) []svcsdktypes.Tag {
+	res := []svcsdktypes.Tag{}
+	for _, t := range tags {
+		res = append(res, svcsdktypes.Tag{Key: t.Key, Value: t.Value})
+	}
+	return res
+}
+
+// keys returns the supplied tags as sorted key=value strings.
+func keys(tags []svcsdktypes.Tag) []string {
+	res := []string{}
+	for _, t := range tags {
+		res = append(res, aws.ToString(t.Key)+"="+aws.ToString(t.Value))
+	}
+	sort.Strings(res)
+	return res
+}
+
+func TestSyncTags_ValueChange(t *testing.T) {
+	ctx := context.TODO()
+	client := svcsdk.NewFromConfig(fakeiam.New().Config())
+	latest := []*svcapitypes.Tag{tag("team", "a"), tag("env", "dev")}
+	desired := []*svcapitypes.Tag{tag("team", "b"), tag("env", "dev")}
+	_, err := client.CreateRole(ctx, &svcsdk.CreateRoleInput{
+		RoleName:                 aws.String("my-role"),
+		AssumeRolePolicyDocument: aws.String(testTrustPolicy),
+		Tags:                     sdkTags(latest),
+	})
+	require.NoError(t, err)
+
+	metrics := &callRecorder{}
+	err = SyncTags(ctx, client, metrics, ARNResourceTypeRole,
+		aws.String("my-role"), desired, latest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TagRole"}, metrics.calls)
+	resp, err := client.ListRoleTags(ctx, &svcsdk.ListRoleTagsInput{RoleName: aws.String("my-role")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"env=dev", "team=b"}, keys(resp.Tags))
+}
+
+func TestSyncTags_AddAndRemove(t *testing.T) {
+	ctx := context.TODO()
+	client := svcsdk.NewFromConfig(fakeiam.New().Config())
+	latest := []*svcapitypes.Tag{tag("team", "a"), tag("old", "x")}
+	desired := []*svcapitypes.Tag{tag("team", "a"), tag("new", "y")}
+	resp, err := client.CreatePolicy(ctx, &svcsdk.CreatePolicyInput{
+		PolicyName:     aws.String("my-policy"),
+		PolicyDocument: aws.String(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`),
+		Tags:           sdkTags(latest),
+	})
+	require.NoError(t, err)
+	arn := resp.Policy.Arn
+
+	metrics := &callRecorder{}
+	err = SyncTags(ctx, client, metrics, ARNResourceTypePolicy, arn, desired, latest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"UntagPolicy", "TagPolicy"}, metrics.calls)
+	tags, err := client.ListPolicyTags(ctx, &svcsdk.ListPolicyTagsInput{PolicyArn: arn})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new=y", "team=a"}, keys(tags.Tags))
+}
+
+func TestSyncTags_NoChange(t *testing.T) {
+	ctx := context.TODO()
+	client := svcsdk.NewFromConfig(fakeiam.New().Config())
+	tags := []*svcapitypes.Tag{tag("team", "a")}
+	_, err := client.CreateUser(ctx, &svcsdk.CreateUserInput{
+		UserName: aws.String("alice"),
+		Tags:     sdkTags(tags),
+	})
+	require.NoError(t, err)
+
+	metrics := &callRecorder{}
+	err = SyncTags(ctx, client, metrics, ARNResourceTypeUser,
+		aws.String("alice"), []*svcapitypes.Tag{tag("team", "a")}, tags)
+	require.NoError(t, err)
+	assert.Empty(t, metrics.calls)
+}
+
+func TestSyncTags_ReplaceAll(t *testing.T) {
+	ctx := context.TODO()
+	client := svcsdk.NewFromConfig(fakeiam.New().Config())
+	latest := []*svcapitypes.Tag{}
+	desired := []*svcapitypes.Tag{}
+	for i := 0; i < MaxTagsPerRequest; i++ {
+		latest = append(latest, tag(fmt.Sprintf("old-%d", i), "x"))
+		desired = append(desired, tag(fmt.Sprintf("new-%d", i), "y"))
+	}
+	_, err := client.CreateInstanceProfile(ctx, &svcsdk.CreateInstanceProfileInput{
+		InstanceProfileName: aws.String("nodes"),
+		Tags:                sdkTags(latest),
+	})
+	require.NoError(t, err)
+
+	// The old tags are removed first, as the instance profile can not carry
+	// both the old and the new ones.
+	metrics := &callRecorder{}
+	err = SyncTags(ctx, client, metrics, ARNResourceTypeInstanceProfile,
+		aws.String("nodes"), desired, latest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"UntagInstanceProfile", "TagInstanceProfile"}, metrics.calls)
+	resp, err := client.ListInstanceProfileTags(ctx, &svcsdk.ListInstanceProfileTagsInput{InstanceProfileName: aws.String("nodes")})
+	require.NoError(t, err)
+	assert.Len(t, resp.Tags, MaxTagsPerRequest)
+}
+
+func TestSyncTags_UnsupportedResourceType(t *testing.T) {
+	ctx := context.TODO()
+	client := svcsdk.NewFromConfig(fakeiam.New().Config())
+	_, err := client.CreateGroup(ctx, &svcsdk.CreateGroupInput{GroupName: aws.String("admins")})
+	require.NoError(t, err)
+
+	err = SyncTags(ctx, client, noopRecorder{}, ARNResourceTypeGroup,
+		aws.String("admins"), []*svcapitypes.Tag{tag("team", "a")}, nil)
+	assert.ErrorContains(t, err, "not supported")
+}
@@ -1,6 +1,10 @@
+	if err := rm.removeMembers(ctx, r); err != nil {
+		return nil, err
+	}
 	// This deletes all associated managed and inline policies from the user
 	groupCpy := r.ko.DeepCopy()
 	groupCpy.Spec.Policies = nil
+	groupCpy.Spec.AWSManagedPolicies = nil
 	if err := rm.syncManagedPolicies(ctx, &resource{ko: groupCpy}, r); err != nil {
 		return nil, err
 	}
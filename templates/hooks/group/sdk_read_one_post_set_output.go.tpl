@@ -2,7 +2,10 @@
 	if err != nil {
 		return nil, err
 	}
+	rm.splitAWSManagedPolicies(ctx, ko)
 	ko.Spec.InlinePolicies, err = rm.getInlinePolicies(ctx, &resource{ko})
 	if err != nil {
 		return nil, err
 	}
+	rm.setRenderedInlinePolicies(r, ko)
+	rm.syncServiceLastAccessed(ctx, ko)
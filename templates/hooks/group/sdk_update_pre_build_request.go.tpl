@@ -1,4 +1,4 @@
-	if delta.DifferentAt("Spec.Policies") {
+	if delta.DifferentAt("Spec.Policies") || delta.DifferentAt("Spec.AWSManagedPolicies") {
 		err = rm.syncManagedPolicies(ctx, desired, latest)
 		if err != nil {
 			return nil, err
@@ -10,6 +10,6 @@
 			return nil, err
 		}
 	}
-	if !delta.DifferentExcept("Spec.Tags", "Spec.Policies", "Spec.InlinePolicies", "Spec.PermissionsBoundary") {
+	if !delta.DifferentExcept("Spec.Tags", "Spec.Policies", "Spec.AWSManagedPolicies", "Spec.InlinePolicies", "Spec.PermissionsBoundary") {
 		return desired, nil
 	}
@@ -0,0 +1,9 @@
+	if err = applyClusterIssuer(ctx, desired); err != nil {
+		return nil, err
+	}
+	if err = observeThumbprint(ctx, desired); err != nil {
+		return nil, err
+	}
+	if desired.ko.Status.DiscoveredThumbprint != nil {
+		desired.ko.Spec.Thumbprints = []*string{desired.ko.Status.DiscoveredThumbprint}
+	}
@@ -2,4 +2,6 @@
 		return nil, err
 	} else {
 		ko.Spec.Tags = tags
-	}
\ No newline at end of file
+	}
+	refreshClusterIssuer(ctx, &resource{ko})
+	refreshThumbprint(ctx, &resource{ko})
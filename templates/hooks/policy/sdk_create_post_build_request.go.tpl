@@ -0,0 +1,3 @@
+	if input.PolicyDocument, err = rm.primaryPolicyDocument(desired); err != nil {
+		return nil, err
+	}
@@ -0,0 +1,5 @@
+    if splitOversizedDocument(desired) {
+        // The shards are tagged with the ARN of the Policy, so they are
+        // created on update.
+        return &resource{ko}, ackrequeue.Needed(fmt.Errorf("policy created, requeuing to create its shards"))
+    }
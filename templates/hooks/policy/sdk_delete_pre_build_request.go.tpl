@@ -1,8 +1,16 @@
+	// DeletePolicy also fails with DeleteConflict while the policy is attached
+	// to any entity or used as a permissions boundary.
+	if err = rm.prepareForDeletion(ctx, r); err != nil {
+		return r, err
+	}
+	if err = rm.deletePolicyShards(ctx, r); err != nil {
+		return r, err
+	}
 	// This is to avoid the following error:
 	//
 	// DeleteConflict: This policy has more than one version. Before you delete a
 	// policy, you must delete the policy's versions. The default version is
 	// deleted with the policy.
-	if err = rm.deleteNonDefaultPolicyVersions(ctx, r); err != nil {
+	if err = rm.deleteNonDefaultPolicyVersions(ctx, string(*r.ko.Status.ACKResourceMetadata.ARN)); err != nil {
 		return r, err
 	}
@@ -7,5 +7,14 @@
             return nil, err
         } else {
             ko.Spec.PolicyDocument = &pv.document
+            rm.setRenderedPolicyDocument(r, ko)
+            if inSync, err := rm.observePolicyShards(ctx, r, ko); err != nil {
+                return nil, err
+            } else if !inSync {
+                // Report the document read from IAM so that the shards are
+                // synced on update.
+                ko.Spec.PolicyDocument = &pv.document
+            }
         }
     }
+    rm.setAttachedEntities(ctx, ko)
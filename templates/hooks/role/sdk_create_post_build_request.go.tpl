@@ -0,0 +1,3 @@
+	if input.AssumeRolePolicyDocument, err = renderedAssumeRolePolicyDocument(desired, string(rm.awsPartition)); err != nil {
+		return nil, err
+	}
@@ -1,4 +1,8 @@
-    if ko.Spec.AssumeRolePolicyDocument != nil {
+    if hasRenderedTrust(desired) {
+        // Keep the hand-written trust policy in the spec. The statements
+        // rendered from ServiceAccountTrust and TrustPresets only live in IAM.
+        ko.Spec.AssumeRolePolicyDocument = desired.ko.Spec.AssumeRolePolicyDocument
+    } else if ko.Spec.AssumeRolePolicyDocument != nil {
         if doc, err := decodeDocument(*ko.Spec.AssumeRolePolicyDocument); err != nil {
             return nil, err
         } else {
@@ -1,10 +1,8 @@
-	// This deletes all associated managed and inline policies from the role
-	roleCpy := r.ko.DeepCopy()
-	roleCpy.Spec.Policies = nil
-	if err := rm.syncManagedPolicies(ctx, &resource{ko: roleCpy}, r); err != nil {
+	if err := rm.removeServiceAccountBindings(ctx, r); err != nil {
 		return nil, err
 	}
-	roleCpy.Spec.InlinePolicies = map[string]*string{}
-	if err := rm.syncInlinePolicies(ctx, &resource{ko: roleCpy}, r); err != nil {
-		return nil, err
+	// This removes the role from its instance profiles and deletes its
+	// permissions boundary and all associated managed and inline policies
+	if err := rm.cleanupBeforeDeletion(ctx, r); err != nil {
+		return r, err
 	}
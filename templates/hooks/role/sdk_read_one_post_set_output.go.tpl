@@ -9,11 +9,19 @@
 	if err != nil {
 		return nil, err
 	}
+	rm.splitAWSManagedPolicies(ctx, ko)
 	ko.Spec.InlinePolicies, err = rm.getInlinePolicies(ctx, &resource{ko})
 	if err != nil {
 		return nil, err
 	}
+	rm.setRenderedInlinePolicies(r, ko)
+	setDeletionBlocked(ko, checkDeletionPolicies(r.ko, ko))
 	ko.Spec.Tags, err = rm.getTags(ctx, &resource{ko})
 	if err != nil {
 		return nil, err
 	}
+	if err = rm.observeInstanceProfile(ctx, ko); err != nil {
+		return nil, err
+	}
+	rm.syncServiceLastAccessed(ctx, ko)
+	rm.syncPolicyRecommendation(ctx, ko)
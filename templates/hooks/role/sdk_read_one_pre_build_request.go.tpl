@@ -0,0 +1,5 @@
+	// The shards of the Policies referenced in PolicyRefs are declared, and
+	// attached, along with them.
+	if err = rm.declarePolicyShards(ctx, r.ko); err != nil {
+		return nil, err
+	}
@@ -1,4 +1,4 @@
-	if delta.DifferentAt("Spec.Policies") {
+	if delta.DifferentAt("Spec.Policies") || delta.DifferentAt("Spec.AWSManagedPolicies") {
 		err = rm.syncManagedPolicies(ctx, desired, latest)
 		if err != nil {
 			return nil, err
@@ -28,6 +28,18 @@
 			return nil, err
 		}
 	}
-	if !delta.DifferentExcept("Spec.Tags", "Spec.Policies", "Spec.InlinePolicies", "Spec.PermissionsBoundary", "Spec.AssumeRolePolicyDocument") {
+	if delta.DifferentAt("Spec.ServiceAccountBindings") {
+		err = rm.syncServiceAccountBindings(ctx, desired, latest)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if delta.DifferentAt("Spec.InstanceProfile") {
+		err = rm.syncInstanceProfile(ctx, desired, latest)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !delta.DifferentExcept("Spec.Tags", "Spec.Policies", "Spec.AWSManagedPolicies", "Spec.InlinePolicies", "Spec.PermissionsBoundary", "Spec.AssumeRolePolicyDocument", "Spec.ServiceAccountBindings", "Spec.InstanceProfile") {
 		return desired, nil
 	}
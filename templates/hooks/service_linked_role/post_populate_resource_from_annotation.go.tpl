@@ -1,5 +1,12 @@
-    tmp, ok := fields["roleName"]
+    if tmp, ok := fields["awsServiceName"]; ok {
+		r.ko.Spec.AWSServiceName = &tmp
+	}
+	tmp, ok := fields["roleName"]
 	if !ok {
-		return ackerrors.NewTerminalError(fmt.Errorf("required field missing: roleName"))
+		// The role is then looked up by its service name.
+		if r.ko.Spec.AWSServiceName != nil {
+			return nil
+		}
+		return ackerrors.NewTerminalError(fmt.Errorf("required field missing: roleName or awsServiceName"))
 	}
 	r.ko.Status.RoleName = &tmp
@@ -0,0 +1,6 @@
+	if len(desired.ko.Spec.Tags) > 0 {
+		if err = rm.syncTags(ctx, desired, &resource{ko}); err != nil {
+			return nil, err
+		}
+		ko.Spec.Tags = desired.ko.Spec.Tags
+	}
@@ -0,0 +1,5 @@
+	if err == nil && resp.DeletionTaskId != nil {
+		ko := r.ko.DeepCopy()
+		ko.Status.DeletionTaskID = resp.DeletionTaskId
+		return &resource{ko}, ackrequeue.NeededAfter(errDeletionInProgress, deletionTaskRequeueDelay)
+	}
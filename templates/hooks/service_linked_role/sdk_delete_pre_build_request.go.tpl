@@ -0,0 +1,3 @@
+	if r.ko.Status.DeletionTaskID != nil {
+		return rm.awaitDeletionTask(ctx, r)
+	}
@@ -1,10 +1,5 @@
-	// This deletes all associated managed and inline policies from the user
-	userCpy := r.ko.DeepCopy()
-	userCpy.Spec.Policies = nil
-	if err := rm.syncManagedPolicies(ctx, &resource{ko: userCpy}, r); err != nil {
-		return nil, err
-	}
-	userCpy.Spec.InlinePolicies = map[string]*string{}
-	if err := rm.syncInlinePolicies(ctx, &resource{ko: userCpy}, r); err != nil {
+	// This deletes all associated managed and inline policies from the user,
+	// and with the full deletion cleanup everything else blocking DeleteUser
+	if err := rm.cleanupBeforeDeletion(ctx, r); err != nil {
 		return nil, err
 	}
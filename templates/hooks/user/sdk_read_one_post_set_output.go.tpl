@@ -3,12 +3,15 @@
 	} else {
 		ko.Spec.Policies = policies
 	}
+	rm.splitAWSManagedPolicies(ctx, ko)
 	ko.Spec.InlinePolicies, err = rm.getInlinePolicies(ctx, &resource{ko})
 	if err != nil {
 		return nil, err
 	}
+	rm.setRenderedInlinePolicies(r, ko)
 	if tags, err := rm.getTags(ctx, &resource{ko}); err != nil {
 		return nil, err
 	} else {
 		ko.Spec.Tags = tags
 	}
+	rm.syncServiceLastAccessed(ctx, ko)
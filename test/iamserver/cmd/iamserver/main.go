@@ -0,0 +1,58 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Command iamserver serves the IAM query protocol from memory, for the
+// controller binary to run against without an AWS account:
+//
+//	iamserver --bind-address 127.0.0.1:8900 &
+//	controller --aws-region us-west-2 \
+//	    --aws-endpoint-url http://127.0.0.1:8900 \
+//	    --aws-identity-endpoint-url http://127.0.0.1:8900 \
+//	    --allow-unsafe-aws-endpoint-urls
+//
+// Any credentials are accepted. The IAM resources are lost when it exits.
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	flag "github.com/spf13/pflag"
+	"go.uber.org/zap/zapcore"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+	"github.com/aws-controllers-k8s/iam-controller/test/iamserver"
+)
+
+func main() {
+	var (
+		bindAddress string
+		verbose     bool
+	)
+	flag.StringVar(&bindAddress, "bind-address", "127.0.0.1:8900", "The address the server listens on.")
+	flag.BoolVarP(&verbose, "verbose", "v", false, "Log every request.")
+	flag.Parse()
+
+	level := zapcore.InfoLevel
+	if verbose {
+		level = zapcore.DebugLevel
+	}
+	log := zap.New(zap.WriteTo(os.Stderr), zap.Level(level))
+	log.Info("serving IAM", "address", bindAddress, "accountID", fakeiam.AccountID)
+	if err := http.ListenAndServe(bindAddress, iamserver.New(fakeiam.New(), log)); err != nil {
+		fmt.Fprintf(os.Stderr, "iamserver: %v\n", err)
+		os.Exit(1)
+	}
+}
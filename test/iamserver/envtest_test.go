@@ -0,0 +1,205 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package iamserver_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ackv1alpha1 "github.com/aws-controllers-k8s/runtime/apis/core/v1alpha1"
+	ackcfg "github.com/aws-controllers-k8s/runtime/pkg/config"
+	ackrt "github.com/aws-controllers-k8s/runtime/pkg/runtime"
+	acktypes "github.com/aws-controllers-k8s/runtime/pkg/types"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrlrt "sigs.k8s.io/controller-runtime"
+	ctrlrtclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	svcapitypes "github.com/aws-controllers-k8s/iam-controller/apis/v1alpha1"
+	svcresource "github.com/aws-controllers-k8s/iam-controller/pkg/resource"
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/group"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/instance_profile"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/open_id_connect_provider"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/policy"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/role"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/service_linked_role"
+	_ "github.com/aws-controllers-k8s/iam-controller/pkg/resource/user"
+)
+
+const (
+	eventuallyTimeout = 30 * time.Second
+	eventuallyTick    = 250 * time.Millisecond
+)
+
+// startController starts an API server with the controller's CRDs and the
+// controller, configured like the controller binary to call the supplied IAM
+// endpoint. It returns a client of the API server.
+//
+// It needs the envtest binaries, found through the KUBEBUILDER_ASSETS
+// environment variable.
+func startController(t *testing.T, endpointURL string) ctrlrtclient.Client {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set, install the envtest binaries with setup-envtest")
+	}
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths: []string{
+			filepath.Join("..", "..", "config", "crd", "bases"),
+			filepath.Join("..", "..", "config", "crd", "common", "bases"),
+		},
+		ErrorIfCRDPathMissing: true,
+	}
+	restCfg, err := testEnv.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, testEnv.Stop())
+	})
+
+	// Some resource managers read custom resources with their own client,
+	// built from the kubeconfig.
+	user, err := testEnv.AddUser(envtest.User{Name: "controller", Groups: []string{"system:masters"}}, nil)
+	require.NoError(t, err)
+	kubeconfig, err := user.KubeConfig()
+	require.NoError(t, err)
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfigPath, kubeconfig, 0o600))
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "SECRET")
+
+	ackCfg := ackcfg.Config{
+		Region:                         fakeiam.Region,
+		EndpointURL:                    endpointURL,
+		IdentityEndpointURL:            endpointURL,
+		AllowUnsafeEndpointURL:         true,
+		ReconcileDefaultMaxConcurrency: 1,
+	}
+	require.NoError(t, ackCfg.Validate(context.TODO()))
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, svcapitypes.AddToScheme(scheme))
+	require.NoError(t, ackv1alpha1.AddToScheme(scheme))
+	mgr, err := ctrlrt.NewManager(restCfg, ctrlrt.Options{
+		Scheme:  scheme,
+		Metrics: metricsserver.Options{BindAddress: "0"},
+	})
+	require.NoError(t, err)
+
+	sc := ackrt.NewServiceController(
+		"iam", "iam.services.k8s.aws", acktypes.VersionInfo{},
+	).WithLogger(
+		logr.Discard(),
+	).WithResourceManagerFactories(
+		svcresource.GetManagerFactories(),
+	).WithPrometheusRegistry(
+		prometheus.NewRegistry(),
+	)
+	require.NoError(t, sc.BindControllerManager(mgr, ackCfg))
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- mgr.Start(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-stopped)
+	})
+
+	c, err := ctrlrtclient.New(restCfg, ctrlrtclient.Options{Scheme: scheme})
+	require.NoError(t, err)
+	return c
+}
+
+// TestEnvtest_RoleWithPolicyRef runs the reconcile loops of a Role referencing
+// a Policy, from their creation to the removal of their finalizers.
+func TestEnvtest_RoleWithPolicyRef(t *testing.T) {
+	ctx := context.TODO()
+	srv, cfg := newServer(t)
+	c := startController(t, srv.URL)
+	iam := svcsdk.NewFromConfig(cfg)
+
+	policy := &svcapitypes.Policy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "default"},
+		Spec: svcapitypes.PolicySpec{
+			Name:           aws.String("test-policy"),
+			PolicyDocument: aws.String(policyDocument),
+		},
+	}
+	role := &svcapitypes.Role{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-role", Namespace: "default"},
+		Spec: svcapitypes.RoleSpec{
+			Name:                     aws.String("test-role"),
+			AssumeRolePolicyDocument: aws.String(trustPolicy),
+			PolicyRefs: []*ackv1alpha1.AWSResourceReferenceWrapper{
+				{From: &ackv1alpha1.AWSResourceReference{Name: aws.String("test-policy")}},
+			},
+		},
+	}
+	// The Role is created first, and waits for the Policy it references.
+	require.NoError(t, c.Create(ctx, role))
+	require.NoError(t, c.Create(ctx, policy))
+
+	policyARN := "arn:aws:iam::" + fakeiam.AccountID + ":policy/test-policy"
+	require.Eventually(t, func() bool {
+		out, err := iam.ListAttachedRolePolicies(ctx, &svcsdk.ListAttachedRolePoliciesInput{
+			RoleName: aws.String("test-role"),
+		})
+		return err == nil && len(out.AttachedPolicies) == 1 && *out.AttachedPolicies[0].PolicyArn == policyARN
+	}, eventuallyTimeout, eventuallyTick)
+
+	require.Eventually(t, func() bool {
+		latest := &svcapitypes.Role{}
+		if err := c.Get(ctx, ctrlrtclient.ObjectKeyFromObject(role), latest); err != nil {
+			return false
+		}
+		for _, cond := range latest.Status.Conditions {
+			if cond.Type == ackv1alpha1.ConditionTypeResourceSynced {
+				return cond.Status == "True"
+			}
+		}
+		return false
+	}, eventuallyTimeout, eventuallyTick)
+
+	// Deleting the custom resources deletes the IAM resources before the
+	// finalizers are removed.
+	require.NoError(t, c.Delete(ctx, role))
+	require.NoError(t, c.Delete(ctx, policy))
+	for _, obj := range []ctrlrtclient.Object{role, policy} {
+		require.Eventually(t, func() bool {
+			return apierrors.IsNotFound(c.Get(ctx, ctrlrtclient.ObjectKeyFromObject(obj), obj))
+		}, eventuallyTimeout, eventuallyTick)
+	}
+	_, err := iam.GetRole(ctx, &svcsdk.GetRoleInput{RoleName: aws.String("test-role")})
+	var notFound *svcsdktypes.NoSuchEntityException
+	assert.True(t, errors.As(err, &notFound))
+	_, err = iam.GetPolicy(ctx, &svcsdk.GetPolicyInput{PolicyArn: aws.String(policyARN)})
+	assert.True(t, errors.As(err, &notFound))
+}
@@ -0,0 +1,262 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package iamserver
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	metadataType = reflect.TypeOf(middleware.Metadata{})
+)
+
+// decode sets the supplied value from the query parameters of the supplied
+// name, which are named after the fields of the SDK structures, with lists
+// serialized as Name.member.N:
+//
+//	Action=TagRole&RoleName=my-role&Tags.member.1.Key=team&Tags.member.1.Value=iam
+//
+// Top-level structures are decoded with an empty name.
+func decode(v reflect.Value, name string, params url.Values) error {
+	if name != "" && !hasParam(params, name) {
+		return nil
+	}
+	if v.Type() == timeType {
+		t, err := time.Parse(time.RFC3339, params.Get(name))
+		if err != nil {
+			return paramError(name, params.Get(name), err)
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := decode(elem.Elem(), name, params); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.String:
+		v.SetString(params.Get(name))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(params.Get(name))
+		if err != nil {
+			return paramError(name, params.Get(name), err)
+		}
+		v.SetBool(b)
+	case reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(params.Get(name), 10, v.Type().Bits())
+		if err != nil {
+			return paramError(name, params.Get(name), err)
+		}
+		v.SetInt(n)
+	case reflect.Slice:
+		// An empty list is serialized as a parameter with an empty value.
+		list := reflect.MakeSlice(v.Type(), 0, 0)
+		for i := 1; ; i++ {
+			member := fmt.Sprintf("%s.member.%d", name, i)
+			if !hasParam(params, member) {
+				break
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := decode(elem, member, params); err != nil {
+				return err
+			}
+			list = reflect.Append(list, elem)
+		}
+		v.Set(list)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if !f.IsExported() || f.Type == metadataType {
+				continue
+			}
+			fieldName := f.Name
+			if name != "" {
+				fieldName = name + "." + f.Name
+			}
+			if err := decode(v.Field(i), fieldName, params); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("parameter %s of type %s is not supported", name, v.Type())
+	}
+	return nil
+}
+
+// hasParam returns true if there is a query parameter of the supplied name, or
+// nested under it.
+func hasParam(params url.Values, name string) bool {
+	for k := range params {
+		if k == name || strings.HasPrefix(k, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func paramError(name string, value string, err error) error {
+	if numErr, ok := err.(*strconv.NumError); ok {
+		err = numErr.Err
+	}
+	return fmt.Errorf("invalid value %q for parameter %s: %v", value, name, err)
+}
+
+// encodeResult returns the XML response of the supplied action, holding its
+// output:
+//
+//	<ActionResponse xmlns="...">
+//	  <ActionResult>...</ActionResult>
+//	  <ResponseMetadata><RequestId>...</RequestId></ResponseMetadata>
+//	</ActionResponse>
+func encodeResult(
+	action string,
+	namespace string,
+	requestID string,
+	output reflect.Value,
+) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	response := xml.StartElement{
+		Name: xml.Name{Local: action + "Response"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: namespace}},
+	}
+	if err := enc.EncodeToken(response); err != nil {
+		return nil, err
+	}
+	if err := encode(enc, action+"Result", output); err != nil {
+		return nil, err
+	}
+	if err := enc.EncodeElement(struct {
+		RequestID string `xml:"RequestId"`
+	}{requestID}, xml.StartElement{Name: xml.Name{Local: "ResponseMetadata"}}); err != nil {
+		return nil, err
+	}
+	if err := enc.EncodeToken(response.End()); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encode writes the supplied value as an element of the supplied name. Lists
+// are written as member elements and maps as entry elements. Nil pointers,
+// lists and maps are left out.
+func encode(enc *xml.Encoder, name string, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+	}
+	if v.Kind() == reflect.Ptr {
+		return encode(enc, name, v.Elem())
+	}
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	var err error
+	switch {
+	case v.Type() == timeType:
+		err = encodeText(enc, v.Interface().(time.Time).UTC().Format(time.RFC3339))
+	case v.Kind() == reflect.String:
+		err = encodeText(enc, v.String())
+	case v.Kind() == reflect.Bool:
+		err = encodeText(enc, strconv.FormatBool(v.Bool()))
+	case v.Kind() == reflect.Int32 || v.Kind() == reflect.Int64:
+		err = encodeText(enc, strconv.FormatInt(v.Int(), 10))
+	case v.Kind() == reflect.Slice:
+		for i := 0; i < v.Len() && err == nil; i++ {
+			err = encode(enc, "member", v.Index(i))
+		}
+	case v.Kind() == reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			if err = enc.EncodeToken(xml.StartElement{Name: xml.Name{Local: "entry"}}); err != nil {
+				return err
+			}
+			if err = encode(enc, "key", k); err != nil {
+				return err
+			}
+			if err = encode(enc, "value", v.MapIndex(k)); err != nil {
+				return err
+			}
+			err = enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "entry"}})
+		}
+	case v.Kind() == reflect.Struct:
+		for i := 0; i < v.NumField() && err == nil; i++ {
+			f := v.Type().Field(i)
+			if !f.IsExported() || f.Type == metadataType {
+				continue
+			}
+			err = encode(enc, f.Name, v.Field(i))
+		}
+	default:
+		err = fmt.Errorf("field %s of type %s is not supported", name, v.Type())
+	}
+	if err != nil {
+		return err
+	}
+	return enc.EncodeToken(start.End())
+}
+
+func encodeText(enc *xml.Encoder, text string) error {
+	return enc.EncodeToken(xml.CharData(text))
+}
+
+// encodeError returns the XML error response of the supplied error code and
+// message.
+func encodeError(code string, message string, fault smithy.ErrorFault, requestID string) []byte {
+	type errorDetail struct {
+		Type    string
+		Code    string
+		Message string
+	}
+	errType := "Sender"
+	if fault == smithy.FaultServer {
+		errType = "Receiver"
+	}
+	body, _ := xml.Marshal(struct {
+		XMLName   xml.Name `xml:"ErrorResponse"`
+		Namespace string   `xml:"xmlns,attr"`
+		Error     errorDetail
+		RequestID string `xml:"RequestId"`
+	}{
+		Namespace: iamNamespace,
+		Error:     errorDetail{Type: errType, Code: code, Message: message},
+		RequestID: requestID,
+	})
+	return body
+}
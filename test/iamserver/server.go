@@ -0,0 +1,180 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package iamserver serves the IAM query protocol over HTTP from an in-memory
+// fakeiam.Backend, so that the controller can be pointed at it with the
+// aws.endpoint_url setting and run against envtest without an AWS account:
+//
+//	srv := httptest.NewServer(iamserver.New(fakeiam.New(), log))
+//	ackCfg.EndpointURL = srv.URL
+//	ackCfg.IdentityEndpointURL = srv.URL
+//	ackCfg.AllowUnsafeEndpointURL = true
+//
+// The server also answers the STS GetCallerIdentity call the controller makes
+// on startup to find its account ID. Requests are not authenticated.
+package iamserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/go-logr/logr"
+
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+)
+
+const (
+	iamNamespace = "https://iam.amazonaws.com/doc/2010-05-08/"
+	stsNamespace = "https://sts.amazonaws.com/doc/2011-06-15/"
+)
+
+// Server is an http.Handler serving the IAM query protocol from a
+// fakeiam.Backend.
+type Server struct {
+	backend *fakeiam.Backend
+	// client is an IAM client calling the backend. Requests are decoded into
+	// the input of the client's method for their action, which is then
+	// called, so that inputs are validated as the SDK does before reaching
+	// IAM.
+	client *svcsdk.Client
+	log    logr.Logger
+	// requests counts the requests served, to make up request IDs.
+	requests atomic.Int64
+}
+
+// New returns a Server serving the IAM resources of the supplied Backend.
+func New(backend *fakeiam.Backend, log logr.Logger) *Server {
+	return &Server{
+		backend: backend,
+		client:  svcsdk.NewFromConfig(backend.Config()),
+		log:     log,
+	}
+}
+
+// Backend returns the Backend of the Server.
+func (s *Server) Backend() *fakeiam.Backend {
+	return s.backend
+}
+
+// ServeHTTP decodes the IAM action of the supplied request, runs it on the
+// Backend and writes its result or error as an XML document.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := fmt.Sprintf("00000000-0000-0000-0000-%012d", s.requests.Add(1))
+	if err := r.ParseForm(); err != nil {
+		s.writeError(w, requestID, "", queryError("MalformedQueryString", err.Error()))
+		return
+	}
+	action := r.Form.Get("Action")
+	if action == "GetCallerIdentity" {
+		s.writeResult(w, requestID, action, stsNamespace, reflect.ValueOf(&sts.GetCallerIdentityOutput{
+			Account: ptr(fakeiam.AccountID),
+			Arn:     ptr(fmt.Sprintf("arn:aws:iam::%s:root", fakeiam.AccountID)),
+			UserId:  ptr(fakeiam.AccountID),
+		}))
+		return
+	}
+
+	method := reflect.ValueOf(s.client).MethodByName(action)
+	if action == "" || !method.IsValid() || !s.implements(action) {
+		s.writeError(w, requestID, action, queryError(
+			"InvalidAction", fmt.Sprintf("Could not find operation %s for version 2010-05-08", action),
+		))
+		return
+	}
+	input := reflect.New(method.Type().In(1).Elem())
+	if err := decode(input.Elem(), "", r.Form); err != nil {
+		s.writeError(w, requestID, action, queryError("MalformedQueryString", err.Error()))
+		return
+	}
+	res := method.Call([]reflect.Value{reflect.ValueOf(r.Context()), input})
+	if err, _ := res[1].Interface().(error); err != nil {
+		s.writeError(w, requestID, action, err)
+		return
+	}
+	s.writeResult(w, requestID, action, iamNamespace, res[0])
+}
+
+// implements returns true if the Backend implements the supplied action.
+func (s *Server) implements(action string) bool {
+	for _, op := range s.backend.Operations() {
+		if op == action {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) writeResult(
+	w http.ResponseWriter,
+	requestID string,
+	action string,
+	namespace string,
+	output reflect.Value,
+) {
+	s.log.V(1).Info("served IAM request", "action", action, "requestID", requestID)
+	body, err := encodeResult(action, namespace, requestID, output)
+	if err != nil {
+		s.writeError(w, requestID, action, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (s *Server) writeError(
+	w http.ResponseWriter,
+	requestID string,
+	action string,
+	err error,
+) {
+	code, message, fault := "ServiceFailure", err.Error(), smithy.FaultServer
+	var apiErr smithy.APIError
+	var paramsErr smithy.InvalidParamsError
+	switch {
+	case errors.As(err, &apiErr):
+		code, message, fault = apiErr.ErrorCode(), apiErr.ErrorMessage(), apiErr.ErrorFault()
+	case errors.As(err, &paramsErr):
+		code, message, fault = "ValidationError", paramsErr.Error(), smithy.FaultClient
+	}
+	s.log.V(1).Info("IAM request failed", "action", action, "requestID", requestID, "code", code, "message", message)
+
+	status := http.StatusBadRequest
+	switch {
+	case fault == smithy.FaultServer:
+		status = http.StatusInternalServerError
+	case code == "NoSuchEntity":
+		status = http.StatusNotFound
+	case code == "EntityAlreadyExists" || code == "DeleteConflict":
+		status = http.StatusConflict
+	}
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write(encodeError(code, message, fault, requestID))
+}
+
+// queryError returns a client error of the supplied code and message about
+// the request itself rather than the IAM resources.
+func queryError(code string, message string) error {
+	return &smithy.GenericAPIError{Code: code, Message: message, Fault: smithy.FaultClient}
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -0,0 +1,228 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package iamserver_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	svcsdk "github.com/aws/aws-sdk-go-v2/service/iam"
+	svcsdktypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws-controllers-k8s/iam-controller/pkg/testutil/fakeiam"
+	"github.com/aws-controllers-k8s/iam-controller/test/iamserver"
+)
+
+const (
+	trustPolicy    = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole"}]}`
+	policyDocument = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`
+)
+
+// newServer returns an HTTP server serving a new Backend, and an aws.Config
+// whose clients call it.
+func newServer(t *testing.T) (*httptest.Server, aws.Config) {
+	srv := httptest.NewServer(iamserver.New(fakeiam.New(), logr.Discard()))
+	t.Cleanup(srv.Close)
+	return srv, aws.Config{
+		Region:       fakeiam.Region,
+		Credentials:  credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		BaseEndpoint: aws.String(srv.URL),
+	}
+}
+
+func TestServer_Role(t *testing.T) {
+	ctx := context.TODO()
+	_, cfg := newServer(t)
+	client := svcsdk.NewFromConfig(cfg)
+
+	created, err := client.CreateRole(ctx, &svcsdk.CreateRoleInput{
+		RoleName:                 aws.String("my-role"),
+		AssumeRolePolicyDocument: aws.String(trustPolicy),
+		Description:              aws.String("<escaped> & quoted"),
+		MaxSessionDuration:       aws.Int32(7200),
+		Tags: []svcsdktypes.Tag{
+			{Key: aws.String("team"), Value: aws.String("iam")},
+			{Key: aws.String("env"), Value: aws.String("test")},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:iam::"+fakeiam.AccountID+":role/my-role", *created.Role.Arn)
+
+	out, err := client.GetRole(ctx, &svcsdk.GetRoleInput{RoleName: aws.String("my-role")})
+	require.NoError(t, err)
+	assert.Equal(t, "<escaped> & quoted", *out.Role.Description)
+	assert.Equal(t, int32(7200), *out.Role.MaxSessionDuration)
+	assert.WithinDuration(t, time.Now(), *out.Role.CreateDate, time.Minute)
+	doc, err := url.QueryUnescape(*out.Role.AssumeRolePolicyDocument)
+	require.NoError(t, err)
+	assert.Equal(t, trustPolicy, doc)
+	assert.ElementsMatch(t, created.Role.Tags, out.Role.Tags)
+
+	_, err = client.PutRolePolicy(ctx, &svcsdk.PutRolePolicyInput{
+		RoleName:       aws.String("my-role"),
+		PolicyName:     aws.String("s3"),
+		PolicyDocument: aws.String(policyDocument),
+	})
+	require.NoError(t, err)
+	names, err := client.ListRolePolicies(ctx, &svcsdk.ListRolePoliciesInput{RoleName: aws.String("my-role")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s3"}, names.PolicyNames)
+	assert.False(t, names.IsTruncated)
+
+	_, err = client.DeleteRole(ctx, &svcsdk.DeleteRoleInput{RoleName: aws.String("my-role")})
+	var conflict *svcsdktypes.DeleteConflictException
+	assert.ErrorAs(t, err, &conflict)
+
+	_, err = client.GetRole(ctx, &svcsdk.GetRoleInput{RoleName: aws.String("other-role")})
+	var notFound *svcsdktypes.NoSuchEntityException
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestServer_PolicyVersions(t *testing.T) {
+	ctx := context.TODO()
+	_, cfg := newServer(t)
+	client := svcsdk.NewFromConfig(cfg)
+
+	created, err := client.CreatePolicy(ctx, &svcsdk.CreatePolicyInput{
+		PolicyName:     aws.String("my-policy"),
+		PolicyDocument: aws.String(policyDocument),
+	})
+	require.NoError(t, err)
+	_, err = client.CreatePolicyVersion(ctx, &svcsdk.CreatePolicyVersionInput{
+		PolicyArn:      created.Policy.Arn,
+		PolicyDocument: aws.String(policyDocument),
+		SetAsDefault:   true,
+	})
+	require.NoError(t, err)
+
+	versions, err := client.ListPolicyVersions(ctx, &svcsdk.ListPolicyVersionsInput{PolicyArn: created.Policy.Arn})
+	require.NoError(t, err)
+	require.Len(t, versions.Versions, 2)
+	assert.Equal(t, "v2", *versions.Versions[0].VersionId)
+	assert.True(t, versions.Versions[0].IsDefaultVersion)
+	assert.False(t, versions.Versions[1].IsDefaultVersion)
+
+	local, err := client.ListPolicies(ctx, &svcsdk.ListPoliciesInput{Scope: svcsdktypes.PolicyScopeTypeLocal})
+	require.NoError(t, err)
+	require.Len(t, local.Policies, 1)
+	assert.Equal(t, "v2", *local.Policies[0].DefaultVersionId)
+}
+
+func TestServer_OpenIDConnectProvider(t *testing.T) {
+	ctx := context.TODO()
+	_, cfg := newServer(t)
+	client := svcsdk.NewFromConfig(cfg)
+
+	created, err := client.CreateOpenIDConnectProvider(ctx, &svcsdk.CreateOpenIDConnectProviderInput{
+		Url:            aws.String("https://oidc.example.com"),
+		ClientIDList:   []string{"sts.amazonaws.com", "my-app"},
+		ThumbprintList: []string{strings.Repeat("a", 40)},
+	})
+	require.NoError(t, err)
+
+	out, err := client.GetOpenIDConnectProvider(ctx, &svcsdk.GetOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: created.OpenIDConnectProviderArn,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "oidc.example.com", *out.Url)
+	assert.Equal(t, []string{"sts.amazonaws.com", "my-app"}, out.ClientIDList)
+	assert.Equal(t, []string{strings.Repeat("a", 40)}, out.ThumbprintList)
+}
+
+func TestServer_GetCallerIdentity(t *testing.T) {
+	_, cfg := newServer(t)
+
+	out, err := sts.NewFromConfig(cfg).GetCallerIdentity(context.TODO(), &sts.GetCallerIdentityInput{})
+	require.NoError(t, err)
+	assert.Equal(t, fakeiam.AccountID, *out.Account)
+}
+
+func TestServer_QueryErrors(t *testing.T) {
+	srv, _ := newServer(t)
+
+	for _, tc := range []struct {
+		name   string
+		params url.Values
+		status int
+		code   string
+	}{
+		{
+			name:   "unknown action",
+			params: url.Values{"Action": {"CreateSAMLProvider"}, "Version": {"2010-05-08"}},
+			status: http.StatusBadRequest,
+			code:   "InvalidAction",
+		},
+		{
+			name:   "missing required parameter",
+			params: url.Values{"Action": {"GetRole"}, "Version": {"2010-05-08"}},
+			status: http.StatusBadRequest,
+			code:   "ValidationError",
+		},
+		{
+			name: "malformed parameter",
+			params: url.Values{
+				"Action":             {"UpdateRole"},
+				"RoleName":           {"my-role"},
+				"MaxSessionDuration": {"one hour"},
+			},
+			status: http.StatusBadRequest,
+			code:   "MalformedQueryString",
+		},
+		{
+			name:   "no such entity",
+			params: url.Values{"Action": {"GetRole"}, "RoleName": {"my-role"}},
+			status: http.StatusNotFound,
+			code:   "NoSuchEntity",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.PostForm(srv.URL, tc.params)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.status, resp.StatusCode)
+			assert.Contains(t, string(body), "<Code>"+tc.code+"</Code>")
+		})
+	}
+}
+
+func TestServer_ErrorCodes(t *testing.T) {
+	_, cfg := newServer(t)
+	client := svcsdk.NewFromConfig(cfg)
+
+	_, err := client.CreateRole(context.TODO(), &svcsdk.CreateRoleInput{
+		RoleName:                 aws.String("my-role"),
+		AssumeRolePolicyDocument: aws.String("{"),
+	})
+	var apiErr smithy.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "MalformedPolicyDocument", apiErr.ErrorCode())
+	assert.Equal(t, smithy.FaultClient, apiErr.ErrorFault())
+}